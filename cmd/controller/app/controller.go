@@ -28,14 +28,17 @@ import (
 	nuclioioclient "github.com/nuclio/nuclio/pkg/platform/kube/client/clientset/versioned"
 	"github.com/nuclio/nuclio/pkg/platform/kube/controller"
 	"github.com/nuclio/nuclio/pkg/platform/kube/functionres"
+	"github.com/nuclio/nuclio/pkg/platform/kube/gatewayapi"
 	"github.com/nuclio/nuclio/pkg/platform/kube/ingress"
 	"github.com/nuclio/nuclio/pkg/platformconfig"
 	// load all sinks
 	_ "github.com/nuclio/nuclio/pkg/sinks"
 
 	"github.com/nuclio/errors"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 func Run(kubeconfigPath string,
@@ -48,6 +51,9 @@ func Run(kubeconfigPath string,
 	functionMonitorIntervalStr,
 	cronJobStaleResourcesCleanupIntervalStr string,
 	evictedPodsCleanupIntervalStr string,
+	rolloutMonitorIntervalStr string,
+	resourceRecommendationIntervalStr string,
+	resourceRecommendationWindowStr string,
 	functionEventOperatorNumWorkersStr string,
 	projectOperatorNumWorkersStr string,
 	apiGatewayOperatorNumWorkersStr string) error {
@@ -62,6 +68,9 @@ func Run(kubeconfigPath string,
 		functionMonitorIntervalStr,
 		cronJobStaleResourcesCleanupIntervalStr,
 		evictedPodsCleanupIntervalStr,
+		rolloutMonitorIntervalStr,
+		resourceRecommendationIntervalStr,
+		resourceRecommendationWindowStr,
 		functionEventOperatorNumWorkersStr,
 		projectOperatorNumWorkersStr,
 		apiGatewayOperatorNumWorkersStr)
@@ -88,6 +97,9 @@ func createController(kubeconfigPath string,
 	functionMonitorIntervalStr string,
 	cronJobStaleResourcesCleanupIntervalStr string,
 	evictedPodsCleanupIntervalStr string,
+	rolloutMonitorIntervalStr string,
+	resourceRecommendationIntervalStr string,
+	resourceRecommendationWindowStr string,
 	functionEventOperatorNumWorkersStr string,
 	projectOperatorNumWorkersStr string,
 	apiGatewayOperatorNumWorkersStr string) (*controller.Controller, error) {
@@ -122,6 +134,21 @@ func createController(kubeconfigPath string,
 		return nil, errors.Wrap(err, "Failed to parse cron job stale pods deletion interval")
 	}
 
+	rolloutMonitorInterval, err := time.ParseDuration(rolloutMonitorIntervalStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse rollout monitor interval")
+	}
+
+	resourceRecommendationInterval, err := time.ParseDuration(resourceRecommendationIntervalStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse resource recommendation interval")
+	}
+
+	resourceRecommendationWindow, err := time.ParseDuration(resourceRecommendationWindowStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse resource recommendation window")
+	}
+
 	projectOperatorNumWorkers, err := strconv.Atoi(projectOperatorNumWorkersStr)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to resolve number of workers for project operator")
@@ -159,8 +186,20 @@ func createController(kubeconfigPath string,
 		return nil, errors.Wrap(err, "Failed to create nuclio client set")
 	}
 
+	// used to read the resource usage of function pods
+	metricsClientSet, err := metricsclientset.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create metrics client set")
+	}
+
+	// used to manage custom resources of other operators (e.g. keda scaled objects)
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create dynamic client")
+	}
+
 	// create a client for function deployments
-	functionresClient, err := functionres.NewLazyClient(rootLogger, kubeClientSet, nuclioClientSet)
+	functionresClient, err := functionres.NewLazyClient(rootLogger, kubeClientSet, nuclioClientSet, dynamicClient)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to create function deployment client")
 	}
@@ -177,8 +216,19 @@ func createController(kubeconfigPath string,
 		return nil, errors.Wrap(err, "Failed to create ingress manager")
 	}
 
+	// create gateway API route manager
+	httpRouteManager, err := gatewayapi.NewManager(rootLogger, dynamicClient)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create gateway API route manager")
+	}
+
 	// create api gateway provisioner
-	apigatewayresClient, err := apigatewayres.NewLazyClient(rootLogger, kubeClientSet, nuclioClientSet, ingressManager)
+	apigatewayresClient, err := apigatewayres.NewLazyClient(rootLogger,
+		kubeClientSet,
+		nuclioClientSet,
+		ingressManager,
+		httpRouteManager,
+		platformConfiguration)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to create api gateway provisioner")
 	}
@@ -192,10 +242,14 @@ func createController(kubeconfigPath string,
 		nuclioClientSet,
 		functionresClient,
 		apigatewayresClient,
+		metricsClientSet,
 		resyncInterval,
 		functionMonitorInterval,
 		cronJobStaleResourcesCleanupInterval,
 		evictedPodsCleanupInterval,
+		rolloutMonitorInterval,
+		resourceRecommendationInterval,
+		resourceRecommendationWindow,
 		platformConfiguration,
 		platformConfigurationName,
 		functionOperatorNumWorkers,
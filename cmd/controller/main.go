@@ -55,6 +55,9 @@ func main() {
 	functionMonitorIntervalStr := flag.String("function-monitor-interval", common.GetEnvOrDefaultString("NUCLIO_CONTROLLER_FUNCTION_MONITOR_INTERVAL", "3m"), "Set function monitor interval (optional)")
 	cronJobStaleResourcesCleanupIntervalStr := flag.String("cron-job-stale-resources-cleanup-interval", common.GetEnvOrDefaultString("NUCLIO_CONTROLLER_CRON_JOB_STALE_RESOURCES_CLEANUP_INTERVAL", "1m"), "Set interval for the cleanup of stale cron job resources (optional)")
 	evictedPodsCleanupIntervalStr := flag.String("evicted-pods-cleanup-interval", common.GetEnvOrDefaultString("NUCLIO_CONTROLLER_EVICTED_PODS_CLEANUP_INTERVAL", "30m"), "Set interval for the cleanup of evicted function pods (optional)")
+	rolloutMonitorIntervalStr := flag.String("rollout-monitor-interval", common.GetEnvOrDefaultString("NUCLIO_CONTROLLER_ROLLOUT_MONITOR_INTERVAL", "10s"), "Set interval for advancing function rollouts (optional)")
+	resourceRecommendationIntervalStr := flag.String("resource-recommendation-interval", common.GetEnvOrDefaultString("NUCLIO_CONTROLLER_RESOURCE_RECOMMENDATION_INTERVAL", "1m"), "Set interval for sampling function resource usage, 0 to disable resource recommendations (optional)")
+	resourceRecommendationWindowStr := flag.String("resource-recommendation-window", common.GetEnvOrDefaultString("NUCLIO_CONTROLLER_RESOURCE_RECOMMENDATION_WINDOW", "24h"), "Set the window of function resource usage that resource recommendations are based on (optional)")
 	functionEventOperatorNumWorkersStr := flag.String("function-event-operator-num-workers", common.GetEnvOrDefaultString("NUCLIO_CONTROLLER_FUNCTION_EVENT_OPERATOR_NUM_WORKERS", "2"), "Set number of workers for the function event operator (optional)")
 	projectOperatorNumWorkersStr := flag.String("project-operator-num-workers", common.GetEnvOrDefaultString("NUCLIO_CONTROLLER_PROJECT_OPERATOR_NUM_WORKERS", "2"), "Set number of workers for the project operator (optional)")
 	apiGatewayOperatorNumWorkersStr := flag.String("api-gateway-operator-num-workers", common.GetEnvOrDefaultString("NUCLIO_CONTROLLER_API_GATEWAY_OPERATOR_NUM_WORKERS", "2"), "Set number of workers for the api gateway operator (optional)")
@@ -74,6 +77,9 @@ func main() {
 		*functionMonitorIntervalStr,
 		*cronJobStaleResourcesCleanupIntervalStr,
 		*evictedPodsCleanupIntervalStr,
+		*rolloutMonitorIntervalStr,
+		*resourceRecommendationIntervalStr,
+		*resourceRecommendationWindowStr,
 		*functionEventOperatorNumWorkersStr,
 		*projectOperatorNumWorkersStr,
 		*apiGatewayOperatorNumWorkersStr); err != nil {
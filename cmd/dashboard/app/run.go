@@ -248,7 +248,7 @@ func newDashboardServer(createDashboardServerOptions *CreateDashboardServerOptio
 			"templatesGitRef", createDashboardServerOptions.templatesGitRef)
 
 		// attach credentials if given
-		templatesGitRepository := attachCredentialsToGitRepository(createDashboardServerOptions.logger,
+		templatesGitRepository := functiontemplates.AttachCredentialsToGitRepository(createDashboardServerOptions.logger,
 			createDashboardServerOptions.templatesGitRepository,
 			createDashboardServerOptions.templatesGitUsername,
 			createDashboardServerOptions.templatesGitPassword,
@@ -420,21 +420,3 @@ func getDefaultCredRefreshInterval(logger logger.Logger, defaultCredRefreshInter
 
 	return &defaultCredRefreshInterval
 }
-
-// create new repo URL with the credentials inside of it (when credentials are passed)
-// example: https://github.com/owner/repo.git -> https://<USERNAME>:<PASSWORD>@github.com/owner/repo.git
-func attachCredentialsToGitRepository(logger logger.Logger, repo, username, password, accessToken string) string {
-	if accessToken != "" {
-		username = accessToken
-		password = "x-oauth-basic"
-	} else if username == "" || password == "" {
-		return repo
-	}
-
-	splitRepo := strings.Split(repo, "//")
-	if len(splitRepo) != 2 {
-		logger.WarnWith("Unknown git repository structure. Skipping credentials attachment", "repo", repo)
-		return repo
-	}
-	return strings.Join([]string{splitRepo[0], "//", username, ":", password, "@", splitRepo[1]}, "")
-}
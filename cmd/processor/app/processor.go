@@ -36,6 +36,12 @@ import (
 	"github.com/nuclio/nuclio/pkg/processor"
 	"github.com/nuclio/nuclio/pkg/processor/config"
 	"github.com/nuclio/nuclio/pkg/processor/controlcommunication"
+	// load all data bindings
+	_ "github.com/nuclio/nuclio/pkg/processor/databinding/eventhub"
+	_ "github.com/nuclio/nuclio/pkg/processor/databinding/redis"
+	_ "github.com/nuclio/nuclio/pkg/processor/databinding/s3"
+	_ "github.com/nuclio/nuclio/pkg/processor/databinding/sql"
+	_ "github.com/nuclio/nuclio/pkg/processor/databinding/v3io"
 	"github.com/nuclio/nuclio/pkg/processor/healthcheck"
 	"github.com/nuclio/nuclio/pkg/processor/metricsink"
 	"github.com/nuclio/nuclio/pkg/processor/runtime"
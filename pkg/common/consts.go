@@ -38,6 +38,12 @@ const NuclioResourceLabelKeyProjectName = "nuclio.io/project-name"
 const NuclioResourceLabelKeyFunctionName = "nuclio.io/function-name"
 const NuclioResourceLabelKeyApiGatewayName = "nuclio.io/apigateway-name"
 const NuclioResourceLabelKeyVolumeName = "nuclio.io/volume-name"
+const NuclioResourceLabelKeyFunctionRevision = "nuclio.io/function-revision"
+const NuclioResourceLabelKeyApplySet = "nuclio.io/apply-set"
+
+// annotations with which "nuctl apply" tracks what it last applied to a function
+const NuclioResourceAnnotationKeyAppliedConfigHash = "nuclio.io/applied-config-hash"
+const NuclioResourceAnnotationKeyAppliedBuildHash = "nuclio.io/applied-build-hash"
 
 // KubernetesDomainLevelMaxLength DNS domain level limitation is 63 chars
 // https://en.wikipedia.org/wiki/Subdomain#Overview
@@ -428,6 +428,22 @@ func CompileImageName(registryURL string, imageName string) string {
 	return strings.TrimSuffix(registryURL, "/") + "/" + imageName
 }
 
+// PinImageDigest replaces the tag (or digest) of an image with the given digest
+// e.g. registry:5000/image:latest -> registry:5000/image@sha256:...
+func PinImageDigest(image string, digest string) string {
+	repository := image
+	if digestIndex := strings.Index(repository, "@"); digestIndex != -1 {
+		repository = repository[:digestIndex]
+	}
+
+	// a colon after the last slash separates the tag, one before it separates the registry port
+	if tagIndex := strings.LastIndex(repository, ":"); tagIndex > strings.LastIndex(repository, "/") {
+		repository = repository[:tagIndex]
+	}
+
+	return repository + "@" + digest
+}
+
 func AnyPositiveInSliceInt64(numbers []int64) bool {
 	for _, number := range numbers {
 		if number >= 0 {
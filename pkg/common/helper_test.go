@@ -444,6 +444,27 @@ func (suite *LabelsMapMatcherTestSuite) Test() {
 	}
 }
 
+type PinImageDigestTestSuite struct {
+	suite.Suite
+}
+
+func (suite *PinImageDigestTestSuite) Test() {
+	for _, testCase := range []struct {
+		image         string
+		expectedImage string
+	}{
+		{image: "image", expectedImage: "image@sha256:1234"},
+		{image: "image:latest", expectedImage: "image@sha256:1234"},
+		{image: "registry:5000/image", expectedImage: "registry:5000/image@sha256:1234"},
+		{image: "registry:5000/project/image:1.0", expectedImage: "registry:5000/project/image@sha256:1234"},
+		{image: "image:latest@sha256:abcd", expectedImage: "image@sha256:1234"},
+	} {
+		suite.Run(testCase.image, func() {
+			suite.Require().Equal(testCase.expectedImage, PinImageDigest(testCase.image, "sha256:1234"))
+		})
+	}
+}
+
 func TestHelperTestSuite(t *testing.T) {
 	suite.Run(t, new(RetryUntilSuccessfulTestSuite))
 	suite.Run(t, new(RetryUntilSuccessfulOnErrorPatternsTestSuite))
@@ -453,4 +474,5 @@ func TestHelperTestSuite(t *testing.T) {
 	suite.Run(t, new(IsFileTestSuite))
 	suite.Run(t, new(StripPrefixesTestSuite))
 	suite.Run(t, new(LabelsMapMatcherTestSuite))
+	suite.Run(t, new(PinImageDigestTestSuite))
 }
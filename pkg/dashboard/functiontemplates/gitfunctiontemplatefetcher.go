@@ -261,3 +261,21 @@ func (gftf *GitFunctionTemplateFetcher) getFileFromTreeEntries(entries *object.T
 		}
 	}
 }
+
+// AttachCredentialsToGitRepository creates a new repo URL with the credentials inside of it (when credentials are passed)
+// example: https://github.com/owner/repo.git -> https://<USERNAME>:<PASSWORD>@github.com/owner/repo.git
+func AttachCredentialsToGitRepository(logger logger.Logger, repo, username, password, accessToken string) string {
+	if accessToken != "" {
+		username = accessToken
+		password = "x-oauth-basic"
+	} else if username == "" || password == "" {
+		return repo
+	}
+
+	splitRepo := strings.Split(repo, "//")
+	if len(splitRepo) != 2 {
+		logger.WarnWith("Unknown git repository structure. Skipping credentials attachment", "repo", repo)
+		return repo
+	}
+	return strings.Join([]string{splitRepo[0], "//", username, ":", password, "@", splitRepo[1]}, "")
+}
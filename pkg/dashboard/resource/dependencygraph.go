@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"net/http"
+
+	"github.com/nuclio/nuclio/pkg/dashboard"
+	"github.com/nuclio/nuclio/pkg/opa"
+	"github.com/nuclio/nuclio/pkg/platform"
+	"github.com/nuclio/nuclio/pkg/restful"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/nuclio-sdk-go"
+)
+
+type dependencyGraphResource struct {
+	*resource
+}
+
+func (dgr *dependencyGraphResource) ExtendMiddlewares() error {
+	dgr.resource.addAuthMiddleware(nil)
+	return nil
+}
+
+// GetAll returns the dependency graph of the namespace, or of a project when the project name header is set
+func (dgr *dependencyGraphResource) GetAll(request *http.Request) (map[string]restful.Attributes, error) {
+	ctx := request.Context()
+
+	// get namespace
+	namespace := dgr.getNamespaceFromRequest(request)
+	if namespace == "" {
+		return nil, nuclio.NewErrBadRequest("Namespace must exist")
+	}
+
+	dependencyGraph, err := dgr.getPlatform().GetDependencyGraph(ctx, &platform.GetDependencyGraphOptions{
+		Namespace:   namespace,
+		ProjectName: request.Header.Get("x-nuclio-project-name"),
+		AuthSession: dgr.getCtxSession(ctx),
+		PermissionOptions: opa.PermissionOptions{
+			MemberIds:           opa.GetUserAndGroupIdsFromAuthSession(dgr.getCtxSession(ctx)),
+			OverrideHeaderValue: request.Header.Get(opa.OverrideHeader),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get dependency graph")
+	}
+
+	response := map[string]restful.Attributes{
+		"dependencyGraph": {
+			"nodes": dependencyGraph.Nodes,
+			"edges": dependencyGraph.Edges,
+		},
+	}
+
+	return response, nil
+}
+
+func (dgr *dependencyGraphResource) getNamespaceFromRequest(request *http.Request) string {
+	return dgr.getNamespaceOrDefault(request.Header.Get("x-nuclio-project-namespace"))
+}
+
+// register the resource
+var dependencyGraphResourceInstance = &dependencyGraphResource{
+	resource: newResource("api/dependency_graph", []restful.ResourceMethod{
+		restful.ResourceMethodGetList,
+	}),
+}
+
+func init() {
+	dependencyGraphResourceInstance.Resource = dependencyGraphResourceInstance
+	dependencyGraphResourceInstance.Register(dashboard.DashboardResourceRegistrySingleton)
+}
@@ -23,6 +23,7 @@ import (
 	"io"
 	"net/http"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"time"
 
@@ -200,6 +201,16 @@ func (fr *functionResource) GetCustomRoutes() ([]restful.CustomRoute, error) {
 			StreamRouteFunc: fr.getFunctionLogs,
 			Stream:          true,
 		},
+		{
+			Pattern:   "/{id}/revisions",
+			Method:    http.MethodGet,
+			RouteFunc: fr.getFunctionRevisions,
+		},
+		{
+			Pattern:   "/{id}/revisions/diff",
+			Method:    http.MethodGet,
+			RouteFunc: fr.getFunctionRevisionsDiff,
+		},
 	}, nil
 }
 
@@ -407,6 +418,131 @@ func (fr *functionResource) getFunctionReplicas(request *http.Request) (
 	}, nil
 }
 
+func (fr *functionResource) getFunctionRevisions(request *http.Request) (
+	*restful.CustomRouteFuncResponse, error) {
+
+	functionRevisions, err := fr.getFunctionRevisionsFromRequest(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get function revisions")
+	}
+
+	resources := map[string]restful.Attributes{}
+	for _, functionRevision := range functionRevisions {
+		resources[strconv.Itoa(functionRevision.Revision)] = restful.Attributes{
+			"revision":    functionRevision.Revision,
+			"config":      functionRevision.Config,
+			"image":       functionRevision.Image,
+			"imageDigest": functionRevision.ImageDigest,
+			"deployedBy":  functionRevision.DeployedBy,
+			"deployedAt":  functionRevision.DeployedAt,
+		}
+	}
+
+	return &restful.CustomRouteFuncResponse{
+		Resources:  resources,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		StatusCode: http.StatusOK,
+	}, nil
+}
+
+// getFunctionRevisionsDiff returns the fields that changed from one revision to another (the latest by default)
+func (fr *functionResource) getFunctionRevisionsDiff(request *http.Request) (
+	*restful.CustomRouteFuncResponse, error) {
+
+	fromRevision, err := strconv.Atoi(request.URL.Query().Get("from"))
+	if err != nil {
+		return nil, nuclio.NewErrBadRequest("A numeric \"from\" revision must be provided")
+	}
+
+	toRevision := 0
+	if toRevisionStr := request.URL.Query().Get("to"); toRevisionStr != "" {
+		toRevision, err = strconv.Atoi(toRevisionStr)
+		if err != nil {
+			return nil, nuclio.NewErrBadRequest("The \"to\" revision must be numeric")
+		}
+	}
+
+	functionRevisions, err := fr.getFunctionRevisionsFromRequest(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get function revisions")
+	}
+
+	if len(functionRevisions) == 0 {
+		return nil, nuclio.NewErrNotFound("Function has no revisions")
+	}
+
+	// revisions are sorted newest first
+	if toRevision == 0 {
+		toRevision = functionRevisions[0].Revision
+	}
+
+	var fromFunctionRevision, toFunctionRevision *platform.FunctionRevision
+	for _, functionRevision := range functionRevisions {
+		switch functionRevision.Revision {
+		case fromRevision:
+			fromFunctionRevision = functionRevision
+		case toRevision:
+			toFunctionRevision = functionRevision
+		}
+	}
+
+	// diffing a revision with itself is allowed, and yields nothing
+	if fromRevision == toRevision {
+		toFunctionRevision = fromFunctionRevision
+	}
+
+	if fromFunctionRevision == nil || toFunctionRevision == nil {
+		return nil, nuclio.NewErrNotFound("Function revision not found")
+	}
+
+	differences, err := functionconfig.DiffConfigs(&fromFunctionRevision.Config, &toFunctionRevision.Config)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to diff function revisions")
+	}
+
+	if differences == nil {
+		differences = []functionconfig.ConfigDifference{}
+	}
+
+	return &restful.CustomRouteFuncResponse{
+		Resources: map[string]restful.Attributes{
+			"diff": {
+				"from":        fromRevision,
+				"to":          toRevision,
+				"differences": differences,
+			},
+		},
+		Single:     true,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		StatusCode: http.StatusOK,
+	}, nil
+}
+
+func (fr *functionResource) getFunctionRevisionsFromRequest(request *http.Request) (
+	[]*platform.FunctionRevision, error) {
+
+	// ensure namespace
+	namespace := fr.getNamespaceFromRequest(request)
+	if namespace == "" {
+		return nil, nuclio.NewErrBadRequest("Namespace must exist")
+	}
+
+	// ensure function name
+	functionName := fr.GetRouterURLParam(request, "id")
+	if functionName == "" {
+		return nil, nuclio.NewErrBadRequest("Function name must not be empty")
+	}
+
+	getFunctionsOptions := fr.resolveGetFunctionOptionsFromRequest(request, functionName, true)
+
+	return fr.getPlatform().GetFunctionRevisions(request.Context(), &platform.GetFunctionRevisionsOptions{
+		Name:              functionName,
+		Namespace:         namespace,
+		PermissionOptions: getFunctionsOptions.PermissionOptions,
+		AuthSession:       getFunctionsOptions.AuthSession,
+	})
+}
+
 func (fr *functionResource) deleteFunction(request *http.Request) (*restful.CustomRouteFuncResponse, error) {
 	ctx := request.Context()
 
@@ -443,6 +579,9 @@ func (fr *functionResource) deleteFunction(request *http.Request) (*restful.Cust
 
 	deleteFunctionOptions.FunctionConfig.Meta = *functionInfo.Meta
 
+	// resolve the resources that depend on the function before it's gone, to warn the caller about them
+	functionDependents := fr.getFunctionDependents(ctx, &deleteFunctionOptions)
+
 	if err := fr.getPlatform().DeleteFunction(ctx, &deleteFunctionOptions); err != nil {
 		return &restful.CustomRouteFuncResponse{
 			Single:     true,
@@ -450,11 +589,48 @@ func (fr *functionResource) deleteFunction(request *http.Request) (*restful.Cust
 		}, err
 	}
 
-	return &restful.CustomRouteFuncResponse{
+	response := &restful.CustomRouteFuncResponse{
 		ResourceType: "function",
 		Single:       true,
 		StatusCode:   http.StatusNoContent,
-	}, nil
+	}
+
+	if len(functionDependents) > 0 {
+		var encodedFunctionDependents []string
+		for _, functionDependent := range functionDependents {
+			encodedFunctionDependents = append(encodedFunctionDependents, functionDependent.String())
+		}
+
+		fr.Logger.WarnWithCtx(ctx,
+			"Deleted a function that other resources depend on",
+			"name", functionInfo.Meta.Name,
+			"namespace", functionInfo.Meta.Namespace,
+			"dependents", strings.Join(encodedFunctionDependents, ", "))
+
+		response.Headers = map[string]string{
+			"x-nuclio-function-dependents": strings.Join(encodedFunctionDependents, ", "),
+		}
+	}
+
+	return response, nil
+}
+
+// getFunctionDependents returns the resources that depend on the function to be deleted. since it only serves
+// as a warning, failing to resolve them doesn't fail the deletion
+func (fr *functionResource) getFunctionDependents(ctx context.Context,
+	deleteFunctionOptions *platform.DeleteFunctionOptions) []platform.FunctionDependent {
+
+	dependencyGraph, err := fr.getPlatform().GetDependencyGraph(ctx, &platform.GetDependencyGraphOptions{
+		Namespace:         deleteFunctionOptions.FunctionConfig.Meta.Namespace,
+		PermissionOptions: deleteFunctionOptions.PermissionOptions,
+		AuthSession:       deleteFunctionOptions.AuthSession,
+	})
+	if err != nil {
+		fr.Logger.DebugWithCtx(ctx, "Failed to get dependency graph", "err", err.Error())
+		return nil
+	}
+
+	return dependencyGraph.GetFunctionDependents(deleteFunctionOptions.FunctionConfig.Meta.Name)
 }
 
 func (fr *functionResource) functionToAttributes(function platform.Function) restful.Attributes {
@@ -35,6 +35,7 @@ import (
 	"time"
 
 	"github.com/nuclio/nuclio/pkg/auth"
+	"github.com/nuclio/nuclio/pkg/common"
 	"github.com/nuclio/nuclio/pkg/dashboard"
 	"github.com/nuclio/nuclio/pkg/dashboard/functiontemplates"
 	_ "github.com/nuclio/nuclio/pkg/dashboard/resource"
@@ -362,6 +363,126 @@ func (suite *functionTestSuite) TestGetDetailSuccessful() {
 	suite.mockPlatform.AssertExpectations(suite.T())
 }
 
+func (suite *functionTestSuite) TestGetRevisionsSuccessful() {
+	functionRevision := &platform.FunctionRevision{
+		Revision:   2,
+		Image:      "my-image:2",
+		DeployedBy: "some-user",
+		DeployedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	functionRevision.Config.Meta.Name = "f1"
+	functionRevision.Config.Spec.Image = "my-image:2"
+
+	verifyGetFunctionRevisions := func(getFunctionRevisionsOptions *platform.GetFunctionRevisionsOptions) bool {
+		suite.Require().Equal("f1", getFunctionRevisionsOptions.Name)
+		suite.Require().Equal("f1-namespace", getFunctionRevisionsOptions.Namespace)
+
+		return true
+	}
+
+	suite.mockPlatform.
+		On("GetFunctionRevisions", mock.Anything, mock.MatchedBy(verifyGetFunctionRevisions)).
+		Return([]*platform.FunctionRevision{functionRevision}, nil).
+		Once()
+
+	headers := map[string]string{
+		"x-nuclio-function-namespace": "f1-namespace",
+	}
+
+	expectedStatusCode := http.StatusOK
+	expectedResponseBody := `{
+	"2": {
+		"revision": 2,
+		"config": {
+			"metadata": {
+				"name": "f1"
+			},
+			"spec": {
+				"image": "my-image:2",
+				"resources": {},
+				"build": {},
+				"platform": {},
+				"eventTimeout": ""
+			}
+		},
+		"image": "my-image:2",
+		"imageDigest": "",
+		"deployedBy": "some-user",
+		"deployedAt": "2023-01-01T00:00:00Z"
+	}
+}`
+
+	suite.sendRequest("GET",
+		"/api/functions/f1/revisions",
+		headers,
+		nil,
+		&expectedStatusCode,
+		expectedResponseBody)
+
+	suite.mockPlatform.AssertExpectations(suite.T())
+}
+
+func (suite *functionTestSuite) TestGetRevisionsDiffSuccessful() {
+	var functionRevisions []*platform.FunctionRevision
+	for _, revision := range []int{3, 2, 1} {
+		functionRevision := &platform.FunctionRevision{
+			Revision: revision,
+			Image:    fmt.Sprintf("my-image:%d", revision),
+		}
+		functionRevision.Config.Meta.Name = "f1"
+		functionRevision.Config.Spec.Image = functionRevision.Image
+		functionRevisions = append(functionRevisions, functionRevision)
+	}
+
+	suite.mockPlatform.
+		On("GetFunctionRevisions", mock.Anything, mock.Anything).
+		Return(functionRevisions, nil).
+		Once()
+
+	headers := map[string]string{
+		"x-nuclio-function-namespace": "f1-namespace",
+	}
+
+	// compared to the latest revision by default
+	expectedStatusCode := http.StatusOK
+	expectedResponseBody := `{
+	"from": 1,
+	"to": 3,
+	"differences": [
+		{
+			"path": "spec.image",
+			"from": "my-image:1",
+			"to": "my-image:3"
+		}
+	]
+}`
+
+	suite.sendRequest("GET",
+		"/api/functions/f1/revisions/diff?from=1",
+		headers,
+		nil,
+		&expectedStatusCode,
+		expectedResponseBody)
+
+	suite.mockPlatform.AssertExpectations(suite.T())
+}
+
+func (suite *functionTestSuite) TestGetRevisionsDiffNoFromRevision() {
+	headers := map[string]string{
+		"x-nuclio-function-namespace": "f1-namespace",
+	}
+
+	expectedStatusCode := http.StatusBadRequest
+	suite.sendRequest("GET",
+		"/api/functions/f1/revisions/diff",
+		headers,
+		nil,
+		&expectedStatusCode,
+		nil)
+
+	suite.mockPlatform.AssertExpectations(suite.T())
+}
+
 func (suite *functionTestSuite) TestGetDetailNoNamespace() {
 	expectedStatusCode := http.StatusBadRequest
 	ecv := restful.NewErrorContainsVerifier(suite.logger, []string{"Namespace must exist"})
@@ -515,6 +636,71 @@ func (suite *functionTestSuite) TestCreateSuccessful() {
 	suite.mockPlatform.AssertExpectations(suite.T())
 }
 
+func (suite *functionTestSuite) TestCreateWithSidecars() {
+
+	// verify
+	verifyCreateFunction := func(createFunctionOptions *platform.CreateFunctionOptions) bool {
+		suite.Require().Equal("f1", createFunctionOptions.FunctionConfig.Meta.Name)
+		suite.Require().Equal("log-shipper", createFunctionOptions.FunctionConfig.Spec.Sidecars[0].Name)
+		suite.Require().Equal("shared", createFunctionOptions.FunctionConfig.Spec.Sidecars[0].VolumeMounts[0].Name)
+		suite.Require().Equal("fetch-model", createFunctionOptions.FunctionConfig.Spec.InitContainers[0].Name)
+
+		return true
+	}
+
+	suite.mockPlatform.
+		On("CreateFunction", mock.Anything, mock.MatchedBy(verifyCreateFunction)).
+		Return(&platform.CreateFunctionResult{}, nil).
+		Once()
+
+	suite.mockPlatform.
+		On("GetFunctions", mock.Anything, mock.Anything).
+		Return([]platform.Function{}, nil).
+		Once()
+
+	headers := map[string]string{
+		"x-nuclio-wait-function-action": "true",
+		"x-nuclio-project-name":         "proj",
+		"x-nuclio-function-namespace":   "f1-namespace",
+	}
+
+	expectedStatusCode := http.StatusAccepted
+	requestBody := `{
+	"metadata": {
+		"name": "f1",
+		"namespace": "f1-namespace"
+	},
+	"spec": {
+		"resources": {},
+		"build": {},
+		"platform": {},
+		"runtime": "r1",
+		"sidecars": [
+			{
+				"name": "log-shipper",
+				"image": "fluent/fluent-bit:2.1",
+				"volumeMounts": [{"name": "shared", "mountPath": "/var/log/function"}]
+			}
+		],
+		"initContainers": [
+			{
+				"name": "fetch-model",
+				"image": "busybox"
+			}
+		]
+	}
+}`
+
+	suite.sendRequest("POST",
+		"/api/functions",
+		headers,
+		bytes.NewBufferString(requestBody),
+		&expectedStatusCode,
+		nil)
+
+	suite.mockPlatform.AssertExpectations(suite.T())
+}
+
 func (suite *functionTestSuite) TestCreateNoMetadata() {
 	suite.sendRequestNoMetadata("POST")
 }
@@ -639,6 +825,28 @@ func (suite *functionTestSuite) TestDeleteSuccessful() {
 		Return(nil).
 		Once()
 
+	// an api gateway routes to the deleted function
+	dependencyGraph := platform.NewDependencyGraph(nil, []*platform.APIGatewayConfig{
+		{
+			Meta: platform.APIGatewayMeta{Name: "agw1"},
+			Spec: platform.APIGatewaySpec{
+				Upstreams: []platform.APIGatewayUpstreamSpec{
+					{
+						Kind:           platform.APIGatewayUpstreamKindNuclioFunction,
+						NuclioFunction: &platform.NuclioFunctionAPIGatewaySpec{Name: "f1"},
+					},
+				},
+			},
+		},
+	}, nil)
+
+	suite.mockPlatform.
+		On("GetDependencyGraph", mock.Anything, mock.MatchedBy(func(getDependencyGraphOptions *platform.GetDependencyGraphOptions) bool {
+			return getDependencyGraphOptions.Namespace == "f1-namespace"
+		})).
+		Return(dependencyGraph, nil).
+		Once()
+
 	headers := map[string]string{
 		"x-nuclio-wait-function-action": "true",
 	}
@@ -651,13 +859,15 @@ func (suite *functionTestSuite) TestDeleteSuccessful() {
 	}
 }`
 
-	suite.sendRequest("DELETE",
+	response, _ := suite.sendRequest("DELETE",
 		"/api/functions",
 		headers,
 		bytes.NewBufferString(requestBody),
 		&expectedStatusCode,
 		nil)
 
+	suite.Require().Equal("apiGateway agw1", response.Header.Get("x-nuclio-function-dependents"))
+
 	suite.mockPlatform.AssertExpectations(suite.T())
 }
 
@@ -3376,6 +3586,65 @@ func (suite *miscTestSuite) TestGetExternalIPAddresses() {
 	suite.mockPlatform.AssertExpectations(suite.T())
 }
 
+func (suite *miscTestSuite) TestGetDependencyGraph() {
+	functionConfig := functionconfig.NewConfig()
+	functionConfig.Meta.Name = "f1"
+	functionConfig.Meta.Labels = map[string]string{common.NuclioResourceLabelKeyProjectName: "p1"}
+	functionConfig.Spec.CallableFunctions = []functionconfig.FunctionReference{{Name: "f2"}}
+
+	verifyGetDependencyGraph := func(getDependencyGraphOptions *platform.GetDependencyGraphOptions) bool {
+		suite.Require().Equal("some-namespace", getDependencyGraphOptions.Namespace)
+		suite.Require().Equal("p1", getDependencyGraphOptions.ProjectName)
+
+		return true
+	}
+
+	suite.mockPlatform.
+		On("GetDependencyGraph", mock.Anything, mock.MatchedBy(verifyGetDependencyGraph)).
+		Return(platform.NewDependencyGraph([]*functionconfig.Config{functionConfig}, nil, nil), nil).
+		Once()
+
+	headers := map[string]string{
+		"x-nuclio-project-namespace": "some-namespace",
+		"x-nuclio-project-name":      "p1",
+	}
+
+	expectedStatusCode := http.StatusOK
+	expectedResponseBody := `{
+	"dependencyGraph": {
+		"nodes": [
+			{
+				"id": "function/f1",
+				"kind": "function",
+				"name": "f1",
+				"project": "p1"
+			},
+			{
+				"id": "function/f2",
+				"kind": "function",
+				"name": "f2"
+			}
+		],
+		"edges": [
+			{
+				"from": "function/f1",
+				"to": "function/f2",
+				"kind": "invocation"
+			}
+		]
+	}
+}`
+
+	suite.sendRequest("GET",
+		"/api/dependency_graph",
+		headers,
+		nil,
+		&expectedStatusCode,
+		expectedResponseBody)
+
+	suite.mockPlatform.AssertExpectations(suite.T())
+}
+
 func (suite *miscTestSuite) TestGetFrontendSpec() {
 	returnedAddresses := []string{"address1", "address2", "address3"}
 	imageNamePrefixTemplate := "{{ .ProjectName }}-{{ .FunctionName }}-"
@@ -3524,6 +3793,18 @@ func (suite *miscTestSuite) TestGetFrontendSpec() {
 				"displayType": "int",
 				"threshold": 0
 			},
+			{
+				"metricName": "nuclio_processor_trigger_backlog_messages",
+				"sourceType": "TriggerBacklog",
+				"displayType": "int",
+				"threshold": 0
+			},
+			{
+				"metricName": "nuclio_processor_trigger_backlog_oldest_unprocessed_age_seconds",
+				"sourceType": "TriggerBacklog",
+				"displayType": "int",
+				"threshold": 0
+			},
 			{
 				"metricName": "nuclio_processor_worker_pending_allocation_current",
 				"sourceType": "External",
@@ -0,0 +1,184 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package functionconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/nuclio/errors"
+)
+
+// ConfigDifference is a single field that differs between two function configurations. From is nil for
+// fields that were added, and To is nil for fields that were removed
+type ConfigDifference struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// DiffConfigs returns the fields that differ between two function configurations, sorted by path. Lists of
+// named items (e.g. env vars) are compared by name rather than by position, so reordering them is no change
+func DiffConfigs(from *Config, to *Config) ([]ConfigDifference, error) {
+	var fromObject, toObject interface{}
+	if from != nil {
+		fromObject = from
+	}
+	if to != nil {
+		toObject = to
+	}
+
+	return DiffObjects(fromObject, toObject)
+}
+
+// DiffObjects is like DiffConfigs, but compares any two JSON-serializable objects (e.g. project or
+// API gateway configurations)
+func DiffObjects(from interface{}, to interface{}) ([]ConfigDifference, error) {
+	fromValue, err := objectToGeneric(from)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to convert source configuration")
+	}
+
+	toValue, err := objectToGeneric(to)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to convert target configuration")
+	}
+
+	var differences []ConfigDifference
+	diffValues("", fromValue, toValue, &differences)
+
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Path < differences[j].Path
+	})
+
+	return differences, nil
+}
+
+func objectToGeneric(object interface{}) (interface{}, error) {
+	if object == nil {
+		return nil, nil
+	}
+
+	encodedConfig, err := json.Marshal(object)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to encode configuration")
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(encodedConfig, &value); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode configuration")
+	}
+
+	return value, nil
+}
+
+func diffValues(path string, from interface{}, to interface{}, differences *[]ConfigDifference) {
+	switch typedFrom := from.(type) {
+	case map[string]interface{}:
+		if typedTo, isMap := to.(map[string]interface{}); isMap {
+			diffMaps(path, typedFrom, typedTo, differences)
+			return
+		}
+	case []interface{}:
+		if typedTo, isList := to.([]interface{}); isList {
+			diffLists(path, typedFrom, typedTo, differences)
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*differences = append(*differences, ConfigDifference{
+			Path: path,
+			From: from,
+			To:   to,
+		})
+	}
+}
+
+func diffMaps(path string, from map[string]interface{}, to map[string]interface{}, differences *[]ConfigDifference) {
+	for key, fromValue := range from {
+		diffValues(joinDiffPath(path, key), fromValue, to[key], differences)
+	}
+
+	for key, toValue := range to {
+		if _, found := from[key]; !found {
+			diffValues(joinDiffPath(path, key), nil, toValue, differences)
+		}
+	}
+}
+
+func diffLists(path string, from []interface{}, to []interface{}, differences *[]ConfigDifference) {
+	fromByName, fromNamed := listItemsByName(from)
+	toByName, toNamed := listItemsByName(to)
+
+	if fromNamed && toNamed {
+		for name, fromValue := range fromByName {
+			diffValues(fmt.Sprintf("%s[%s]", path, name), fromValue, toByName[name], differences)
+		}
+
+		for name, toValue := range toByName {
+			if _, found := fromByName[name]; !found {
+				diffValues(fmt.Sprintf("%s[%s]", path, name), nil, toValue, differences)
+			}
+		}
+		return
+	}
+
+	for index := 0; index < len(from) || index < len(to); index++ {
+		var fromValue, toValue interface{}
+		if index < len(from) {
+			fromValue = from[index]
+		}
+		if index < len(to) {
+			toValue = to[index]
+		}
+		diffValues(fmt.Sprintf("%s[%d]", path, index), fromValue, toValue, differences)
+	}
+}
+
+// listItemsByName indexes a list by the "name" field of its items, if all of them have a unique one
+func listItemsByName(list []interface{}) (map[string]interface{}, bool) {
+	itemsByName := map[string]interface{}{}
+	for _, item := range list {
+		typedItem, isMap := item.(map[string]interface{})
+		if !isMap {
+			return nil, false
+		}
+
+		name, isString := typedItem["name"].(string)
+		if !isString || name == "" {
+			return nil, false
+		}
+
+		if _, found := itemsByName[name]; found {
+			return nil, false
+		}
+
+		itemsByName[name] = item
+	}
+
+	return itemsByName, true
+}
+
+func joinDiffPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
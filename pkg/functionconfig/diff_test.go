@@ -0,0 +1,149 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package functionconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"k8s.io/api/core/v1"
+)
+
+type DiffTestSuite struct {
+	suite.Suite
+}
+
+func (suite *DiffTestSuite) TestDiffConfigs() {
+	from := NewConfig()
+	from.Meta.Name = "my-function"
+	from.Spec.Image = "my-image:v1"
+	from.Spec.Env = []v1.EnvVar{
+		{Name: "A", Value: "1"},
+		{Name: "B", Value: "2"},
+		{Name: "C", Value: "3"},
+	}
+	from.Spec.Triggers = map[string]Trigger{
+		"http": {
+			Kind:       "http",
+			MaxWorkers: 1,
+		},
+	}
+
+	to := NewConfig()
+	to.Meta.Name = "my-function"
+	to.Spec.Image = "my-image:v2"
+
+	// reordered, changed, removed and added env vars
+	to.Spec.Env = []v1.EnvVar{
+		{Name: "B", Value: "2"},
+		{Name: "A", Value: "10"},
+		{Name: "D", Value: "4"},
+	}
+	to.Spec.Triggers = map[string]Trigger{
+		"http": {
+			Kind:       "http",
+			MaxWorkers: 4,
+		},
+	}
+
+	differences, err := DiffConfigs(from, to)
+	suite.Require().NoError(err)
+	suite.Require().Equal([]ConfigDifference{
+		{
+			Path: "spec.env[A].value",
+			From: "1",
+			To:   "10",
+		},
+		{
+			Path: "spec.env[C]",
+			From: map[string]interface{}{"name": "C", "value": "3"},
+		},
+		{
+			Path: "spec.env[D]",
+			To:   map[string]interface{}{"name": "D", "value": "4"},
+		},
+		{
+			Path: "spec.image",
+			From: "my-image:v1",
+			To:   "my-image:v2",
+		},
+		{
+			Path: "spec.triggers.http.maxWorkers",
+			From: float64(1),
+			To:   float64(4),
+		},
+	}, differences)
+
+	// no difference between identical configurations
+	differences, err = DiffConfigs(to, to)
+	suite.Require().NoError(err)
+	suite.Require().Empty(differences)
+}
+
+func (suite *DiffTestSuite) TestDiffConfigsUnnamedLists() {
+	from := NewConfig()
+	from.Spec.Build.Commands = []string{"apt-get update", "pip install a"}
+
+	to := NewConfig()
+	to.Spec.Build.Commands = []string{"pip install a"}
+
+	differences, err := DiffConfigs(from, to)
+	suite.Require().NoError(err)
+	suite.Require().Equal([]ConfigDifference{
+		{
+			Path: "spec.build.commands[0]",
+			From: "apt-get update",
+			To:   "pip install a",
+		},
+		{
+			Path: "spec.build.commands[1]",
+			From: "pip install a",
+		},
+	}, differences)
+}
+
+func (suite *DiffTestSuite) TestDiffObjects() {
+	type object struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels,omitempty"`
+	}
+
+	differences, err := DiffObjects(&object{Name: "a"}, &object{Name: "a", Labels: map[string]string{"b": "c"}})
+	suite.Require().NoError(err)
+	suite.Require().Equal([]ConfigDifference{
+		{
+			Path: "labels",
+			To:   map[string]interface{}{"b": "c"},
+		},
+	}, differences)
+
+	// a missing object differs in every field
+	differences, err = DiffObjects(nil, &object{Name: "a"})
+	suite.Require().NoError(err)
+	suite.Require().Equal([]ConfigDifference{
+		{
+			Path: "",
+			To:   map[string]interface{}{"name": "a"},
+		},
+	}, differences)
+}
+
+func TestDiffTestSuite(t *testing.T) {
+	suite.Run(t, new(DiffTestSuite))
+}
@@ -30,6 +30,7 @@ import (
 	"k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
@@ -48,6 +49,138 @@ type DataBinding struct {
 	Attributes map[string]interface{} `json:"attributes,omitempty"`
 }
 
+// OutputBinding holds configuration for a destination the processor publishes the handler's response to
+type OutputBinding struct {
+	Kind string `json:"kind"`
+	URL  string `json:"url"`
+
+	// the kafka topic, nats subject or rabbitmq exchange to publish to (unused for http)
+	Target     string                 `json:"target,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// FunctionReference references a function by name. when the project is omitted, the function is
+// looked up in the project of the referencing function
+type FunctionReference struct {
+	Name    string `json:"name"`
+	Project string `json:"project,omitempty"`
+}
+
+// DisruptionBudget limits how many of the function's replicas may be voluntarily evicted at once
+// (e.g. during a node drain). only one of MinAvailable and MaxUnavailable may be set
+type DisruptionBudget struct {
+	Disable        bool                `json:"disable,omitempty"`
+	MinAvailable   *intstr.IntOrString `json:"minAvailable,omitempty"`
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// NetworkPolicy restricts the traffic that may reach the function's pods (and optionally, the traffic
+// that may leave them). nuclio's own services (e.g. the dashboard and DLX) are always allowed in
+type NetworkPolicy struct {
+	Disable bool                 `json:"disable,omitempty"`
+	Ingress NetworkPolicyIngress `json:"ingress,omitempty"`
+	Egress  *NetworkPolicyEgress `json:"egress,omitempty"`
+}
+
+// NetworkPolicyIngress lists the sources allowed to reach the function
+type NetworkPolicyIngress struct {
+
+	// functions of the same project
+	AllowSameProject bool `json:"allowSameProject,omitempty"`
+
+	// the ingress controller, through which function ingresses and API gateways are served
+	AllowIngressController bool `json:"allowIngressController,omitempty"`
+
+	// functions in the same namespace, by name
+	AllowFunctions []string `json:"allowFunctions,omitempty"`
+
+	AllowCIDRs []string `json:"allowCIDRs,omitempty"`
+}
+
+// NetworkPolicyEgress lists the destinations the function may reach. when set, any other destination
+// is blocked, except for DNS
+type NetworkPolicyEgress struct {
+	AllowSameProject bool     `json:"allowSameProject,omitempty"`
+	AllowFunctions   []string `json:"allowFunctions,omitempty"`
+	AllowCIDRs       []string `json:"allowCIDRs,omitempty"`
+}
+
+type RolloutStrategy string
+
+const (
+
+	// the new version gets all of the traffic at once, and the previous version is kept until it's proven healthy
+	RolloutStrategyBlueGreen RolloutStrategy = "blueGreen"
+
+	// the new version gets a growing share of the traffic, step by step
+	RolloutStrategyCanary RolloutStrategy = "canary"
+)
+
+const (
+	DefaultRolloutStepDurationSeconds = 60
+	DefaultRolloutMaxErrorRate        = 0.05
+	DefaultRolloutMinEvents           = 10
+)
+
+// DefaultRolloutCanarySteps are the traffic weights of a canary rollout that doesn't specify its own
+var DefaultRolloutCanarySteps = []int{10, 50}
+
+// Rollout makes redeploys of a running function progressive - the new version is deployed alongside the
+// running one and traffic is shifted to it, as long as its error rate remains under MaxErrorRate
+type Rollout struct {
+	Strategy RolloutStrategy `json:"strategy,omitempty"`
+
+	// the share of traffic (in percent) the new version gets at each step of a canary rollout. the new
+	// version is promoted once the last step is done
+	Steps []int `json:"steps,omitempty"`
+
+	// how long each step lasts before moving on to the next one
+	StepDurationSeconds int `json:"stepDurationSeconds,omitempty"`
+
+	// the ratio of failed to handled events (0-1) above which the new version is rolled back
+	MaxErrorRate float64 `json:"maxErrorRate,omitempty"`
+
+	// the number of events the new version must handle before its error rate is judged
+	MinEvents int `json:"minEvents,omitempty"`
+}
+
+// GetSteps returns the traffic weights of the rollout's steps
+func (r *Rollout) GetSteps() []int {
+	if r.Strategy == RolloutStrategyBlueGreen {
+		return []int{100}
+	}
+
+	if len(r.Steps) == 0 {
+		return DefaultRolloutCanarySteps
+	}
+
+	return r.Steps
+}
+
+func (r *Rollout) GetStepDuration() time.Duration {
+	if r.StepDurationSeconds == 0 {
+		return DefaultRolloutStepDurationSeconds * time.Second
+	}
+
+	return time.Duration(r.StepDurationSeconds) * time.Second
+}
+
+func (r *Rollout) GetMaxErrorRate() float64 {
+	if r.MaxErrorRate == 0 {
+		return DefaultRolloutMaxErrorRate
+	}
+
+	return r.MaxErrorRate
+}
+
+func (r *Rollout) GetMinEvents() int {
+	if r.MinEvents == 0 {
+		return DefaultRolloutMinEvents
+	}
+
+	return r.MinEvents
+}
+
 // Checkpoint is a partition checkpoint
 type Checkpoint *string
 
@@ -276,6 +409,15 @@ const (
 	AutoScaleMetricTypePercentage AutoScaleDisplayType = "percentage"
 )
 
+// AutoScaleMetricSourceTypeTriggerBacklog scales on the backlog reported by the function's stream triggers,
+// exposed per pod through the metrics adapter
+const AutoScaleMetricSourceTypeTriggerBacklog autosv2.MetricSourceType = "TriggerBacklog"
+
+const (
+	TriggerBacklogMessagesMetricName             = "nuclio_processor_trigger_backlog_messages"
+	TriggerBacklogOldestUnprocessedAgeMetricName = "nuclio_processor_trigger_backlog_oldest_unprocessed_age_seconds"
+)
+
 type AutoScaleMetric struct {
 	ScaleResource `json:",inline"`
 	SourceType    autosv2.MetricSourceType `json:"sourceType,omitempty"`
@@ -320,39 +462,46 @@ type Build struct {
 
 // Spec holds all parameters related to a function's configuration
 type Spec struct {
-	Description                   string                  `json:"description,omitempty"`
-	Disable                       bool                    `json:"disable,omitempty"`
-	Publish                       bool                    `json:"publish,omitempty"`
-	Handler                       string                  `json:"handler,omitempty"`
-	Runtime                       string                  `json:"runtime,omitempty"`
-	Env                           []v1.EnvVar             `json:"env,omitempty"`
-	Resources                     v1.ResourceRequirements `json:"resources,omitempty"`
-	Image                         string                  `json:"image,omitempty"`
-	ImageHash                     string                  `json:"imageHash,omitempty"`
-	Replicas                      *int                    `json:"replicas,omitempty"`
-	MinReplicas                   *int                    `json:"minReplicas,omitempty"`
-	MaxReplicas                   *int                    `json:"maxReplicas,omitempty"`
-	TargetCPU                     int                     `json:"targetCPU,omitempty"`
-	DataBindings                  map[string]DataBinding  `json:"dataBindings,omitempty"`
-	Triggers                      map[string]Trigger      `json:"triggers,omitempty"`
-	Volumes                       []Volume                `json:"volumes,omitempty"`
-	Version                       int                     `json:"version,omitempty"`
-	Alias                         string                  `json:"alias,omitempty"`
-	Build                         Build                   `json:"build,omitempty"`
-	RunRegistry                   string                  `json:"runRegistry,omitempty"`
-	ImagePullSecrets              string                  `json:"imagePullSecrets,omitempty"`
-	RuntimeAttributes             map[string]interface{}  `json:"runtimeAttributes,omitempty"`
-	LoggerSinks                   []LoggerSink            `json:"loggerSinks,omitempty"`
-	DealerURI                     string                  `json:"dealerURI,omitempty"`
-	Platform                      Platform                `json:"platform,omitempty"`
-	ReadinessTimeoutSeconds       int                     `json:"readinessTimeoutSeconds,omitempty"`
-	Avatar                        string                  `json:"avatar,omitempty"`
-	ServiceType                   v1.ServiceType          `json:"serviceType,omitempty"`
-	ImagePullPolicy               v1.PullPolicy           `json:"imagePullPolicy,omitempty"`
-	SecurityContext               *v1.PodSecurityContext  `json:"securityContext,omitempty"`
-	ServiceAccount                string                  `json:"serviceAccount,omitempty"`
-	ScaleToZero                   *ScaleToZeroSpec        `json:"scaleToZero,omitempty"`
-	DisableSensitiveFieldsMasking bool                    `json:"disableSensitiveFieldsMasking,omitempty"`
+	Description                   string                   `json:"description,omitempty"`
+	Disable                       bool                     `json:"disable,omitempty"`
+	Publish                       bool                     `json:"publish,omitempty"`
+	Handler                       string                   `json:"handler,omitempty"`
+	Runtime                       string                   `json:"runtime,omitempty"`
+	Env                           []v1.EnvVar              `json:"env,omitempty"`
+	Resources                     v1.ResourceRequirements  `json:"resources,omitempty"`
+	Image                         string                   `json:"image,omitempty"`
+	ImageHash                     string                   `json:"imageHash,omitempty"`
+	Replicas                      *int                     `json:"replicas,omitempty"`
+	MinReplicas                   *int                     `json:"minReplicas,omitempty"`
+	MaxReplicas                   *int                     `json:"maxReplicas,omitempty"`
+	TargetCPU                     int                      `json:"targetCPU,omitempty"`
+	DataBindings                  map[string]DataBinding   `json:"dataBindings,omitempty"`
+	OutputBindings                map[string]OutputBinding `json:"outputBindings,omitempty"`
+	CallableFunctions             []FunctionReference      `json:"callableFunctions,omitempty"`
+	DisruptionBudget              *DisruptionBudget        `json:"disruptionBudget,omitempty"`
+	NetworkPolicy                 *NetworkPolicy           `json:"networkPolicy,omitempty"`
+	Triggers                      map[string]Trigger       `json:"triggers,omitempty"`
+	Volumes                       []Volume                 `json:"volumes,omitempty"`
+	Sidecars                      []v1.Container           `json:"sidecars,omitempty"`
+	InitContainers                []v1.Container           `json:"initContainers,omitempty"`
+	Rollout                       *Rollout                 `json:"rollout,omitempty"`
+	Version                       int                      `json:"version,omitempty"`
+	Alias                         string                   `json:"alias,omitempty"`
+	Build                         Build                    `json:"build,omitempty"`
+	RunRegistry                   string                   `json:"runRegistry,omitempty"`
+	ImagePullSecrets              string                   `json:"imagePullSecrets,omitempty"`
+	RuntimeAttributes             map[string]interface{}   `json:"runtimeAttributes,omitempty"`
+	LoggerSinks                   []LoggerSink             `json:"loggerSinks,omitempty"`
+	DealerURI                     string                   `json:"dealerURI,omitempty"`
+	Platform                      Platform                 `json:"platform,omitempty"`
+	ReadinessTimeoutSeconds       int                      `json:"readinessTimeoutSeconds,omitempty"`
+	Avatar                        string                   `json:"avatar,omitempty"`
+	ServiceType                   v1.ServiceType           `json:"serviceType,omitempty"`
+	ImagePullPolicy               v1.PullPolicy            `json:"imagePullPolicy,omitempty"`
+	SecurityContext               *v1.PodSecurityContext   `json:"securityContext,omitempty"`
+	ServiceAccount                string                   `json:"serviceAccount,omitempty"`
+	ScaleToZero                   *ScaleToZeroSpec         `json:"scaleToZero,omitempty"`
+	DisableSensitiveFieldsMasking bool                     `json:"disableSensitiveFieldsMasking,omitempty"`
 
 	// Run function on a particular set of node(s)
 	// https://kubernetes.io/docs/concepts/scheduling-eviction/assign-pod-node/
@@ -393,6 +542,9 @@ type Spec struct {
 	// When filled, tolerations, node labels, and affinity would be populated correspondingly to
 	// the platformconfig.PreemptibleNodes values.
 	PreemptionMode RunOnPreemptibleNodeMode `json:"preemptionMode,omitempty"`
+
+	// When true, the resources recommended for the function (in its status) replace its resources on the next deploy
+	AutoApplyResourceRecommendation bool `json:"autoApplyResourceRecommendation,omitempty"`
 }
 
 type RunOnPreemptibleNodeMode string
@@ -816,12 +968,117 @@ type Status struct {
 	// list of external urls, containing ingresses and external-ip:function-port
 	// e.g.: [ my-function.some-domain.com/pathA, other-ingress.some-domain.co, 1.2.3.4:3000 ]
 	ExternalInvocationURLs []string `json:"externalInvocationUrls,omitempty"`
+
+	// the state of the resource scaling the function, when one exists
+	AutoScaler *AutoScalerStatus `json:"autoScaler,omitempty"`
+
+	// the progress (or outcome) of the function's last rollout
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+
+	// the resources recommended for the function, based on its observed usage
+	ResourceRecommendation *ResourceRecommendation `json:"resourceRecommendation,omitempty"`
 }
 
 func (s *Status) InvocationURLs() []string {
 	return append(s.InternalInvocationURLs, s.ExternalInvocationURLs...)
 }
 
+type AutoScalerStatus struct {
+	Kind    string `json:"kind,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Ready   bool   `json:"ready,omitempty"`
+	Active  bool   `json:"active,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type RolloutPhase string
+
+const (
+	RolloutPhaseProgressing RolloutPhase = "progressing"
+	RolloutPhasePromoted    RolloutPhase = "promoted"
+	RolloutPhaseRolledBack  RolloutPhase = "rolledBack"
+)
+
+type RolloutStatus struct {
+	Phase    RolloutPhase    `json:"phase,omitempty"`
+	Strategy RolloutStrategy `json:"strategy,omitempty"`
+
+	// the current step, and the share of traffic (in percent) the new version gets in it
+	Step   int `json:"step"`
+	Weight int `json:"weight"`
+
+	StartedAt     *time.Time `json:"startedAt,omitempty"`
+	StepStartedAt *time.Time `json:"stepStartedAt,omitempty"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty"`
+
+	// the events handled by the new version since the rollout started
+	HandledEvents uint64  `json:"handledEvents,omitempty"`
+	FailedEvents  uint64  `json:"failedEvents,omitempty"`
+	ErrorRate     float64 `json:"errorRate,omitempty"`
+
+	Message string `json:"message,omitempty"`
+}
+
+// ResourceRecommendation holds the resources recommended for the processor container of a function, computed from
+// the usage observed by its replicas over a window of time
+type ResourceRecommendation struct {
+	Requests v1.ResourceList `json:"requests,omitempty"`
+	Limits   v1.ResourceList `json:"limits,omitempty"`
+
+	// the usage samples the recommendation is based on, and the window they were collected over
+	Samples    int        `json:"samples,omitempty"`
+	Window     string     `json:"window,omitempty"`
+	ComputedAt *time.Time `json:"computedAt,omitempty"`
+}
+
+// ApplyTo sets the recommended resources on the given requirements. Other resources (e.g. gpus) are kept, as are
+// limits that aren't recommended - in which case the recommended request is capped by them
+func (r *ResourceRecommendation) ApplyTo(resources *v1.ResourceRequirements) {
+	for resourceName, quantity := range r.Requests {
+		if limit, limitFound := resources.Limits[resourceName]; limitFound {
+			if _, limitRecommended := r.Limits[resourceName]; !limitRecommended && limit.Cmp(quantity) < 0 {
+				quantity = limit
+			}
+		}
+
+		if resources.Requests == nil {
+			resources.Requests = v1.ResourceList{}
+		}
+		resources.Requests[resourceName] = quantity
+	}
+
+	for resourceName, quantity := range r.Limits {
+		if resources.Limits == nil {
+			resources.Limits = v1.ResourceList{}
+		}
+		resources.Limits[resourceName] = quantity
+	}
+}
+
+// IsEqual returns whether both recommendations suggest the same resources
+func (r *ResourceRecommendation) IsEqual(other *ResourceRecommendation) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+
+	return resourceListsEqual(r.Requests, other.Requests) && resourceListsEqual(r.Limits, other.Limits)
+}
+
+func resourceListsEqual(a v1.ResourceList, b v1.ResourceList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for resourceName, quantity := range a {
+		otherQuantity, found := b[resourceName]
+		if !found || quantity.Cmp(otherQuantity) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
 type ScaleToZeroStatus struct {
 	LastScaleEvent     scalertypes.ScaleEvent `json:"lastScaleEvent,omitempty"`
 	LastScaleEventTime *time.Time             `json:"lastScaleEventTime,omitempty"`
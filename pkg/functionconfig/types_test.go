@@ -24,6 +24,8 @@ import (
 	"github.com/nuclio/logger"
 	"github.com/nuclio/zap"
 	"github.com/stretchr/testify/suite"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 type TypesTestSuite struct {
@@ -101,6 +103,57 @@ func (suite *ReaderTestSuite) TestGetInvocationURLs() {
 	suite.Require().Equal([]string{"a", "b", "c", "d"}, functionStatus.InvocationURLs())
 }
 
+func (suite *TypesTestSuite) TestResourceRecommendationApplyTo() {
+	recommendation := &ResourceRecommendation{
+		Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("500m"),
+			v1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+		Limits: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse("192Mi"),
+		},
+	}
+
+	resources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+		Limits: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("200m"),
+			v1.ResourceMemory: resource.MustParse("2Gi"),
+			"nvidia.com/gpu":  resource.MustParse("1"),
+		},
+	}
+
+	recommendation.ApplyTo(&resources)
+
+	// the user's cpu limit caps the recommended cpu request
+	suite.Require().Equal("200m", resources.Requests.Cpu().String())
+	suite.Require().Equal("128Mi", resources.Requests.Memory().String())
+	suite.Require().Equal("200m", resources.Limits.Cpu().String())
+	suite.Require().Equal("192Mi", resources.Limits.Memory().String())
+
+	// resources that aren't recommended are kept
+	gpuLimit := resources.Limits["nvidia.com/gpu"]
+	suite.Require().Equal("1", gpuLimit.String())
+}
+
+func (suite *TypesTestSuite) TestResourceRecommendationIsEqual() {
+	recommendation := &ResourceRecommendation{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+		Samples:  10,
+	}
+
+	suite.Require().True(recommendation.IsEqual(&ResourceRecommendation{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("0.1")},
+		Samples:  20,
+	}))
+	suite.Require().False(recommendation.IsEqual(&ResourceRecommendation{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("200m")},
+	}))
+	suite.Require().False(recommendation.IsEqual(nil))
+}
+
 func TestTypesTestSuite(t *testing.T) {
 	suite.Run(t, new(TypesTestSuite))
 }
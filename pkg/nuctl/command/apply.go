@@ -0,0 +1,791 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	nuctlcommon "github.com/nuclio/nuclio/pkg/nuctl/command/common"
+	"github.com/nuclio/nuclio/pkg/platform"
+	"github.com/nuclio/nuclio/pkg/processor/build"
+	"github.com/nuclio/nuclio/pkg/renderer"
+
+	"github.com/nuclio/errors"
+	"github.com/spf13/cobra"
+)
+
+type applyAction string
+
+const (
+	applyActionCreate    applyAction = "create"
+	applyActionUpdate    applyAction = "update"
+	applyActionUnchanged applyAction = "unchanged"
+	applyActionDelete    applyAction = "delete"
+)
+
+// applyPlanItem is a single change that applying the manifests makes to a resource
+type applyPlanItem struct {
+	action      applyAction
+	kind        string
+	namespace   string
+	name        string
+	differences []functionconfig.ConfigDifference
+
+	// for functions, whether the function image is built (rather than the current one redeployed)
+	build bool
+
+	execute func(ctx context.Context) error
+}
+
+type applyCommandeer struct {
+	cmd            *cobra.Command
+	rootCommandeer *RootCommandeer
+	filenames      []string
+	applySet       string
+	prune          bool
+	dryRun         bool
+}
+
+func newApplyCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *applyCommandeer {
+	commandeer := &applyCommandeer{
+		rootCommandeer: rootCommandeer,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "apply -f <file or directory>",
+		Short: "Bring functions, projects, function events and API gateways to the state declared in manifests",
+		Long: `Bring functions, projects, function events and API gateways to the state declared in manifests.
+
+Creates the declared resources that don't exist and updates the ones that differ from their manifests.
+Functions are built only when their source or build configuration changed since they were last applied,
+and otherwise redeployed with their current image.
+
+Each manifest file holds one or more YAML (or JSON) documents, either a single resource identified
+by its "kind" (NuclioProject, NuclioFunction, NuclioFunctionEvent or NuclioAPIGateway; may be omitted
+for functions) or the output of 'nuctl export project'.
+
+With --apply-set, resources are labeled as belonging to the named set, and --prune deletes the
+resources of the set that are no longer declared.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(commandeer.filenames) == 0 {
+				return errors.New("Apply requires at least one manifest file or directory (-f)")
+			}
+
+			if commandeer.prune && commandeer.applySet == "" {
+				return errors.New("Pruning requires an apply set (--apply-set)")
+			}
+
+			// initialize root
+			if err := rootCommandeer.initialize(); err != nil {
+				return errors.Wrap(err, "Failed to initialize root")
+			}
+
+			readManifests, err := readManifests(commandeer.filenames)
+			if err != nil {
+				return errors.Wrap(err, "Failed to read manifests")
+			}
+
+			commandeer.enrichManifests(readManifests)
+
+			plan, err := commandeer.computePlan(ctx, readManifests)
+			if err != nil {
+				return errors.Wrap(err, "Failed to compute the apply plan")
+			}
+
+			if err := renderApplyPlan(plan, cmd.OutOrStdout()); err != nil {
+				return errors.Wrap(err, "Failed to render the apply plan")
+			}
+
+			if commandeer.dryRun {
+				return nil
+			}
+
+			return commandeer.executePlan(ctx, plan)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&commandeer.filenames, "filename", "f", []string{}, "Manifest file or directory (read recursively); may be repeated")
+	cmd.Flags().StringVar(&commandeer.applySet, "apply-set", "", "Name of the set of resources managed by these manifests; resources are labeled with it")
+	cmd.Flags().BoolVar(&commandeer.prune, "prune", false, "Delete resources of the apply set that are no longer declared (requires --apply-set)")
+	cmd.Flags().BoolVar(&commandeer.dryRun, "dry-run", false, "Print the plan without applying it")
+
+	commandeer.cmd = cmd
+
+	return commandeer
+}
+
+// enrichManifests sets the namespace of resources that don't declare one, and labels them with the apply set
+func (a *applyCommandeer) enrichManifests(manifests *manifests) {
+	enrichMeta := func(namespace *string, labels *map[string]string) {
+		if *namespace == "" {
+			*namespace = a.rootCommandeer.namespace
+		}
+
+		if a.applySet != "" {
+			if *labels == nil {
+				*labels = map[string]string{}
+			}
+			(*labels)[common.NuclioResourceLabelKeyApplySet] = a.applySet
+		}
+	}
+
+	for _, projectConfig := range manifests.projects {
+		enrichMeta(&projectConfig.Meta.Namespace, &projectConfig.Meta.Labels)
+	}
+
+	for _, function := range manifests.functions {
+		enrichMeta(&function.config.Meta.Namespace, &function.config.Meta.Labels)
+	}
+
+	for _, functionEventConfig := range manifests.functionEvents {
+		enrichMeta(&functionEventConfig.Meta.Namespace, &functionEventConfig.Meta.Labels)
+	}
+
+	for _, apiGatewayConfig := range manifests.apiGateways {
+		enrichMeta(&apiGatewayConfig.Meta.Namespace, &apiGatewayConfig.Meta.Labels)
+	}
+}
+
+// computePlan returns the changes to apply, in the order they need to be applied - projects first and
+// API gateways last, then deletions in reverse order
+func (a *applyCommandeer) computePlan(ctx context.Context, manifests *manifests) ([]*applyPlanItem, error) {
+	var plan []*applyPlanItem
+
+	if len(manifests.apiGateways) > 0 && a.rootCommandeer.platform.GetName() != common.KubePlatformName {
+		return nil, errors.New("API gateways are supported only on the kube platform")
+	}
+
+	for _, projectConfig := range manifests.projects {
+		planItem, err := a.planProject(ctx, projectConfig)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to plan project %s", projectConfig.Meta.Name)
+		}
+		plan = append(plan, planItem)
+	}
+
+	for _, function := range manifests.functions {
+		planItem, err := a.planFunction(ctx, function)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to plan function %s", function.config.Meta.Name)
+		}
+		plan = append(plan, planItem)
+	}
+
+	for _, functionEventConfig := range manifests.functionEvents {
+		planItem, err := a.planFunctionEvent(ctx, functionEventConfig)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to plan function event %s", functionEventConfig.Meta.Name)
+		}
+		plan = append(plan, planItem)
+	}
+
+	for _, apiGatewayConfig := range manifests.apiGateways {
+		planItem, err := a.planAPIGateway(ctx, apiGatewayConfig)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to plan API gateway %s", apiGatewayConfig.Meta.Name)
+		}
+		plan = append(plan, planItem)
+	}
+
+	if a.prune {
+		prunePlan, err := a.planPrune(ctx, manifests)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to plan pruning")
+		}
+		plan = append(plan, prunePlan...)
+	}
+
+	return plan, nil
+}
+
+func (a *applyCommandeer) planProject(ctx context.Context,
+	projectConfig *platform.ProjectConfig) (*applyPlanItem, error) {
+
+	planItem := &applyPlanItem{
+		kind:      manifestKindProject,
+		namespace: projectConfig.Meta.Namespace,
+		name:      projectConfig.Meta.Name,
+	}
+
+	projects, err := a.rootCommandeer.platform.GetProjects(ctx, &platform.GetProjectsOptions{
+		Meta: platform.ProjectMeta{
+			Name:      projectConfig.Meta.Name,
+			Namespace: projectConfig.Meta.Namespace,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get project")
+	}
+
+	if len(projects) == 0 {
+		planItem.action = applyActionCreate
+		planItem.execute = func(ctx context.Context) error {
+			newProject, err := platform.NewAbstractProject(a.rootCommandeer.loggerInstance,
+				a.rootCommandeer.platform,
+				*projectConfig)
+			if err != nil {
+				return errors.Wrap(err, "Failed to create abstract project")
+			}
+
+			return newProject.CreateAndWait(ctx, &platform.CreateProjectOptions{
+				ProjectConfig: newProject.GetConfig(),
+			})
+		}
+		return planItem, nil
+	}
+
+	if err := a.setDeclaredDifferences(planItem, projects[0].GetConfig(), projectConfig); err != nil {
+		return nil, err
+	}
+
+	planItem.execute = func(ctx context.Context) error {
+		return a.rootCommandeer.platform.UpdateProject(ctx, &platform.UpdateProjectOptions{
+			ProjectConfig: *projectConfig,
+		})
+	}
+
+	return planItem, nil
+}
+
+func (a *applyCommandeer) planFunction(ctx context.Context, function *functionManifest) (*applyPlanItem, error) {
+	functionConfig := function.config
+
+	planItem := &applyPlanItem{
+		kind:      manifestKindFunction,
+		namespace: functionConfig.Meta.Namespace,
+		name:      functionConfig.Meta.Name,
+		build:     true,
+	}
+
+	configHash, err := computeFunctionConfigHash(functionConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to hash function configuration")
+	}
+
+	buildHash, err := computeFunctionBuildHash(function)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to hash function source")
+	}
+
+	// record what was applied, to tell next time whether anything changed
+	if functionConfig.Meta.Annotations == nil {
+		functionConfig.Meta.Annotations = map[string]string{}
+	}
+	functionConfig.Meta.Annotations[common.NuclioResourceAnnotationKeyAppliedConfigHash] = configHash
+	functionConfig.Meta.Annotations[common.NuclioResourceAnnotationKeyAppliedBuildHash] = buildHash
+
+	functions, err := a.rootCommandeer.platform.GetFunctions(ctx, &platform.GetFunctionsOptions{
+		Name:      functionConfig.Meta.Name,
+		Namespace: functionConfig.Meta.Namespace,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get function")
+	}
+
+	if len(functions) == 0 {
+		planItem.action = applyActionCreate
+		planItem.execute = func(ctx context.Context) error {
+			return a.rootCommandeer.deployFunction(ctx, *functionConfig)
+		}
+		return planItem, nil
+	}
+
+	existingConfig := functions[0].GetConfig()
+	existingImage := functions[0].GetStatus().ContainerImage
+
+	if err := a.setDeclaredDifferences(planItem, existingConfig, functionConfig); err != nil {
+		return nil, err
+	}
+
+	// the build configuration and source are tracked by the build hash, and the platform may rewrite
+	// them (e.g. store the source code it built)
+	var differences []functionconfig.ConfigDifference
+	for _, difference := range planItem.differences {
+		if !strings.HasPrefix(difference.Path, "spec.build.") {
+			differences = append(differences, difference)
+		}
+	}
+	planItem.differences = differences
+
+	configChanged := existingConfig.Meta.Annotations[common.NuclioResourceAnnotationKeyAppliedConfigHash] != configHash
+	planItem.build = existingConfig.Meta.Annotations[common.NuclioResourceAnnotationKeyAppliedBuildHash] != buildHash ||
+		existingImage == "" ||
+		hasRemoteSource(functionConfig.Spec.Build)
+
+	switch {
+	case planItem.build || configChanged || len(planItem.differences) > 0:
+		planItem.action = applyActionUpdate
+	default:
+		planItem.action = applyActionUnchanged
+	}
+
+	planItem.execute = func(ctx context.Context) error {
+		deployedConfig := *functionConfig
+
+		// redeploy the current image as is
+		if !planItem.build {
+			deployedConfig.Spec.Image = existingImage
+			deployedConfig.Spec.Build.Mode = functionconfig.NeverBuild
+		}
+
+		return a.rootCommandeer.deployFunction(ctx, deployedConfig)
+	}
+
+	return planItem, nil
+}
+
+func (a *applyCommandeer) planFunctionEvent(ctx context.Context,
+	functionEventConfig *platform.FunctionEventConfig) (*applyPlanItem, error) {
+
+	planItem := &applyPlanItem{
+		kind:      manifestKindFunctionEvent,
+		namespace: functionEventConfig.Meta.Namespace,
+		name:      functionEventConfig.Meta.Name,
+	}
+
+	functionEvents, err := a.rootCommandeer.platform.GetFunctionEvents(ctx, &platform.GetFunctionEventsOptions{
+		Meta: platform.FunctionEventMeta{
+			Name:      functionEventConfig.Meta.Name,
+			Namespace: functionEventConfig.Meta.Namespace,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get function event")
+	}
+
+	if len(functionEvents) == 0 {
+		planItem.action = applyActionCreate
+		planItem.execute = func(ctx context.Context) error {
+			return a.rootCommandeer.platform.CreateFunctionEvent(ctx, &platform.CreateFunctionEventOptions{
+				FunctionEventConfig: *functionEventConfig,
+			})
+		}
+		return planItem, nil
+	}
+
+	if err := a.setDeclaredDifferences(planItem, functionEvents[0].GetConfig(), functionEventConfig); err != nil {
+		return nil, err
+	}
+
+	planItem.execute = func(ctx context.Context) error {
+		return a.rootCommandeer.platform.UpdateFunctionEvent(ctx, &platform.UpdateFunctionEventOptions{
+			FunctionEventConfig: *functionEventConfig,
+		})
+	}
+
+	return planItem, nil
+}
+
+func (a *applyCommandeer) planAPIGateway(ctx context.Context,
+	apiGatewayConfig *platform.APIGatewayConfig) (*applyPlanItem, error) {
+
+	planItem := &applyPlanItem{
+		kind:      manifestKindAPIGateway,
+		namespace: apiGatewayConfig.Meta.Namespace,
+		name:      apiGatewayConfig.Meta.Name,
+	}
+
+	apiGateways, err := a.rootCommandeer.platform.GetAPIGateways(ctx, &platform.GetAPIGatewaysOptions{
+		Name:      apiGatewayConfig.Meta.Name,
+		Namespace: apiGatewayConfig.Meta.Namespace,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get API gateway")
+	}
+
+	if len(apiGateways) == 0 {
+		planItem.action = applyActionCreate
+		planItem.execute = func(ctx context.Context) error {
+			return a.rootCommandeer.platform.CreateAPIGateway(ctx, &platform.CreateAPIGatewayOptions{
+				APIGatewayConfig:           apiGatewayConfig,
+				ValidateFunctionsExistence: true,
+			})
+		}
+		return planItem, nil
+	}
+
+	if err := a.setDeclaredDifferences(planItem, apiGateways[0].GetConfig(), apiGatewayConfig); err != nil {
+		return nil, err
+	}
+
+	planItem.execute = func(ctx context.Context) error {
+		return a.rootCommandeer.platform.UpdateAPIGateway(ctx, &platform.UpdateAPIGatewayOptions{
+			APIGatewayConfig:           apiGatewayConfig,
+			ValidateFunctionsExistence: true,
+		})
+	}
+
+	return planItem, nil
+}
+
+// planPrune plans deleting the resources of the apply set that the manifests no longer declare
+func (a *applyCommandeer) planPrune(ctx context.Context, manifests *manifests) ([]*applyPlanItem, error) {
+	var plan []*applyPlanItem
+
+	declared := map[string]bool{}
+	declare := func(kind string, namespace string, name string) {
+		declared[fmt.Sprintf("%s/%s/%s", kind, namespace, name)] = true
+	}
+	isDeclared := func(kind string, namespace string, name string) bool {
+		return declared[fmt.Sprintf("%s/%s/%s", kind, namespace, name)]
+	}
+	isInApplySet := func(labels map[string]string) bool {
+		return labels[common.NuclioResourceLabelKeyApplySet] == a.applySet
+	}
+
+	// prune in every namespace the manifests apply to
+	namespaces := []string{a.rootCommandeer.namespace}
+	addNamespace := func(namespace string) {
+		if !common.StringSliceContainsString(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
+	for _, projectConfig := range manifests.projects {
+		declare(manifestKindProject, projectConfig.Meta.Namespace, projectConfig.Meta.Name)
+		addNamespace(projectConfig.Meta.Namespace)
+	}
+	for _, function := range manifests.functions {
+		declare(manifestKindFunction, function.config.Meta.Namespace, function.config.Meta.Name)
+		addNamespace(function.config.Meta.Namespace)
+	}
+	for _, functionEventConfig := range manifests.functionEvents {
+		declare(manifestKindFunctionEvent, functionEventConfig.Meta.Namespace, functionEventConfig.Meta.Name)
+		addNamespace(functionEventConfig.Meta.Namespace)
+	}
+	for _, apiGatewayConfig := range manifests.apiGateways {
+		declare(manifestKindAPIGateway, apiGatewayConfig.Meta.Namespace, apiGatewayConfig.Meta.Name)
+		addNamespace(apiGatewayConfig.Meta.Namespace)
+	}
+
+	applySetSelector := fmt.Sprintf("%s=%s", common.NuclioResourceLabelKeyApplySet, a.applySet)
+
+	// api gateways first, as they depend on functions
+	if a.rootCommandeer.platform.GetName() == common.KubePlatformName {
+		for _, namespace := range namespaces {
+			apiGateways, err := a.rootCommandeer.platform.GetAPIGateways(ctx, &platform.GetAPIGatewaysOptions{
+				Namespace: namespace,
+				Labels:    applySetSelector,
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed to get API gateways")
+			}
+
+			for _, apiGateway := range apiGateways {
+				apiGatewayConfig := apiGateway.GetConfig()
+				if isDeclared(manifestKindAPIGateway, namespace, apiGatewayConfig.Meta.Name) {
+					continue
+				}
+
+				plan = append(plan, &applyPlanItem{
+					action:    applyActionDelete,
+					kind:      manifestKindAPIGateway,
+					namespace: namespace,
+					name:      apiGatewayConfig.Meta.Name,
+					execute: func(ctx context.Context) error {
+						return a.rootCommandeer.platform.DeleteAPIGateway(ctx, &platform.DeleteAPIGatewayOptions{
+							Meta: apiGatewayConfig.Meta,
+						})
+					},
+				})
+			}
+		}
+	}
+
+	for _, namespace := range namespaces {
+		functionEvents, err := a.rootCommandeer.platform.GetFunctionEvents(ctx, &platform.GetFunctionEventsOptions{
+			Meta: platform.FunctionEventMeta{
+				Namespace: namespace,
+			},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to get function events")
+		}
+
+		for _, functionEvent := range functionEvents {
+			functionEventConfig := functionEvent.GetConfig()
+			if !isInApplySet(functionEventConfig.Meta.Labels) ||
+				isDeclared(manifestKindFunctionEvent, namespace, functionEventConfig.Meta.Name) {
+				continue
+			}
+
+			plan = append(plan, &applyPlanItem{
+				action:    applyActionDelete,
+				kind:      manifestKindFunctionEvent,
+				namespace: namespace,
+				name:      functionEventConfig.Meta.Name,
+				execute: func(ctx context.Context) error {
+					return a.rootCommandeer.platform.DeleteFunctionEvent(ctx, &platform.DeleteFunctionEventOptions{
+						Meta: functionEventConfig.Meta,
+					})
+				},
+			})
+		}
+	}
+
+	for _, namespace := range namespaces {
+		functions, err := a.rootCommandeer.platform.GetFunctions(ctx, &platform.GetFunctionsOptions{
+			Namespace: namespace,
+			Labels:    applySetSelector,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to get functions")
+		}
+
+		for _, function := range functions {
+			functionConfig := function.GetConfig()
+			if isDeclared(manifestKindFunction, namespace, functionConfig.Meta.Name) {
+				continue
+			}
+
+			plan = append(plan, &applyPlanItem{
+				action:    applyActionDelete,
+				kind:      manifestKindFunction,
+				namespace: namespace,
+				name:      functionConfig.Meta.Name,
+				execute: func(ctx context.Context) error {
+					return a.rootCommandeer.platform.DeleteFunction(ctx, &platform.DeleteFunctionOptions{
+						FunctionConfig: *functionConfig,
+					})
+				},
+			})
+		}
+	}
+
+	for _, namespace := range namespaces {
+		projects, err := a.rootCommandeer.platform.GetProjects(ctx, &platform.GetProjectsOptions{
+			Meta: platform.ProjectMeta{
+				Namespace: namespace,
+			},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to get projects")
+		}
+
+		for _, project := range projects {
+			projectConfig := project.GetConfig()
+			if !isInApplySet(projectConfig.Meta.Labels) ||
+				isDeclared(manifestKindProject, namespace, projectConfig.Meta.Name) {
+				continue
+			}
+
+			plan = append(plan, &applyPlanItem{
+				action:    applyActionDelete,
+				kind:      manifestKindProject,
+				namespace: namespace,
+				name:      projectConfig.Meta.Name,
+				execute: func(ctx context.Context) error {
+					return a.rootCommandeer.platform.DeleteProject(ctx, &platform.DeleteProjectOptions{
+						Meta:     projectConfig.Meta,
+						Strategy: platform.DeleteProjectStrategyRestricted,
+					})
+				},
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+// setDeclaredDifferences sets the plan item's differences between the existing resource and its manifest to
+// those of the fields the manifest declares, so that fields the platform fills in aren't reported as changes
+func (a *applyCommandeer) setDeclaredDifferences(planItem *applyPlanItem,
+	existing interface{},
+	declared interface{}) error {
+
+	differences, err := functionconfig.DiffObjects(existing, declared)
+	if err != nil {
+		return errors.Wrap(err, "Failed to compare with the existing resource")
+	}
+
+	planItem.differences = nil
+	for _, difference := range differences {
+		if difference.To == nil || strings.HasPrefix(difference.Path, "metadata.annotations.nuclio.io/applied-") {
+			continue
+		}
+		planItem.differences = append(planItem.differences, difference)
+	}
+
+	if len(planItem.differences) > 0 {
+		planItem.action = applyActionUpdate
+	} else {
+		planItem.action = applyActionUnchanged
+	}
+
+	return nil
+}
+
+func (a *applyCommandeer) executePlan(ctx context.Context, plan []*applyPlanItem) error {
+	for _, planItem := range plan {
+		if planItem.action == applyActionUnchanged {
+			continue
+		}
+
+		a.rootCommandeer.loggerInstance.InfoWithCtx(ctx,
+			"Applying",
+			"action", planItem.action,
+			"kind", planItem.kind,
+			"namespace", planItem.namespace,
+			"name", planItem.name)
+
+		if err := planItem.execute(ctx); err != nil {
+			return errors.Wrapf(err, "Failed to %s %s %s", planItem.action, planItem.kind, planItem.name)
+		}
+	}
+
+	return nil
+}
+
+// renderApplyPlan renders the plan as a table, followed by the changed fields of each updated resource
+func renderApplyPlan(plan []*applyPlanItem, writer io.Writer) error {
+	var records [][]string
+	for _, planItem := range plan {
+		build := ""
+		if planItem.kind == manifestKindFunction && planItem.action != applyActionDelete {
+			build = "no"
+			if planItem.build {
+				build = "yes"
+			}
+		}
+
+		records = append(records, []string{
+			string(planItem.action),
+			planItem.kind,
+			planItem.namespace,
+			planItem.name,
+			build,
+		})
+	}
+
+	renderer.NewRenderer(writer).RenderTable([]string{"Action", "Kind", "Namespace", "Name", "Build"}, records)
+
+	for _, planItem := range plan {
+		if planItem.action != applyActionUpdate || len(planItem.differences) == 0 {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(writer, "\n%s %s:\n", planItem.kind, planItem.name); err != nil {
+			return errors.Wrap(err, "Failed to write plan")
+		}
+
+		if err := nuctlcommon.RenderConfigDifferences(planItem.differences, writer); err != nil {
+			return errors.Wrap(err, "Failed to render differences")
+		}
+	}
+
+	return nil
+}
+
+// computeFunctionConfigHash hashes the function configuration, excluding what the build hash covers
+func computeFunctionConfigHash(functionConfig *functionconfig.Config) (string, error) {
+	hashedConfig := *functionConfig
+	hashedConfig.Spec.Build = functionconfig.Build{}
+
+	encodedConfig, err := json.Marshal(hashedConfig)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to encode function configuration")
+	}
+
+	configHash := sha256.Sum256(encodedConfig)
+	return hex.EncodeToString(configHash[:]), nil
+}
+
+// computeFunctionBuildHash hashes everything that goes into the function image - its runtime, build
+// configuration and local source files - so that an unchanged image can be redeployed without a build
+func computeFunctionBuildHash(function *functionManifest) (string, error) {
+	buildHash := sha256.New()
+
+	build := function.config.Spec.Build
+	localSource := isLocalSourcePath(build.Path)
+
+	// the local path differs between checkouts, its contents are what matters
+	if localSource {
+		build.Path = ""
+	}
+
+	encodedBuild, err := json.Marshal(struct {
+		Runtime string               `json:"runtime"`
+		Build   functionconfig.Build `json:"build"`
+	}{
+		Runtime: function.config.Spec.Runtime,
+		Build:   build,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to encode build configuration")
+	}
+
+	buildHash.Write(encodedBuild) // nolint: errcheck
+
+	if localSource {
+		if err := hashLocalSource(buildHash, function.config.Spec.Build.Path, function.path); err != nil {
+			return "", errors.Wrap(err, "Failed to hash source files")
+		}
+	}
+
+	return hex.EncodeToString(buildHash.Sum(nil)), nil
+}
+
+// hasRemoteSource returns whether the function is built from a source that can't be hashed (e.g. git, an archive
+// or s3), which may change without its url changing
+func hasRemoteSource(functionBuild functionconfig.Build) bool {
+	switch functionBuild.CodeEntryType {
+	case build.GitEntryType, build.GithubEntryType, build.ArchiveEntryType, build.S3EntryType:
+		return true
+	}
+
+	return functionBuild.Path != "" && !isLocalSourcePath(functionBuild.Path)
+}
+
+// hashLocalSource hashes the names and contents of the source files, skipping the function's own manifest
+func hashLocalSource(sourceHash hash.Hash, sourcePath string, manifestPath string) error {
+	return filepath.WalkDir(sourcePath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || filePath == manifestPath {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(sourcePath, filePath)
+		if err != nil {
+			return errors.Wrap(err, "Failed to resolve relative path")
+		}
+
+		contents, err := os.ReadFile(filePath)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to read %s", filePath)
+		}
+
+		sourceHash.Write([]byte(relativePath + "\x00")) // nolint: errcheck
+		sourceHash.Write(contents)                      // nolint: errcheck
+
+		return nil
+	})
+}
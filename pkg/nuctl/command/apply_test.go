@@ -0,0 +1,261 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/platform"
+	mockplatform "github.com/nuclio/nuclio/pkg/platform/mock"
+
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"k8s.io/api/core/v1"
+)
+
+type applyTestSuite struct {
+	suite.Suite
+	ctx          context.Context
+	mockPlatform *mockplatform.Platform
+	commandeer   *applyCommandeer
+	sourceDir    string
+}
+
+func (suite *applyTestSuite) SetupTest() {
+	loggerInstance, err := nucliozap.NewNuclioZapTest("test")
+	suite.Require().NoError(err)
+
+	suite.ctx = context.Background()
+	suite.mockPlatform = &mockplatform.Platform{}
+	suite.commandeer = &applyCommandeer{
+		rootCommandeer: &RootCommandeer{
+			loggerInstance: loggerInstance,
+			platform:       suite.mockPlatform,
+			namespace:      "default-namespace",
+		},
+		applySet: "my-apply-set",
+	}
+
+	suite.sourceDir = suite.T().TempDir()
+	err = os.WriteFile(filepath.Join(suite.sourceDir, "main.py"), []byte("def handler(context, event):\n    pass\n"), 0644)
+	suite.Require().NoError(err)
+}
+
+func (suite *applyTestSuite) TestPlanFunctionCreate() {
+	suite.mockPlatform.
+		On("GetFunctions", mock.Anything, mock.Anything).
+		Return([]platform.Function{}, nil).
+		Once()
+
+	planItem, err := suite.commandeer.planFunction(suite.ctx, suite.newFunctionManifest("value"))
+	suite.Require().NoError(err)
+	suite.Require().Equal(applyActionCreate, planItem.action)
+	suite.Require().True(planItem.build)
+	suite.Require().Equal("default-namespace", planItem.namespace)
+}
+
+func (suite *applyTestSuite) TestPlanFunctionUnchanged() {
+	suite.mockExistingFunction(suite.appliedFunctionConfig("value"), "my-image:latest")
+
+	planItem, err := suite.commandeer.planFunction(suite.ctx, suite.newFunctionManifest("value"))
+	suite.Require().NoError(err)
+	suite.Require().Equal(applyActionUnchanged, planItem.action)
+	suite.Require().Empty(planItem.differences)
+}
+
+func (suite *applyTestSuite) TestPlanFunctionUpdateWithoutBuild() {
+	suite.mockExistingFunction(suite.appliedFunctionConfig("value"), "my-image:latest")
+
+	planItem, err := suite.commandeer.planFunction(suite.ctx, suite.newFunctionManifest("other-value"))
+	suite.Require().NoError(err)
+	suite.Require().Equal(applyActionUpdate, planItem.action)
+	suite.Require().False(planItem.build)
+	suite.Require().Len(planItem.differences, 1)
+	suite.Require().Equal("spec.env[MY_ENV].value", planItem.differences[0].Path)
+
+	// the current image is redeployed as is
+	suite.mockPlatform.
+		On("CreateFunction", mock.Anything, mock.MatchedBy(func(createFunctionOptions *platform.CreateFunctionOptions) bool {
+			return createFunctionOptions.FunctionConfig.Spec.Build.Mode == functionconfig.NeverBuild &&
+				createFunctionOptions.FunctionConfig.Spec.Image == "my-image:latest"
+		})).
+		Return(&platform.CreateFunctionResult{}, nil).
+		Once()
+
+	err = planItem.execute(suite.ctx)
+	suite.Require().NoError(err)
+	suite.mockPlatform.AssertExpectations(suite.T())
+}
+
+func (suite *applyTestSuite) TestPlanFunctionUpdateWithBuild() {
+	suite.mockExistingFunction(suite.appliedFunctionConfig("value"), "my-image:latest")
+
+	// change the source after it was applied
+	err := os.WriteFile(filepath.Join(suite.sourceDir, "main.py"), []byte("def handler(context, event):\n    return 1\n"), 0644)
+	suite.Require().NoError(err)
+
+	planItem, err := suite.commandeer.planFunction(suite.ctx, suite.newFunctionManifest("value"))
+	suite.Require().NoError(err)
+	suite.Require().Equal(applyActionUpdate, planItem.action)
+	suite.Require().True(planItem.build)
+}
+
+func (suite *applyTestSuite) TestPlanFunctionRemoteSource() {
+	appliedConfig := suite.appliedFunctionConfig("value")
+	appliedConfig.Spec.Build.Path = "https://example.com/my-function.zip"
+	appliedConfig.Spec.Build.CodeEntryType = "archive"
+	suite.mockExistingFunction(appliedConfig, "my-image:latest")
+
+	function := suite.newFunctionManifest("value")
+	function.config.Spec.Build.Path = "https://example.com/my-function.zip"
+	function.config.Spec.Build.CodeEntryType = "archive"
+
+	buildHash, err := computeFunctionBuildHash(function)
+	suite.Require().NoError(err)
+	appliedConfig.Meta.Annotations[common.NuclioResourceAnnotationKeyAppliedBuildHash] = buildHash
+
+	// the archive may have changed behind the same url
+	planItem, err := suite.commandeer.planFunction(suite.ctx, function)
+	suite.Require().NoError(err)
+	suite.Require().Equal(applyActionUpdate, planItem.action)
+	suite.Require().True(planItem.build)
+}
+
+func (suite *applyTestSuite) TestPlanPrune() {
+	declaredFunction := suite.newFunctionManifest("value")
+	declaredFunction.config.Meta.Namespace = "default-namespace"
+
+	prunedFunctionConfig := &functionconfig.Config{
+		Meta: functionconfig.Meta{
+			Name:      "pruned-function",
+			Namespace: "default-namespace",
+			Labels: map[string]string{
+				common.NuclioResourceLabelKeyApplySet: "my-apply-set",
+			},
+		},
+	}
+
+	suite.mockPlatform.On("GetName").Return(common.LocalPlatformName)
+	suite.mockPlatform.
+		On("GetFunctionEvents", mock.Anything, mock.Anything).
+		Return([]platform.FunctionEvent{}, nil).
+		Once()
+
+	var appliedFunctions []platform.Function
+	for _, functionConfig := range []*functionconfig.Config{declaredFunction.config, prunedFunctionConfig} {
+		appliedFunction, err := platform.NewAbstractFunction(suite.commandeer.rootCommandeer.loggerInstance,
+			suite.mockPlatform,
+			functionConfig,
+			&functionconfig.Status{},
+			nil)
+		suite.Require().NoError(err)
+		appliedFunctions = append(appliedFunctions, appliedFunction)
+	}
+
+	suite.mockPlatform.
+		On("GetFunctions", mock.Anything, &platform.GetFunctionsOptions{
+			Namespace: "default-namespace",
+			Labels:    "nuclio.io/apply-set=my-apply-set",
+		}).
+		Return(appliedFunctions, nil).
+		Once()
+	suite.mockPlatform.
+		On("GetProjects", mock.Anything, mock.Anything).
+		Return([]platform.Project{}, nil).
+		Once()
+
+	plan, err := suite.commandeer.planPrune(suite.ctx, &manifests{
+		functions: []*functionManifest{declaredFunction},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(plan, 1)
+	suite.Require().Equal(applyActionDelete, plan[0].action)
+	suite.Require().Equal("pruned-function", plan[0].name)
+}
+
+func (suite *applyTestSuite) newFunctionManifest(envValue string) *functionManifest {
+	function := &functionManifest{
+		config: &functionconfig.Config{
+			Meta: functionconfig.Meta{
+				Name: "my-function",
+			},
+			Spec: functionconfig.Spec{
+				Runtime: "python:3.9",
+				Handler: "main:handler",
+				Env: []v1.EnvVar{
+					{Name: "MY_ENV", Value: envValue},
+				},
+				Build: functionconfig.Build{
+					Path: suite.sourceDir,
+				},
+			},
+		},
+	}
+
+	suite.commandeer.enrichManifests(&manifests{functions: []*functionManifest{function}})
+
+	return function
+}
+
+// appliedFunctionConfig returns the configuration of a function that was applied with the given env value
+func (suite *applyTestSuite) appliedFunctionConfig(envValue string) *functionconfig.Config {
+	function := suite.newFunctionManifest(envValue)
+
+	configHash, err := computeFunctionConfigHash(function.config)
+	suite.Require().NoError(err)
+
+	buildHash, err := computeFunctionBuildHash(function)
+	suite.Require().NoError(err)
+
+	function.config.Meta.Annotations = map[string]string{
+		common.NuclioResourceAnnotationKeyAppliedConfigHash: configHash,
+		common.NuclioResourceAnnotationKeyAppliedBuildHash:  buildHash,
+	}
+
+	// fields the platform fills in aren't changes
+	function.config.Spec.Replicas = nil
+	function.config.Spec.Build.FunctionSourceCode = "ZGVmIGhhbmRsZXI="
+	function.config.Meta.Labels[common.NuclioResourceLabelKeyProjectName] = "default"
+
+	return function.config
+}
+
+func (suite *applyTestSuite) mockExistingFunction(functionConfig *functionconfig.Config, image string) {
+	existingFunction, err := platform.NewAbstractFunction(suite.commandeer.rootCommandeer.loggerInstance,
+		suite.mockPlatform,
+		functionConfig,
+		&functionconfig.Status{ContainerImage: image},
+		nil)
+	suite.Require().NoError(err)
+
+	suite.mockPlatform.
+		On("GetFunctions", mock.Anything, mock.Anything).
+		Return([]platform.Function{existingFunction}, nil).
+		Once()
+}
+
+func TestApplyTestSuite(t *testing.T) {
+	suite.Run(t, new(applyTestSuite))
+}
@@ -0,0 +1,621 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	nuctlcommon "github.com/nuclio/nuclio/pkg/nuctl/command/common"
+	"github.com/nuclio/nuclio/pkg/platformconfig"
+	"github.com/nuclio/nuclio/pkg/renderer"
+
+	"github.com/nuclio/errors"
+	"github.com/spf13/cobra"
+)
+
+// benchReport summarizes the responses to a benchmark's requests
+type benchReport struct {
+	Requests               int                    `json:"requests"`
+	DurationSeconds        float64                `json:"durationSeconds"`
+	Throughput             float64                `json:"throughput"`
+	Latency                benchLatency           `json:"latency"`
+	StatusCodes            map[int]int            `json:"statusCodes,omitempty"`
+	TransportErrors        int                    `json:"transportErrors"`
+	ErrorRate              float64                `json:"errorRate"`
+	ServiceUnavailableRate float64                `json:"serviceUnavailableRate"`
+	WorkerAllocation       *benchWorkerAllocation `json:"workerAllocation,omitempty"`
+}
+
+// benchLatency holds latency percentiles, in milliseconds
+type benchLatency struct {
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// benchWorkerAllocation holds the processors' worker allocations during the benchmark
+type benchWorkerAllocation struct {
+	Allocations            uint64  `json:"allocations"`
+	Immediate              uint64  `json:"immediate"`
+	AfterWait              uint64  `json:"afterWait"`
+	Timeouts               uint64  `json:"timeouts"`
+	MeanWaitMilliseconds   float64 `json:"meanWaitMilliseconds"`
+	WaitedOrTimedOutRate   float64 `json:"waitedOrTimedOutRate"`
+	EventsHandledSuccesses uint64  `json:"eventsHandledSuccesses"`
+	EventsHandledFailures  uint64  `json:"eventsHandledFailures"`
+}
+
+// triggerStatistics is a trigger's statistics, as returned by the processor's web admin
+type triggerStatistics struct {
+	EventsHandledSuccessTotal                   uint64 `json:"eventsHandledSuccessTotal"`
+	EventsHandledFailureTotal                   uint64 `json:"eventsHandledFailureTotal"`
+	WorkerAllocationCount                       uint64 `json:"workerAllocationCount"`
+	WorkerAllocationSuccessImmediateTotal       uint64 `json:"workerAllocationSuccessImmediateTotal"`
+	WorkerAllocationSuccessAfterWaitTotal       uint64 `json:"workerAllocationSuccessAfterWaitTotal"`
+	WorkerAllocationTimeoutTotal                uint64 `json:"workerAllocationTimeoutTotal"`
+	WorkerAllocationWaitDurationMilliSecondsSum uint64 `json:"workerAllocationWaitDurationMilliSecondsSum"`
+}
+
+func (s *triggerStatistics) add(other *triggerStatistics) {
+	s.EventsHandledSuccessTotal += other.EventsHandledSuccessTotal
+	s.EventsHandledFailureTotal += other.EventsHandledFailureTotal
+	s.WorkerAllocationCount += other.WorkerAllocationCount
+	s.WorkerAllocationSuccessImmediateTotal += other.WorkerAllocationSuccessImmediateTotal
+	s.WorkerAllocationSuccessAfterWaitTotal += other.WorkerAllocationSuccessAfterWaitTotal
+	s.WorkerAllocationTimeoutTotal += other.WorkerAllocationTimeoutTotal
+	s.WorkerAllocationWaitDurationMilliSecondsSum += other.WorkerAllocationWaitDurationMilliSecondsSum
+}
+
+type benchResponse struct {
+	statusCode int
+	latency    time.Duration
+}
+
+type benchCommandeer struct {
+	cmd                 *cobra.Command
+	rootCommandeer      *RootCommandeer
+	url                 string
+	invokeVia           string
+	externalIPAddresses string
+	duration            time.Duration
+	requests            int
+	concurrency         int
+	rate                float64
+	method              string
+	path                string
+	body                string
+	headers             string
+	contentType         string
+	timeout             time.Duration
+	webAdminURLs        []string
+	triggerNames        []string
+	output              string
+	skipTLSVerification bool
+}
+
+func newBenchCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *benchCommandeer {
+	commandeer := &benchCommandeer{
+		rootCommandeer: rootCommandeer,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "bench function-name",
+		Short: "Load a function's HTTP trigger and report latency, throughput and errors",
+		Long: `Load a function's HTTP trigger and report latency, throughput and errors.
+
+Sends requests to the function from --concurrency clients for --duration (or until --requests were
+sent), at up to --rate requests per second across all clients (default - as fast as they can), and
+reports latency percentiles, throughput, and the error and 503 (no available worker) rates.
+
+With --webadmin-url, the processors' trigger statistics are read before and after the benchmark, to
+report how many worker allocations had to wait for a worker or timed out, and for how long they
+waited - helping size the trigger's maxWorkers and the function's replicas.
+
+Arguments:
+  <function-name> (string) The name of the function to benchmark`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("Function bench requires name")
+			}
+			functionName := args[0]
+
+			if err := commandeer.validate(); err != nil {
+				return errors.Wrap(err, "Invalid benchmark")
+			}
+
+			// the platform is only needed to find the deployed function
+			var err error
+			if commandeer.url == "" {
+				if err := rootCommandeer.initialize(); err != nil {
+					return errors.Wrap(err, "Failed to initialize root")
+				}
+			} else if rootCommandeer.loggerInstance, err = rootCommandeer.createLogger(); err != nil {
+				return errors.Wrap(err, "Failed to create logger")
+			}
+
+			// resolve the function's url the same way as test
+			functionTestCommandeer := &functionTestCommandeer{
+				rootCommandeer:      rootCommandeer,
+				url:                 commandeer.url,
+				invokeVia:           commandeer.invokeVia,
+				externalIPAddresses: commandeer.externalIPAddresses,
+				timeout:             commandeer.timeout,
+				skipTLSVerification: commandeer.skipTLSVerification,
+			}
+
+			invocationURL, err := functionTestCommandeer.resolveInvocationURL(ctx, functionName)
+			if err != nil {
+				return errors.Wrap(err, "Failed to resolve invocation URL")
+			}
+
+			request, err := commandeer.resolveRequest(functionName, invocationURL)
+			if err != nil {
+				return errors.Wrap(err, "Failed to resolve request")
+			}
+
+			httpClient := functionTestCommandeer.createHTTPClient()
+			httpClient.Transport = commandeer.createTransport(httpClient.Transport)
+
+			var statisticsBefore *triggerStatistics
+			if len(commandeer.webAdminURLs) > 0 {
+				if statisticsBefore, err = commandeer.getTriggerStatistics(ctx, httpClient); err != nil {
+					return errors.Wrap(err, "Failed to get trigger statistics")
+				}
+			}
+
+			rootCommandeer.loggerInstance.InfoWithCtx(ctx,
+				"Running benchmark",
+				"url", request.URL.String(),
+				"duration", commandeer.duration,
+				"concurrency", commandeer.concurrency,
+				"rate", commandeer.rate)
+
+			responses, duration := commandeer.run(ctx, httpClient, request)
+			report := createBenchReport(responses, duration)
+
+			if statisticsBefore != nil {
+				statisticsAfter, err := commandeer.getTriggerStatistics(ctx, httpClient)
+				if err != nil {
+					return errors.Wrap(err, "Failed to get trigger statistics")
+				}
+
+				report.WorkerAllocation = createBenchWorkerAllocation(statisticsBefore, statisticsAfter)
+			}
+
+			return commandeer.renderReport(cmd.OutOrStdout(), report)
+		},
+	}
+
+	cmd.Flags().StringVar(&commandeer.url, "url", "", "Send the requests to this URL instead of the deployed function (e.g. localhost:8080)")
+	cmd.Flags().StringVarP(&commandeer.invokeVia, "via", "", "any", "Invoke the function via - \"any\": a load balancer or an external IP; \"loadbalancer\": a load balancer; \"external-ip\": an external IP")
+	cmd.Flags().StringVarP(&commandeer.externalIPAddresses, "external-ips", "", os.Getenv("NUCTL_EXTERNAL_IP_ADDRESSES"), "External IP addresses (comma-delimited) with which to invoke the function")
+	cmd.Flags().DurationVar(&commandeer.duration, "duration", 10*time.Second, "How long to send requests for")
+	cmd.Flags().IntVar(&commandeer.requests, "requests", 0, "Stop after sending this many requests (default - no limit)")
+	cmd.Flags().IntVarP(&commandeer.concurrency, "concurrency", "c", 10, "Number of concurrent clients")
+	cmd.Flags().Float64Var(&commandeer.rate, "rate", 0, "Requests per second across all clients (default - no limit)")
+	cmd.Flags().StringVarP(&commandeer.method, "method", "m", "", "HTTP method (default - POST if a body is given, GET otherwise)")
+	cmd.Flags().StringVarP(&commandeer.path, "path", "p", "", "Path of the requests")
+	cmd.Flags().StringVarP(&commandeer.body, "body", "b", "", "HTTP message body")
+	cmd.Flags().StringVarP(&commandeer.headers, "headers", "d", "", "HTTP headers (name=val1[,name=val2,...])")
+	cmd.Flags().StringVarP(&commandeer.contentType, "content-type", "", "", "HTTP Content-Type")
+	cmd.Flags().DurationVarP(&commandeer.timeout, "timeout", "t", platformconfig.DefaultFunctionInvocationTimeoutSeconds*time.Second, "Request timeout")
+	cmd.Flags().StringSliceVar(&commandeer.webAdminURLs, "webadmin-url", nil, "URLs of the function's processors' web admin (e.g. localhost:8081), one per replica, to report worker allocation statistics")
+	cmd.Flags().StringSliceVar(&commandeer.triggerNames, "trigger", nil, "Names of the triggers whose statistics are reported (default - the HTTP triggers)")
+	cmd.Flags().StringVarP(&commandeer.output, "output", "o", nuctlcommon.OutputFormatText, "Output format - \"text\", \"json\", or \"yaml\"")
+	cmd.Flags().BoolVarP(&commandeer.skipTLSVerification, "skip-tls", "", false, "Skip TLS verification")
+
+	commandeer.cmd = cmd
+
+	return commandeer
+}
+
+func (b *benchCommandeer) validate() error {
+	if b.duration <= 0 && b.requests <= 0 {
+		return errors.New("Either a duration or a number of requests must be given")
+	}
+
+	if b.concurrency <= 0 {
+		return errors.New("Concurrency must be positive")
+	}
+
+	if b.rate < 0 {
+		return errors.New("Rate can't be negative")
+	}
+
+	switch b.output {
+	case nuctlcommon.OutputFormatText, nuctlcommon.OutputFormatJSON, nuctlcommon.OutputFormatYAML:
+	default:
+		return errors.Errorf("Unsupported output format %s", b.output)
+	}
+
+	return nil
+}
+
+// resolveRequest creates the request sent by all clients, whose body is replayed on each send
+func (b *benchCommandeer) resolveRequest(functionName string, invocationURL string) (*http.Request, error) {
+	method := b.method
+	if method == "" {
+		method = http.MethodGet
+		if b.body != "" {
+			method = http.MethodPost
+		}
+	}
+
+	request, err := http.NewRequest(method,
+		invocationURL+"/"+strings.TrimLeft(b.path, "/"),
+		bytes.NewReader([]byte(b.body)))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create HTTP request")
+	}
+
+	for headerName, headerValue := range common.StringToStringMap(b.headers, "=") {
+		request.Header.Set(headerName, headerValue)
+	}
+	if b.contentType != "" {
+		request.Header.Set("Content-Type", b.contentType)
+	}
+	request.Header.Set("x-nuclio-target", functionName)
+
+	return request, nil
+}
+
+// createTransport keeps a connection per client alive between requests, as the default transport only keeps two
+func (b *benchCommandeer) createTransport(transport http.RoundTripper) http.RoundTripper {
+	benchTransport := http.DefaultTransport.(*http.Transport).Clone()
+	if httpTransport, ok := transport.(*http.Transport); ok {
+		benchTransport.TLSClientConfig = httpTransport.TLSClientConfig
+	}
+
+	benchTransport.MaxIdleConnsPerHost = b.concurrency
+
+	return benchTransport
+}
+
+// run sends requests until the duration passes or the requests are sent, returning the responses and how long it took
+func (b *benchCommandeer) run(ctx context.Context,
+	httpClient *http.Client,
+	request *http.Request) ([]benchResponse, time.Duration) {
+
+	runCtx := ctx
+	if b.duration > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, b.duration)
+		defer cancel()
+	}
+
+	// each token lets a client send a request, so the clients are limited by the token rate
+	tokens := make(chan struct{})
+	go b.produceTokens(runCtx, tokens)
+
+	var responsesLock sync.Mutex
+	var responses []benchResponse
+	var clientsWaitGroup sync.WaitGroup
+
+	startTime := time.Now()
+
+	for clientIndex := 0; clientIndex < b.concurrency; clientIndex++ {
+		clientsWaitGroup.Add(1)
+
+		go func() {
+			defer clientsWaitGroup.Done()
+
+			for range tokens {
+				response := b.send(runCtx, httpClient, request)
+
+				// requests cut short by the end of the benchmark aren't counted
+				if runCtx.Err() != nil && response.statusCode == 0 {
+					return
+				}
+
+				responsesLock.Lock()
+				responses = append(responses, response)
+				responsesLock.Unlock()
+			}
+		}()
+	}
+
+	clientsWaitGroup.Wait()
+
+	return responses, time.Since(startTime)
+}
+
+func (b *benchCommandeer) produceTokens(ctx context.Context, tokens chan<- struct{}) {
+	defer close(tokens)
+
+	var ticker *time.Ticker
+	if b.rate > 0 {
+		ticker = time.NewTicker(time.Duration(float64(time.Second) / b.rate))
+		defer ticker.Stop()
+	}
+
+	for sentRequests := 0; b.requests == 0 || sentRequests < b.requests; sentRequests++ {
+		if ticker != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case tokens <- struct{}{}:
+		}
+	}
+}
+
+func (b *benchCommandeer) send(ctx context.Context, httpClient *http.Client, request *http.Request) benchResponse {
+	clonedRequest := request.Clone(ctx)
+
+	// the body is read by each send, so each needs its own
+	if request.GetBody != nil {
+		clonedRequest.Body, _ = request.GetBody()
+	}
+
+	startTime := time.Now()
+
+	response, err := httpClient.Do(clonedRequest)
+	if err != nil {
+		return benchResponse{latency: time.Since(startTime)}
+	}
+
+	io.Copy(io.Discard, response.Body) // nolint: errcheck
+	response.Body.Close()              // nolint: errcheck
+
+	return benchResponse{
+		statusCode: response.StatusCode,
+		latency:    time.Since(startTime),
+	}
+}
+
+// getTriggerStatistics sums the statistics of the benchmarked triggers across the processors' web admins
+func (b *benchCommandeer) getTriggerStatistics(ctx context.Context,
+	httpClient *http.Client) (*triggerStatistics, error) {
+	statistics := &triggerStatistics{}
+
+	for _, webAdminURL := range b.webAdminURLs {
+		if !strings.HasPrefix(webAdminURL, "http://") && !strings.HasPrefix(webAdminURL, "https://") {
+			webAdminURL = "http://" + webAdminURL
+		}
+		webAdminURL = strings.TrimRight(webAdminURL, "/")
+
+		triggerNames := b.triggerNames
+		if len(triggerNames) == 0 {
+			triggers := map[string]map[string]interface{}{}
+			if err := getWebAdminResource(ctx, httpClient, webAdminURL+"/triggers", &triggers); err != nil {
+				return nil, errors.Wrapf(err, "Failed to get triggers from %s", webAdminURL)
+			}
+
+			for triggerName, triggerConfiguration := range triggers {
+				if triggerConfiguration["kind"] == "http" {
+					triggerNames = append(triggerNames, triggerName)
+				}
+			}
+
+			if len(triggerNames) == 0 {
+				return nil, errors.Errorf("No HTTP triggers found in %s", webAdminURL)
+			}
+		}
+
+		for _, triggerName := range triggerNames {
+			triggerStatistics := &triggerStatistics{}
+			if err := getWebAdminResource(ctx,
+				httpClient,
+				fmt.Sprintf("%s/triggers/%s/stats", webAdminURL, triggerName),
+				triggerStatistics); err != nil {
+				return nil, errors.Wrapf(err, "Failed to get statistics of trigger %s from %s", triggerName, webAdminURL)
+			}
+
+			statistics.add(triggerStatistics)
+		}
+	}
+
+	return statistics, nil
+}
+
+func getWebAdminResource(ctx context.Context, httpClient *http.Client, url string, resource interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create HTTP request")
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "Failed to send HTTP request")
+	}
+	defer response.Body.Close() // nolint: errcheck
+
+	if response.StatusCode != http.StatusOK {
+		return errors.Errorf("Got unexpected status %d", response.StatusCode)
+	}
+
+	return json.NewDecoder(response.Body).Decode(resource)
+}
+
+func createBenchReport(responses []benchResponse, duration time.Duration) *benchReport {
+	report := &benchReport{
+		Requests:        len(responses),
+		DurationSeconds: duration.Seconds(),
+		StatusCodes:     map[int]int{},
+	}
+
+	if len(responses) == 0 {
+		return report
+	}
+
+	var failedRequests, serviceUnavailableRequests int
+	var totalLatency time.Duration
+	latencies := make([]time.Duration, 0, len(responses))
+
+	for _, response := range responses {
+		latencies = append(latencies, response.latency)
+		totalLatency += response.latency
+
+		if response.statusCode == 0 {
+			report.TransportErrors++
+		} else {
+			report.StatusCodes[response.statusCode]++
+		}
+
+		if response.statusCode < 200 || response.statusCode > 299 {
+			failedRequests++
+		}
+		if response.statusCode == http.StatusServiceUnavailable {
+			serviceUnavailableRequests++
+		}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+
+	report.Throughput = float64(len(responses)) / duration.Seconds()
+	report.ErrorRate = float64(failedRequests) / float64(len(responses))
+	report.ServiceUnavailableRate = float64(serviceUnavailableRequests) / float64(len(responses))
+	report.Latency = benchLatency{
+		Mean: durationToMilliseconds(totalLatency / time.Duration(len(latencies))),
+		P50:  durationToMilliseconds(latencyPercentile(latencies, 50)),
+		P90:  durationToMilliseconds(latencyPercentile(latencies, 90)),
+		P95:  durationToMilliseconds(latencyPercentile(latencies, 95)),
+		P99:  durationToMilliseconds(latencyPercentile(latencies, 99)),
+		Max:  durationToMilliseconds(latencies[len(latencies)-1]),
+	}
+
+	return report
+}
+
+func createBenchWorkerAllocation(statisticsBefore *triggerStatistics,
+	statisticsAfter *triggerStatistics) *benchWorkerAllocation {
+	workerAllocation := &benchWorkerAllocation{
+		Allocations: statisticsAfter.WorkerAllocationCount - statisticsBefore.WorkerAllocationCount,
+		Immediate: statisticsAfter.WorkerAllocationSuccessImmediateTotal -
+			statisticsBefore.WorkerAllocationSuccessImmediateTotal,
+		AfterWait: statisticsAfter.WorkerAllocationSuccessAfterWaitTotal -
+			statisticsBefore.WorkerAllocationSuccessAfterWaitTotal,
+		Timeouts: statisticsAfter.WorkerAllocationTimeoutTotal - statisticsBefore.WorkerAllocationTimeoutTotal,
+		EventsHandledSuccesses: statisticsAfter.EventsHandledSuccessTotal -
+			statisticsBefore.EventsHandledSuccessTotal,
+		EventsHandledFailures: statisticsAfter.EventsHandledFailureTotal -
+			statisticsBefore.EventsHandledFailureTotal,
+	}
+
+	// only allocations that waited and got a worker count towards the wait duration
+	if workerAllocation.AfterWait > 0 {
+		waitDurationMilliseconds := statisticsAfter.WorkerAllocationWaitDurationMilliSecondsSum -
+			statisticsBefore.WorkerAllocationWaitDurationMilliSecondsSum
+		workerAllocation.MeanWaitMilliseconds = float64(waitDurationMilliseconds) / float64(workerAllocation.AfterWait)
+	}
+
+	if workerAllocation.Allocations > 0 {
+		workerAllocation.WaitedOrTimedOutRate = float64(workerAllocation.AfterWait+workerAllocation.Timeouts) /
+			float64(workerAllocation.Allocations)
+	}
+
+	return workerAllocation
+}
+
+// latencyPercentile returns the nearest-rank percentile of sorted latencies
+func latencyPercentile(sortedLatencies []time.Duration, percentile float64) time.Duration {
+	rank := int(math.Ceil(percentile / 100 * float64(len(sortedLatencies))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sortedLatencies[rank-1]
+}
+
+func durationToMilliseconds(duration time.Duration) float64 {
+	return float64(duration.Microseconds()) / 1000
+}
+
+func (b *benchCommandeer) renderReport(writer io.Writer, report *benchReport) error {
+	switch b.output {
+	case nuctlcommon.OutputFormatJSON:
+		return renderer.NewRenderer(writer).RenderJSON(report)
+	case nuctlcommon.OutputFormatYAML:
+		return renderer.NewRenderer(writer).RenderYAML(report)
+	}
+
+	formatRate := func(rate float64) string {
+		return strconv.FormatFloat(rate*100, 'f', 2, 64) + "%"
+	}
+
+	formatMilliseconds := func(milliseconds float64) string {
+		return strconv.FormatFloat(milliseconds, 'f', 2, 64) + "ms"
+	}
+
+	var statusCodes []int
+	for statusCode := range report.StatusCodes {
+		statusCodes = append(statusCodes, statusCode)
+	}
+	sort.Ints(statusCodes)
+
+	var encodedStatusCodes []string
+	for _, statusCode := range statusCodes {
+		encodedStatusCodes = append(encodedStatusCodes,
+			fmt.Sprintf("%d: %d", statusCode, report.StatusCodes[statusCode]))
+	}
+
+	records := [][]string{
+		{"Requests", strconv.Itoa(report.Requests)},
+		{"Duration", strconv.FormatFloat(report.DurationSeconds, 'f', 2, 64) + "s"},
+		{"Throughput", strconv.FormatFloat(report.Throughput, 'f', 2, 64) + " req/s"},
+		{"Latency mean", formatMilliseconds(report.Latency.Mean)},
+		{"Latency p50", formatMilliseconds(report.Latency.P50)},
+		{"Latency p90", formatMilliseconds(report.Latency.P90)},
+		{"Latency p95", formatMilliseconds(report.Latency.P95)},
+		{"Latency p99", formatMilliseconds(report.Latency.P99)},
+		{"Latency max", formatMilliseconds(report.Latency.Max)},
+		{"Status codes", strings.Join(encodedStatusCodes, ", ")},
+		{"Transport errors", strconv.Itoa(report.TransportErrors)},
+		{"Error rate", formatRate(report.ErrorRate)},
+		{"503 rate", formatRate(report.ServiceUnavailableRate)},
+	}
+
+	if workerAllocation := report.WorkerAllocation; workerAllocation != nil {
+		records = append(records,
+			[]string{"Worker allocations", strconv.FormatUint(workerAllocation.Allocations, 10)},
+			[]string{"Allocated immediately", strconv.FormatUint(workerAllocation.Immediate, 10)},
+			[]string{"Allocated after wait", strconv.FormatUint(workerAllocation.AfterWait, 10)},
+			[]string{"Allocation timeouts", strconv.FormatUint(workerAllocation.Timeouts, 10)},
+			[]string{"Allocation wait mean", formatMilliseconds(workerAllocation.MeanWaitMilliseconds)},
+			[]string{"Waited or timed out", formatRate(workerAllocation.WaitedOrTimedOutRate)},
+			[]string{"Events failed", strconv.FormatUint(workerAllocation.EventsHandledFailures, 10)},
+		)
+	}
+
+	renderer.NewRenderer(writer).RenderTable([]string{"Metric", "Value"}, records)
+
+	return nil
+}
@@ -0,0 +1,164 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/suite"
+)
+
+type benchTestSuite struct {
+	suite.Suite
+}
+
+func (suite *benchTestSuite) TestRun() {
+	var receivedRequests int64
+
+	loggerInstance, err := nucliozap.NewNuclioZapTest("test")
+	suite.Require().NoError(err)
+
+	// every fourth request is rejected as if no worker was available
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		if atomic.AddInt64(&receivedRequests, 1)%4 == 0 {
+			responseWriter.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	commandeer := &benchCommandeer{
+		rootCommandeer: &RootCommandeer{
+			loggerInstance: loggerInstance,
+		},
+		concurrency: 4,
+		timeout:     5 * time.Second,
+		requests:    40,
+		body:        "payload",
+	}
+
+	request, err := commandeer.resolveRequest("greeter", server.URL)
+	suite.Require().NoError(err, "Resolve request should succeed")
+	suite.Require().Equal(http.MethodPost, request.Method)
+
+	httpClient := &http.Client{
+		Timeout:   commandeer.timeout,
+		Transport: commandeer.createTransport(nil),
+	}
+
+	responses, duration := commandeer.run(context.Background(), httpClient, request)
+	report := createBenchReport(responses, duration)
+
+	suite.Require().Equal(40, report.Requests)
+	suite.Require().Equal(map[int]int{http.StatusOK: 30, http.StatusServiceUnavailable: 10}, report.StatusCodes)
+	suite.Require().Equal(0.25, report.ErrorRate)
+	suite.Require().Equal(0.25, report.ServiceUnavailableRate)
+}
+
+func (suite *benchTestSuite) TestCreateBenchReport() {
+	var responses []benchResponse
+	for latency := 1; latency <= 100; latency++ {
+		responses = append(responses, benchResponse{
+			statusCode: http.StatusOK,
+			latency:    time.Duration(latency) * time.Millisecond,
+		})
+	}
+	responses = append(responses, benchResponse{latency: time.Second})
+
+	report := createBenchReport(responses, 2*time.Second)
+
+	suite.Require().Equal(101, report.Requests)
+	suite.Require().Equal(50.5, report.Throughput)
+	suite.Require().Equal(1, report.TransportErrors)
+	suite.Require().InDelta(1.0/101, report.ErrorRate, 0.0001)
+	suite.Require().Equal(float64(51), report.Latency.P50)
+	suite.Require().Equal(float64(91), report.Latency.P90)
+	suite.Require().Equal(float64(100), report.Latency.P99)
+	suite.Require().Equal(float64(1000), report.Latency.Max)
+}
+
+func (suite *benchTestSuite) TestGetTriggerStatistics() {
+	loggerInstance, err := nucliozap.NewNuclioZapTest("test")
+	suite.Require().NoError(err)
+
+	statistics := map[string]triggerStatistics{
+		"http": {
+			WorkerAllocationCount:                       100,
+			WorkerAllocationSuccessImmediateTotal:       60,
+			WorkerAllocationSuccessAfterWaitTotal:       30,
+			WorkerAllocationTimeoutTotal:                10,
+			WorkerAllocationWaitDurationMilliSecondsSum: 300,
+		},
+		"cron": {
+			WorkerAllocationCount: 5,
+		},
+	}
+
+	webAdminServer := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/triggers":
+			json.NewEncoder(responseWriter).Encode(map[string]interface{}{ // nolint: errcheck
+				"http": map[string]interface{}{"kind": "http"},
+				"cron": map[string]interface{}{"kind": "cron"},
+			})
+		case "/triggers/http/stats":
+			json.NewEncoder(responseWriter).Encode(statistics["http"]) // nolint: errcheck
+		default:
+			responseWriter.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer webAdminServer.Close()
+
+	// two replicas
+	commandeer := &benchCommandeer{
+		rootCommandeer: &RootCommandeer{
+			loggerInstance: loggerInstance,
+		},
+		webAdminURLs: []string{webAdminServer.URL, webAdminServer.URL},
+	}
+
+	statisticsBefore, err := commandeer.getTriggerStatistics(context.Background(), http.DefaultClient)
+	suite.Require().NoError(err, "Get trigger statistics should succeed")
+	suite.Require().Equal(uint64(200), statisticsBefore.WorkerAllocationCount)
+
+	statisticsAfter := *statisticsBefore
+	statisticsAfter.add(statisticsBefore)
+
+	workerAllocation := createBenchWorkerAllocation(statisticsBefore, &statisticsAfter)
+	suite.Require().Equal(uint64(200), workerAllocation.Allocations)
+	suite.Require().Equal(uint64(60), workerAllocation.AfterWait)
+	suite.Require().Equal(uint64(20), workerAllocation.Timeouts)
+	suite.Require().Equal(float64(10), workerAllocation.MeanWaitMilliseconds)
+	suite.Require().Equal(0.4, workerAllocation.WaitedOrTimedOutRate)
+
+	// unknown trigger
+	commandeer.triggerNames = []string{"kafka"}
+	_, err = commandeer.getTriggerStatistics(context.Background(), http.DefaultClient)
+	suite.Require().Error(err, "Get statistics of an unknown trigger should not succeed")
+}
+
+func TestBenchTestSuite(t *testing.T) {
+	suite.Run(t, new(benchTestSuite))
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/errgroup"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	nuctlcommon "github.com/nuclio/nuclio/pkg/nuctl/command/common"
+	"github.com/nuclio/nuclio/pkg/platform"
+	"github.com/nuclio/nuclio/pkg/renderer"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/nuclio-sdk-go"
+	"github.com/spf13/cobra"
+)
+
+type bulkFunctionResultKind string
+
+const (
+	bulkFunctionResultUpdated     bulkFunctionResultKind = "updated"
+	bulkFunctionResultRedeployed  bulkFunctionResultKind = "redeployed"
+	bulkFunctionResultUnchanged   bulkFunctionResultKind = "unchanged"
+	bulkFunctionResultSkipped     bulkFunctionResultKind = "skipped"
+	bulkFunctionResultFailed      bulkFunctionResultKind = "failed"
+	bulkFunctionResultWouldUpdate bulkFunctionResultKind = "would update"
+	bulkFunctionResultWouldDeploy bulkFunctionResultKind = "would redeploy"
+)
+
+// bulkFunctionResult is the outcome of an operation on one of the selected functions
+type bulkFunctionResult struct {
+	namespace   string
+	name        string
+	kind        bulkFunctionResultKind
+	details     string
+	differences []functionconfig.ConfigDifference
+	err         error
+}
+
+// bulkFunctionCommandeer selects functions by name, project and labels, and runs an operation on each of them
+// with bounded concurrency
+type bulkFunctionCommandeer struct {
+	rootCommandeer *RootCommandeer
+	projectName    string
+	selector       string
+	concurrency    int
+	dryRun         bool
+}
+
+func (b *bulkFunctionCommandeer) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&b.projectName, "project", "", "Select the functions of a project")
+	cmd.Flags().StringVar(&b.selector, "selector", "", "Select functions by labels (lbl1=val1[,lbl2=val2,...])")
+	cmd.Flags().IntVarP(&b.concurrency, "concurrency", "c", 4, "Number of functions to deploy at once")
+	cmd.Flags().BoolVar(&b.dryRun, "dry-run", false, "Print what would be done without deploying")
+}
+
+// getFunctions returns the selected functions, sorted by name. either a function name, a project or a selector
+// is required, so that all the functions of a namespace aren't deployed by mistake
+func (b *bulkFunctionCommandeer) getFunctions(ctx context.Context, functionName string) ([]platform.Function, error) {
+	if functionName == "" && b.projectName == "" && b.selector == "" {
+		return nil, errors.New("A function name, --project or --selector is required")
+	}
+
+	if b.concurrency < 1 {
+		return nil, errors.New("Concurrency must be positive")
+	}
+
+	labels := b.selector
+	if b.projectName != "" {
+		if labels != "" {
+			labels += ","
+		}
+		labels += fmt.Sprintf("%s=%s", common.NuclioResourceLabelKeyProjectName, b.projectName)
+	}
+
+	functions, err := b.rootCommandeer.platform.GetFunctions(ctx, &platform.GetFunctionsOptions{
+		Name:      functionName,
+		Namespace: b.rootCommandeer.namespace,
+		Labels:    labels,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get functions")
+	}
+
+	if len(functions) == 0 {
+		return nil, nuclio.NewErrNotFound("No functions found")
+	}
+
+	sort.Slice(functions, func(i, j int) bool {
+		return functions[i].GetConfig().Meta.Name < functions[j].GetConfig().Meta.Name
+	})
+
+	return functions, nil
+}
+
+// run runs the operation on the functions, returning the results in the order of the functions
+func (b *bulkFunctionCommandeer) run(ctx context.Context,
+	functions []platform.Function,
+	operation func(ctx context.Context, function platform.Function) *bulkFunctionResult) []*bulkFunctionResult {
+
+	results := make([]*bulkFunctionResult, len(functions))
+	errGroup, errGroupCtx := errgroup.WithContextSemaphore(ctx, b.rootCommandeer.loggerInstance, uint(b.concurrency))
+
+	for functionIndex, function := range functions {
+		functionIndex, function := functionIndex, function
+
+		errGroup.Go("run bulk function operation", func() error {
+
+			// in case the operation panics
+			results[functionIndex] = &bulkFunctionResult{
+				namespace: function.GetConfig().Meta.Namespace,
+				name:      function.GetConfig().Meta.Name,
+				kind:      bulkFunctionResultFailed,
+				details:   "Unexpected failure",
+			}
+
+			result := operation(errGroupCtx, function)
+			result.namespace = function.GetConfig().Meta.Namespace
+			result.name = function.GetConfig().Meta.Name
+
+			if result.err != nil {
+				result.kind = bulkFunctionResultFailed
+				result.details = errors.Cause(result.err).Error()
+			}
+
+			results[functionIndex] = result
+
+			// a failed function doesn't stop the others
+			return nil
+		})
+	}
+
+	errGroup.Wait() // nolint: errcheck
+
+	return results
+}
+
+// getDeployableFunctionConfig returns a copy of the function's configuration that can be modified and deployed,
+// or a skipped result if the function can't be deployed as is
+func (b *bulkFunctionCommandeer) getDeployableFunctionConfig(function platform.Function) (*functionconfig.Config,
+	*bulkFunctionResult) {
+
+	if function.GetStatus().State == functionconfig.FunctionStateImported {
+		return nil, &bulkFunctionResult{
+			kind:    bulkFunctionResultSkipped,
+			details: "imported and never deployed",
+		}
+	}
+
+	functionConfig, err := copyFunctionConfig(function.GetConfig())
+	if err != nil {
+		return nil, &bulkFunctionResult{
+			err: errors.Wrap(err, "Failed to copy function configuration"),
+		}
+	}
+
+	return functionConfig, nil
+}
+
+// deployExistingImage deploys the function's current image, without building it again
+func (b *bulkFunctionCommandeer) deployExistingImage(ctx context.Context,
+	function platform.Function,
+	functionConfig *functionconfig.Config) error {
+	existingImage := function.GetStatus().ContainerImage
+	if existingImage == "" {
+		return errors.New("Function has no image to deploy")
+	}
+
+	functionConfig.Spec.Image = existingImage
+	functionConfig.Spec.Build.Mode = functionconfig.NeverBuild
+
+	return b.rootCommandeer.deployFunction(ctx, *functionConfig)
+}
+
+func copyFunctionConfig(functionConfig *functionconfig.Config) (*functionconfig.Config, error) {
+	encodedFunctionConfig, err := json.Marshal(functionConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to encode function configuration")
+	}
+
+	functionConfigCopy := &functionconfig.Config{}
+	if err := json.Unmarshal(encodedFunctionConfig, functionConfigCopy); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode function configuration")
+	}
+
+	return functionConfigCopy, nil
+}
+
+// renderBulkFunctionResults renders the results as a table, followed by the changed fields of each function, and
+// returns an error if any of the functions failed
+func renderBulkFunctionResults(results []*bulkFunctionResult, writer io.Writer) error {
+	var records [][]string
+	var failedFunctions int
+
+	for _, result := range results {
+		if result.kind == bulkFunctionResultFailed {
+			failedFunctions++
+		}
+
+		details := result.details
+		switch {
+		case details != "":
+		case len(result.differences) == 1:
+			details = "1 change"
+		case len(result.differences) > 1:
+			details = fmt.Sprintf("%d changes", len(result.differences))
+		}
+
+		records = append(records, []string{
+			result.namespace,
+			result.name,
+			string(result.kind),
+			details,
+		})
+	}
+
+	renderer.NewRenderer(writer).RenderTable([]string{"Namespace", "Name", "Result", "Details"}, records)
+
+	for _, result := range results {
+		if len(result.differences) == 0 {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(writer, "\nfunction %s:\n", result.name); err != nil {
+			return errors.Wrap(err, "Failed to write results")
+		}
+
+		if err := nuctlcommon.RenderConfigDifferences(result.differences, writer); err != nil {
+			return errors.Wrap(err, "Failed to render differences")
+		}
+	}
+
+	if failedFunctions > 0 {
+		return errors.Errorf("Failed to deploy %d of %d functions", failedFunctions, len(results))
+	}
+
+	return nil
+}
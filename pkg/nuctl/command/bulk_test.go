@@ -0,0 +1,303 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/platform"
+	mockplatform "github.com/nuclio/nuclio/pkg/platform/mock"
+
+	"github.com/nuclio/errors"
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+type bulkTestSuite struct {
+	suite.Suite
+	ctx                     context.Context
+	mockPlatform            *mockplatform.Platform
+	rootCommandeer          *RootCommandeer
+	deployedFunctionConfigs map[string]functionconfig.Config
+	deployedLock            sync.Mutex
+}
+
+func (suite *bulkTestSuite) SetupTest() {
+	loggerInstance, err := nucliozap.NewNuclioZapTest("test")
+	suite.Require().NoError(err, "Create logger should succeed")
+
+	suite.ctx = context.Background()
+	suite.mockPlatform = &mockplatform.Platform{}
+	suite.rootCommandeer = &RootCommandeer{
+		loggerInstance: loggerInstance,
+		platform:       suite.mockPlatform,
+		namespace:      "default-namespace",
+	}
+	suite.deployedFunctionConfigs = map[string]functionconfig.Config{}
+}
+
+func (suite *bulkTestSuite) TestUpdateFunctions() {
+	suite.mockFunctions("tier=web,nuclio.io/project-name=my-project",
+		suite.newBulkFunction("changed", "changed:1", functionconfig.FunctionStateReady),
+		suite.newBulkFunction("unchanged", "unchanged:1", functionconfig.FunctionStateReady,
+			func(functionConfig *functionconfig.Config) {
+				functionConfig.Meta.Annotations = map[string]string{"owner": "team"}
+				functionConfig.Spec.Env = []v1.EnvVar{{Name: "A", Value: "new"}, {Name: "C", Value: "3"}}
+				functionConfig.Spec.Resources.Limits = v1.ResourceList{
+					v1.ResourceMemory: resource.MustParse("256Mi"),
+				}
+			}),
+		suite.newBulkFunction("imported", "", functionconfig.FunctionStateImported),
+		suite.newBulkFunction("failing", "failing:1", functionconfig.FunctionStateReady))
+	suite.mockDeploy("changed", nil)
+	suite.mockDeploy("failing", errors.New("Function deployment timed out"))
+
+	commandeer := suite.newUpdateFunctionCommandeer()
+	commandeer.encodedEnv = stringSliceFlag{"A=new"}
+	commandeer.unsetEnv = stringSliceFlag{"B"}
+	commandeer.encodedAnnotations = stringSliceFlag{"owner=team"}
+	commandeer.unsetAnnotations = stringSliceFlag{"stale"}
+	commandeer.resourceLimits = stringSliceFlag{"memory=256Mi"}
+	commandeer.unsetResourceLimits = stringSliceFlag{"cpu"}
+
+	output, err := suite.runUpdate(commandeer)
+	suite.Require().Error(err, "Update should fail when a deployment fails")
+	suite.Require().Contains(err.Error(), "Failed to deploy 1 of 4 functions")
+
+	suite.Require().Regexp(`changed +\| updated +\| 6 changes`, output)
+	suite.Require().Regexp(`failing +\| failed +\| Function deployment timed out`, output)
+	suite.Require().Regexp(`imported +\| skipped +\| imported and never deployed`, output)
+	suite.Require().Regexp(`unchanged +\| unchanged`, output)
+	suite.Require().Contains(output, `  ~ spec.env[A].value: "old" -> "new"`)
+	suite.Require().Contains(output, `  - spec.env[B]:`)
+
+	// only the changed function is deployed, with its current image
+	suite.Require().Len(suite.deployedFunctionConfigs, 2)
+	deployedConfig := suite.deployedFunctionConfigs["changed"]
+	suite.Require().Equal("changed:1", deployedConfig.Spec.Image)
+	suite.Require().Equal(functionconfig.NeverBuild, deployedConfig.Spec.Build.Mode)
+	suite.Require().Equal([]v1.EnvVar{{Name: "A", Value: "new"}, {Name: "C", Value: "3"}}, deployedConfig.Spec.Env)
+	suite.Require().Equal(map[string]string{"owner": "team"}, deployedConfig.Meta.Annotations)
+	suite.Require().Equal(v1.ResourceList{
+		v1.ResourceMemory: resource.MustParse("256Mi"),
+	}, deployedConfig.Spec.Resources.Limits)
+	suite.Require().Equal("my-project", deployedConfig.Meta.Labels[common.NuclioResourceLabelKeyProjectName])
+}
+
+func (suite *bulkTestSuite) TestUpdateFunctionsDryRun() {
+	suite.mockFunctions("nuclio.io/project-name=my-project",
+		suite.newBulkFunction("first", "first:1", functionconfig.FunctionStateReady),
+		suite.newBulkFunction("second", "second:1", functionconfig.FunctionStateReady))
+
+	commandeer := suite.newUpdateFunctionCommandeer()
+	commandeer.selector = ""
+	commandeer.disable = true
+	commandeer.dryRun = true
+
+	output, err := suite.runUpdate(commandeer)
+	suite.Require().NoError(err, "Dry run update should succeed")
+	suite.Require().Regexp(`first +\| would update +\| 1 change `, output)
+	suite.Require().Regexp(`second +\| would update +\| 1 change `, output)
+	suite.Require().Contains(output, "  + spec.disable: true")
+
+	// nothing is deployed
+	suite.mockPlatform.AssertNotCalled(suite.T(), "CreateFunction", mock.Anything, mock.Anything)
+}
+
+func (suite *bulkTestSuite) TestRedeployFunctions() {
+	for _, testCase := range []struct {
+		name             string
+		rebuild          bool
+		expectedImage    string
+		expectedMode     functionconfig.BuildMode
+		expectedFailures []string
+	}{
+		{
+			name:             "existingImage",
+			expectedImage:    "built:1",
+			expectedMode:     functionconfig.NeverBuild,
+			expectedFailures: []string{"notBuilt"},
+		},
+		{
+			name:         "rebuild",
+			rebuild:      true,
+			expectedMode: functionconfig.AlwaysBuild,
+		},
+	} {
+		suite.Run(testCase.name, func() {
+			suite.SetupTest()
+
+			suite.mockFunctions("nuclio.io/project-name=my-project",
+				suite.newBulkFunction("built", "built:1", functionconfig.FunctionStateReady),
+				suite.newBulkFunction("notBuilt", "", functionconfig.FunctionStateError))
+			suite.mockDeploy("built", nil)
+			suite.mockDeploy("notBuilt", nil)
+
+			redeployCommandeer := &redeployCommandeer{rootCommandeer: suite.rootCommandeer}
+			commandeer := &redeployFunctionCommandeer{
+				redeployCommandeer: redeployCommandeer,
+				bulkFunctionCommandeer: bulkFunctionCommandeer{
+					rootCommandeer: suite.rootCommandeer,
+					projectName:    "my-project",
+					concurrency:    2,
+				},
+				rebuild: testCase.rebuild,
+			}
+
+			functions, err := commandeer.getFunctions(suite.ctx, "")
+			suite.Require().NoError(err, "Get functions should succeed")
+
+			var failures []string
+			for _, result := range commandeer.run(suite.ctx, functions, commandeer.redeployFunction) {
+				if result.kind == bulkFunctionResultFailed {
+					failures = append(failures, result.name)
+				}
+			}
+			suite.Require().Equal(testCase.expectedFailures, failures)
+
+			deployedConfig := suite.deployedFunctionConfigs["built"]
+			suite.Require().Equal(testCase.expectedImage, deployedConfig.Spec.Image)
+			suite.Require().Equal(testCase.expectedMode, deployedConfig.Spec.Build.Mode)
+		})
+	}
+}
+
+func (suite *bulkTestSuite) TestValidation() {
+	commandeer := suite.newUpdateFunctionCommandeer()
+	commandeer.projectName = ""
+	commandeer.selector = ""
+	commandeer.encodedEnv = nil
+
+	// all the functions of a namespace must be selected explicitly
+	_, err := commandeer.getFunctions(suite.ctx, "")
+	suite.Require().Error(err, "Get functions without a selector should fail")
+
+	// no changes
+	suite.Require().Error(commandeer.resolveChanges())
+
+	commandeer.disable = true
+	commandeer.enable = true
+	suite.Require().Error(commandeer.resolveChanges())
+
+	commandeer.enable = false
+	commandeer.encodedEnv = stringSliceFlag{"NO_VALUE"}
+	suite.Require().Error(commandeer.resolveChanges())
+
+	commandeer.encodedEnv = stringSliceFlag{"URL=http://host?a=b"}
+	suite.Require().NoError(commandeer.resolveChanges())
+	suite.Require().Equal(map[string]string{"URL": "http://host?a=b"}, commandeer.env)
+}
+
+func (suite *bulkTestSuite) newUpdateFunctionCommandeer() *updateFunctionCommandeer {
+	return &updateFunctionCommandeer{
+		updateCommandeer: &updateCommandeer{
+			rootCommandeer: suite.rootCommandeer,
+		},
+		bulkFunctionCommandeer: bulkFunctionCommandeer{
+			rootCommandeer: suite.rootCommandeer,
+			projectName:    "my-project",
+			selector:       "tier=web",
+			concurrency:    2,
+		},
+		encodedEnv: stringSliceFlag{"C=3"},
+	}
+}
+
+func (suite *bulkTestSuite) runUpdate(commandeer *updateFunctionCommandeer) (string, error) {
+	suite.Require().NoError(commandeer.resolveChanges(), "Resolve changes should succeed")
+
+	functions, err := commandeer.getFunctions(suite.ctx, "")
+	suite.Require().NoError(err, "Get functions should succeed")
+
+	output := bytes.Buffer{}
+	err = renderBulkFunctionResults(commandeer.run(suite.ctx, functions, commandeer.updateFunction), &output)
+
+	return output.String(), err
+}
+
+func (suite *bulkTestSuite) newBulkFunction(name string,
+	image string,
+	state functionconfig.FunctionState,
+	modifiers ...func(functionConfig *functionconfig.Config)) platform.Function {
+	functionConfig := functionconfig.NewConfig()
+	functionConfig.Meta.Name = name
+	functionConfig.Meta.Namespace = "default-namespace"
+	functionConfig.Meta.Labels = map[string]string{
+		common.NuclioResourceLabelKeyProjectName: "my-project",
+	}
+	functionConfig.Meta.Annotations = map[string]string{"stale": "true"}
+	functionConfig.Spec.Env = []v1.EnvVar{
+		{Name: "A", Value: "old"},
+		{Name: "B", Value: "2"},
+		{Name: "C", Value: "3"},
+	}
+	functionConfig.Spec.Resources.Limits = v1.ResourceList{
+		v1.ResourceCPU: resource.MustParse("1"),
+	}
+
+	for _, modifier := range modifiers {
+		modifier(functionConfig)
+	}
+
+	function, err := platform.NewAbstractFunction(suite.rootCommandeer.loggerInstance,
+		suite.mockPlatform,
+		functionConfig,
+		&functionconfig.Status{State: state, ContainerImage: image},
+		nil)
+	suite.Require().NoError(err, "Create function should succeed")
+
+	return function
+}
+
+func (suite *bulkTestSuite) mockFunctions(labels string, functions ...platform.Function) {
+	suite.mockPlatform.
+		On("GetFunctions", mock.Anything, &platform.GetFunctionsOptions{
+			Namespace: "default-namespace",
+			Labels:    labels,
+		}).
+		Return(functions, nil).
+		Once()
+}
+
+func (suite *bulkTestSuite) mockDeploy(functionName string, deployErr error) {
+	suite.mockPlatform.
+		On("CreateFunction", mock.Anything, mock.MatchedBy(func(createFunctionOptions *platform.CreateFunctionOptions) bool {
+			return createFunctionOptions.FunctionConfig.Meta.Name == functionName
+		})).
+		Run(func(args mock.Arguments) {
+			suite.deployedLock.Lock()
+			defer suite.deployedLock.Unlock()
+
+			createFunctionOptions := args.Get(1).(*platform.CreateFunctionOptions)
+			suite.deployedFunctionConfigs[functionName] = createFunctionOptions.FunctionConfig
+		}).
+		Return(&platform.CreateFunctionResult{}, deployErr)
+}
+
+func TestBulkTestSuite(t *testing.T) {
+	suite.Run(t, new(bulkTestSuite))
+}
@@ -18,10 +18,13 @@ package common
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nuclio/nuclio/pkg/common"
 	"github.com/nuclio/nuclio/pkg/errgroup"
@@ -31,6 +34,7 @@ import (
 
 	"github.com/nuclio/errors"
 	"github.com/nuclio/logger"
+	v1 "k8s.io/api/core/v1"
 )
 
 const (
@@ -47,25 +51,42 @@ func RenderFunctions(ctx context.Context,
 	writer io.Writer,
 	renderCallback func(functions []platform.Function, renderer func(interface{}) error) error) error {
 
+	if err := InitializeFunctions(ctx, logger, functions); err != nil {
+		return errors.Wrap(err, "Failed to initialize functions")
+	}
+
+	return RenderInitializedFunctions(functions, format, writer, renderCallback)
+}
+
+// InitializeFunctions initializes functions in parallel, so that their replicas and ports are populated.
+// functions that fail to initialize are rendered with what's known about them
+func InitializeFunctions(ctx context.Context, logger logger.Logger, functions []platform.Function) error {
 	errGroup, errGroupCtx := errgroup.WithContext(ctx, logger)
-	var renderNodePort bool
 
-	// iterate over each function and make sure it's initialized
 	for _, function := range functions {
 		function := function
 		errGroup.Go("initialize function", func() error {
 			if err := function.Initialize(errGroupCtx, nil); err != nil {
 				logger.DebugWith("Failed to initialize function", "err", err.Error())
 			}
-			if function.GetStatus().HTTPPort > 0 {
-				renderNodePort = true
-			}
 			return nil
 		})
 	}
 
-	if err := errGroup.Wait(); err != nil {
-		return errors.Wrap(err, "Failed to initialize functions")
+	return errGroup.Wait()
+}
+
+// RenderInitializedFunctions renders functions that were already initialized
+func RenderInitializedFunctions(functions []platform.Function,
+	format string,
+	writer io.Writer,
+	renderCallback func(functions []platform.Function, renderer func(interface{}) error) error) error {
+
+	var renderNodePort bool
+	for _, function := range functions {
+		if function.GetStatus().HTTPPort > 0 {
+			renderNodePort = true
+		}
 	}
 
 	rendererInstance := renderer.NewRenderer(writer)
@@ -81,6 +102,7 @@ func RenderFunctions(ctx context.Context,
 				"Labels",
 				"Internal Invocation URL",
 				"External Invocation URLs",
+				"Recommended Resources",
 			}...)
 		}
 
@@ -95,7 +117,7 @@ func RenderFunctions(ctx context.Context,
 				function.GetConfig().Meta.Namespace,
 				function.GetConfig().Meta.Name,
 				function.GetConfig().Meta.Labels[common.NuclioResourceLabelKeyProjectName],
-				encodeFunctionState(function),
+				EncodeFunctionState(function),
 				fmt.Sprintf("%d/%d", availableReplicas, specifiedReplicas),
 			}
 
@@ -114,6 +136,7 @@ func RenderFunctions(ctx context.Context,
 					common.StringMapToString(function.GetConfig().Meta.Labels),
 					strings.Join(function.GetStatus().InternalInvocationURLs, ", "),
 					strings.Join(function.GetStatus().ExternalInvocationURLs, ", "),
+					encodeResourceRecommendation(function.GetStatus().ResourceRecommendation),
 				}...)
 			}
 
@@ -298,7 +321,147 @@ func RenderAPIGateways(apiGateways []platform.APIGateway,
 	return nil
 }
 
-func encodeFunctionState(function platform.Function) string {
+func RenderFunctionRevisions(functionRevisions []*platform.FunctionRevision,
+	format string,
+	writer io.Writer) error {
+
+	rendererInstance := renderer.NewRenderer(writer)
+
+	switch format {
+	case OutputFormatText, OutputFormatWide:
+		header := []string{"Revision", "Image", "Deployed By", "Deployed At"}
+		if format == OutputFormatWide {
+			header = append(header, []string{
+				"Image Digest",
+			}...)
+		}
+
+		var functionRevisionRecords [][]string
+
+		// for each field
+		for _, functionRevision := range functionRevisions {
+
+			// get its fields
+			functionRevisionFields := []string{
+				strconv.Itoa(functionRevision.Revision),
+				functionRevision.Image,
+				functionRevision.DeployedBy,
+				functionRevision.DeployedAt.Format(time.RFC3339),
+			}
+
+			// add fields for wide view
+			if format == OutputFormatWide {
+				functionRevisionFields = append(functionRevisionFields, []string{
+					functionRevision.ImageDigest,
+				}...)
+			}
+
+			// add to records
+			functionRevisionRecords = append(functionRevisionRecords, functionRevisionFields)
+		}
+
+		rendererInstance.RenderTable(header, functionRevisionRecords)
+	case OutputFormatYAML:
+		return rendererInstance.RenderYAML(functionRevisions)
+	case OutputFormatJSON:
+		return rendererInstance.RenderJSON(functionRevisions)
+	}
+
+	return nil
+}
+
+func RenderDependencyGraph(dependencyGraph *platform.DependencyGraph,
+	format string,
+	writer io.Writer) error {
+
+	rendererInstance := renderer.NewRenderer(writer)
+
+	switch format {
+	case OutputFormatText, OutputFormatWide:
+		header := []string{"From", "To", "Kind", "Name"}
+		if format == OutputFormatWide {
+			header = append(header, []string{
+				"From Project",
+				"To Project",
+			}...)
+		}
+
+		nodesByID := map[string]*platform.DependencyGraphNode{}
+		for _, node := range dependencyGraph.Nodes {
+			nodesByID[node.ID] = node
+		}
+
+		var edgeRecords [][]string
+
+		// for each edge
+		for _, edge := range dependencyGraph.Edges {
+
+			// get its fields
+			edgeFields := []string{
+				edge.From,
+				edge.To,
+				string(edge.Kind),
+				edge.Name,
+			}
+
+			// add fields for wide view
+			if format == OutputFormatWide {
+				edgeFields = append(edgeFields, []string{
+					nodesByID[edge.From].Project,
+					nodesByID[edge.To].Project,
+				}...)
+			}
+
+			// add to records
+			edgeRecords = append(edgeRecords, edgeFields)
+		}
+
+		rendererInstance.RenderTable(header, edgeRecords)
+	case OutputFormatYAML:
+		return rendererInstance.RenderYAML(dependencyGraph)
+	case OutputFormatJSON:
+		return rendererInstance.RenderJSON(dependencyGraph)
+	}
+
+	return nil
+}
+
+// RenderConfigDifferences renders configuration differences one per line, marking added fields with "+",
+// removed fields with "-" and changed fields with "~"
+func RenderConfigDifferences(differences []functionconfig.ConfigDifference, writer io.Writer) error {
+	for _, difference := range differences {
+		var line string
+
+		switch {
+		case difference.From == nil:
+			line = fmt.Sprintf("  + %s: %s", difference.Path, encodeDifferenceValue(difference.To))
+		case difference.To == nil:
+			line = fmt.Sprintf("  - %s: %s", difference.Path, encodeDifferenceValue(difference.From))
+		default:
+			line = fmt.Sprintf("  ~ %s: %s -> %s",
+				difference.Path,
+				encodeDifferenceValue(difference.From),
+				encodeDifferenceValue(difference.To))
+		}
+
+		if _, err := fmt.Fprintln(writer, line); err != nil {
+			return errors.Wrap(err, "Failed to write difference")
+		}
+	}
+
+	return nil
+}
+
+func encodeDifferenceValue(value interface{}) string {
+	encodedValue, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encodedValue)
+}
+
+// EncodeFunctionState returns the state of a function as shown to users
+func EncodeFunctionState(function platform.Function) string {
 	functionStatus := function.GetStatus()
 	functionSpec := function.GetConfig().Spec
 	if functionStatus.State == functionconfig.FunctionStateReady && functionSpec.Disable {
@@ -308,3 +471,26 @@ func encodeFunctionState(function platform.Function) string {
 	}
 	return string(functionStatus.State)
 }
+
+// encodeResourceRecommendation encodes a recommendation as e.g. "requests: cpu=100m,memory=128Mi; limits: memory=192Mi"
+func encodeResourceRecommendation(recommendation *functionconfig.ResourceRecommendation) string {
+	if recommendation == nil {
+		return ""
+	}
+
+	encodeResourceList := func(resourceList v1.ResourceList) string {
+		var resources []string
+		for resourceName, quantity := range resourceList {
+			resources = append(resources, fmt.Sprintf("%s=%s", resourceName, quantity.String()))
+		}
+		sort.Strings(resources)
+		return strings.Join(resources, ",")
+	}
+
+	encodedRecommendation := fmt.Sprintf("requests: %s", encodeResourceList(recommendation.Requests))
+	if len(recommendation.Limits) > 0 {
+		encodedRecommendation += fmt.Sprintf("; limits: %s", encodeResourceList(recommendation.Limits))
+	}
+
+	return encodedRecommendation
+}
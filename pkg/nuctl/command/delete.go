@@ -18,6 +18,7 @@ package command
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/nuclio/nuclio/pkg/functionconfig"
@@ -90,6 +91,8 @@ func newDeleteFunctionCommandeer(ctx context.Context, deleteCommandeer *deleteCo
 			commandeer.functionConfig.Meta.Name = args[0]
 			commandeer.functionConfig.Meta.Namespace = deleteCommandeer.rootCommandeer.namespace
 
+			commandeer.warnAboutFunctionDependents(ctx)
+
 			return deleteCommandeer.rootCommandeer.platform.DeleteFunction(ctx, &platform.DeleteFunctionOptions{
 				FunctionConfig: commandeer.functionConfig,
 			})
@@ -101,6 +104,33 @@ func newDeleteFunctionCommandeer(ctx context.Context, deleteCommandeer *deleteCo
 	return commandeer
 }
 
+// warnAboutFunctionDependents warns about resources that route to, invoke or consume the output of the function,
+// since deleting it breaks them. failing to resolve them doesn't block the deletion
+func (d *deleteFunctionCommandeer) warnAboutFunctionDependents(ctx context.Context) {
+	dependencyGraph, err := d.rootCommandeer.platform.GetDependencyGraph(ctx, &platform.GetDependencyGraphOptions{
+		Namespace: d.functionConfig.Meta.Namespace,
+	})
+	if err != nil {
+		d.rootCommandeer.loggerInstance.DebugWithCtx(ctx, "Failed to get dependency graph", "err", err.Error())
+		return
+	}
+
+	dependents := dependencyGraph.GetFunctionDependents(d.functionConfig.Meta.Name)
+	if len(dependents) == 0 {
+		return
+	}
+
+	var encodedDependents []string
+	for _, dependent := range dependents {
+		encodedDependents = append(encodedDependents, dependent.String())
+	}
+
+	d.rootCommandeer.loggerInstance.WarnWithCtx(ctx,
+		"Other resources depend on the deleted function",
+		"name", d.functionConfig.Meta.Name,
+		"dependents", strings.Join(encodedDependents, ", "))
+}
+
 type deleteProjectCommandeer struct {
 	*deleteCommandeer
 	projectMeta      platform.ProjectMeta
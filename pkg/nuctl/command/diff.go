@@ -0,0 +1,339 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	nuctlcommon "github.com/nuclio/nuclio/pkg/nuctl/command/common"
+	"github.com/nuclio/nuclio/pkg/platform"
+	"github.com/nuclio/nuclio/pkg/renderer"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/nuclio-sdk-go"
+	"github.com/spf13/cobra"
+)
+
+const (
+
+	// maskedDifferenceValue stands for the values of sensitive fields, which are never printed
+	maskedDifferenceValue = "*****"
+
+	// unknownMaskedDifferenceValue stands for deployed values of sensitive fields that couldn't be resolved
+	unknownMaskedDifferenceValue = "***** (may differ)"
+)
+
+type diffCommandeer struct {
+	cmd            *cobra.Command
+	rootCommandeer *RootCommandeer
+}
+
+func newDiffCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *diffCommandeer {
+	commandeer := &diffCommandeer{
+		rootCommandeer: rootCommandeer,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare local configurations with deployed resources",
+	}
+
+	diffFunctionCommand := newDiffFunctionCommandeer(ctx, commandeer).cmd
+
+	cmd.AddCommand(
+		diffFunctionCommand,
+	)
+
+	commandeer.cmd = cmd
+
+	return commandeer
+}
+
+type diffFunctionCommandeer struct {
+	*diffCommandeer
+	functionConfigPath string
+	output             string
+}
+
+func newDiffFunctionCommandeer(ctx context.Context, diffCommandeer *diffCommandeer) *diffFunctionCommandeer {
+	commandeer := &diffFunctionCommandeer{
+		diffCommandeer: diffCommandeer,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "functions [name] -f <function.yaml>",
+		Aliases: []string{"fu", "fn", "function"},
+		Short:   "(or function) Show what redeploying a function with a configuration file would change",
+		Long: `(or function) Show what redeploying a function with a configuration file would change.
+
+The configuration file is loaded and enriched the same way 'nuctl deploy' does, and compared
+field by field with the deployed function. Triggers, env vars and other lists of named items
+are compared by name. Values of sensitive fields (e.g. passwords) are masked, and reported as
+possibly different when the deployed ones can't be resolved.
+
+Arguments:
+  <name> (string) The name of the deployed function (default - the name in the configuration file)`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if commandeer.functionConfigPath == "" {
+				return errors.New("Function diff requires a function-configuration file (-f)")
+			}
+
+			// initialize root
+			if err := diffCommandeer.rootCommandeer.initialize(); err != nil {
+				return errors.Wrap(err, "Failed to initialize root")
+			}
+
+			var functionName string
+			if len(args) > 0 {
+				functionName = args[0]
+			}
+
+			localFunctionConfig, err := commandeer.readLocalFunctionConfig(ctx, functionName)
+			if err != nil {
+				return errors.Wrap(err, "Failed to read the local function configuration")
+			}
+
+			deployedFunctionConfig, err := commandeer.getDeployedFunctionConfig(ctx, localFunctionConfig.Meta.Name)
+			if err != nil {
+				return errors.Wrap(err, "Failed to get the deployed function configuration")
+			}
+
+			differences, err := commandeer.diffFunctionConfigs(ctx, deployedFunctionConfig, localFunctionConfig)
+			if err != nil {
+				return errors.Wrap(err, "Failed to compare function configurations")
+			}
+
+			return commandeer.renderDifferences(localFunctionConfig.Meta.Name, differences)
+		},
+	}
+
+	cmd.Flags().StringVarP(&commandeer.functionConfigPath, "file", "f", "", "Path to a function-configuration file")
+	cmd.Flags().StringVarP(&commandeer.output, "output", "o", nuctlcommon.OutputFormatText, "Output format - \"text\", \"yaml\", or \"json\"")
+
+	commandeer.cmd = cmd
+
+	return commandeer
+}
+
+// readLocalFunctionConfig reads the configuration file and enriches it, as deploying it would
+func (d *diffFunctionCommandeer) readLocalFunctionConfig(ctx context.Context,
+	functionName string) (*functionconfig.Config, error) {
+
+	functionConfigFile, err := nuctlcommon.OpenFile(d.functionConfigPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to open function configuration file")
+	}
+	defer functionConfigFile.Close() // nolint: errcheck
+
+	functionConfigReader, err := functionconfig.NewReader(d.rootCommandeer.loggerInstance)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create function configuration reader")
+	}
+
+	functionConfig := functionconfig.NewConfig()
+	if err := functionConfigReader.Read(functionConfigFile, "yaml", functionConfig); err != nil {
+		return nil, errors.Wrap(err, "Failed to read function configuration file")
+	}
+
+	if functionName != "" {
+		functionConfig.Meta.Name = functionName
+	}
+	if functionConfig.Meta.Name == "" {
+		return nil, errors.New("Function name must be given, either as an argument or in the configuration file")
+	}
+	functionConfig.Meta.Namespace = d.rootCommandeer.namespace
+
+	if err := d.rootCommandeer.platform.EnrichFunctionConfig(ctx, functionConfig); err != nil {
+		return nil, errors.Wrap(err, "Failed to enrich function configuration")
+	}
+
+	return functionConfig, nil
+}
+
+func (d *diffFunctionCommandeer) getDeployedFunctionConfig(ctx context.Context,
+	functionName string) (*functionconfig.Config, error) {
+
+	functions, err := d.rootCommandeer.platform.GetFunctions(ctx, &platform.GetFunctionsOptions{
+		Name:      functionName,
+		Namespace: d.rootCommandeer.namespace,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get function")
+	}
+
+	if len(functions) == 0 {
+		return nil, nuclio.NewErrNotFound(fmt.Sprintf("Function %s not found", functionName))
+	}
+
+	deployedFunctionConfig := *functions[0].GetConfig()
+	return &deployedFunctionConfig, nil
+}
+
+// diffFunctionConfigs returns the differences between the deployed and local configurations, leaving out
+// the fields that deploying fills in and masking sensitive values
+func (d *diffFunctionCommandeer) diffFunctionConfigs(ctx context.Context,
+	deployedFunctionConfig *functionconfig.Config,
+	localFunctionConfig *functionconfig.Config) ([]functionconfig.ConfigDifference, error) {
+
+	maskedDeployedFunctionConfig, deployedSecrets, err := d.maskSensitiveFields(ctx, deployedFunctionConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to mask the deployed function configuration")
+	}
+
+	maskedLocalFunctionConfig, localSecrets, err := d.maskSensitiveFields(ctx, localFunctionConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to mask the local function configuration")
+	}
+
+	for _, functionConfig := range []*functionconfig.Config{
+		maskedDeployedFunctionConfig,
+		maskedLocalFunctionConfig,
+	} {
+		functionConfig.Meta.ResourceVersion = ""
+		functionConfig.CleanFunctionSpec()
+	}
+
+	differences, err := functionconfig.DiffConfigs(maskedDeployedFunctionConfig, maskedLocalFunctionConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to compare function configurations")
+	}
+
+	differences = append(filterDeployedOnlyDifferences(differences),
+		diffMaskedValues(deployedSecrets, localSecrets)...)
+
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Path < differences[j].Path
+	})
+
+	return differences, nil
+}
+
+// maskSensitiveFields replaces the values of sensitive fields with references, returning the masked values by
+// reference. A configuration that is already masked (e.g. of a function deployed with masking) is returned as is,
+// with its values resolved through the function secret. They are nil if the secret can't be resolved
+func (d *diffFunctionCommandeer) maskSensitiveFields(ctx context.Context,
+	functionConfig *functionconfig.Config) (*functionconfig.Config, map[string]string, error) {
+
+	sensitiveFields := d.rootCommandeer.platform.GetConfig().SensitiveFields.CompileSensitiveFieldsRegex()
+	scrubber := functionconfig.NewScrubber(sensitiveFields, nil)
+
+	masked, err := scrubber.HasScrubbedConfig(functionConfig, sensitiveFields)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Failed to check for masked fields")
+	}
+
+	if masked {
+		maskedFunctionConfig := *functionConfig
+
+		secrets, err := d.rootCommandeer.platform.GetFunctionSecretMap(ctx,
+			functionConfig.Meta.Name,
+			functionConfig.Meta.Namespace)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "Failed to get function secret")
+		}
+
+		return &maskedFunctionConfig, secrets, nil
+	}
+
+	maskedFunctionConfig, secrets, err := scrubber.Scrub(functionConfig, nil, sensitiveFields)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Failed to mask sensitive fields")
+	}
+
+	if secrets == nil {
+		secrets = map[string]string{}
+	}
+
+	return maskedFunctionConfig, secrets, nil
+}
+
+// filterDeployedOnlyDifferences leaves out the image and build fields that the deployed function has and the local
+// configuration doesn't set, as building the function fills them in
+func filterDeployedOnlyDifferences(
+	differences []functionconfig.ConfigDifference) []functionconfig.ConfigDifference {
+
+	var filteredDifferences []functionconfig.ConfigDifference
+	for _, difference := range differences {
+		if difference.To == nil &&
+			(difference.Path == "spec.image" || strings.HasPrefix(difference.Path, "spec.build.")) {
+			continue
+		}
+
+		filteredDifferences = append(filteredDifferences, difference)
+	}
+
+	return filteredDifferences
+}
+
+// diffMaskedValues returns a masked difference for each sensitive field whose value changed. When the deployed
+// values aren't known, every sensitive field may have changed
+func diffMaskedValues(deployedSecrets map[string]string,
+	localSecrets map[string]string) []functionconfig.ConfigDifference {
+
+	var differences []functionconfig.ConfigDifference
+	for reference, localValue := range localSecrets {
+		difference := functionconfig.ConfigDifference{
+			Path: strings.TrimPrefix(reference, functionconfig.ReferencePrefix),
+			To:   maskedDifferenceValue,
+		}
+
+		deployedValue, found := deployedSecrets[reference]
+		switch {
+		case deployedSecrets == nil:
+			difference.From = unknownMaskedDifferenceValue
+		case !found:
+
+			// the field isn't set on the deployed function
+		case deployedValue == localValue:
+			continue
+		default:
+			difference.From = maskedDifferenceValue
+		}
+
+		differences = append(differences, difference)
+	}
+
+	return differences
+}
+
+func (d *diffFunctionCommandeer) renderDifferences(functionName string,
+	differences []functionconfig.ConfigDifference) error {
+
+	writer := d.cmd.OutOrStdout()
+
+	switch d.output {
+	case nuctlcommon.OutputFormatJSON:
+		return renderer.NewRenderer(writer).RenderJSON(differences)
+	case nuctlcommon.OutputFormatYAML:
+		return renderer.NewRenderer(writer).RenderYAML(differences)
+	}
+
+	if len(differences) == 0 {
+		_, err := fmt.Fprintf(writer, "Function %s is up to date\n", functionName)
+		return err
+	}
+
+	if _, err := fmt.Fprintf(writer, "Function %s:\n", functionName); err != nil {
+		return errors.Wrap(err, "Failed to write differences")
+	}
+
+	return nuctlcommon.RenderConfigDifferences(differences, writer)
+}
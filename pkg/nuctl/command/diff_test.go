@@ -0,0 +1,163 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	mockplatform "github.com/nuclio/nuclio/pkg/platform/mock"
+	"github.com/nuclio/nuclio/pkg/platformconfig"
+
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"k8s.io/api/core/v1"
+)
+
+type diffTestSuite struct {
+	suite.Suite
+	mockPlatform *mockplatform.Platform
+	commandeer   *diffFunctionCommandeer
+}
+
+func (suite *diffTestSuite) SetupTest() {
+	loggerInstance, err := nucliozap.NewNuclioZapTest("test")
+	suite.Require().NoError(err)
+
+	suite.mockPlatform = &mockplatform.Platform{}
+	suite.mockPlatform.On("GetConfig").Return(&platformconfig.Config{})
+
+	suite.commandeer = &diffFunctionCommandeer{
+		diffCommandeer: &diffCommandeer{
+			rootCommandeer: &RootCommandeer{
+				loggerInstance: loggerInstance,
+				platform:       suite.mockPlatform,
+				namespace:      "default-namespace",
+			},
+		},
+	}
+}
+
+func (suite *diffTestSuite) TestDiffFunctionConfigs() {
+	deployedFunctionConfig := suite.newPasswordFunctionConfig("1", "deployed-password")
+	deployedFunctionConfig.Meta.ResourceVersion = "123"
+	deployedFunctionConfig.Spec.Image = "my-image:latest"
+	deployedFunctionConfig.Spec.Build.FunctionSourceCode = "ZGVmIGhhbmRsZXI="
+
+	localFunctionConfig := suite.newPasswordFunctionConfig("2", "local-password")
+	localFunctionConfig.Spec.Env = append(localFunctionConfig.Spec.Env, v1.EnvVar{Name: "OTHER_ENV", Value: "a"})
+
+	differences, err := suite.commandeer.diffFunctionConfigs(context.Background(),
+		deployedFunctionConfig,
+		localFunctionConfig)
+	suite.Require().NoError(err, "Diff should succeed")
+
+	// the built image and source code aren't differences, and the password is masked
+	suite.Require().Equal([]functionconfig.ConfigDifference{
+		{
+			Path: "/spec/triggers/my-trigger/password",
+			From: maskedDifferenceValue,
+			To:   maskedDifferenceValue,
+		},
+		{
+			Path: "spec.env[MY_ENV].value",
+			From: "1",
+			To:   "2",
+		},
+		{
+			Path: "spec.env[OTHER_ENV]",
+			To: map[string]interface{}{
+				"name":  "OTHER_ENV",
+				"value": "a",
+			},
+		},
+	}, differences)
+}
+
+func (suite *diffTestSuite) TestDiffFunctionConfigsMaskedDeployedFunction() {
+	deployedFunctionConfig := suite.newPasswordFunctionConfig("1", "$ref:/spec/triggers/my-trigger/password")
+
+	// the deployed password is resolved through the function secret
+	suite.mockPlatform.
+		On("GetFunctionSecretMap", mock.Anything, "my-function", mock.Anything).
+		Return(map[string]string{"$ref:/spec/triggers/my-trigger/password": "deployed-password"}, nil)
+
+	differences, err := suite.commandeer.diffFunctionConfigs(context.Background(),
+		deployedFunctionConfig,
+		suite.newPasswordFunctionConfig("1", "deployed-password"))
+	suite.Require().NoError(err, "Diff should succeed")
+	suite.Require().Empty(differences)
+
+	differences, err = suite.commandeer.diffFunctionConfigs(context.Background(),
+		deployedFunctionConfig,
+		suite.newPasswordFunctionConfig("1", "local-password"))
+	suite.Require().NoError(err, "Diff should succeed")
+	suite.Require().Equal([]functionconfig.ConfigDifference{
+		{
+			Path: "/spec/triggers/my-trigger/password",
+			From: maskedDifferenceValue,
+			To:   maskedDifferenceValue,
+		},
+	}, differences)
+}
+
+func (suite *diffTestSuite) TestDiffFunctionConfigsUnresolvedDeployedFunction() {
+	deployedFunctionConfig := suite.newPasswordFunctionConfig("1", "$ref:/spec/triggers/my-trigger/password")
+
+	// without a function secret the deployed password isn't known, so it may differ
+	suite.mockPlatform.
+		On("GetFunctionSecretMap", mock.Anything, "my-function", mock.Anything).
+		Return(map[string]string(nil), nil)
+
+	differences, err := suite.commandeer.diffFunctionConfigs(context.Background(),
+		deployedFunctionConfig,
+		suite.newPasswordFunctionConfig("1", "deployed-password"))
+	suite.Require().NoError(err, "Diff should succeed")
+	suite.Require().Equal([]functionconfig.ConfigDifference{
+		{
+			Path: "/spec/triggers/my-trigger/password",
+			From: unknownMaskedDifferenceValue,
+			To:   maskedDifferenceValue,
+		},
+	}, differences)
+}
+
+func (suite *diffTestSuite) newPasswordFunctionConfig(envValue string, password string) *functionconfig.Config {
+	functionConfig := functionconfig.NewConfig()
+	functionConfig.Meta.Name = "my-function"
+	functionConfig.Spec.Runtime = "python:3.9"
+	functionConfig.Spec.Env = []v1.EnvVar{
+		{Name: "MY_ENV", Value: envValue},
+	}
+	functionConfig.Spec.Triggers = map[string]functionconfig.Trigger{
+		"my-trigger": {
+			Name:     "my-trigger",
+			Kind:     "kafka-cluster",
+			Password: password,
+		},
+	}
+
+	return functionConfig
+}
+
+func TestDiffTestSuite(t *testing.T) {
+	suite.Run(t, new(diffTestSuite))
+}
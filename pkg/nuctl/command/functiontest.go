@@ -0,0 +1,747 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/platform"
+	"github.com/nuclio/nuclio/pkg/platformconfig"
+	"github.com/nuclio/nuclio/pkg/renderer"
+
+	"github.com/ghodss/yaml"
+	"github.com/nuclio/errors"
+	"github.com/nuclio/nuclio-sdk-go"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// functionTestFile is the file listing the events sent to a function and the responses expected of it
+type functionTestFile struct {
+	Tests []functionTest `json:"tests"`
+}
+
+type functionTest struct {
+	Name   string                  `json:"name"`
+	Event  functionTestEvent       `json:"event,omitempty"`
+	Expect functionTestExpectation `json:"expect,omitempty"`
+}
+
+type functionTestEvent struct {
+
+	// name of a function event recorded for the function, whose fields are used unless given here
+	FunctionEvent string            `json:"functionEvent,omitempty"`
+	TriggerKind   string            `json:"triggerKind,omitempty"`
+	Method        string            `json:"method,omitempty"`
+	Path          string            `json:"path,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+
+	// a string is sent as is, anything else is encoded as JSON
+	Body interface{} `json:"body,omitempty"`
+}
+
+type functionTestExpectation struct {
+
+	// when not given, any 2xx status is expected
+	Status       int                         `json:"status,omitempty"`
+	Headers      map[string]string           `json:"headers,omitempty"`
+	Body         *string                     `json:"body,omitempty"`
+	BodyContains string                      `json:"bodyContains,omitempty"`
+	JSON         []functionTestJSONAssertion `json:"json,omitempty"`
+	Latency      functionTestLatency         `json:"latency,omitempty"`
+}
+
+// functionTestJSONAssertion asserts on the value found in the response body at a JSON path (e.g. $.items[0].id)
+type functionTestJSONAssertion struct {
+	Path    string          `json:"path"`
+	Equals  json.RawMessage `json:"equals,omitempty"`
+	Exists  *bool           `json:"exists,omitempty"`
+	Matches string          `json:"matches,omitempty"`
+}
+
+type functionTestLatency struct {
+	Min string `json:"min,omitempty"`
+	Max string `json:"max,omitempty"`
+}
+
+type functionTestRequest struct {
+	method  string
+	path    string
+	headers http.Header
+	body    []byte
+}
+
+type functionTestResult struct {
+	name       string
+	statusCode int
+	latency    time.Duration
+	failures   []string
+
+	// set when the event couldn't be sent, as opposed to the response not meeting expectations
+	err error
+}
+
+func (r *functionTestResult) passed() bool {
+	return r.err == nil && len(r.failures) == 0
+}
+
+type functionTestCommandeer struct {
+	cmd                 *cobra.Command
+	rootCommandeer      *RootCommandeer
+	testFilePath        string
+	url                 string
+	invokeVia           string
+	externalIPAddresses string
+	timeout             time.Duration
+	junitReportPath     string
+	skipTLSVerification bool
+}
+
+func newFunctionTestCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *functionTestCommandeer {
+	commandeer := &functionTestCommandeer{
+		rootCommandeer: rootCommandeer,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "test function-name -f tests.yaml",
+		Short: "Test a function's responses to a list of events",
+		Long: `Test a function's responses to a list of events.
+
+Sends the events listed in a test file to a function and checks its responses against the expected
+status, headers, body, JSON-path assertions and latency bounds. The function is either the deployed
+function, or one listening on --url (e.g. a function started with 'nuctl run --local').
+
+Exits with an error if any test fails. With --junit-report, the results are also written as JUnit XML.
+
+Arguments:
+  <function-name> (string) The name of the function to test`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("Function test requires name")
+			}
+			functionName := args[0]
+
+			if commandeer.testFilePath == "" {
+				return errors.New("Test file must be given with -f")
+			}
+
+			tests, err := readFunctionTests(commandeer.testFilePath)
+			if err != nil {
+				return errors.Wrap(err, "Failed to read test file")
+			}
+
+			// the platform is only needed to find the deployed function and its recorded events
+			if commandeer.url == "" || functionTestsUseFunctionEvents(tests) {
+				if err := rootCommandeer.initialize(); err != nil {
+					return errors.Wrap(err, "Failed to initialize root")
+				}
+			} else if rootCommandeer.loggerInstance, err = rootCommandeer.createLogger(); err != nil {
+				return errors.Wrap(err, "Failed to create logger")
+			}
+
+			invocationURL, err := commandeer.resolveInvocationURL(ctx, functionName)
+			if err != nil {
+				return errors.Wrap(err, "Failed to resolve invocation URL")
+			}
+
+			httpClient := commandeer.createHTTPClient()
+
+			var results []*functionTestResult
+			for _, test := range tests {
+				results = append(results, commandeer.runTest(ctx, httpClient, functionName, invocationURL, test))
+			}
+
+			renderFunctionTestResults(cmd.OutOrStdout(), results)
+
+			if commandeer.junitReportPath != "" {
+				if err := commandeer.writeJUnitReport(functionName, results); err != nil {
+					return errors.Wrap(err, "Failed to write JUnit report")
+				}
+			}
+
+			var failedTests int
+			for _, result := range results {
+				if !result.passed() {
+					failedTests++
+				}
+			}
+
+			if failedTests > 0 {
+				return errors.Errorf("%d of %d tests failed", failedTests, len(results))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&commandeer.testFilePath, "file", "f", "", "Path to the test file")
+	cmd.Flags().StringVar(&commandeer.url, "url", "", "Send the events to this URL instead of the deployed function (e.g. localhost:8080)")
+	cmd.Flags().StringVarP(&commandeer.invokeVia, "via", "", "any", "Invoke the function via - \"any\": a load balancer or an external IP; \"loadbalancer\": a load balancer; \"external-ip\": an external IP")
+	cmd.Flags().StringVarP(&commandeer.externalIPAddresses, "external-ips", "", os.Getenv("NUCTL_EXTERNAL_IP_ADDRESSES"), "External IP addresses (comma-delimited) with which to invoke the function")
+	cmd.Flags().DurationVarP(&commandeer.timeout, "timeout", "t", platformconfig.DefaultFunctionInvocationTimeoutSeconds*time.Second, "Timeout of each event")
+	cmd.Flags().StringVar(&commandeer.junitReportPath, "junit-report", "", "Write the results as JUnit XML to this path")
+	cmd.Flags().BoolVarP(&commandeer.skipTLSVerification, "skip-tls", "", false, "Skip TLS verification")
+
+	commandeer.cmd = cmd
+
+	return commandeer
+}
+
+func readFunctionTests(testFilePath string) ([]functionTest, error) {
+	encodedTestFile, err := os.ReadFile(testFilePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read test file")
+	}
+
+	testFile := functionTestFile{}
+	if err := yaml.Unmarshal(encodedTestFile, &testFile); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse test file")
+	}
+
+	if len(testFile.Tests) == 0 {
+		return nil, errors.New("Test file has no tests")
+	}
+
+	testNames := map[string]bool{}
+	for testIndex, test := range testFile.Tests {
+		if test.Name == "" {
+			return nil, errors.Errorf("Test #%d has no name", testIndex+1)
+		}
+
+		if testNames[test.Name] {
+			return nil, errors.Errorf("Test %s is defined more than once", test.Name)
+		}
+		testNames[test.Name] = true
+
+		// events can only be sent to the function's HTTP trigger
+		if test.Event.TriggerKind != "" && test.Event.TriggerKind != "http" {
+			return nil, errors.Errorf("Test %s: unsupported trigger kind %s, only http events can be sent",
+				test.Name,
+				test.Event.TriggerKind)
+		}
+
+		if err := validateFunctionTestExpectation(&test.Expect); err != nil {
+			return nil, errors.Wrapf(err, "Test %s: invalid expectation", test.Name)
+		}
+	}
+
+	return testFile.Tests, nil
+}
+
+func validateFunctionTestExpectation(expectation *functionTestExpectation) error {
+	for _, latencyBound := range []string{expectation.Latency.Min, expectation.Latency.Max} {
+		if latencyBound == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(latencyBound); err != nil {
+			return errors.Wrap(err, "Invalid latency bound")
+		}
+	}
+
+	for _, jsonAssertion := range expectation.JSON {
+		if _, err := parseFunctionTestJSONPath(jsonAssertion.Path); err != nil {
+			return errors.Wrapf(err, "Invalid JSON path %s", jsonAssertion.Path)
+		}
+		if jsonAssertion.Matches != "" {
+			if _, err := regexp.Compile(jsonAssertion.Matches); err != nil {
+				return errors.Wrapf(err, "Invalid pattern of JSON path %s", jsonAssertion.Path)
+			}
+		}
+	}
+
+	return nil
+}
+
+func functionTestsUseFunctionEvents(tests []functionTest) bool {
+	for _, test := range tests {
+		if test.Event.FunctionEvent != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *functionTestCommandeer) resolveInvocationURL(ctx context.Context, functionName string) (string, error) {
+	invocationURL := f.url
+
+	if invocationURL == "" {
+
+		// resolve the deployed function's url the same way as invoke
+		invokeCommandeer := &invokeCommandeer{
+			rootCommandeer:      f.rootCommandeer,
+			invokeVia:           f.invokeVia,
+			externalIPAddresses: f.externalIPAddresses,
+			createFunctionInvocationOptions: platform.CreateFunctionInvocationOptions{
+				Name:      functionName,
+				Namespace: f.rootCommandeer.namespace,
+			},
+		}
+
+		if err := invokeCommandeer.resolveInvocationURL(ctx); err != nil {
+			return "", errors.Wrap(err, "Failed to resolve function invocation URL")
+		}
+
+		invocationURL = invokeCommandeer.createFunctionInvocationOptions.URL
+	}
+
+	if !strings.HasPrefix(invocationURL, "http://") && !strings.HasPrefix(invocationURL, "https://") {
+		invocationURL = "http://" + invocationURL
+	}
+
+	return strings.TrimRight(invocationURL, "/"), nil
+}
+
+func (f *functionTestCommandeer) createHTTPClient() *http.Client {
+	httpClient := &http.Client{
+		Timeout: f.timeout,
+	}
+
+	if f.skipTLSVerification {
+		httpClient.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // nolint: gosec
+		}
+	}
+
+	return httpClient
+}
+
+func (f *functionTestCommandeer) runTest(ctx context.Context,
+	httpClient *http.Client,
+	functionName string,
+	invocationURL string,
+	test functionTest) *functionTestResult {
+	result := &functionTestResult{
+		name: test.Name,
+	}
+
+	request, err := f.resolveTestRequest(ctx, functionName, test.Event)
+	if err != nil {
+		result.err = errors.Wrap(err, "Failed to resolve event")
+		return result
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx,
+		request.method,
+		invocationURL+"/"+strings.TrimLeft(request.path, "/"),
+		bytes.NewReader(request.body))
+	if err != nil {
+		result.err = errors.Wrap(err, "Failed to create HTTP request")
+		return result
+	}
+
+	httpRequest.Header = request.headers
+	httpRequest.Header.Set("x-nuclio-target", functionName)
+
+	f.rootCommandeer.loggerInstance.DebugWithCtx(ctx,
+		"Sending event",
+		"test", test.Name,
+		"method", request.method,
+		"url", httpRequest.URL.String())
+
+	startTime := time.Now()
+	httpResponse, err := httpClient.Do(httpRequest)
+	if err != nil {
+		result.err = errors.Wrap(err, "Failed to send event")
+		return result
+	}
+	defer httpResponse.Body.Close() // nolint: errcheck
+
+	responseBody, err := io.ReadAll(httpResponse.Body)
+	result.latency = time.Since(startTime)
+	if err != nil {
+		result.err = errors.Wrap(err, "Failed to read response body")
+		return result
+	}
+
+	result.statusCode = httpResponse.StatusCode
+	result.failures = checkFunctionTestExpectation(&test.Expect,
+		httpResponse.StatusCode,
+		httpResponse.Header,
+		responseBody,
+		result.latency)
+
+	return result
+}
+
+// resolveTestRequest builds the request sent for a test's event, starting from its recorded function event, if any
+func (f *functionTestCommandeer) resolveTestRequest(ctx context.Context,
+	functionName string,
+	event functionTestEvent) (*functionTestRequest, error) {
+	request := &functionTestRequest{
+		headers: http.Header{},
+	}
+
+	if event.FunctionEvent != "" {
+		functionEventRequest, err := f.getFunctionEventRequest(ctx, functionName, event.FunctionEvent)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to get function event")
+		}
+		request = functionEventRequest
+	}
+
+	if event.Method != "" {
+		request.method = event.Method
+	}
+	if event.Path != "" {
+		request.path = event.Path
+	}
+	for headerName, headerValue := range event.Headers {
+		request.headers.Set(headerName, headerValue)
+	}
+
+	switch typedBody := event.Body.(type) {
+	case nil:
+	case string:
+		request.body = []byte(typedBody)
+	default:
+		encodedBody, err := json.Marshal(typedBody)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to encode body")
+		}
+		request.body = encodedBody
+		if request.headers.Get("Content-Type") == "" {
+			request.headers.Set("Content-Type", "application/json")
+		}
+	}
+
+	if request.method == "" {
+		request.method = http.MethodGet
+		if len(request.body) > 0 {
+			request.method = http.MethodPost
+		}
+	}
+
+	return request, nil
+}
+
+func (f *functionTestCommandeer) getFunctionEventRequest(ctx context.Context,
+	functionName string,
+	functionEventName string) (*functionTestRequest, error) {
+	functionEvents, err := f.rootCommandeer.platform.GetFunctionEvents(ctx, &platform.GetFunctionEventsOptions{
+		Meta: platform.FunctionEventMeta{
+			Name:      functionEventName,
+			Namespace: f.rootCommandeer.namespace,
+		},
+		FunctionNames: []string{functionName},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get function events")
+	}
+
+	if len(functionEvents) == 0 {
+		return nil, nuclio.NewErrNotFound(fmt.Sprintf("Function event %s not found", functionEventName))
+	}
+
+	return functionEventToRequest(functionEvents[0].GetConfig())
+}
+
+// functionEventToRequest builds a request from a function event, whose attributes hold the method, path and headers
+func functionEventToRequest(functionEventConfig *platform.FunctionEventConfig) (*functionTestRequest, error) {
+	if functionEventConfig.Spec.TriggerKind != "" && functionEventConfig.Spec.TriggerKind != "http" {
+		return nil, errors.Errorf("Unsupported trigger kind %s, only http events can be sent",
+			functionEventConfig.Spec.TriggerKind)
+	}
+
+	request := &functionTestRequest{
+		headers: http.Header{},
+		body:    []byte(functionEventConfig.Spec.Body),
+	}
+
+	attributes := functionEventConfig.Spec.Attributes
+	if method, ok := attributes["method"].(string); ok {
+		request.method = method
+	}
+	if path, ok := attributes["path"].(string); ok {
+		request.path = path
+	}
+	if headers, ok := attributes["headers"].(map[string]interface{}); ok {
+		for headerName, headerValue := range headers {
+			request.headers.Set(headerName, fmt.Sprint(headerValue))
+		}
+	}
+
+	return request, nil
+}
+
+// checkFunctionTestExpectation returns a description of each expectation the response doesn't meet
+func checkFunctionTestExpectation(expectation *functionTestExpectation,
+	statusCode int,
+	headers http.Header,
+	body []byte,
+	latency time.Duration) []string {
+	var failures []string
+
+	if expectation.Status != 0 {
+		if statusCode != expectation.Status {
+			failures = append(failures, fmt.Sprintf("expected status %d, got %d", expectation.Status, statusCode))
+		}
+	} else if statusCode < 200 || statusCode > 299 {
+		failures = append(failures, fmt.Sprintf("expected a 2xx status, got %d", statusCode))
+	}
+
+	for headerName, expectedHeaderValue := range expectation.Headers {
+		if headerValue := headers.Get(headerName); headerValue != expectedHeaderValue {
+			failures = append(failures, fmt.Sprintf("expected header %s to be %q, got %q",
+				headerName,
+				expectedHeaderValue,
+				headerValue))
+		}
+	}
+
+	if expectation.Body != nil && string(body) != *expectation.Body {
+		failures = append(failures, fmt.Sprintf("expected body %q, got %q", *expectation.Body, string(body)))
+	}
+
+	if expectation.BodyContains != "" && !strings.Contains(string(body), expectation.BodyContains) {
+		failures = append(failures, fmt.Sprintf("expected body to contain %q", expectation.BodyContains))
+	}
+
+	if len(expectation.JSON) > 0 {
+		var decodedBody interface{}
+		if err := json.Unmarshal(body, &decodedBody); err != nil {
+			failures = append(failures, "expected a JSON body")
+		} else {
+			for _, jsonAssertion := range expectation.JSON {
+				if failure := checkFunctionTestJSONAssertion(&jsonAssertion, decodedBody); failure != "" {
+					failures = append(failures, failure)
+				}
+			}
+		}
+	}
+
+	if expectation.Latency.Min != "" {
+		if minLatency, _ := time.ParseDuration(expectation.Latency.Min); latency < minLatency {
+			failures = append(failures, fmt.Sprintf("expected latency of at least %s, got %s", minLatency, latency))
+		}
+	}
+
+	if expectation.Latency.Max != "" {
+		if maxLatency, _ := time.ParseDuration(expectation.Latency.Max); latency > maxLatency {
+			failures = append(failures, fmt.Sprintf("expected latency of at most %s, got %s", maxLatency, latency))
+		}
+	}
+
+	return failures
+}
+
+func checkFunctionTestJSONAssertion(jsonAssertion *functionTestJSONAssertion, decodedBody interface{}) string {
+	value, found := findFunctionTestJSONPath(jsonAssertion.Path, decodedBody)
+
+	if jsonAssertion.Exists != nil && *jsonAssertion.Exists != found {
+		if found {
+			return fmt.Sprintf("expected %s not to exist", jsonAssertion.Path)
+		}
+		return fmt.Sprintf("expected %s to exist", jsonAssertion.Path)
+	}
+
+	if len(jsonAssertion.Equals) == 0 && jsonAssertion.Matches == "" {
+		return ""
+	}
+
+	if !found {
+		return fmt.Sprintf("expected %s to exist", jsonAssertion.Path)
+	}
+
+	if len(jsonAssertion.Equals) > 0 {
+		var expectedValue interface{}
+		if err := json.Unmarshal(jsonAssertion.Equals, &expectedValue); err != nil {
+			return fmt.Sprintf("invalid expected value of %s: %s", jsonAssertion.Path, err)
+		}
+
+		if !reflect.DeepEqual(expectedValue, value) {
+			encodedValue, _ := json.Marshal(value)
+			return fmt.Sprintf("expected %s to equal %s, got %s",
+				jsonAssertion.Path,
+				string(jsonAssertion.Equals),
+				string(encodedValue))
+		}
+	}
+
+	if jsonAssertion.Matches != "" {
+		stringValue := fmt.Sprint(value)
+		if matched, _ := regexp.MatchString(jsonAssertion.Matches, stringValue); !matched {
+			return fmt.Sprintf("expected %s to match %q, got %q", jsonAssertion.Path, jsonAssertion.Matches, stringValue)
+		}
+	}
+
+	return ""
+}
+
+// parseFunctionTestJSONPath parses a JSON path, with or without the leading $ (e.g. $.items[0].id, items[0].id)
+func parseFunctionTestJSONPath(path string) (*jsonpath.JSONPath, error) {
+	path = strings.TrimPrefix(path, "$")
+	if !strings.HasPrefix(path, ".") && !strings.HasPrefix(path, "[") {
+		path = "." + path
+	}
+
+	parsedPath := jsonpath.New("assertion")
+	if err := parsedPath.Parse("{" + path + "}"); err != nil {
+		return nil, err
+	}
+
+	return parsedPath, nil
+}
+
+// findFunctionTestJSONPath returns the value at a JSON path, or a list of values if it matches more than one
+func findFunctionTestJSONPath(path string, decodedBody interface{}) (interface{}, bool) {
+	parsedPath, err := parseFunctionTestJSONPath(path)
+	if err != nil {
+		return nil, false
+	}
+
+	results, err := parsedPath.FindResults(decodedBody)
+	if err != nil || len(results) == 0 || len(results[0]) == 0 {
+		return nil, false
+	}
+
+	if len(results[0]) == 1 {
+		return results[0][0].Interface(), true
+	}
+
+	var values []interface{}
+	for _, result := range results[0] {
+		values = append(values, result.Interface())
+	}
+
+	return values, true
+}
+
+func renderFunctionTestResults(writer io.Writer, results []*functionTestResult) {
+	var records [][]string
+	for _, result := range results {
+		outcome := "PASS"
+		details := ""
+
+		if result.err != nil {
+			outcome = "ERROR"
+			details = errors.Cause(result.err).Error()
+		} else if len(result.failures) > 0 {
+			outcome = "FAIL"
+			details = strings.Join(result.failures, "; ")
+		}
+
+		statusCode := ""
+		if result.statusCode != 0 {
+			statusCode = strconv.Itoa(result.statusCode)
+		}
+
+		records = append(records, []string{
+			outcome,
+			result.name,
+			statusCode,
+			result.latency.Round(time.Millisecond).String(),
+			details,
+		})
+	}
+
+	renderer.NewRenderer(writer).RenderTable([]string{"Result", "Test", "Status", "Latency", "Details"}, records)
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+func (f *functionTestCommandeer) writeJUnitReport(functionName string, results []*functionTestResult) error {
+	junitReport := renderFunctionTestJUnitReport(functionName, time.Now(), results)
+
+	return os.WriteFile(f.junitReportPath, junitReport, 0644)
+}
+
+// renderFunctionTestJUnitReport renders the results as a JUnit XML report, with a test suite named after the function
+func renderFunctionTestJUnitReport(functionName string, timestamp time.Time, results []*functionTestResult) []byte {
+	testSuite := junitTestSuite{
+		Name:      functionName,
+		Tests:     len(results),
+		Timestamp: timestamp.UTC().Format("2006-01-02T15:04:05"),
+	}
+
+	var totalLatency time.Duration
+	for _, result := range results {
+		testCase := junitTestCase{
+			Name:      result.name,
+			ClassName: functionName,
+			Time:      formatJUnitSeconds(result.latency),
+		}
+
+		if result.err != nil {
+			testSuite.Errors++
+			testCase.Error = &junitProblem{
+				Message:  errors.Cause(result.err).Error(),
+				Contents: errors.GetErrorStackString(result.err, 10),
+			}
+		} else if len(result.failures) > 0 {
+			testSuite.Failures++
+			testCase.Failure = &junitProblem{
+				Message:  result.failures[0],
+				Contents: strings.Join(result.failures, "\n"),
+			}
+		}
+
+		totalLatency += result.latency
+		testSuite.TestCases = append(testSuite.TestCases, testCase)
+	}
+	testSuite.Time = formatJUnitSeconds(totalLatency)
+
+	// marshalling these types can't fail
+	encodedReport, _ := xml.MarshalIndent(junitTestSuites{
+		TestSuites: []junitTestSuite{testSuite},
+	}, "", "  ")
+
+	return append([]byte(xml.Header), append(encodedReport, '\n')...)
+}
+
+func formatJUnitSeconds(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'f', 3, 64)
+}
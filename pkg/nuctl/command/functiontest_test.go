@@ -0,0 +1,285 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/platform"
+	mockplatform "github.com/nuclio/nuclio/pkg/platform/mock"
+
+	"github.com/nuclio/errors"
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type functionTestTestSuite struct {
+	suite.Suite
+	mockPlatform *mockplatform.Platform
+	commandeer   *functionTestCommandeer
+	server       *httptest.Server
+}
+
+func (suite *functionTestTestSuite) SetupTest() {
+	loggerInstance, err := nucliozap.NewNuclioZapTest("test")
+	suite.Require().NoError(err)
+
+	// echoes the request back as JSON
+	suite.server = httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		responseWriter.Header().Set("Content-Type", "application/json")
+		if request.URL.Path == "/missing" {
+			responseWriter.WriteHeader(http.StatusNotFound)
+		}
+		responseWriter.Write([]byte(`{"method": "` + request.Method + `", "path": "` + request.URL.Path + // nolint: errcheck
+			`", "target": "` + request.Header.Get("x-nuclio-target") + `", "body": ` + string(body) + `}`))
+	}))
+
+	suite.mockPlatform = &mockplatform.Platform{}
+	suite.commandeer = &functionTestCommandeer{
+		rootCommandeer: &RootCommandeer{
+			loggerInstance: loggerInstance,
+			platform:       suite.mockPlatform,
+			namespace:      "default-namespace",
+		},
+		url:     suite.server.URL,
+		timeout: 5 * time.Second,
+	}
+}
+
+func (suite *functionTestTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *functionTestTestSuite) TestReadFunctionTests() {
+	for _, testCase := range []struct {
+		name          string
+		testFile      string
+		expectedError bool
+	}{
+		{
+			name: "valid",
+			testFile: `tests:
+- name: greets
+  event:
+    body: {name: world}
+  expect:
+    status: 200
+    json:
+    - path: $.body.name
+      equals: world
+    latency:
+      max: 500ms
+`,
+		},
+		{
+			name:          "noTests",
+			testFile:      "tests: []",
+			expectedError: true,
+		},
+		{
+			name: "duplicateNames",
+			testFile: `tests:
+- name: greets
+- name: greets
+`,
+			expectedError: true,
+		},
+		{
+			name: "unsupportedTriggerKind",
+			testFile: `tests:
+- name: greets
+  event:
+    triggerKind: kafka-cluster
+`,
+			expectedError: true,
+		},
+		{
+			name: "invalidLatency",
+			testFile: `tests:
+- name: greets
+  expect:
+    latency:
+      max: fast
+`,
+			expectedError: true,
+		},
+	} {
+		suite.Run(testCase.name, func() {
+			testFilePath := filepath.Join(suite.T().TempDir(), "tests.yaml")
+			suite.Require().NoError(os.WriteFile(testFilePath, []byte(testCase.testFile), 0600))
+
+			tests, err := readFunctionTests(testFilePath)
+			if testCase.expectedError {
+				suite.Require().Error(err, "Read invalid tests should not succeed")
+				return
+			}
+
+			suite.Require().NoError(err, "Read tests should succeed")
+			suite.Require().Len(tests, 1)
+		})
+	}
+}
+
+func (suite *functionTestTestSuite) TestRunTest() {
+	trueValue := true
+	falseValue := false
+	expectedBody := "unexpected"
+
+	for _, testCase := range []struct {
+		name             string
+		test             functionTest
+		expectedFailures int
+	}{
+		{
+			name: "passing",
+			test: functionTest{
+				Event: functionTestEvent{
+					Path: "/greet",
+					Body: map[string]interface{}{"name": "world", "tags": []interface{}{"a", "b"}},
+				},
+				Expect: functionTestExpectation{
+					Status:  200,
+					Headers: map[string]string{"Content-Type": "application/json"},
+					JSON: []functionTestJSONAssertion{
+						{Path: "$.method", Equals: []byte(`"POST"`)},
+						{Path: "path", Matches: "^/gr"},
+						{Path: "$.target", Equals: []byte(`"greeter"`)},
+						{Path: "$.body.tags[1]", Equals: []byte(`"b"`)},
+						{Path: "$.body", Equals: []byte(`{"name": "world", "tags": ["a", "b"]}`)},
+						{Path: "$.body.missing", Exists: &falseValue},
+					},
+					Latency: functionTestLatency{Max: "1m"},
+				},
+			},
+		},
+		{
+			name: "failing",
+			test: functionTest{
+				Event: functionTestEvent{
+					Method: http.MethodPut,
+					Path:   "/missing",
+					Body:   `"raw"`,
+				},
+				Expect: functionTestExpectation{
+					Body: &expectedBody,
+					JSON: []functionTestJSONAssertion{
+						{Path: "$.method", Equals: []byte(`"PUT"`)},
+						{Path: "$.body", Equals: []byte(`"cooked"`)},
+						{Path: "$.body.missing", Exists: &trueValue},
+					},
+					Latency: functionTestLatency{Min: "1m"},
+				},
+			},
+
+			// status, body, two json assertions and latency
+			expectedFailures: 5,
+		},
+	} {
+		suite.Run(testCase.name, func() {
+			testCase.test.Name = testCase.name
+			result := suite.commandeer.runTest(context.Background(),
+				suite.commandeer.createHTTPClient(),
+				"greeter",
+				suite.server.URL,
+				testCase.test)
+
+			suite.Require().NoError(result.err)
+			suite.Require().Len(result.failures, testCase.expectedFailures, result.failures)
+		})
+	}
+}
+
+func (suite *functionTestTestSuite) TestRunTestWithFunctionEvent() {
+	functionEvent := &platform.AbstractFunctionEvent{}
+	functionEvent.FunctionEventConfig.Meta.Name = "recorded"
+	functionEvent.FunctionEventConfig.Spec.TriggerKind = "http"
+	functionEvent.FunctionEventConfig.Spec.Body = `{"name": "recorded"}`
+	functionEvent.FunctionEventConfig.Spec.Attributes = map[string]interface{}{
+		"method": http.MethodPatch,
+		"path":   "/recorded",
+	}
+
+	suite.mockPlatform.
+		On("GetFunctionEvents", mock.Anything, mock.MatchedBy(func(options *platform.GetFunctionEventsOptions) bool {
+			return options.Meta.Name == "recorded" && options.FunctionNames[0] == "greeter"
+		})).
+		Return([]platform.FunctionEvent{functionEvent}, nil).
+		Once()
+
+	result := suite.commandeer.runTest(context.Background(),
+		suite.commandeer.createHTTPClient(),
+		"greeter",
+		suite.server.URL,
+		functionTest{
+			Name: "recorded",
+			Event: functionTestEvent{
+				FunctionEvent: "recorded",
+				Path:          "/overridden",
+			},
+			Expect: functionTestExpectation{
+				JSON: []functionTestJSONAssertion{
+					{Path: "$.method", Equals: []byte(`"PATCH"`)},
+					{Path: "$.path", Equals: []byte(`"/overridden"`)},
+					{Path: "$.body.name", Equals: []byte(`"recorded"`)},
+				},
+			},
+		})
+
+	suite.Require().NoError(result.err)
+	suite.Require().Empty(result.failures)
+	suite.mockPlatform.AssertExpectations(suite.T())
+}
+
+func (suite *functionTestTestSuite) TestRenderJUnitReport() {
+	results := []*functionTestResult{
+		{name: "passes", statusCode: 200, latency: 10 * time.Millisecond},
+		{name: "fails", statusCode: 500, latency: 20 * time.Millisecond, failures: []string{"expected a 2xx status, got 500"}},
+		{name: "errors", err: errors.New("connection refused")},
+	}
+
+	encodedReport := renderFunctionTestJUnitReport("greeter", time.Now(), results)
+
+	report := junitTestSuites{}
+	suite.Require().NoError(xml.Unmarshal(encodedReport, &report), "Report should be valid XML")
+	suite.Require().Len(report.TestSuites, 1)
+
+	testSuite := report.TestSuites[0]
+	suite.Require().Equal("greeter", testSuite.Name)
+	suite.Require().Equal(3, testSuite.Tests)
+	suite.Require().Equal(1, testSuite.Failures)
+	suite.Require().Equal(1, testSuite.Errors)
+	suite.Require().Equal("0.030", testSuite.Time)
+	suite.Require().Nil(testSuite.TestCases[0].Failure)
+	suite.Require().Equal("expected a 2xx status, got 500", testSuite.TestCases[1].Failure.Message)
+	suite.Require().Equal("connection refused", testSuite.TestCases[2].Error.Message)
+}
+
+func TestFunctionTestTestSuite(t *testing.T) {
+	suite.Run(t, new(functionTestTestSuite))
+}
@@ -17,11 +17,19 @@ limitations under the License.
 package command
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/nuclio/nuclio/pkg/functionconfig"
 	"github.com/nuclio/nuclio/pkg/nuctl/command/common"
 	"github.com/nuclio/nuclio/pkg/platform"
+	"github.com/nuclio/nuclio/pkg/renderer"
 
 	"github.com/nuclio/errors"
 	"github.com/nuclio/nuclio-sdk-go"
@@ -47,12 +55,16 @@ func newGetCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *getC
 	getProjectCommand := newGetProjectCommandeer(ctx, commandeer).cmd
 	getFunctionEventCommand := newGetFunctionEventCommandeer(ctx, commandeer).cmd
 	getAPIGatewayCommand := newGetAPIGatewayCommandeer(ctx, commandeer).cmd
+	getFunctionRevisionCommand := newGetFunctionRevisionCommandeer(ctx, commandeer).cmd
+	getGraphCommand := newGetGraphCommandeer(ctx, commandeer).cmd
 
 	cmd.AddCommand(
 		getFunctionCommand,
 		getProjectCommand,
 		getFunctionEventCommand,
 		getAPIGatewayCommand,
+		getFunctionRevisionCommand,
+		getGraphCommand,
 	)
 
 	commandeer.cmd = cmd
@@ -64,6 +76,15 @@ type getFunctionCommandeer struct {
 	*getCommandeer
 	getFunctionsOptions platform.GetFunctionsOptions
 	output              string
+	watch               bool
+	watchInterval       time.Duration
+}
+
+// functionDeletionRecord is rendered when watching functions, for each function that was removed. it has the
+// same metadata and status envelope as the functions rendered alongside it
+type functionDeletionRecord struct {
+	Meta   functionconfig.Meta   `json:"metadata"`
+	Status functionconfig.Status `json:"status"`
 }
 
 func newGetFunctionCommandeer(ctx context.Context, getCommandeer *getCommandeer) *getFunctionCommandeer {
@@ -91,6 +112,14 @@ func newGetFunctionCommandeer(ctx context.Context, getCommandeer *getCommandeer)
 
 			commandeer.getFunctionsOptions.Namespace = getCommandeer.rootCommandeer.namespace
 
+			if commandeer.watch {
+				if commandeer.watchInterval <= 0 {
+					return errors.New("Watch interval must be positive")
+				}
+
+				return commandeer.watchFunctions(ctx, cmd.OutOrStdout())
+			}
+
 			functions, err := getCommandeer.rootCommandeer.platform.GetFunctions(ctx, &commandeer.getFunctionsOptions)
 			if err != nil {
 				return errors.Wrap(err, "Failed to get functions")
@@ -116,11 +145,90 @@ func newGetFunctionCommandeer(ctx context.Context, getCommandeer *getCommandeer)
 
 	cmd.PersistentFlags().StringVarP(&commandeer.getFunctionsOptions.Labels, "labels", "l", "", "Function labels (lbl1=val1[,lbl2=val2,...])")
 	cmd.PersistentFlags().StringVarP(&commandeer.output, "output", "o", common.OutputFormatText, "Output format - \"text\", \"wide\", \"yaml\", or \"json\"")
+	cmd.PersistentFlags().BoolVarP(&commandeer.watch, "watch", "w", false, "Keep rendering the functions whenever a function is added, removed, or changes state or replicas")
+	cmd.PersistentFlags().DurationVar(&commandeer.watchInterval, "watch-interval", 2*time.Second, "Time between checks for changes when watching")
 	commandeer.cmd = cmd
 
 	return commandeer
 }
 
+// watchFunctions renders the functions whenever they change, until interrupted. text is re-rendered in place on a
+// terminal, while YAML and JSON are rendered as a stream of documents, one for each function that changed
+func (g *getFunctionCommandeer) watchFunctions(ctx context.Context, writer io.Writer) error {
+	watchCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stateTracker := newFunctionStateTracker()
+	rendered := false
+
+	return pollFunctions(watchCtx,
+		g.rootCommandeer,
+		&g.getFunctionsOptions,
+		g.watchInterval,
+		0,
+		func(functions []platform.Function, now time.Time) error {
+			changedFunctions, removedStates := stateTracker.update(functions, now)
+			if rendered && len(changedFunctions) == 0 && len(removedStates) == 0 {
+				return nil
+			}
+			rendered = true
+
+			switch g.output {
+			case common.OutputFormatYAML, common.OutputFormatJSON:
+				return g.renderFunctionChanges(changedFunctions, removedStates, writer)
+			default:
+				frame := bytes.Buffer{}
+				if len(functions) == 0 {
+					frame.WriteString("No functions found\n")
+				} else if err := common.RenderInitializedFunctions(functions,
+					g.output,
+					&frame,
+					g.renderFunctionConfigWithStatus); err != nil {
+					return errors.Wrap(err, "Failed to render functions")
+				}
+
+				return renderFrame(writer, frame.Bytes())
+			}
+		})
+}
+
+// renderFunctionChanges renders a document for each function that changed, followed by a deletion record for each
+// function that was removed, so that whoever consumes the stream can tell them apart from functions that stopped
+// changing. YAML documents are separated by "---" so that the stream can be parsed as a multi-document YAML
+func (g *getFunctionCommandeer) renderFunctionChanges(changedFunctions []platform.Function,
+	removedStates []functionTrackedState,
+	writer io.Writer) error {
+	rendererInstance := renderer.NewRenderer(writer)
+
+	render := rendererInstance.RenderJSON
+	if g.output == common.OutputFormatYAML {
+		render = func(document interface{}) error {
+			fmt.Fprintln(writer, "---") // nolint: errcheck
+			return rendererInstance.RenderYAML(document)
+		}
+	}
+
+	if err := g.renderFunctionConfigWithStatus(changedFunctions, render); err != nil {
+		return errors.Wrap(err, "Failed to render functions")
+	}
+
+	for _, removedState := range removedStates {
+		if err := render(functionDeletionRecord{
+			Meta: functionconfig.Meta{
+				Name:      removedState.name,
+				Namespace: removedState.namespace,
+			},
+			Status: functionconfig.Status{
+				State: functionDeletedState,
+			},
+		}); err != nil {
+			return errors.Wrap(err, "Failed to render function deletion")
+		}
+	}
+
+	return nil
+}
+
 func (g *getFunctionCommandeer) renderFunctionConfigWithStatus(functions []platform.Function,
 	renderer func(interface{}) error) error {
 	for _, function := range functions {
@@ -346,3 +454,103 @@ func (g *getFunctionEventCommandeer) renderFunctionEventConfig(functionEvents []
 
 	return nil
 }
+
+type getFunctionRevisionCommandeer struct {
+	*getCommandeer
+	getFunctionRevisionsOptions platform.GetFunctionRevisionsOptions
+	output                      string
+}
+
+func newGetFunctionRevisionCommandeer(ctx context.Context, getCommandeer *getCommandeer) *getFunctionRevisionCommandeer {
+	commandeer := &getFunctionRevisionCommandeer{
+		getCommandeer: getCommandeer,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "function-revisions function-name",
+		Aliases: []string{"fr", "function-revision"},
+		Short:   "(or function-revision) Display the revisions of a function",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("Function revisions require a function name")
+			}
+
+			commandeer.getFunctionRevisionsOptions.Name = args[0]
+
+			// initialize root
+			if err := getCommandeer.rootCommandeer.initialize(); err != nil {
+				return errors.Wrap(err, "Failed to initialize root")
+			}
+
+			commandeer.getFunctionRevisionsOptions.Namespace = getCommandeer.rootCommandeer.namespace
+
+			functionRevisions, err := getCommandeer.rootCommandeer.platform.GetFunctionRevisions(ctx,
+				&commandeer.getFunctionRevisionsOptions)
+			if err != nil {
+				return errors.Wrap(err, "Failed to get function revisions")
+			}
+
+			if len(functionRevisions) == 0 {
+				cmd.OutOrStdout().Write([]byte("No function revisions found\n")) // nolint: errcheck
+				return nil
+			}
+
+			return common.RenderFunctionRevisions(functionRevisions, commandeer.output, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.PersistentFlags().IntVarP(&commandeer.getFunctionRevisionsOptions.Revision, "revision", "r", 0, "A specific revision to display")
+	cmd.PersistentFlags().StringVarP(&commandeer.output, "output", "o", common.OutputFormatText, "Output format - \"text\", \"wide\", \"yaml\", or \"json\"")
+
+	commandeer.cmd = cmd
+
+	return commandeer
+}
+
+type getGraphCommandeer struct {
+	*getCommandeer
+	getDependencyGraphOptions platform.GetDependencyGraphOptions
+	output                    string
+}
+
+func newGetGraphCommandeer(ctx context.Context, getCommandeer *getCommandeer) *getGraphCommandeer {
+	commandeer := &getGraphCommandeer{
+		getCommandeer: getCommandeer,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "graph",
+		Aliases: []string{"dependency-graph"},
+		Short:   "Display the dependencies between functions, api gateways and function events",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			// initialize root
+			if err := getCommandeer.rootCommandeer.initialize(); err != nil {
+				return errors.Wrap(err, "Failed to initialize root")
+			}
+
+			commandeer.getDependencyGraphOptions.Namespace = getCommandeer.rootCommandeer.namespace
+
+			dependencyGraph, err := getCommandeer.rootCommandeer.platform.GetDependencyGraph(ctx,
+				&commandeer.getDependencyGraphOptions)
+			if err != nil {
+				return errors.Wrap(err, "Failed to get dependency graph")
+			}
+
+			if len(dependencyGraph.Edges) == 0 &&
+				(commandeer.output == common.OutputFormatText || commandeer.output == common.OutputFormatWide) {
+				cmd.OutOrStdout().Write([]byte("No dependencies found\n")) // nolint: errcheck
+				return nil
+			}
+
+			return common.RenderDependencyGraph(dependencyGraph, commandeer.output, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&commandeer.getDependencyGraphOptions.ProjectName, "project", "", "Limit the graph to the resources of a project")
+	cmd.PersistentFlags().StringVarP(&commandeer.output, "output", "o", common.OutputFormatText, "Output format - \"text\", \"wide\", \"yaml\", or \"json\"")
+
+	commandeer.cmd = cmd
+
+	return commandeer
+}
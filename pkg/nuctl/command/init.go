@@ -0,0 +1,557 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/dashboard/functiontemplates"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/renderer"
+
+	"github.com/ghodss/yaml"
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+	"github.com/spf13/cobra"
+)
+
+// functionTemplateRuntimePattern finds the runtime of templates that can't be rendered without values
+var functionTemplateRuntimePattern = regexp.MustCompile(`(?m)^\s+runtime:\s*["']?([^\s"']+)`)
+
+// runtimeSourceFileExtensions maps runtime names to the extension of their source files, as in the builder
+var runtimeSourceFileExtensions = map[string]string{
+	"shell":      "sh",
+	"golang":     "go",
+	"python":     "py",
+	"nodejs":     "js",
+	"java":       "java",
+	"ruby":       "rb",
+	"dotnetcore": "cs",
+}
+
+const functionTemplateDescriptionMaxLength = 80
+
+const functionTemplateTestFile = `# Run with: nuctl test {{ .Name }} -f tests.yaml
+# (add --url localhost:8080 to test a function started with 'nuctl run --local')
+tests:
+- name: responds
+  event:
+    method: POST
+    body: ""
+  expect:
+    status: 200
+    latency:
+      max: 5s
+`
+
+type initCommandeer struct {
+	cmd                        *cobra.Command
+	rootCommandeer             *RootCommandeer
+	loggerInstance             logger.Logger
+	templateName               string
+	runtime                    string
+	encodedValues              []string
+	path                       string
+	list                       bool
+	force                      bool
+	templatesGitRepository     string
+	templatesGitRef            string
+	templatesGitUsername       string
+	templatesGitPassword       string
+	templatesGithubAccessToken string
+	templatesArchiveAddress    string
+}
+
+func newInitCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *initCommandeer {
+	commandeer := &initCommandeer{
+		rootCommandeer: rootCommandeer,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "init [function-name] --template name",
+		Short: "Create a function directory from a function template",
+		Long: `Create a function directory from a function template.
+
+Renders one of the function templates offered by the dashboard and writes a directory, ready to be
+deployed with 'nuctl deploy --path', holding the function's function.yaml, its handler's source and a
+sample test file for 'nuctl test'. Pass --list to list the available templates.
+
+Templates are the ones built into Nuclio, and those in a git repository (--templates-git-repository and
+--templates-git-ref) or a zip archive (--templates-archive-address). The values of templates that take
+values default to the template's defaults, and are given with --set name=value.
+
+Arguments:
+  <function-name> (string) The name of the function (default - the name of the template)`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			// creating functions from templates requires no platform, only a logger
+			commandeer.loggerInstance, err = rootCommandeer.createLogger()
+			if err != nil {
+				return errors.Wrap(err, "Failed to create logger")
+			}
+
+			functionTemplatesRepository, err := commandeer.createFunctionTemplatesRepository()
+			if err != nil {
+				return errors.Wrap(err, "Failed to get function templates")
+			}
+
+			if commandeer.list {
+				return commandeer.renderFunctionTemplates(cmd.OutOrStdout(), functionTemplatesRepository)
+			}
+
+			if commandeer.templateName == "" {
+				return errors.New("Template must be given with --template (list the templates with --list)")
+			}
+
+			functionTemplate, err := commandeer.resolveFunctionTemplate(functionTemplatesRepository)
+			if err != nil {
+				return errors.Wrap(err, "Failed to resolve function template")
+			}
+
+			values, err := parseFunctionTemplateValues(commandeer.encodedValues)
+			if err != nil {
+				return errors.Wrap(err, "Failed to parse values")
+			}
+
+			functionConfig, err := renderFunctionTemplate(commandeer.loggerInstance, functionTemplate, values)
+			if err != nil {
+				return errors.Wrap(err, "Failed to render function template")
+			}
+
+			functionConfig.Meta.Name = getFunctionTemplateBaseName(functionTemplate)
+			if len(args) > 0 {
+				functionConfig.Meta.Name = args[0]
+			}
+
+			functionPath := commandeer.path
+			if functionPath == "" {
+				functionPath = functionConfig.Meta.Name
+			}
+
+			if err := commandeer.writeFunctionDirectory(functionPath, functionConfig, functionTemplate.SourceCode); err != nil {
+				return errors.Wrap(err, "Failed to write function directory")
+			}
+
+			commandeer.loggerInstance.InfoWithCtx(ctx,
+				"Function created",
+				"name", functionConfig.Meta.Name,
+				"template", functionTemplate.Name,
+				"path", functionPath)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&commandeer.templateName, "template", "t", "", "Name of the function template (e.g. helloworld)")
+	cmd.Flags().StringVarP(&commandeer.runtime, "runtime", "r", "", "Runtime of the function template, if the template exists for several runtimes")
+	cmd.Flags().StringArrayVar(&commandeer.encodedValues, "set", nil, "Template value name=value (may be given more than once)")
+	cmd.Flags().StringVarP(&commandeer.path, "path", "p", "", "Path to the function directory (default - the function name, under the current directory)")
+	cmd.Flags().BoolVarP(&commandeer.list, "list", "l", false, "List the function templates (filtered by --template and --runtime)")
+	cmd.Flags().BoolVar(&commandeer.force, "force", false, "Write to the function directory even if it isn't empty, overwriting its files")
+	cmd.Flags().StringVar(&commandeer.templatesGitRepository, "templates-git-repository", common.GetEnvOrDefaultString("NUCLIO_TEMPLATES_GIT_REPOSITORY", ""), "Git repository of function templates")
+	cmd.Flags().StringVar(&commandeer.templatesGitRef, "templates-git-ref", common.GetEnvOrDefaultString("NUCLIO_TEMPLATES_GIT_REF", ""), "Git reference of the function templates (e.g. refs/heads/master)")
+	cmd.Flags().StringVar(&commandeer.templatesGitUsername, "templates-git-username", common.GetEnvOrDefaultString("NUCLIO_TEMPLATES_GIT_USERNAME", ""), "Username of the git repository of function templates")
+	cmd.Flags().StringVar(&commandeer.templatesGitPassword, "templates-git-password", common.GetEnvOrDefaultString("NUCLIO_TEMPLATES_GIT_PASSWORD", ""), "Password of the git repository of function templates")
+	cmd.Flags().StringVar(&commandeer.templatesGithubAccessToken, "templates-github-access-token", common.GetEnvOrDefaultString("NUCLIO_TEMPLATES_GITHUB_ACCESS_TOKEN", ""), "GitHub access token of the git repository of function templates")
+	cmd.Flags().StringVar(&commandeer.templatesArchiveAddress, "templates-archive-address", common.GetEnvOrDefaultString("NUCLIO_TEMPLATES_ARCHIVE_ADDRESS", ""), "Address of a zip archive of function templates (e.g. file://path/to/templates.zip)")
+
+	commandeer.cmd = cmd
+
+	return commandeer
+}
+
+// createFunctionTemplatesRepository fetches the templates with the same fetchers as the dashboard
+func (i *initCommandeer) createFunctionTemplatesRepository() (*functiontemplates.Repository, error) {
+	generatedFunctionTemplateFetcher, err := functiontemplates.NewGeneratedFunctionTemplateFetcher(i.loggerInstance)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create pre-generated fetcher")
+	}
+
+	functionTemplateFetchers := []functiontemplates.FunctionTemplateFetcher{generatedFunctionTemplateFetcher}
+
+	if i.templatesGitRepository != "" && i.templatesGitRef != "" {
+		templatesGitRepository := functiontemplates.AttachCredentialsToGitRepository(i.loggerInstance,
+			i.templatesGitRepository,
+			i.templatesGitUsername,
+			i.templatesGitPassword,
+			i.templatesGithubAccessToken)
+
+		gitFunctionTemplateFetcher, err := functiontemplates.NewGitFunctionTemplateFetcher(i.loggerInstance,
+			templatesGitRepository,
+			i.templatesGitRef,
+			common.GetEnvOrDefaultString("NUCLIO_TEMPLATES_GIT_CA_CERT_CONTENTS", ""))
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to create git fetcher")
+		}
+
+		functionTemplateFetchers = append(functionTemplateFetchers, gitFunctionTemplateFetcher)
+	}
+
+	if i.templatesArchiveAddress != "" {
+		zipFunctionTemplateFetcher, err := functiontemplates.NewZipFunctionTemplateFetcher(i.loggerInstance,
+			i.templatesArchiveAddress)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to create zip template fetcher")
+		}
+
+		functionTemplateFetchers = append(functionTemplateFetchers, zipFunctionTemplateFetcher)
+	}
+
+	return functiontemplates.NewRepository(i.loggerInstance, functionTemplateFetchers)
+}
+
+// getMatchingFunctionTemplates returns the templates named (with or without their runtime) as given, and of the
+// given runtime
+func (i *initCommandeer) getMatchingFunctionTemplates(
+	functionTemplatesRepository *functiontemplates.Repository) []*functiontemplates.FunctionTemplate {
+	var matchingFunctionTemplates []*functiontemplates.FunctionTemplate
+
+	for _, functionTemplate := range functionTemplatesRepository.GetFunctionTemplates(nil) {
+		if i.templateName != "" &&
+			functionTemplate.Name != i.templateName &&
+			getFunctionTemplateBaseName(functionTemplate) != i.templateName {
+			continue
+		}
+
+		if i.runtime != "" {
+			runtimeName, _ := common.GetRuntimeNameAndVersion(i.runtime)
+			templateRuntimeName, _ := common.GetRuntimeNameAndVersion(i.getFunctionTemplateRuntime(functionTemplate))
+			if runtimeName != templateRuntimeName {
+				continue
+			}
+		}
+
+		matchingFunctionTemplates = append(matchingFunctionTemplates, functionTemplate)
+	}
+
+	sort.Slice(matchingFunctionTemplates, func(first, second int) bool {
+		return matchingFunctionTemplates[first].Name < matchingFunctionTemplates[second].Name
+	})
+
+	return matchingFunctionTemplates
+}
+
+func (i *initCommandeer) resolveFunctionTemplate(
+	functionTemplatesRepository *functiontemplates.Repository) (*functiontemplates.FunctionTemplate, error) {
+	matchingFunctionTemplates := i.getMatchingFunctionTemplates(functionTemplatesRepository)
+
+	switch len(matchingFunctionTemplates) {
+	case 0:
+		return nil, errors.Errorf("No template named %s was found (list the templates with --list)", i.templateName)
+	case 1:
+		return matchingFunctionTemplates[0], nil
+	}
+
+	var matchingFunctionTemplateNames []string
+	for _, functionTemplate := range matchingFunctionTemplates {
+		matchingFunctionTemplateNames = append(matchingFunctionTemplateNames, functionTemplate.Name)
+	}
+
+	return nil, errors.Errorf("Several templates match %s, choose one with --runtime: %s",
+		i.templateName,
+		strings.Join(matchingFunctionTemplateNames, ", "))
+}
+
+// getFunctionTemplateRuntime returns a template's runtime, which templates with values may only set when rendered
+func (i *initCommandeer) getFunctionTemplateRuntime(functionTemplate *functiontemplates.FunctionTemplate) string {
+	if functionTemplate.FunctionConfigTemplate == "" {
+		return functionTemplate.FunctionConfig.Spec.Runtime
+	}
+
+	functionConfig, err := renderFunctionTemplate(i.loggerInstance, functionTemplate, nil)
+	if err == nil {
+		return functionConfig.Spec.Runtime
+	}
+
+	// required values are missing - look for the runtime in the template itself
+	if match := functionTemplateRuntimePattern.FindStringSubmatch(functionTemplate.FunctionConfigTemplate); match != nil {
+		return match[1]
+	}
+
+	return ""
+}
+
+func (i *initCommandeer) renderFunctionTemplates(writer io.Writer,
+	functionTemplatesRepository *functiontemplates.Repository) error {
+	var records [][]string
+
+	for _, functionTemplate := range i.getMatchingFunctionTemplates(functionTemplatesRepository) {
+		description := functionTemplate.FunctionConfig.Spec.Description
+		if functionTemplate.FunctionConfigTemplate != "" {
+			if functionConfig, err := renderFunctionTemplate(i.loggerInstance, functionTemplate, nil); err == nil {
+				description = functionConfig.Spec.Description
+			}
+		}
+
+		// the first line is enough for a listing
+		description = strings.TrimSpace(strings.SplitN(strings.TrimSpace(description), "\n", 2)[0])
+		if len(description) > functionTemplateDescriptionMaxLength {
+			description = description[:functionTemplateDescriptionMaxLength-3] + "..."
+		}
+
+		var valueNames []string
+		for valueName := range functionTemplate.FunctionConfigValues {
+			valueNames = append(valueNames, valueName)
+		}
+		sort.Strings(valueNames)
+
+		records = append(records, []string{
+			getFunctionTemplateBaseName(functionTemplate),
+			i.getFunctionTemplateRuntime(functionTemplate),
+			strings.Join(valueNames, ", "),
+			description,
+		})
+	}
+
+	renderer.NewRenderer(writer).RenderTable([]string{"Name", "Runtime", "Values", "Description"}, records)
+
+	return nil
+}
+
+// getFunctionTemplateBaseName returns the template's name without its runtime (e.g. helloworld:python -> helloworld)
+func getFunctionTemplateBaseName(functionTemplate *functiontemplates.FunctionTemplate) string {
+	return strings.SplitN(functionTemplate.Name, ":", 2)[0]
+}
+
+// parseFunctionTemplateValues parses name=value pairs, where values are YAML (e.g. 2 is a number, "2" a string)
+func parseFunctionTemplateValues(encodedValues []string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	for _, encodedValue := range encodedValues {
+		valueNameAndValue := strings.SplitN(encodedValue, "=", 2)
+		if len(valueNameAndValue) != 2 || valueNameAndValue[0] == "" {
+			return nil, errors.Errorf("Value must be in the form of name=value: %s", encodedValue)
+		}
+
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(valueNameAndValue[1]), &value); err != nil || value == nil {
+			value = valueNameAndValue[1]
+		}
+
+		values[valueNameAndValue[0]] = value
+	}
+
+	return values, nil
+}
+
+// renderFunctionTemplate renders a template with the given values, on top of the template's defaults. Templates
+// without values are already rendered
+func renderFunctionTemplate(loggerInstance logger.Logger,
+	functionTemplate *functiontemplates.FunctionTemplate,
+	values map[string]interface{}) (*functionconfig.Config, error) {
+
+	if functionTemplate.FunctionConfigTemplate == "" {
+		if len(values) > 0 {
+			return nil, errors.Errorf("Template %s takes no values", functionTemplate.Name)
+		}
+
+		functionConfig := *functionTemplate.FunctionConfig
+		return &functionConfig, nil
+	}
+
+	renderValues := map[string]interface{}{}
+	var missingValueNames []string
+
+	for valueName, valueDefinition := range functionTemplate.FunctionConfigValues {
+		if defaultValue, found := getFunctionTemplateDefaultValue(valueDefinition); found {
+			renderValues[valueName] = defaultValue
+		} else if _, found := values[valueName]; !found {
+			missingValueNames = append(missingValueNames, valueName)
+		}
+	}
+
+	for valueName, value := range values {
+		if _, found := functionTemplate.FunctionConfigValues[valueName]; !found {
+			return nil, errors.Errorf("Template %s has no value named %s", functionTemplate.Name, valueName)
+		}
+
+		renderValues[valueName] = value
+	}
+
+	if len(missingValueNames) > 0 {
+		sort.Strings(missingValueNames)
+		return nil, errors.Errorf("Values without defaults must be given with --set: %s",
+			strings.Join(missingValueNames, ", "))
+	}
+
+	return functiontemplates.NewFunctionTemplateRenderer(loggerInstance).Render(&functiontemplates.RenderConfig{
+		Template: functionTemplate.FunctionConfigTemplate,
+		Values:   renderValues,
+	})
+}
+
+// getFunctionTemplateDefaultValue returns the default of a template value, which is either the value itself, or in
+// the defaultValue (or attributes.defaultValue) field of its definition
+func getFunctionTemplateDefaultValue(valueDefinition interface{}) (interface{}, bool) {
+	typedValueDefinition, isDefinition := valueDefinition.(map[string]interface{})
+	if !isDefinition {
+		return valueDefinition, valueDefinition != nil
+	}
+
+	if attributes, ok := typedValueDefinition["attributes"].(map[string]interface{}); ok {
+		if defaultValue, found := attributes["defaultValue"]; found {
+			return defaultValue, true
+		}
+	}
+
+	defaultValue, found := typedValueDefinition["defaultValue"]
+	return defaultValue, found
+}
+
+// writeFunctionDirectory writes the function's configuration, handler source and a sample test file
+func (i *initCommandeer) writeFunctionDirectory(functionPath string,
+	functionConfig *functionconfig.Config,
+	sourceCode string) error {
+
+	if !i.force {
+		if entries, err := os.ReadDir(functionPath); err == nil && len(entries) > 0 {
+			return errors.Errorf("Directory %s isn't empty, pass --force to write to it anyway", functionPath)
+		}
+	}
+
+	if err := os.MkdirAll(functionPath, 0755); err != nil {
+		return errors.Wrap(err, "Failed to create function directory")
+	}
+
+	// the source is written next to the configuration, rather than inlined in it
+	functionConfig.Spec.Build.FunctionSourceCode = ""
+
+	if sourceCode != "" {
+
+		// the written source replaces the template's, so a path to fetch it from (e.g. a git repository) is dropped
+		functionConfig.Spec.Build.Path = ""
+
+		sourceFileName, err := getFunctionSourceFileName(functionConfig)
+		if err != nil {
+			return errors.Wrap(err, "Failed to resolve handler source file name")
+		}
+
+		sourceFileMode := os.FileMode(0644)
+		if runtimeName, _ := common.GetRuntimeNameAndVersion(functionConfig.Spec.Runtime); runtimeName == "shell" {
+			sourceFileMode = 0755
+		}
+
+		if err := os.WriteFile(filepath.Join(functionPath, sourceFileName), []byte(sourceCode), sourceFileMode); err != nil {
+			return errors.Wrap(err, "Failed to write handler source")
+		}
+	}
+
+	encodedFunctionConfig, err := encodeFunctionTemplateConfig(&functionconfig.Config{
+		Meta: functionconfig.Meta{
+			Name:        functionConfig.Meta.Name,
+			Labels:      functionConfig.Meta.Labels,
+			Annotations: functionConfig.Meta.Annotations,
+		},
+		Spec: functionConfig.Spec,
+	})
+	if err != nil {
+		return errors.Wrap(err, "Failed to encode function configuration")
+	}
+
+	if err := os.WriteFile(filepath.Join(functionPath, "function.yaml"), encodedFunctionConfig, 0644); err != nil {
+		return errors.Wrap(err, "Failed to write function configuration")
+	}
+
+	testFile := strings.ReplaceAll(functionTemplateTestFile, "{{ .Name }}", functionConfig.Meta.Name)
+	if err := os.WriteFile(filepath.Join(functionPath, "tests.yaml"), []byte(testFile), 0644); err != nil {
+		return errors.Wrap(err, "Failed to write test file")
+	}
+
+	return nil
+}
+
+// encodeFunctionTemplateConfig encodes a function configuration as YAML, without the empty fields that aren't
+// omitted when encoding (e.g. "build: {}"), so that the written configuration holds only what the template sets
+func encodeFunctionTemplateConfig(functionConfig *functionconfig.Config) ([]byte, error) {
+	encodedFunctionConfig, err := json.Marshal(functionConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to encode function configuration")
+	}
+
+	var genericFunctionConfig interface{}
+	if err := json.Unmarshal(encodedFunctionConfig, &genericFunctionConfig); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode function configuration")
+	}
+
+	return yaml.Marshal(pruneEmptyValues(genericFunctionConfig))
+}
+
+// pruneEmptyValues removes empty strings, maps and lists from a decoded JSON value, returning nil if it's empty
+func pruneEmptyValues(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case string:
+		if typedValue == "" {
+			return nil
+		}
+	case map[string]interface{}:
+		for key, fieldValue := range typedValue {
+			if prunedFieldValue := pruneEmptyValues(fieldValue); prunedFieldValue != nil {
+				typedValue[key] = prunedFieldValue
+			} else {
+				delete(typedValue, key)
+			}
+		}
+		if len(typedValue) == 0 {
+			return nil
+		}
+	case []interface{}:
+		if len(typedValue) == 0 {
+			return nil
+		}
+	}
+
+	return value
+}
+
+// getFunctionSourceFileName names the handler source the way the builder does when given inline source code - by
+// the handler's module, with the runtime's extension
+func getFunctionSourceFileName(functionConfig *functionconfig.Config) (string, error) {
+	moduleFileName, entrypoint, err := functionconfig.ParseHandler(functionConfig.Spec.Handler)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to parse handler")
+	}
+
+	if moduleFileName == "" {
+		moduleFileName = entrypoint
+	}
+
+	if moduleFileName == "" {
+		return "", errors.New("Function has no handler")
+	}
+
+	if !strings.Contains(moduleFileName, ".") {
+		runtimeName, _ := common.GetRuntimeNameAndVersion(functionConfig.Spec.Runtime)
+		extension, found := runtimeSourceFileExtensions[runtimeName]
+		if !found {
+			return "", errors.Errorf("Unsupported runtime %s", functionConfig.Spec.Runtime)
+		}
+
+		moduleFileName = fmt.Sprintf("%s.%s", moduleFileName, extension)
+	}
+
+	return moduleFileName, nil
+}
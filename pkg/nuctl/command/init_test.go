@@ -0,0 +1,249 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	"github.com/ghodss/yaml"
+	"github.com/nuclio/logger"
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/suite"
+)
+
+type initTestSuite struct {
+	suite.Suite
+	logger     logger.Logger
+	commandeer *initCommandeer
+}
+
+func (suite *initTestSuite) SetupTest() {
+	var err error
+
+	suite.logger, err = nucliozap.NewNuclioZapTest("test")
+	suite.Require().NoError(err)
+
+	suite.commandeer = &initCommandeer{
+		loggerInstance: suite.logger,
+	}
+}
+
+func (suite *initTestSuite) TestResolveGeneratedFunctionTemplate() {
+	functionTemplatesRepository, err := suite.commandeer.createFunctionTemplatesRepository()
+	suite.Require().NoError(err)
+
+	// helloworld exists for several runtimes
+	suite.commandeer.templateName = "helloworld"
+	_, err = suite.commandeer.resolveFunctionTemplate(functionTemplatesRepository)
+	suite.Require().Error(err)
+
+	suite.commandeer.runtime = "python:3.9"
+	functionTemplate, err := suite.commandeer.resolveFunctionTemplate(functionTemplatesRepository)
+	suite.Require().NoError(err)
+	suite.Require().Equal("helloworld:python", functionTemplate.Name)
+
+	// templates without values take none
+	_, err = renderFunctionTemplate(suite.logger, functionTemplate, map[string]interface{}{"handler": "main:handler"})
+	suite.Require().Error(err)
+
+	functionConfig, err := renderFunctionTemplate(suite.logger, functionTemplate, nil)
+	suite.Require().NoError(err)
+	functionConfig.Meta.Name = "greeter"
+
+	functionPath := filepath.Join(suite.T().TempDir(), "greeter")
+	suite.Require().NoError(suite.commandeer.writeFunctionDirectory(functionPath, functionConfig, functionTemplate.SourceCode))
+
+	sourceCode, err := os.ReadFile(filepath.Join(functionPath, "helloworld.py"))
+	suite.Require().NoError(err)
+	suite.Require().Equal(functionTemplate.SourceCode, string(sourceCode))
+
+	writtenFunctionConfig := suite.readFunctionConfig(functionPath)
+	suite.Require().Equal("greeter", writtenFunctionConfig.Meta.Name)
+	suite.Require().Equal("helloworld:handler", writtenFunctionConfig.Spec.Handler)
+	suite.Require().Empty(writtenFunctionConfig.Spec.Build.FunctionSourceCode)
+
+	testFile, err := os.ReadFile(filepath.Join(functionPath, "tests.yaml"))
+	suite.Require().NoError(err)
+	suite.Require().Contains(string(testFile), "nuctl test greeter")
+
+	// existing directories aren't overwritten unless forced
+	suite.Require().Error(suite.commandeer.writeFunctionDirectory(functionPath, functionConfig, functionTemplate.SourceCode))
+	suite.commandeer.force = true
+	suite.Require().NoError(suite.commandeer.writeFunctionDirectory(functionPath, functionConfig, functionTemplate.SourceCode))
+}
+
+func (suite *initTestSuite) TestRenderArchivedFunctionTemplate() {
+	archivePath := filepath.Join(suite.T().TempDir(), "templates.zip")
+	suite.writeTemplatesArchive(archivePath, map[string]string{
+		"templates-master/reader/main.js": "exports.handler = function(context, event) {};",
+		"templates-master/reader/function.yaml.template": `spec:
+  runtime: nodejs
+  handler: main:handler
+  description: Reads a stream
+  minReplicas: {{ .minReplicas }}
+  env:
+  - name: STREAM
+    value: {{ .stream }}
+  build:
+    functionSourceCode: {{ .SourceCode }}
+`,
+		"templates-master/reader/function.yaml.values": `minReplicas:
+  displayName: Minimum replicas
+  kind: number
+  attributes:
+    defaultValue: 1
+stream:
+  displayName: Stream
+  kind: string
+  required: true
+`,
+	})
+
+	suite.commandeer.templatesArchiveAddress = "file://" + strings.TrimPrefix(archivePath, "/")
+	suite.commandeer.templateName = "reader"
+
+	functionTemplatesRepository, err := suite.commandeer.createFunctionTemplatesRepository()
+	suite.Require().NoError(err)
+
+	functionTemplate, err := suite.commandeer.resolveFunctionTemplate(functionTemplatesRepository)
+	suite.Require().NoError(err)
+	suite.Require().Equal("nodejs", suite.commandeer.getFunctionTemplateRuntime(functionTemplate))
+
+	// stream has no default
+	_, err = renderFunctionTemplate(suite.logger, functionTemplate, nil)
+	suite.Require().Error(err)
+	suite.Require().Contains(err.Error(), "stream")
+
+	// unknown values
+	_, err = renderFunctionTemplate(suite.logger, functionTemplate, map[string]interface{}{
+		"stream":  "events",
+		"unknown": "value",
+	})
+	suite.Require().Error(err)
+
+	values, err := parseFunctionTemplateValues([]string{"stream=events", "minReplicas=2"})
+	suite.Require().NoError(err)
+
+	functionConfig, err := renderFunctionTemplate(suite.logger, functionTemplate, values)
+	suite.Require().NoError(err)
+	suite.Require().Equal(2, *functionConfig.Spec.MinReplicas)
+	suite.Require().Equal("events", functionConfig.Spec.Env[0].Value)
+	functionConfig.Meta.Name = "reader"
+
+	functionPath := filepath.Join(suite.T().TempDir(), "reader")
+	suite.Require().NoError(suite.commandeer.writeFunctionDirectory(functionPath, functionConfig, functionTemplate.SourceCode))
+	suite.Require().FileExists(filepath.Join(functionPath, "main.js"))
+
+	writtenFunctionConfig := suite.readFunctionConfig(functionPath)
+	suite.Require().Empty(writtenFunctionConfig.Spec.Build.FunctionSourceCode)
+	suite.Require().Equal("events", writtenFunctionConfig.Spec.Env[0].Value)
+}
+
+func (suite *initTestSuite) TestWriteFunctionDirectoryBuildPath() {
+	functionConfig := functionconfig.NewConfig()
+	functionConfig.Meta.Name = "fetcher"
+	functionConfig.Spec.Runtime = "python:3.9"
+	functionConfig.Spec.Handler = "main:handler"
+	functionConfig.Spec.Build.Path = "https://github.com/nuclio/fetcher"
+
+	// without inline source, the function is built from its path
+	functionPath := filepath.Join(suite.T().TempDir(), "fetcher")
+	suite.Require().NoError(suite.commandeer.writeFunctionDirectory(functionPath, functionConfig, ""))
+	suite.Require().NoFileExists(filepath.Join(functionPath, "main.py"))
+	suite.Require().Equal("https://github.com/nuclio/fetcher", suite.readFunctionConfig(functionPath).Spec.Build.Path)
+
+	// the written source replaces it
+	functionPath = filepath.Join(suite.T().TempDir(), "fetcher")
+	suite.Require().NoError(suite.commandeer.writeFunctionDirectory(functionPath, functionConfig, "def handler(context, event):\n    pass\n"))
+	suite.Require().FileExists(filepath.Join(functionPath, "main.py"))
+	suite.Require().Empty(suite.readFunctionConfig(functionPath).Spec.Build.Path)
+}
+
+func (suite *initTestSuite) TestParseFunctionTemplateValues() {
+	values, err := parseFunctionTemplateValues([]string{"count=2", "name=world", "quoted=\"2\"", "list=a,b", "empty="})
+	suite.Require().NoError(err)
+	suite.Require().Equal(map[string]interface{}{
+		"count":  float64(2),
+		"name":   "world",
+		"quoted": "2",
+		"list":   "a,b",
+		"empty":  "",
+	}, values)
+
+	_, err = parseFunctionTemplateValues([]string{"novalue"})
+	suite.Require().Error(err)
+}
+
+func (suite *initTestSuite) TestGetFunctionSourceFileName() {
+	for _, testCase := range []struct {
+		runtime          string
+		handler          string
+		expectedFileName string
+	}{
+		{runtime: "python:3.9", handler: "helloworld:handler", expectedFileName: "helloworld.py"},
+		{runtime: "nodejs", handler: "handler", expectedFileName: "handler.js"},
+		{runtime: "golang", handler: "main:Handler", expectedFileName: "main.go"},
+		{runtime: "shell", handler: "img-convert.sh:main", expectedFileName: "img-convert.sh"},
+	} {
+		suite.Run(testCase.expectedFileName, func() {
+			functionConfig := functionconfig.NewConfig()
+			functionConfig.Spec.Runtime = testCase.runtime
+			functionConfig.Spec.Handler = testCase.handler
+
+			fileName, err := getFunctionSourceFileName(functionConfig)
+			suite.Require().NoError(err)
+			suite.Require().Equal(testCase.expectedFileName, fileName)
+		})
+	}
+}
+
+func (suite *initTestSuite) readFunctionConfig(functionPath string) *functionconfig.Config {
+	encodedFunctionConfig, err := os.ReadFile(filepath.Join(functionPath, "function.yaml"))
+	suite.Require().NoError(err)
+
+	functionConfig := &functionconfig.Config{}
+	suite.Require().NoError(yaml.Unmarshal(encodedFunctionConfig, functionConfig))
+
+	return functionConfig
+}
+
+func (suite *initTestSuite) writeTemplatesArchive(archivePath string, files map[string]string) {
+	archiveFile, err := os.Create(archivePath)
+	suite.Require().NoError(err)
+	defer archiveFile.Close() // nolint: errcheck
+
+	zipWriter := zip.NewWriter(archiveFile)
+	for fileName, fileContents := range files {
+		fileWriter, err := zipWriter.Create(fileName)
+		suite.Require().NoError(err)
+		_, err = fileWriter.Write([]byte(fileContents))
+		suite.Require().NoError(err)
+	}
+	suite.Require().NoError(zipWriter.Close())
+}
+
+func TestInitTestSuite(t *testing.T) {
+	suite.Run(t, new(initTestSuite))
+}
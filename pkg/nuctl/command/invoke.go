@@ -26,6 +26,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -37,6 +38,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/nuclio/errors"
 	"github.com/nuclio/logger"
+	"github.com/nuclio/nuclio-sdk-go"
 	"github.com/nuclio/zap"
 	"github.com/spf13/cobra"
 )
@@ -51,8 +53,14 @@ type invokeCommandeer struct {
 	contentType                     string
 	headers                         string
 	body                            string
+	functionEventName               string
+	allFunctionEvents               bool
 }
 
+// functionEventExpectedResponseAttribute is the function event attribute holding the response expected of the
+// function, in the format of the expectations of nuctl test
+const functionEventExpectedResponseAttribute = "expectedResponse"
+
 func newInvokeCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *invokeCommandeer {
 	commandeer := &invokeCommandeer{
 		rootCommandeer: rootCommandeer,
@@ -69,6 +77,10 @@ func newInvokeCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *i
 				return errors.New("Function invoke requires name")
 			}
 
+			if commandeer.functionEventName != "" && commandeer.allFunctionEvents {
+				return errors.New("Only one of --event and --all-events may be given")
+			}
+
 			// initialize root
 			if err := rootCommandeer.initialize(); err != nil {
 				return errors.Wrap(err, "Failed to initialize root")
@@ -77,6 +89,19 @@ func newInvokeCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *i
 			commandeer.createFunctionInvocationOptions.Name = args[0]
 			commandeer.createFunctionInvocationOptions.Namespace = rootCommandeer.namespace
 
+			// invoke with the requests stored in function events
+			if commandeer.functionEventName != "" || commandeer.allFunctionEvents {
+				if err := commandeer.validateLogLevelName(); err != nil {
+					return errors.Wrap(err, "Invalid log level")
+				}
+
+				if err := commandeer.resolveInvocationURL(ctx); err != nil {
+					return errors.Wrap(err, "Failed to resolve invocation URL")
+				}
+
+				return commandeer.invokeFunctionEvents(ctx, cmd.OutOrStdout())
+			}
+
 			// try parse body input from flag
 			commandeer.createFunctionInvocationOptions.Body, err = commandeer.resolveBody()
 			if err != nil {
@@ -87,13 +112,6 @@ func newInvokeCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *i
 			// resolve invocation method
 			commandeer.createFunctionInvocationOptions.Method = commandeer.resolveMethod()
 
-			// set external IP, if given
-			if commandeer.externalIPAddresses != "" {
-				if err := rootCommandeer.platform.SetExternalIPAddresses(strings.Split(commandeer.externalIPAddresses, ",")); err != nil {
-					return errors.Wrap(err, "Failed to set external IP address")
-				}
-			}
-
 			// set headers
 			for headerName, headerValue := range common.StringToStringMap(commandeer.headers, "=") {
 				commandeer.createFunctionInvocationOptions.Headers.Set(headerName, headerValue)
@@ -105,48 +123,13 @@ func newInvokeCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *i
 			}
 
 			// verify correctness of logger level
-			switch commandeer.createFunctionInvocationOptions.LogLevelName {
-			case "none", "debug", "info", "warn", "error":
-				break
-			default:
-				return errors.New("Invalid logger level name. Must be one of none / debug / info / warn / error")
-			}
-
-			// enrich request with function
-			if err := commandeer.createFunctionInvocationOptions.EnrichFunction(ctx, rootCommandeer.platform); err != nil {
-				return errors.Wrap(err, "Failed to enrich function invocation options")
-			}
-
-			// Implementation detail: first url is the intra-cluster url, following urls are external urls
-			invocationURLs := commandeer.createFunctionInvocationOptions.FunctionInstance.GetStatus().InvocationURLs()
-			if len(invocationURLs) == 0 {
-				return errors.New("Function has no invocation URLs")
+			if err := commandeer.validateLogLevelName(); err != nil {
+				return errors.Wrap(err, "Invalid log level")
 			}
 
-			// convert via
-			switch commandeer.invokeVia {
-			case "any":
-
-				// if running with platform, invoke internally
-				if common.RunningInContainer() || common.IsInKubernetesCluster() {
-					commandeer.createFunctionInvocationOptions.URL = invocationURLs[0]
-					break
-				}
-
-				// default to external ip
-				if err := commandeer.enrichOptionsForExternalIP(invocationURLs); err != nil {
-					return errors.Wrap(err, "Failed to invoke via external IP")
-				}
-
-			case "external-ip", "loadbalancer":
-
-				// unified behavior for BC.
-				if err := commandeer.enrichOptionsForExternalIP(invocationURLs); err != nil {
-					return errors.Wrap(err, "Failed to invoke via external IP")
-				}
-			default:
-				return errors.Errorf(`Unknown invocation method %s. Must be one of "any", "external-ip", "loadbalancer"`,
-					commandeer.invokeVia)
+			// resolve the function and the url through which it's invoked
+			if err := commandeer.resolveInvocationURL(ctx); err != nil {
+				return errors.Wrap(err, "Failed to resolve invocation URL")
 			}
 
 			commandeer.createFunctionInvocationOptions.Timeout = commandeer.timeout
@@ -171,11 +154,238 @@ func newInvokeCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *i
 	cmd.Flags().StringVarP(&commandeer.externalIPAddresses, "external-ips", "", os.Getenv("NUCTL_EXTERNAL_IP_ADDRESSES"), "External IP addresses (comma-delimited) with which to invoke the function")
 	cmd.Flags().DurationVarP(&commandeer.timeout, "timeout", "t", platformconfig.DefaultFunctionInvocationTimeoutSeconds*time.Second, "Invocation request timeout")
 	cmd.Flags().BoolVarP(&commandeer.createFunctionInvocationOptions.SkipTLSVerification, "skip-tls", "", false, "Skip TLS verification")
+	cmd.Flags().StringVar(&commandeer.functionEventName, "event", "", "Invoke with the body, method, path and headers of this function event (flags given override them)")
+	cmd.Flags().BoolVar(&commandeer.allFunctionEvents, "all-events", false, "Invoke with each of the function's function events")
 	commandeer.cmd = cmd
 
 	return commandeer
 }
 
+func (i *invokeCommandeer) validateLogLevelName() error {
+	switch i.createFunctionInvocationOptions.LogLevelName {
+	case "none", "debug", "info", "warn", "error":
+		return nil
+	default:
+		return errors.New("Invalid logger level name. Must be one of none / debug / info / warn / error")
+	}
+}
+
+// invokeFunctionEvents invokes the function with each of its function events (or the given one), checking the
+// responses against those the function events expect, if any
+func (i *invokeCommandeer) invokeFunctionEvents(ctx context.Context, writer io.Writer) error {
+	functionEvents, err := i.rootCommandeer.platform.GetFunctionEvents(ctx, &platform.GetFunctionEventsOptions{
+		Meta: platform.FunctionEventMeta{
+			Name:      i.functionEventName,
+			Namespace: i.rootCommandeer.namespace,
+		},
+		FunctionNames: []string{i.createFunctionInvocationOptions.Name},
+	})
+	if err != nil {
+		return errors.Wrap(err, "Failed to get function events")
+	}
+
+	if len(functionEvents) == 0 {
+		if i.functionEventName != "" {
+			return nuclio.NewErrNotFound(fmt.Sprintf("Function event %s not found", i.functionEventName))
+		}
+		return nuclio.NewErrNotFound("Function has no function events")
+	}
+
+	sort.Slice(functionEvents, func(first, second int) bool {
+		return functionEvents[first].GetConfig().Meta.Name < functionEvents[second].GetConfig().Meta.Name
+	})
+
+	var failedFunctionEventNames []string
+	for _, functionEvent := range functionEvents {
+		functionEventConfig := functionEvent.GetConfig()
+
+		i.rootCommandeer.loggerInstance.InfoWithCtx(ctx,
+			"Invoking function event",
+			"name", functionEventConfig.Meta.Name,
+			"displayName", functionEventConfig.Spec.DisplayName)
+
+		if err := i.invokeFunctionEvent(ctx, functionEventConfig, writer); err != nil {
+			i.rootCommandeer.loggerInstance.WarnWithCtx(ctx,
+				"Function event failed",
+				"name", functionEventConfig.Meta.Name,
+				"err", errors.Cause(err).Error())
+			failedFunctionEventNames = append(failedFunctionEventNames, functionEventConfig.Meta.Name)
+		}
+	}
+
+	if len(failedFunctionEventNames) > 0 {
+		return errors.Errorf("%d of %d function events failed: %s",
+			len(failedFunctionEventNames),
+			len(functionEvents),
+			strings.Join(failedFunctionEventNames, ", "))
+	}
+
+	return nil
+}
+
+func (i *invokeCommandeer) invokeFunctionEvent(ctx context.Context,
+	functionEventConfig *platform.FunctionEventConfig,
+	writer io.Writer) error {
+
+	expectation, err := resolveFunctionEventExpectation(functionEventConfig)
+	if err != nil {
+		return errors.Wrap(err, "Failed to resolve expected response")
+	}
+
+	createFunctionInvocationOptions, err := i.createFunctionEventInvocationOptions(functionEventConfig)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create invocation options")
+	}
+
+	startTime := time.Now()
+	invokeResult, err := i.rootCommandeer.platform.CreateFunctionInvocation(ctx, createFunctionInvocationOptions)
+	if err != nil {
+		return errors.Wrap(err, "Failed to invoke function")
+	}
+	latency := time.Since(startTime)
+
+	if err := i.outputInvokeResult(createFunctionInvocationOptions, invokeResult, writer); err != nil {
+		return errors.Wrap(err, "Failed to output invocation result")
+	}
+
+	if expectation == nil {
+		return nil
+	}
+
+	if failures := checkFunctionTestExpectation(expectation,
+		invokeResult.StatusCode,
+		invokeResult.Headers,
+		invokeResult.Body,
+		latency); len(failures) > 0 {
+		return errors.Errorf("Unexpected response: %s", strings.Join(failures, "; "))
+	}
+
+	i.rootCommandeer.loggerInstance.InfoWithCtx(ctx, "Got expected response", "name", functionEventConfig.Meta.Name)
+
+	return nil
+}
+
+// createFunctionEventInvocationOptions creates the options of invoking with a function event, overridden by the
+// flags given
+func (i *invokeCommandeer) createFunctionEventInvocationOptions(
+	functionEventConfig *platform.FunctionEventConfig) (*platform.CreateFunctionInvocationOptions, error) {
+	request, err := functionEventToRequest(functionEventConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read function event")
+	}
+
+	createFunctionInvocationOptions := i.createFunctionInvocationOptions
+	createFunctionInvocationOptions.Timeout = i.timeout
+	createFunctionInvocationOptions.Body = request.body
+	createFunctionInvocationOptions.Headers = request.headers
+
+	if i.body != "" {
+		createFunctionInvocationOptions.Body = []byte(i.body)
+	}
+
+	if !i.cmd.Flags().Changed("path") {
+		createFunctionInvocationOptions.Path = strings.TrimLeft(request.path, "/")
+	}
+
+	if !i.cmd.Flags().Changed("method") {
+		createFunctionInvocationOptions.Method = request.method
+	}
+	if createFunctionInvocationOptions.Method == "" {
+		createFunctionInvocationOptions.Method = http.MethodGet
+		if len(createFunctionInvocationOptions.Body) > 0 {
+			createFunctionInvocationOptions.Method = http.MethodPost
+		}
+	}
+
+	for headerName, headerValue := range common.StringToStringMap(i.headers, "=") {
+		createFunctionInvocationOptions.Headers.Set(headerName, headerValue)
+	}
+
+	if i.contentType != "" {
+		createFunctionInvocationOptions.Headers.Set("Content-Type", i.contentType)
+	} else if createFunctionInvocationOptions.Headers.Get("Content-Type") == "" {
+		createFunctionInvocationOptions.Headers.Set("Content-Type",
+			http.DetectContentType(createFunctionInvocationOptions.Body))
+	}
+
+	return &createFunctionInvocationOptions, nil
+}
+
+// resolveFunctionEventExpectation returns the response a function event expects, or nil if it expects none
+func resolveFunctionEventExpectation(functionEventConfig *platform.FunctionEventConfig) (*functionTestExpectation, error) {
+	expectedResponse, found := functionEventConfig.Spec.Attributes[functionEventExpectedResponseAttribute]
+	if !found || expectedResponse == nil {
+		return nil, nil
+	}
+
+	encodedExpectedResponse, err := json.Marshal(expectedResponse)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to encode expected response")
+	}
+
+	expectation := &functionTestExpectation{}
+	if err := json.Unmarshal(encodedExpectedResponse, expectation); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode expected response")
+	}
+
+	if err := validateFunctionTestExpectation(expectation); err != nil {
+		return nil, errors.Wrap(err, "Invalid expected response")
+	}
+
+	return expectation, nil
+}
+
+// resolveInvocationURL enriches the invocation options with the function and the url through which it's
+// invoked, according to --via
+func (i *invokeCommandeer) resolveInvocationURL(ctx context.Context) error {
+
+	// set external IP, if given
+	if i.externalIPAddresses != "" {
+		if err := i.rootCommandeer.platform.SetExternalIPAddresses(strings.Split(i.externalIPAddresses, ",")); err != nil {
+			return errors.Wrap(err, "Failed to set external IP address")
+		}
+	}
+
+	// enrich request with function
+	if err := i.createFunctionInvocationOptions.EnrichFunction(ctx, i.rootCommandeer.platform); err != nil {
+		return errors.Wrap(err, "Failed to enrich function invocation options")
+	}
+
+	// Implementation detail: first url is the intra-cluster url, following urls are external urls
+	invocationURLs := i.createFunctionInvocationOptions.FunctionInstance.GetStatus().InvocationURLs()
+	if len(invocationURLs) == 0 {
+		return errors.New("Function has no invocation URLs")
+	}
+
+	// convert via
+	switch i.invokeVia {
+	case "any":
+
+		// if running with platform, invoke internally
+		if common.RunningInContainer() || common.IsInKubernetesCluster() {
+			i.createFunctionInvocationOptions.URL = invocationURLs[0]
+			break
+		}
+
+		// default to external ip
+		if err := i.enrichOptionsForExternalIP(invocationURLs); err != nil {
+			return errors.Wrap(err, "Failed to invoke via external IP")
+		}
+
+	case "external-ip", "loadbalancer":
+
+		// unified behavior for BC.
+		if err := i.enrichOptionsForExternalIP(invocationURLs); err != nil {
+			return errors.Wrap(err, "Failed to invoke via external IP")
+		}
+	default:
+		return errors.Errorf(`Unknown invocation method %s. Must be one of "any", "external-ip", "loadbalancer"`,
+			i.invokeVia)
+	}
+
+	return nil
+}
+
 func (i *invokeCommandeer) enrichOptionsForExternalIP(invocationURLs []string) error {
 	i.createFunctionInvocationOptions.SkipURLValidation = true
 
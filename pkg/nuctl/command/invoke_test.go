@@ -0,0 +1,165 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/platform"
+	mockplatform "github.com/nuclio/nuclio/pkg/platform/mock"
+
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type invokeTestSuite struct {
+	suite.Suite
+	mockPlatform *mockplatform.Platform
+	commandeer   *invokeCommandeer
+}
+
+func (suite *invokeTestSuite) SetupTest() {
+	loggerInstance, err := nucliozap.NewNuclioZapTest("test")
+	suite.Require().NoError(err)
+
+	suite.mockPlatform = &mockplatform.Platform{}
+	suite.commandeer = newInvokeCommandeer(context.Background(), &RootCommandeer{
+		loggerInstance: loggerInstance,
+		platform:       suite.mockPlatform,
+		namespace:      "default-namespace",
+	})
+	suite.commandeer.createFunctionInvocationOptions.Name = "greeter"
+	suite.commandeer.createFunctionInvocationOptions.LogLevelName = "none"
+}
+
+func (suite *invokeTestSuite) TestCreateFunctionEventInvocationOptions() {
+	functionEventConfig := &platform.FunctionEventConfig{
+		Spec: platform.FunctionEventSpec{
+			TriggerKind: "http",
+			Body:        `{"name": "world"}`,
+			Attributes: map[string]interface{}{
+				"method": http.MethodPut,
+				"path":   "/greet",
+				"headers": map[string]interface{}{
+					"Content-Type": "application/json",
+					"X-Attempt":    1,
+				},
+			},
+		},
+	}
+
+	createFunctionInvocationOptions, err := suite.commandeer.createFunctionEventInvocationOptions(functionEventConfig)
+	suite.Require().NoError(err, "Create invocation options should succeed")
+	suite.Require().Equal(http.MethodPut, createFunctionInvocationOptions.Method)
+	suite.Require().Equal("greet", createFunctionInvocationOptions.Path)
+	suite.Require().Equal(`{"name": "world"}`, string(createFunctionInvocationOptions.Body))
+	suite.Require().Equal("application/json", createFunctionInvocationOptions.Headers.Get("Content-Type"))
+	suite.Require().Equal("1", createFunctionInvocationOptions.Headers.Get("X-Attempt"))
+
+	// flags override the function event
+	suite.Require().NoError(suite.commandeer.cmd.Flags().Set("method", http.MethodPost))
+	suite.Require().NoError(suite.commandeer.cmd.Flags().Set("path", "other"))
+	suite.commandeer.headers = "X-Attempt=2"
+	suite.commandeer.body = "plain"
+
+	createFunctionInvocationOptions, err = suite.commandeer.createFunctionEventInvocationOptions(functionEventConfig)
+	suite.Require().NoError(err, "Create invocation options with flags should succeed")
+	suite.Require().Equal(http.MethodPost, createFunctionInvocationOptions.Method)
+	suite.Require().Equal("other", createFunctionInvocationOptions.Path)
+	suite.Require().Equal("plain", string(createFunctionInvocationOptions.Body))
+	suite.Require().Equal("2", createFunctionInvocationOptions.Headers.Get("X-Attempt"))
+
+	// only http events can be sent
+	functionEventConfig.Spec.TriggerKind = "cron"
+	_, err = suite.commandeer.createFunctionEventInvocationOptions(functionEventConfig)
+	suite.Require().Error(err, "Create invocation options of a cron event should not succeed")
+}
+
+func (suite *invokeTestSuite) TestInvokeAllFunctionEvents() {
+	suite.commandeer.allFunctionEvents = true
+
+	var functionEvents []platform.FunctionEvent
+	for _, functionEventName := range []string{"unchecked", "expected", "unexpected"} {
+		functionEvent := &platform.AbstractFunctionEvent{}
+		functionEvent.FunctionEventConfig.Meta.Name = functionEventName
+		functionEvent.FunctionEventConfig.Spec.Body = functionEventName
+		functionEvent.FunctionEventConfig.Spec.Attributes = map[string]interface{}{}
+		functionEvents = append(functionEvents, functionEvent)
+	}
+
+	functionEvents[1].GetConfig().Spec.Attributes[functionEventExpectedResponseAttribute] = map[string]interface{}{
+		"status":       200,
+		"bodyContains": "expected",
+	}
+	functionEvents[2].GetConfig().Spec.Attributes[functionEventExpectedResponseAttribute] = map[string]interface{}{
+		"status": 201,
+	}
+
+	suite.mockPlatform.
+		On("GetFunctionEvents", mock.Anything, mock.MatchedBy(func(options *platform.GetFunctionEventsOptions) bool {
+			return options.Meta.Name == "" && options.FunctionNames[0] == "greeter"
+		})).
+		Return(functionEvents, nil).
+		Once()
+
+	// echoes the body
+	for _, functionEvent := range functionEvents {
+		body := []byte(functionEvent.GetConfig().Spec.Body)
+
+		suite.mockPlatform.
+			On("CreateFunctionInvocation", mock.Anything, mock.MatchedBy(
+				func(createFunctionInvocationOptions *platform.CreateFunctionInvocationOptions) bool {
+					return bytes.Equal(createFunctionInvocationOptions.Body, body)
+				})).
+			Return(&platform.CreateFunctionInvocationResult{
+				Headers:    http.Header{},
+				Body:       body,
+				StatusCode: http.StatusOK,
+			}, nil).
+			Once()
+	}
+
+	output := &bytes.Buffer{}
+	err := suite.commandeer.invokeFunctionEvents(context.Background(), output)
+	suite.Require().Error(err)
+	suite.Require().Contains(err.Error(), "1 of 3 function events failed: unexpected")
+	suite.Require().Contains(output.String(), "unchecked")
+	suite.mockPlatform.AssertExpectations(suite.T())
+}
+
+func (suite *invokeTestSuite) TestInvokeMissingFunctionEvent() {
+	suite.commandeer.functionEventName = "missing"
+
+	suite.mockPlatform.
+		On("GetFunctionEvents", mock.Anything, mock.Anything).
+		Return([]platform.FunctionEvent{}, nil).
+		Once()
+
+	err := suite.commandeer.invokeFunctionEvents(context.Background(), &bytes.Buffer{})
+	suite.Require().Error(err)
+	suite.Require().Contains(err.Error(), "Function event missing not found")
+}
+
+func TestInvokeTestSuite(t *testing.T) {
+	suite.Run(t, new(invokeTestSuite))
+}
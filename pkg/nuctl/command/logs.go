@@ -0,0 +1,272 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/errgroup"
+	"github.com/nuclio/nuclio/pkg/logprocessing"
+	"github.com/nuclio/nuclio/pkg/platform"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/nuclio-sdk-go"
+	"github.com/spf13/cobra"
+)
+
+// maxLogLineSize bounds the length of a single log line, as some user log lines (e.g. of large payloads) are long
+const maxLogLineSize = 1024 * 1024
+
+// logLevelSeverities orders log levels by the first letter of their name, as loggers name them differently
+// (e.g. "warn" and "warning")
+var logLevelSeverities = map[string]int{
+	"D": 0,
+	"I": 1,
+	"W": 2,
+	"E": 3,
+}
+
+type logsCommandeer struct {
+	cmd            *cobra.Command
+	rootCommandeer *RootCommandeer
+	replicaName    string
+	allReplicas    bool
+	follow         bool
+	since          time.Duration
+	level          string
+	grep           string
+
+	// resolved from the flags
+	minimumLevelSeverity int
+	grepRegexp           *regexp.Regexp
+}
+
+func newLogsCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *logsCommandeer {
+	commandeer := &logsCommandeer{
+		rootCommandeer: rootCommandeer,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "logs function-name",
+		Short: "Print the logs of a function's replicas",
+		Long: `Print the logs of a function's replicas (Kubernetes pods or Docker containers).
+
+Log lines of the processor and of the function's logger are rendered as
+"[time] (level) message [arguments]". Other lines are printed as is, and aren't
+filtered by --level. When printing the logs of several replicas, each line is
+prefixed with the name of its replica.
+
+By default, prints the logs of the function's first replica.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("Function logs require a function name")
+			}
+
+			if commandeer.replicaName != "" && commandeer.allReplicas {
+				return errors.New("Either a replica or all replicas may be selected, not both")
+			}
+
+			if err := commandeer.resolveFilters(); err != nil {
+				return errors.Wrap(err, "Failed to resolve log filters")
+			}
+
+			// initialize root
+			if err := rootCommandeer.initialize(); err != nil {
+				return errors.Wrap(err, "Failed to initialize root")
+			}
+
+			replicaNames, err := commandeer.resolveReplicaNames(ctx, args[0])
+			if err != nil {
+				return errors.Wrap(err, "Failed to resolve function replicas")
+			}
+
+			return commandeer.streamLogs(ctx, replicaNames, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&commandeer.replicaName, "replica", "", "Name of the replica (pod or container) to print the logs of")
+	cmd.Flags().BoolVar(&commandeer.allReplicas, "all-replicas", false, "Print the logs of all the function's replicas")
+	cmd.Flags().BoolVarP(&commandeer.follow, "follow", "f", false, "Keep streaming new log lines")
+	cmd.Flags().DurationVar(&commandeer.since, "since", 0, "Print only log lines newer than a relative duration (e.g. 10s, 5m, 1h)")
+	cmd.Flags().StringVar(&commandeer.level, "level", "", "Print only log lines of this level or above - \"debug\", \"info\", \"warn\", or \"error\"")
+	cmd.Flags().StringVar(&commandeer.grep, "grep", "", "Print only log lines matching this regular expression")
+
+	commandeer.cmd = cmd
+
+	return commandeer
+}
+
+func (l *logsCommandeer) resolveFilters() error {
+	if l.level != "" {
+		severity, found := logLevelSeverities[strings.ToUpper(l.level[:1])]
+		if !found {
+			return errors.Errorf("Unknown log level: %s", l.level)
+		}
+		l.minimumLevelSeverity = severity
+	}
+
+	if l.grep != "" {
+		grepRegexp, err := regexp.Compile(l.grep)
+		if err != nil {
+			return errors.Wrap(err, "Failed to compile grep expression")
+		}
+		l.grepRegexp = grepRegexp
+	}
+
+	return nil
+}
+
+func (l *logsCommandeer) resolveReplicaNames(ctx context.Context, functionName string) ([]string, error) {
+	functions, err := l.rootCommandeer.platform.GetFunctions(ctx, &platform.GetFunctionsOptions{
+		Name:      functionName,
+		Namespace: l.rootCommandeer.namespace,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get function")
+	}
+
+	if len(functions) == 0 {
+		return nil, nuclio.NewErrNotFound(fmt.Sprintf("Function %s not found", functionName))
+	}
+
+	replicaNames, err := l.rootCommandeer.platform.GetFunctionReplicaNames(ctx, functions[0].GetConfig())
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get function replica names")
+	}
+
+	if len(replicaNames) == 0 {
+		return nil, nuclio.NewErrNotFound(fmt.Sprintf("Function %s has no replicas", functionName))
+	}
+
+	sort.Strings(replicaNames)
+
+	switch {
+	case l.allReplicas:
+		return replicaNames, nil
+	case l.replicaName != "":
+		if !common.StringSliceContainsString(replicaNames, l.replicaName) {
+			return nil, nuclio.NewErrNotFound(fmt.Sprintf("Function %s has no replica named %s",
+				functionName,
+				l.replicaName))
+		}
+		return []string{l.replicaName}, nil
+	}
+
+	if len(replicaNames) > 1 {
+		l.rootCommandeer.loggerInstance.InfoWithCtx(ctx,
+			"Function has several replicas, printing the logs of the first (use --replica or --all-replicas to choose)",
+			"replica", replicaNames[0],
+			"replicas", replicaNames)
+	}
+
+	return replicaNames[:1], nil
+}
+
+// streamLogs writes the log lines of the replicas as they arrive, prefixing them with their replica's name if
+// there are several
+func (l *logsCommandeer) streamLogs(ctx context.Context, replicaNames []string, writer io.Writer) error {
+	var writerLock sync.Mutex
+	prefixLines := len(replicaNames) > 1
+
+	var sinceSeconds *int64
+	if l.since > 0 {
+		seconds := int64(l.since.Seconds())
+		sinceSeconds = &seconds
+	}
+
+	errGroup, errGroupCtx := errgroup.WithContext(ctx, l.rootCommandeer.loggerInstance)
+	for _, replicaName := range replicaNames {
+		replicaName := replicaName // https://golang.org/doc/faq#closures_and_goroutines
+		errGroup.Go("Stream replica logs", func() error {
+			stream, err := l.rootCommandeer.platform.GetFunctionReplicaLogsStream(errGroupCtx,
+				&platform.GetFunctionReplicaLogsStreamOptions{
+					Name:         replicaName,
+					Namespace:    l.rootCommandeer.namespace,
+					Follow:       l.follow,
+					SinceSeconds: sinceSeconds,
+				})
+			if err != nil {
+				return errors.Wrapf(err, "Failed to get logs stream of replica %s", replicaName)
+			}
+			defer stream.Close() // nolint: errcheck
+
+			scanner := bufio.NewScanner(stream)
+			scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLogLineSize)
+			for scanner.Scan() {
+				formattedLogLine, matches := l.formatLogLine(scanner.Bytes())
+				if !matches {
+					continue
+				}
+
+				if prefixLines {
+					formattedLogLine = fmt.Sprintf("[%s] %s", replicaName, formattedLogLine)
+				}
+
+				writerLock.Lock()
+				_, err := fmt.Fprintln(writer, formattedLogLine)
+				writerLock.Unlock()
+
+				if err != nil {
+					return errors.Wrap(err, "Failed to write log line")
+				}
+			}
+
+			if err := scanner.Err(); err != nil {
+				return errors.Wrapf(err, "Failed to read logs of replica %s", replicaName)
+			}
+
+			return nil
+		})
+	}
+
+	return errGroup.Wait()
+}
+
+// formatLogLine renders a log line, returning whether it passes the filters
+func (l *logsCommandeer) formatLogLine(logLine []byte) (string, bool) {
+	formattedLogLine := string(logLine)
+
+	// not all lines are structured (e.g. what the function prints to stdout), those are printed as is
+	if functionLogLine, err := logprocessing.CreateFunctionLogLine(logLine); err == nil &&
+		functionLogLine.Level != nil &&
+		*functionLogLine.Level != "" {
+
+		if logLevelSeverities[strings.ToUpper((*functionLogLine.Level)[:1])] < l.minimumLevelSeverity {
+			return "", false
+		}
+
+		if prettifiedLogLine, _, err := logprocessing.PrettifyFunctionLogLine(l.rootCommandeer.loggerInstance,
+			logLine); err == nil {
+			formattedLogLine = prettifiedLogLine
+		}
+	}
+
+	if l.grepRegexp != nil && !l.grepRegexp.MatchString(formattedLogLine) {
+		return "", false
+	}
+
+	return formattedLogLine, true
+}
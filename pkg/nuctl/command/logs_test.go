@@ -0,0 +1,169 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/platform"
+	mockplatform "github.com/nuclio/nuclio/pkg/platform/mock"
+
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	debugLogLine = `{"level":"debug","time":"2023-06-01T10:00:00.000Z","name":"processor","message":"Polling"}`
+	infoLogLine  = `{"level":"info","time":"2023-06-01T10:00:01.000Z","name":"processor","message":"Started"}`
+	errorLogLine = `{"level":"error","time":"2023-06-01T10:00:02.000Z","name":"processor","message":"Failed to handle event"}`
+)
+
+type logsTestSuite struct {
+	suite.Suite
+}
+
+func (suite *logsTestSuite) TestFormatLogLine() {
+	loggerInstance, err := nucliozap.NewNuclioZapTest("test")
+	suite.Require().NoError(err)
+
+	for _, testCase := range []struct {
+		name             string
+		level            string
+		grep             string
+		logLine          string
+		expectedLogLine  string
+		expectedFiltered bool
+	}{
+		{
+			name:            "structured",
+			logLine:         infoLogLine,
+			expectedLogLine: "[10:00:01.000] (I) Started",
+		},
+		{
+			name:            "unstructured",
+			logLine:         "printed by the handler",
+			expectedLogLine: "printed by the handler",
+		},
+		{
+			name:             "belowLevel",
+			level:            "warn",
+			logLine:          infoLogLine,
+			expectedFiltered: true,
+		},
+		{
+			name:            "aboveLevel",
+			level:           "warning",
+			logLine:         errorLogLine,
+			expectedLogLine: "[10:00:02.000] (E) Failed to handle event",
+		},
+		{
+			name:            "unstructuredWithLevel",
+			level:           "error",
+			logLine:         "printed by the handler",
+			expectedLogLine: "printed by the handler",
+		},
+		{
+			name:             "notMatchingGrep",
+			grep:             "Fail.*event",
+			logLine:          infoLogLine,
+			expectedFiltered: true,
+		},
+		{
+			name:            "matchingGrep",
+			grep:            "Fail.*event",
+			logLine:         errorLogLine,
+			expectedLogLine: "[10:00:02.000] (E) Failed to handle event",
+		},
+	} {
+		suite.Run(testCase.name, func() {
+			commandeer := &logsCommandeer{
+				rootCommandeer: &RootCommandeer{
+					loggerInstance: loggerInstance,
+				},
+				level: testCase.level,
+				grep:  testCase.grep,
+			}
+			err := commandeer.resolveFilters()
+			suite.Require().NoError(err, "Resolve filters should succeed")
+
+			formattedLogLine, matches := commandeer.formatLogLine([]byte(testCase.logLine))
+			suite.Require().Equal(!testCase.expectedFiltered, matches)
+			suite.Require().Equal(testCase.expectedLogLine, formattedLogLine)
+		})
+	}
+}
+
+func (suite *logsTestSuite) TestResolveFiltersInvalid() {
+	err := (&logsCommandeer{level: "verbose"}).resolveFilters()
+	suite.Require().Error(err, "Resolve unknown level should not succeed")
+
+	err = (&logsCommandeer{grep: "("}).resolveFilters()
+	suite.Require().Error(err, "Resolve invalid grep should not succeed")
+}
+
+func (suite *logsTestSuite) TestStreamLogsOfAllReplicas() {
+	loggerInstance, err := nucliozap.NewNuclioZapTest("test")
+	suite.Require().NoError(err)
+
+	mockPlatform := &mockplatform.Platform{}
+	commandeer := &logsCommandeer{
+		rootCommandeer: &RootCommandeer{
+			loggerInstance: loggerInstance,
+			platform:       mockPlatform,
+			namespace:      "default-namespace",
+		},
+		level: "info",
+	}
+	err = commandeer.resolveFilters()
+	suite.Require().NoError(err, "Resolve filters should succeed")
+
+	for replicaName, logLines := range map[string][]string{
+		"replica-a": {debugLogLine, infoLogLine},
+		"replica-b": {errorLogLine},
+	} {
+		mockPlatform.
+			On("GetFunctionReplicaLogsStream", mock.Anything, &platform.GetFunctionReplicaLogsStreamOptions{
+				Name:      replicaName,
+				Namespace: "default-namespace",
+			}).
+			Return(io.NopCloser(strings.NewReader(strings.Join(logLines, "\n")+"\n")), nil).
+			Once()
+	}
+
+	output := &bytes.Buffer{}
+	err = commandeer.streamLogs(context.Background(), []string{"replica-a", "replica-b"}, output)
+	suite.Require().NoError(err, "Stream logs should succeed")
+
+	// lines are prefixed with their replica, and the debug line is filtered out
+	suite.Require().ElementsMatch([]string{
+		"[replica-a] [10:00:01.000] (I) Started",
+		"[replica-b] [10:00:02.000] (E) Failed to handle event",
+	}, strings.Split(strings.TrimSpace(output.String()), "\n"))
+
+	mockPlatform.AssertExpectations(suite.T())
+}
+
+func TestLogsTestSuite(t *testing.T) {
+	suite.Run(t, new(logsTestSuite))
+}
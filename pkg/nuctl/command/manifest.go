@@ -0,0 +1,348 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/platform"
+
+	"github.com/ghodss/yaml"
+	"github.com/nuclio/errors"
+)
+
+const (
+	manifestKindProject       = "NuclioProject"
+	manifestKindFunction      = "NuclioFunction"
+	manifestKindFunctionEvent = "NuclioFunctionEvent"
+	manifestKindAPIGateway    = "NuclioAPIGateway"
+)
+
+var manifestDocumentSeparator = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+type functionManifest struct {
+	config *functionconfig.Config
+
+	// the manifest file the function was read from, empty for functions of a project export
+	path string
+}
+
+// manifests holds the resources declared by a set of manifest files
+type manifests struct {
+	projects       []*platform.ProjectConfig
+	functions      []*functionManifest
+	functionEvents []*platform.FunctionEventConfig
+	apiGateways    []*platform.APIGatewayConfig
+}
+
+// readManifests reads the resources declared in the given files or directories (recursively). Each file
+// may hold several YAML documents, each being either a single resource identified by its "kind" (functions
+// may omit it) or the output of "nuctl export project"
+func readManifests(paths []string) (*manifests, error) {
+	readManifests := &manifests{}
+
+	for _, path := range paths {
+		if err := readManifests.readPath(path); err != nil {
+			return nil, errors.Wrapf(err, "Failed to read manifests from %s", path)
+		}
+	}
+
+	if err := readManifests.validate(); err != nil {
+		return nil, errors.Wrap(err, "Failed to validate manifests")
+	}
+
+	return readManifests, nil
+}
+
+func (m *manifests) readPath(path string) error {
+	if !common.IsDir(path) {
+		return m.readFile(path)
+	}
+
+	return filepath.WalkDir(path, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		switch strings.ToLower(filepath.Ext(filePath)) {
+		case ".yaml", ".yml", ".json":
+			return m.readFile(filePath)
+		}
+
+		return nil
+	})
+}
+
+func (m *manifests) readFile(path string) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read manifest file")
+	}
+
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		return errors.Wrap(err, "Failed to resolve manifest file path")
+	}
+
+	for documentIndex, document := range manifestDocumentSeparator.Split(string(contents), -1) {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+
+		if err := m.addDocument([]byte(document), absolutePath); err != nil {
+			return errors.Wrapf(err, "Failed to parse document #%d of %s", documentIndex, path)
+		}
+	}
+
+	return nil
+}
+
+func (m *manifests) addDocument(document []byte, path string) error {
+
+	// json is valid yaml, so this handles both
+	encodedDocument, err := yaml.YAMLToJSON(document)
+	if err != nil {
+		return errors.Wrap(err, "Failed to decode document")
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(encodedDocument, &fields); err != nil {
+		return errors.Wrap(err, "Document is not an object")
+	}
+
+	kind, _ := fields["kind"].(string)
+	switch kind {
+	case manifestKindProject:
+		projectConfig := &platform.ProjectConfig{}
+		if err := json.Unmarshal(encodedDocument, projectConfig); err != nil {
+			return errors.Wrap(err, "Failed to decode project")
+		}
+		m.projects = append(m.projects, projectConfig)
+
+	case manifestKindFunctionEvent:
+		functionEventConfig := &platform.FunctionEventConfig{}
+		if err := json.Unmarshal(encodedDocument, functionEventConfig); err != nil {
+			return errors.Wrap(err, "Failed to decode function event")
+		}
+		m.functionEvents = append(m.functionEvents, functionEventConfig)
+
+	case manifestKindAPIGateway:
+		apiGatewayConfig := &platform.APIGatewayConfig{}
+		if err := json.Unmarshal(encodedDocument, apiGatewayConfig); err != nil {
+			return errors.Wrap(err, "Failed to decode API gateway")
+		}
+		m.apiGateways = append(m.apiGateways, apiGatewayConfig)
+
+	case manifestKindFunction:
+		return m.addFunction(encodedDocument, path)
+
+	case "":
+		if _, found := fields["project"]; found {
+			return m.addProjectExport(encodedDocument)
+		}
+
+		if _, found := fields["metadata"]; found {
+			return m.addFunction(encodedDocument, path)
+		}
+
+		if isMultiProjectExport(fields) {
+			projectExports := map[string]json.RawMessage{}
+			if err := json.Unmarshal(encodedDocument, &projectExports); err != nil {
+				return errors.Wrap(err, "Failed to decode project exports")
+			}
+
+			for _, projectExport := range projectExports {
+				if err := m.addProjectExport(projectExport); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		return errors.New("Failed to identify the manifest kind; set it explicitly with the \"kind\" field")
+
+	default:
+		return errors.Errorf("Unknown manifest kind: %s", kind)
+	}
+
+	return nil
+}
+
+func (m *manifests) addFunction(encodedDocument []byte, path string) error {
+	functionConfig := &functionconfig.Config{}
+	if err := json.Unmarshal(encodedDocument, functionConfig); err != nil {
+		return errors.Wrap(err, "Failed to decode function")
+	}
+
+	// like "nuctl deploy --path", a function that declares no source is built from its own directory, and
+	// relative source paths are relative to it
+	dir := filepath.Dir(path)
+	build := &functionConfig.Spec.Build
+	if build.Path == "" &&
+		build.FunctionSourceCode == "" &&
+		build.Image == "" &&
+		functionConfig.Spec.Image == "" {
+		build.Path = dir
+	} else if isLocalSourcePath(build.Path) && !filepath.IsAbs(build.Path) {
+		build.Path = filepath.Join(dir, build.Path)
+	}
+
+	m.functions = append(m.functions, &functionManifest{
+		config: functionConfig,
+		path:   path,
+	})
+
+	return nil
+}
+
+// addProjectExport adds a project along with all of its resources, as exported by "nuctl export project"
+func (m *manifests) addProjectExport(encodedDocument []byte) error {
+	projectImportConfig := &ProjectImportConfig{}
+	if err := json.Unmarshal(encodedDocument, projectImportConfig); err != nil {
+		return errors.Wrap(err, "Failed to decode project export")
+	}
+
+	if projectImportConfig.Project == nil {
+		return errors.New("Project export has no project")
+	}
+
+	projectName := projectImportConfig.Project.Meta.Name
+	m.projects = append(m.projects, projectImportConfig.Project)
+
+	for _, functionConfig := range projectImportConfig.Functions {
+		setProjectLabel(&functionConfig.Meta.Labels, projectName)
+		m.functions = append(m.functions, &functionManifest{config: functionConfig})
+	}
+
+	for _, functionEventConfig := range projectImportConfig.FunctionEvents {
+		setProjectLabel(&functionEventConfig.Meta.Labels, projectName)
+		m.functionEvents = append(m.functionEvents, functionEventConfig)
+	}
+
+	for _, apiGatewayConfig := range projectImportConfig.APIGateways {
+		setProjectLabel(&apiGatewayConfig.Meta.Labels, projectName)
+		m.apiGateways = append(m.apiGateways, apiGatewayConfig)
+	}
+
+	return nil
+}
+
+// validate verifies that every resource is named, and declared only once
+func (m *manifests) validate() error {
+	seen := map[string]bool{}
+	validateName := func(kind string, namespace string, name string) error {
+		if name == "" {
+			return errors.Errorf("%s has no name", kind)
+		}
+
+		key := fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+		if seen[key] {
+			return errors.Errorf("%s %s is declared more than once", kind, name)
+		}
+		seen[key] = true
+
+		return nil
+	}
+
+	for _, projectConfig := range m.projects {
+		if err := validateName(manifestKindProject,
+			projectConfig.Meta.Namespace,
+			projectConfig.Meta.Name); err != nil {
+			return err
+		}
+	}
+
+	for _, function := range m.functions {
+		if err := validateName(manifestKindFunction,
+			function.config.Meta.Namespace,
+			function.config.Meta.Name); err != nil {
+			return err
+		}
+	}
+
+	for _, functionEventConfig := range m.functionEvents {
+		if err := validateName(manifestKindFunctionEvent,
+			functionEventConfig.Meta.Namespace,
+			functionEventConfig.Meta.Name); err != nil {
+			return err
+		}
+
+		if functionEventConfig.Meta.Labels[common.NuclioResourceLabelKeyFunctionName] == "" {
+			return errors.Errorf("Function event %s has no %s label",
+				functionEventConfig.Meta.Name,
+				common.NuclioResourceLabelKeyFunctionName)
+		}
+	}
+
+	for _, apiGatewayConfig := range m.apiGateways {
+		if err := validateName(manifestKindAPIGateway,
+			apiGatewayConfig.Meta.Namespace,
+			apiGatewayConfig.Meta.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isMultiProjectExport returns whether the document is a map of project name to a project export
+func isMultiProjectExport(fields map[string]interface{}) bool {
+	if len(fields) == 0 {
+		return false
+	}
+
+	for _, value := range fields {
+		projectExport, isMap := value.(map[string]interface{})
+		if !isMap {
+			return false
+		}
+
+		if _, found := projectExport["project"]; !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isLocalSourcePath returns whether a function source path is on the local filesystem, rather than a URL
+func isLocalSourcePath(path string) bool {
+	return path != "" && !strings.Contains(path, "://")
+}
+
+func setProjectLabel(labels *map[string]string, projectName string) {
+	if projectName == "" {
+		return
+	}
+
+	if *labels == nil {
+		*labels = map[string]string{}
+	}
+	(*labels)[common.NuclioResourceLabelKeyProjectName] = projectName
+}
@@ -0,0 +1,195 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/common"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type manifestTestSuite struct {
+	suite.Suite
+	tempDir string
+}
+
+func (suite *manifestTestSuite) SetupTest() {
+	suite.tempDir = suite.T().TempDir()
+}
+
+func (suite *manifestTestSuite) TestReadMultiDocumentManifest() {
+	manifestPath := suite.writeManifest("resources.yaml", `
+kind: NuclioProject
+meta:
+  name: my-project
+---
+metadata:
+  name: my-function
+spec:
+  runtime: python:3.9
+  handler: main:handler
+  build:
+    path: src
+---
+kind: NuclioFunction
+metadata:
+  name: my-image-function
+spec:
+  image: my-registry/my-image:latest
+---
+kind: NuclioFunctionEvent
+meta:
+  name: my-event
+  labels:
+    nuclio.io/function-name: my-function
+---
+kind: NuclioAPIGateway
+metadata:
+  name: my-api-gateway
+`)
+
+	readManifests, err := readManifests([]string{manifestPath})
+	suite.Require().NoError(err, "Read manifests should succeed")
+
+	suite.Require().Len(readManifests.projects, 1)
+	suite.Require().Equal("my-project", readManifests.projects[0].Meta.Name)
+
+	suite.Require().Len(readManifests.functions, 2)
+	suite.Require().Equal("my-function", readManifests.functions[0].config.Meta.Name)
+	suite.Require().Equal(manifestPath, readManifests.functions[0].path)
+
+	// relative source paths are resolved against the manifest, and functions with an image have no source
+	suite.Require().Equal(filepath.Join(suite.tempDir, "src"), readManifests.functions[0].config.Spec.Build.Path)
+	suite.Require().Empty(readManifests.functions[1].config.Spec.Build.Path)
+
+	suite.Require().Len(readManifests.functionEvents, 1)
+	suite.Require().Equal("my-event", readManifests.functionEvents[0].Meta.Name)
+
+	suite.Require().Len(readManifests.apiGateways, 1)
+	suite.Require().Equal("my-api-gateway", readManifests.apiGateways[0].Meta.Name)
+}
+
+func (suite *manifestTestSuite) TestReadDirectory() {
+	suite.writeManifest("functions/my-function/function.yaml", `
+metadata:
+  name: my-function
+spec:
+  runtime: python:3.9
+  handler: main:handler
+`)
+	suite.writeManifest("functions/my-function/main.py", "def handler(context, event):\n    pass\n")
+	suite.writeManifest("project.json", `{"kind": "NuclioProject", "meta": {"name": "my-project"}}`)
+
+	readManifests, err := readManifests([]string{suite.tempDir})
+	suite.Require().NoError(err, "Read manifests directory should succeed")
+	suite.Require().Len(readManifests.projects, 1)
+	suite.Require().Len(readManifests.functions, 1)
+
+	// a function without source is built from its own directory
+	suite.Require().Equal(filepath.Join(suite.tempDir, "functions", "my-function"),
+		readManifests.functions[0].config.Spec.Build.Path)
+}
+
+func (suite *manifestTestSuite) TestReadProjectExport() {
+	manifestPath := suite.writeManifest("export.yaml", `
+project:
+  meta:
+    name: my-project
+functions:
+  my-function:
+    metadata:
+      name: my-function
+    spec:
+      image: my-registry/my-image:latest
+functionEvents:
+  my-event:
+    meta:
+      name: my-event
+      labels:
+        nuclio.io/function-name: my-function
+`)
+
+	readManifests, err := readManifests([]string{manifestPath})
+	suite.Require().NoError(err, "Read manifests should succeed")
+
+	suite.Require().Len(readManifests.projects, 1)
+	suite.Require().Len(readManifests.functions, 1)
+	suite.Require().Len(readManifests.functionEvents, 1)
+
+	// resources of the project are labeled with it
+	suite.Require().Equal("my-project",
+		readManifests.functions[0].config.Meta.Labels[common.NuclioResourceLabelKeyProjectName])
+	suite.Require().Equal("my-project",
+		readManifests.functionEvents[0].Meta.Labels[common.NuclioResourceLabelKeyProjectName])
+}
+
+func (suite *manifestTestSuite) TestReadInvalidManifests() {
+	for _, testCase := range []struct {
+		name     string
+		manifest string
+	}{
+		{
+			name:     "unknownKind",
+			manifest: "kind: NuclioSomething\nmetadata:\n  name: something\n",
+		},
+		{
+			name:     "unidentifiedKind",
+			manifest: "name: something\n",
+		},
+		{
+			name:     "duplicateFunction",
+			manifest: "metadata:\n  name: my-function\n---\nmetadata:\n  name: my-function\n",
+		},
+		{
+			name:     "unnamedProject",
+			manifest: "kind: NuclioProject\nmeta:\n  namespace: my-namespace\n",
+		},
+		{
+			name:     "functionEventWithoutFunction",
+			manifest: "kind: NuclioFunctionEvent\nmeta:\n  name: my-event\n",
+		},
+	} {
+		suite.Run(testCase.name, func() {
+			manifestPath := suite.writeManifest(testCase.name+".yaml", testCase.manifest)
+
+			_, err := readManifests([]string{manifestPath})
+			suite.Require().Error(err, "Read invalid manifest should not succeed")
+		})
+	}
+}
+
+func (suite *manifestTestSuite) writeManifest(relativePath string, contents string) string {
+	manifestPath := filepath.Join(suite.tempDir, relativePath)
+
+	err := os.MkdirAll(filepath.Dir(manifestPath), 0755)
+	suite.Require().NoError(err)
+
+	err = os.WriteFile(manifestPath, []byte(contents), 0644)
+	suite.Require().NoError(err)
+
+	return manifestPath
+}
+
+func TestManifestTestSuite(t *testing.T) {
+	suite.Run(t, new(manifestTestSuite))
+}
@@ -21,6 +21,8 @@ import (
 	"os"
 
 	"github.com/nuclio/nuclio/pkg/common"
+	nucliocontext "github.com/nuclio/nuclio/pkg/context"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
 	"github.com/nuclio/nuclio/pkg/platform"
 	"github.com/nuclio/nuclio/pkg/platform/factory"
 	"github.com/nuclio/nuclio/pkg/platformconfig"
@@ -80,6 +82,16 @@ func NewRootCommandeer() *RootCommandeer {
 		newCreateCommandeer(ctx, commandeer).cmd,
 		newExportCommandeer(ctx, commandeer).cmd,
 		newImportCommandeer(ctx, commandeer).cmd,
+		newRollbackCommandeer(ctx, commandeer).cmd,
+		newApplyCommandeer(ctx, commandeer).cmd,
+		newDiffCommandeer(ctx, commandeer).cmd,
+		newLogsCommandeer(ctx, commandeer).cmd,
+		newRunCommandeer(ctx, commandeer).cmd,
+		newFunctionTestCommandeer(ctx, commandeer).cmd,
+		newBenchCommandeer(ctx, commandeer).cmd,
+		newInitCommandeer(ctx, commandeer).cmd,
+		newTopCommandeer(ctx, commandeer).cmd,
+		newRedeployCommandeer(ctx, commandeer).cmd,
 	)
 
 	commandeer.cmd = cmd
@@ -145,6 +157,27 @@ func (rc *RootCommandeer) initialize() error {
 	return nil
 }
 
+// deployFunction deploys a function and saves its deployment logs, even if the deployment failed
+func (rc *RootCommandeer) deployFunction(ctx context.Context, functionConfig functionconfig.Config) error {
+	_, deployErr := rc.platform.CreateFunction(nucliocontext.NewDetached(ctx),
+		&platform.CreateFunctionOptions{
+			Logger:         rc.loggerInstance,
+			FunctionConfig: functionConfig,
+		})
+
+	// don't check deploy error yet, first try to save the logs either way, and then return the error if necessary
+	logSaveErr := rc.platform.SaveFunctionDeployLogs(ctx,
+		functionConfig.Meta.Name,
+		functionConfig.Meta.Namespace)
+
+	if deployErr != nil {
+
+		// preserve the error and let the root commandeer handle unwrapping it
+		return deployErr
+	}
+	return logSaveErr
+}
+
 func (rc *RootCommandeer) createLogger() (logger.Logger, error) {
 	var loggerLevel nucliozap.Level
 
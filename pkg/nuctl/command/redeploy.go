@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/platform"
+
+	"github.com/nuclio/errors"
+	"github.com/spf13/cobra"
+)
+
+type redeployCommandeer struct {
+	cmd            *cobra.Command
+	rootCommandeer *RootCommandeer
+}
+
+func newRedeployCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *redeployCommandeer {
+	commandeer := &redeployCommandeer{
+		rootCommandeer: rootCommandeer,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "redeploy",
+		Short: "Redeploy resources",
+	}
+
+	cmd.AddCommand(
+		newRedeployFunctionCommandeer(ctx, commandeer).cmd,
+	)
+
+	commandeer.cmd = cmd
+
+	return commandeer
+}
+
+type redeployFunctionCommandeer struct {
+	*redeployCommandeer
+	bulkFunctionCommandeer
+	rebuild bool
+}
+
+func newRedeployFunctionCommandeer(ctx context.Context, redeployCommandeer *redeployCommandeer) *redeployFunctionCommandeer {
+	commandeer := &redeployFunctionCommandeer{
+		redeployCommandeer: redeployCommandeer,
+		bulkFunctionCommandeer: bulkFunctionCommandeer{
+			rootCommandeer: redeployCommandeer.rootCommandeer,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "functions [name]",
+		Aliases: []string{"fu", "fn", "function"},
+		Short:   "(or function) Redeploy functions with their current configuration",
+		Long: `Redeploy functions with their current configuration, e.g. to pick up changes
+to the platform configuration or to recreate their resources.
+
+Functions are selected by name, or by project (--project) and labels (--selector).
+By default, each function's current image is redeployed. With --rebuild, the
+functions are built again from their source first.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var functionName string
+			switch len(args) {
+			case 0:
+			case 1:
+				functionName = args[0]
+			default:
+				return errors.New("Function redeploy accepts at most one function name")
+			}
+
+			// initialize root
+			if err := redeployCommandeer.rootCommandeer.initialize(); err != nil {
+				return errors.Wrap(err, "Failed to initialize root")
+			}
+
+			functions, err := commandeer.getFunctions(ctx, functionName)
+			if err != nil {
+				return errors.Wrap(err, "Failed to get functions")
+			}
+
+			results := commandeer.run(ctx, functions, commandeer.redeployFunction)
+
+			return renderBulkFunctionResults(results, cmd.OutOrStdout())
+		},
+	}
+
+	commandeer.addFlags(cmd)
+	cmd.Flags().BoolVar(&commandeer.rebuild, "rebuild", false, "Build the functions again before deploying them")
+
+	commandeer.cmd = cmd
+
+	return commandeer
+}
+
+func (r *redeployFunctionCommandeer) redeployFunction(ctx context.Context,
+	function platform.Function) *bulkFunctionResult {
+	functionConfig, skippedResult := r.getDeployableFunctionConfig(function)
+	if skippedResult != nil {
+		return skippedResult
+	}
+
+	if r.dryRun {
+		return &bulkFunctionResult{kind: bulkFunctionResultWouldDeploy}
+	}
+
+	if !r.rebuild {
+		if err := r.deployExistingImage(ctx, function, functionConfig); err != nil {
+			return &bulkFunctionResult{err: err}
+		}
+
+		return &bulkFunctionResult{kind: bulkFunctionResultRedeployed}
+	}
+
+	functionConfig.Spec.Image = ""
+	functionConfig.Spec.Build.Mode = functionconfig.AlwaysBuild
+
+	if err := r.redeployCommandeer.rootCommandeer.deployFunction(ctx, *functionConfig); err != nil {
+		return &bulkFunctionResult{err: err}
+	}
+
+	return &bulkFunctionResult{kind: bulkFunctionResultRedeployed, details: "rebuilt"}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/platform"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/nuclio-sdk-go"
+	"github.com/spf13/cobra"
+)
+
+type rollbackCommandeer struct {
+	cmd            *cobra.Command
+	rootCommandeer *RootCommandeer
+}
+
+func newRollbackCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *rollbackCommandeer {
+	commandeer := &rollbackCommandeer{
+		rootCommandeer: rootCommandeer,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Roll resources back to a previous revision",
+	}
+
+	rollbackFunctionCommand := newRollbackFunctionCommandeer(ctx, commandeer).cmd
+
+	cmd.AddCommand(
+		rollbackFunctionCommand,
+	)
+
+	commandeer.cmd = cmd
+
+	return commandeer
+}
+
+type rollbackFunctionCommandeer struct {
+	*rollbackCommandeer
+	toRevision int
+	force      bool
+}
+
+func newRollbackFunctionCommandeer(ctx context.Context, rollbackCommandeer *rollbackCommandeer) *rollbackFunctionCommandeer {
+	commandeer := &rollbackFunctionCommandeer{
+		rollbackCommandeer: rollbackCommandeer,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "functions name",
+		Aliases: []string{"fu", "fn", "function"},
+		Short:   "(or function) Redeploy a function with the configuration and image of a previous revision",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("Function rollback requires a function name")
+			}
+
+			// initialize root
+			if err := rollbackCommandeer.rootCommandeer.initialize(); err != nil {
+				return errors.Wrap(err, "Failed to initialize root")
+			}
+
+			functionRevision, err := commandeer.getTargetRevision(ctx, args[0])
+			if err != nil {
+				return errors.Wrap(err, "Failed to get function revision")
+			}
+
+			functionConfig, err := commandeer.prepareFunctionConfigForRollback(functionRevision)
+			if err != nil {
+				return errors.Wrap(err, "Failed to prepare function config for rollback")
+			}
+
+			rollbackCommandeer.rootCommandeer.loggerInstance.InfoWithCtx(ctx,
+				"Rolling function back",
+				"name", functionConfig.Meta.Name,
+				"revision", functionRevision.Revision,
+				"image", functionConfig.Spec.Image)
+
+			_, deployErr := rollbackCommandeer.rootCommandeer.platform.CreateFunction(ctx,
+				&platform.CreateFunctionOptions{
+					Logger:         rollbackCommandeer.rootCommandeer.loggerInstance,
+					FunctionConfig: functionConfig,
+				})
+
+			// don't check deploy error yet, first try to save the logs either way, and then return the error if necessary
+			logSaveErr := rollbackCommandeer.rootCommandeer.platform.SaveFunctionDeployLogs(ctx,
+				functionConfig.Meta.Name,
+				rollbackCommandeer.rootCommandeer.namespace)
+
+			if deployErr != nil {
+
+				// preserve the error and let the root commandeer handle unwrapping it
+				return deployErr
+			}
+			return logSaveErr
+		},
+	}
+
+	cmd.Flags().IntVarP(&commandeer.toRevision, "to-revision", "", 0, "The revision to roll back to (defaults to the one before the latest)")
+	cmd.Flags().BoolVar(&commandeer.force, "force", false, "Roll back to a revision with no recorded image digest or with changed sensitive values, deploying its image tag and sensitive values as they are now")
+
+	commandeer.cmd = cmd
+
+	return commandeer
+}
+
+func (r *rollbackFunctionCommandeer) getTargetRevision(ctx context.Context,
+	functionName string) (*platform.FunctionRevision, error) {
+
+	functionRevisions, err := r.rootCommandeer.platform.GetFunctionRevisions(ctx,
+		&platform.GetFunctionRevisionsOptions{
+			Name:      functionName,
+			Namespace: r.rootCommandeer.namespace,
+			Revision:  r.toRevision,
+		})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get function revisions")
+	}
+
+	// a specific revision was requested
+	if r.toRevision != 0 {
+		return functionRevisions[0], nil
+	}
+
+	// revisions are sorted newest first
+	if len(functionRevisions) < 2 {
+		return nil, nuclio.NewErrPreconditionFailed(
+			fmt.Sprintf("Function %s has no previous revision to roll back to", functionName))
+	}
+
+	return functionRevisions[1], nil
+}
+
+// prepareFunctionConfigForRollback deploys the revision's image as is, without building it again. image tags
+// are mutable, so the image is pinned to the digest recorded with the revision
+func (r *rollbackFunctionCommandeer) prepareFunctionConfigForRollback(
+	functionRevision *platform.FunctionRevision) (functionconfig.Config, error) {
+
+	functionConfig := functionRevision.Config
+	functionConfig.Meta.Namespace = r.rootCommandeer.namespace
+	functionConfig.Meta.ResourceVersion = ""
+	functionConfig.Spec.Build.Mode = functionconfig.NeverBuild
+
+	// the revision only references its sensitive values, which are restored from the function secret
+	if functionRevision.SensitiveValuesChanged && !r.force {
+		return functionconfig.Config{}, nuclio.NewErrPreconditionFailed(
+			fmt.Sprintf("The sensitive values of revision %d have changed since it was deployed, and the current "+
+				"ones would be deployed with it. Use --force to deploy it anyway",
+				functionRevision.Revision))
+	}
+
+	switch {
+	case functionRevision.ImageDigest != "":
+		functionConfig.Spec.Image = common.PinImageDigest(functionRevision.Image, functionRevision.ImageDigest)
+	case r.force:
+		functionConfig.Spec.Image = functionRevision.Image
+	default:
+		return functionconfig.Config{}, nuclio.NewErrPreconditionFailed(
+			fmt.Sprintf("Revision %d has no recorded image digest, and its image tag %s may have been overwritten "+
+				"since. Use --force to deploy it anyway",
+				functionRevision.Revision,
+				functionRevision.Image))
+	}
+
+	return functionConfig, nil
+}
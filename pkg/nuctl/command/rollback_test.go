@@ -0,0 +1,99 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/platform"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type rollbackTestSuite struct {
+	suite.Suite
+}
+
+func (suite *rollbackTestSuite) TestPrepareFunctionConfigForRollback() {
+	commandeer := &rollbackFunctionCommandeer{
+		rollbackCommandeer: &rollbackCommandeer{
+			rootCommandeer: &RootCommandeer{
+				namespace: "nuclio",
+			},
+		},
+	}
+
+	functionRevision := &platform.FunctionRevision{
+		Revision:    3,
+		Image:       "registry:5000/my-function:latest",
+		ImageDigest: "sha256:1234",
+	}
+	functionRevision.Config.Meta.Name = "my-function"
+	functionRevision.Config.Meta.ResourceVersion = "1234"
+
+	// the image is pinned to the recorded digest, rather than to the tag it was deployed with
+	functionConfig, err := commandeer.prepareFunctionConfigForRollback(functionRevision)
+	suite.Require().NoError(err, "Rollback to a revision with a digest should succeed")
+	suite.Require().Equal("registry:5000/my-function@sha256:1234", functionConfig.Spec.Image)
+	suite.Require().Equal(functionconfig.NeverBuild, functionConfig.Spec.Build.Mode)
+	suite.Require().Equal("nuclio", functionConfig.Meta.Namespace)
+	suite.Require().Empty(functionConfig.Meta.ResourceVersion)
+
+	// without a digest, the tag may point at another image by now
+	functionRevision.ImageDigest = ""
+	_, err = commandeer.prepareFunctionConfigForRollback(functionRevision)
+	suite.Require().Error(err, "Rollback to a revision without a digest should not succeed")
+
+	commandeer.force = true
+	functionConfig, err = commandeer.prepareFunctionConfigForRollback(functionRevision)
+	suite.Require().NoError(err, "Forced rollback should succeed")
+	suite.Require().Equal("registry:5000/my-function:latest", functionConfig.Spec.Image)
+}
+
+func (suite *rollbackTestSuite) TestPrepareFunctionConfigForRollbackSensitiveValuesChanged() {
+	commandeer := &rollbackFunctionCommandeer{
+		rollbackCommandeer: &rollbackCommandeer{
+			rootCommandeer: &RootCommandeer{
+				namespace: "nuclio",
+			},
+		},
+	}
+
+	functionRevision := &platform.FunctionRevision{
+		Revision:               3,
+		Image:                  "registry:5000/my-function:latest",
+		ImageDigest:            "sha256:1234",
+		SensitiveValuesChanged: true,
+	}
+	functionRevision.Config.Meta.Name = "my-function"
+
+	// the current sensitive values would be deployed instead of the revision's
+	_, err := commandeer.prepareFunctionConfigForRollback(functionRevision)
+	suite.Require().Error(err, "Rollback to a revision with changed sensitive values should not succeed")
+
+	commandeer.force = true
+	functionConfig, err := commandeer.prepareFunctionConfigForRollback(functionRevision)
+	suite.Require().NoError(err, "Forced rollback should succeed")
+	suite.Require().Equal("registry:5000/my-function@sha256:1234", functionConfig.Spec.Image)
+}
+
+func TestRollbackTestSuite(t *testing.T) {
+	suite.Run(t, new(rollbackTestSuite))
+}
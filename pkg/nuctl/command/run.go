@@ -0,0 +1,489 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/platformconfig"
+	"github.com/nuclio/nuclio/pkg/processor"
+	processorconfig "github.com/nuclio/nuclio/pkg/processor/config"
+
+	"github.com/ghodss/yaml"
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+)
+
+const (
+	runSourcePollInterval   = time.Second
+	runProcessorStopTimeout = 5 * time.Second
+)
+
+type runCommandeer struct {
+	cmd                *cobra.Command
+	rootCommandeer     *RootCommandeer
+	loggerInstance     logger.Logger
+	local              bool
+	path               string
+	functionConfigPath string
+	runtime            string
+	handler            string
+	encodedEnv         stringSliceFlag
+	port               int
+	webAdminPort       int
+	processorPath      string
+	wrapperPath        string
+	noWatch            bool
+}
+
+func newRunCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *runCommandeer {
+	commandeer := &runCommandeer{
+		rootCommandeer: rootCommandeer,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "run [name] --local",
+		Short: "Run a function from a local source directory, without Docker or Kubernetes",
+		Long: `Run a function from a local source directory, without Docker or Kubernetes.
+
+Starts the processor binary on the host, with the function's handler loaded from the source
+directory by the runtime's wrapper on the host (Python and Node.js), or run directly (shell).
+The function's triggers are active, and its HTTP trigger listens on --port. Whenever a file in
+the source directory changes, the processor is restarted with the new code.
+
+The processor binary is looked up in --processor-path, NUCTL_PROCESSOR_PATH, or the PATH
+(build it with 'go build -o processor ./cmd/processor'). Runtimes that compile the function
+into an image (Go, Java, .NET Core, Ruby) can't run locally.
+
+Arguments:
+  <name> (string) The name of the function (default - the name in function.yaml, or the source directory name)`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !commandeer.local {
+				return errors.New("Only local runs are supported, pass --local")
+			}
+
+			var err error
+
+			// running locally requires no platform, only a logger
+			commandeer.loggerInstance, err = rootCommandeer.createLogger()
+			if err != nil {
+				return errors.Wrap(err, "Failed to create logger")
+			}
+
+			var functionName string
+			if len(args) > 0 {
+				functionName = args[0]
+			}
+
+			functionConfig, err := commandeer.resolveFunctionConfig(functionName)
+			if err != nil {
+				return errors.Wrap(err, "Failed to resolve function configuration")
+			}
+
+			runtimeEnv, err := commandeer.resolveRuntimeEnv(functionConfig.Spec.Runtime)
+			if err != nil {
+				return errors.Wrap(err, "Failed to resolve runtime environment")
+			}
+
+			processorPath, err := commandeer.resolveProcessorPath()
+			if err != nil {
+				return errors.Wrap(err, "Failed to find processor binary")
+			}
+
+			runDir, err := os.MkdirTemp("", "nuctl-run-")
+			if err != nil {
+				return errors.Wrap(err, "Failed to create run directory")
+			}
+			defer os.RemoveAll(runDir) // nolint: errcheck
+
+			processorArgs, err := commandeer.writeProcessorConfigs(runDir, functionConfig)
+			if err != nil {
+				return errors.Wrap(err, "Failed to write processor configuration")
+			}
+
+			// processor secrets are mounted on platforms, keep the host's out of reach
+			runtimeEnv = append(runtimeEnv, fmt.Sprintf("NUCLIO_FUNCTION_SECRET_VOLUME_PATH=%s", runDir))
+
+			runCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return commandeer.runProcessor(runCtx, processorPath, processorArgs, runtimeEnv)
+		},
+	}
+
+	cmd.Flags().BoolVar(&commandeer.local, "local", false, "Run the function on the host (required; currently the only mode)")
+	cmd.Flags().StringVarP(&commandeer.path, "path", "p", ".", "Path to the function's source directory")
+	cmd.Flags().StringVarP(&commandeer.functionConfigPath, "file", "f", "", "Path to a function-configuration file (default - function.yaml in the source directory, if any)")
+	cmd.Flags().StringVarP(&commandeer.runtime, "runtime", "", "", "Runtime (e.g. python:3.9, nodejs, shell)")
+	cmd.Flags().StringVarP(&commandeer.handler, "handler", "", "", "Name of the function handler")
+	cmd.Flags().VarP(&commandeer.encodedEnv, "env", "e", "Environment variables env1=val1")
+	cmd.Flags().IntVar(&commandeer.port, "port", 8080, "Port on which the HTTP trigger listens")
+	cmd.Flags().IntVar(&commandeer.webAdminPort, "webadmin-port", 0, "Port on which the processor's web admin (e.g. trigger statistics) listens (default - disabled)")
+	cmd.Flags().StringVar(&commandeer.processorPath, "processor-path", "", "Path to the processor binary (env: NUCTL_PROCESSOR_PATH)")
+	cmd.Flags().StringVar(&commandeer.wrapperPath, "wrapper-path", "", "Path to the runtime's wrapper on the host (e.g. _nuclio_wrapper.py for Python, wrapper.js for Node.js)")
+	cmd.Flags().BoolVar(&commandeer.noWatch, "no-watch", false, "Don't restart the processor when source files change")
+
+	commandeer.cmd = cmd
+
+	return commandeer
+}
+
+// resolveFunctionConfig reads the function configuration, if any, and overrides it with the flags
+func (r *runCommandeer) resolveFunctionConfig(functionName string) (*functionconfig.Config, error) {
+	functionConfig := functionconfig.NewConfig()
+
+	functionConfigPath := r.functionConfigPath
+	if functionConfigPath == "" {
+		if defaultFunctionConfigPath := filepath.Join(r.path, "function.yaml"); common.FileExists(defaultFunctionConfigPath) {
+			functionConfigPath = defaultFunctionConfigPath
+		}
+	}
+
+	if functionConfigPath != "" {
+		functionConfigFile, err := os.Open(functionConfigPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to open function configuration file")
+		}
+		defer functionConfigFile.Close() // nolint: errcheck
+
+		functionConfigReader, err := functionconfig.NewReader(r.loggerInstance)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to create function configuration reader")
+		}
+
+		if err := functionConfigReader.Read(functionConfigFile, "yaml", functionConfig); err != nil {
+			return nil, errors.Wrap(err, "Failed to read function configuration file")
+		}
+	}
+
+	if functionName != "" {
+		functionConfig.Meta.Name = functionName
+	}
+	if functionConfig.Meta.Name == "" {
+		absolutePath, err := filepath.Abs(r.path)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to resolve source directory")
+		}
+		functionConfig.Meta.Name = filepath.Base(absolutePath)
+	}
+
+	if r.runtime != "" {
+		functionConfig.Spec.Runtime = r.runtime
+	}
+	if r.handler != "" {
+		functionConfig.Spec.Handler = r.handler
+	}
+	if functionConfig.Spec.Runtime == "" || functionConfig.Spec.Handler == "" {
+		return nil, errors.New("Runtime and handler must be given, either as flags or in function.yaml")
+	}
+
+	for _, encodedEnvNameAndValue := range r.encodedEnv {
+		envNameAndValue := strings.SplitN(encodedEnvNameAndValue, "=", 2)
+		if len(envNameAndValue) != 2 {
+			return nil, errors.Errorf("Environment variable must be in the form of name=value: %s",
+				encodedEnvNameAndValue)
+		}
+
+		functionConfig.Spec.Env = append(functionConfig.Spec.Env, v1.EnvVar{
+			Name:  envNameAndValue[0],
+			Value: envNameAndValue[1],
+		})
+	}
+
+	r.enrichHTTPTrigger(functionConfig)
+
+	return functionConfig, nil
+}
+
+// enrichHTTPTrigger makes sure the function has an HTTP trigger, listening on the requested port
+func (r *runCommandeer) enrichHTTPTrigger(functionConfig *functionconfig.Config) {
+	if functionConfig.Spec.Triggers == nil {
+		functionConfig.Spec.Triggers = map[string]functionconfig.Trigger{}
+	}
+
+	httpTriggers := functionconfig.GetTriggersByKind(functionConfig.Spec.Triggers, "http")
+	if len(httpTriggers) == 0 {
+		defaultHTTPTrigger := functionconfig.GetDefaultHTTPTrigger()
+		functionConfig.Spec.Triggers[defaultHTTPTrigger.Name] = defaultHTTPTrigger
+		httpTriggers = functionconfig.GetTriggersByKind(functionConfig.Spec.Triggers, "http")
+	}
+
+	for triggerName, httpTrigger := range httpTriggers {
+		httpTrigger.URL = fmt.Sprintf(":%d", r.port)
+		functionConfig.Spec.Triggers[triggerName] = httpTrigger
+	}
+}
+
+// resolveRuntimeEnv returns the environment with which the runtime finds the handler and its wrapper on the host
+func (r *runCommandeer) resolveRuntimeEnv(functionRuntime string) ([]string, error) {
+	sourceDir, err := filepath.Abs(r.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to resolve source directory")
+	}
+
+	var env []string
+	var wrapperPathEnvName, defaultWrapperPath string
+
+	switch runtimeName, _ := common.GetRuntimeNameAndVersion(functionRuntime); runtimeName {
+	case "python":
+		env = append(env, fmt.Sprintf("NUCLIO_PYTHON_PATH=%s", sourceDir))
+		wrapperPathEnvName = "NUCLIO_PYTHON_WRAPPER_PATH"
+		defaultWrapperPath = "/opt/nuclio/_nuclio_wrapper.py"
+	case "nodejs":
+		env = append(env, fmt.Sprintf("NUCLIO_HANDLER_DIR=%s", sourceDir))
+		wrapperPathEnvName = "NUCLIO_NODEJS_WRAPPER_PATH"
+		defaultWrapperPath = "/opt/nuclio/wrapper.js"
+	case "shell":
+		return append(env, fmt.Sprintf("NUCLIO_SHELL_HANDLER_DIR=%s", sourceDir)), nil
+	default:
+		return nil, errors.Errorf("Runtime %s can't run locally (supported: python, nodejs, shell)", functionRuntime)
+	}
+
+	wrapperPath := r.wrapperPath
+	if wrapperPath == "" {
+		wrapperPath = common.GetEnvOrDefaultString(wrapperPathEnvName, defaultWrapperPath)
+	}
+
+	if !common.IsFile(wrapperPath) {
+		return nil, errors.Errorf("Runtime wrapper not found at %s, pass its path with --wrapper-path", wrapperPath)
+	}
+
+	return append(env, fmt.Sprintf("%s=%s", wrapperPathEnvName, wrapperPath)), nil
+}
+
+func (r *runCommandeer) resolveProcessorPath() (string, error) {
+	processorPath := r.processorPath
+	if processorPath == "" {
+		processorPath = common.GetEnvOrDefaultString("NUCTL_PROCESSOR_PATH", "processor")
+	}
+
+	return exec.LookPath(processorPath)
+}
+
+// writeProcessorConfigs writes the processor and platform configurations, returning the processor's arguments
+func (r *runCommandeer) writeProcessorConfigs(runDir string, functionConfig *functionconfig.Config) ([]string, error) {
+	processorConfigPath := filepath.Join(runDir, "processor.yaml")
+	processorConfigFile, err := os.Create(processorConfigPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create processor configuration file")
+	}
+	defer processorConfigFile.Close() // nolint: errcheck
+
+	processorConfigWriter, err := processorconfig.NewWriter()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create processor configuration writer")
+	}
+
+	if err := processorConfigWriter.Write(processorConfigFile, &processor.Configuration{
+		Config: *functionConfig,
+	}); err != nil {
+		return nil, errors.Wrap(err, "Failed to write processor configuration")
+	}
+
+	// the processor's health check server isn't needed, and neither is its web admin unless asked for - both would
+	// take more ports on the host
+	falseValue := false
+	platformConfig := platformconfig.Config{
+		WebAdmin: platformconfig.WebServer{
+			Enabled: &falseValue,
+		},
+		HealthCheck: platformconfig.WebServer{
+			Enabled: &falseValue,
+		},
+	}
+
+	if r.webAdminPort != 0 {
+		trueValue := true
+		platformConfig.WebAdmin = platformconfig.WebServer{
+			Enabled:       &trueValue,
+			ListenAddress: fmt.Sprintf(":%d", r.webAdminPort),
+		}
+	}
+
+	encodedPlatformConfig, err := yaml.Marshal(&platformConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to encode platform configuration")
+	}
+
+	platformConfigPath := filepath.Join(runDir, "platform.yaml")
+	if err := os.WriteFile(platformConfigPath, encodedPlatformConfig, 0600); err != nil {
+		return nil, errors.Wrap(err, "Failed to write platform configuration")
+	}
+
+	return []string{
+		"--config", processorConfigPath,
+		"--platform-config", platformConfigPath,
+	}, nil
+}
+
+// runProcessor runs the processor until interrupted, restarting it whenever the source changes
+func (r *runCommandeer) runProcessor(ctx context.Context,
+	processorPath string,
+	processorArgs []string,
+	runtimeEnv []string) error {
+
+	var sourceChanged <-chan struct{}
+	if !r.noWatch {
+		sourceChanged = r.watchSource(ctx)
+	}
+
+	for {
+		processorCmd := exec.Command(processorPath, processorArgs...)
+		processorCmd.Env = append(os.Environ(), runtimeEnv...)
+		processorCmd.Stdout = r.cmd.OutOrStdout()
+		processorCmd.Stderr = r.cmd.ErrOrStderr()
+
+		r.loggerInstance.InfoWithCtx(ctx, "Starting processor", "port", r.port, "path", r.path)
+		if err := processorCmd.Start(); err != nil {
+			return errors.Wrap(err, "Failed to start processor")
+		}
+
+		processorExited := make(chan error, 1)
+		go func() {
+			processorExited <- processorCmd.Wait()
+		}()
+
+		select {
+		case <-ctx.Done():
+			r.stopProcessor(ctx, processorCmd, processorExited)
+			return nil
+
+		case <-sourceChanged:
+			r.loggerInstance.InfoWithCtx(ctx, "Source changed, restarting processor")
+			r.stopProcessor(ctx, processorCmd, processorExited)
+
+		case err := <-processorExited:
+			if r.noWatch {
+				return errors.Wrap(err, "Processor exited")
+			}
+
+			// most likely the handler failed to load - wait for a fix
+			r.loggerInstance.WarnWithCtx(ctx, "Processor exited, restarting when the source changes", "err", err)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-sourceChanged:
+			}
+		}
+	}
+}
+
+func (r *runCommandeer) stopProcessor(ctx context.Context, processorCmd *exec.Cmd, processorExited <-chan error) {
+
+	// signals other than kill aren't supported on all systems
+	if err := processorCmd.Process.Signal(os.Interrupt); err != nil {
+		processorCmd.Process.Kill() // nolint: errcheck
+	}
+
+	select {
+	case <-processorExited:
+	case <-time.After(runProcessorStopTimeout):
+		r.loggerInstance.WarnWithCtx(ctx, "Processor didn't stop in time, killing it")
+		processorCmd.Process.Kill() // nolint: errcheck
+		<-processorExited
+	}
+}
+
+// watchSource polls the source directory, signaling whenever a file is added, removed or modified
+func (r *runCommandeer) watchSource(ctx context.Context) <-chan struct{} {
+	sourceChanged := make(chan struct{}, 1)
+
+	go func() {
+		lastFingerprint, err := sourceFingerprint(r.path)
+		if err != nil {
+			r.loggerInstance.WarnWithCtx(ctx, "Failed to read source directory", "err", err)
+		}
+
+		ticker := time.NewTicker(runSourcePollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			fingerprint, err := sourceFingerprint(r.path)
+			if err != nil {
+				r.loggerInstance.WarnWithCtx(ctx, "Failed to read source directory", "err", err)
+				continue
+			}
+
+			if fingerprint == lastFingerprint {
+				continue
+			}
+			lastFingerprint = fingerprint
+
+			// don't block if a change is already pending
+			select {
+			case sourceChanged <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return sourceChanged
+}
+
+// sourceFingerprint summarizes the names, sizes and modification times of the files under a directory. Hidden
+// files and directories (e.g. .git) and Python bytecode caches are skipped, as they change without the source
+// changing
+func sourceFingerprint(sourceDir string) (string, error) {
+	var fingerprint strings.Builder
+
+	err := filepath.WalkDir(sourceDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if filePath != sourceDir &&
+			(strings.HasPrefix(entry.Name(), ".") || entry.Name() == "__pycache__") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		fileInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&fingerprint, "%s:%d:%d\n", filePath, fileInfo.Size(), fileInfo.ModTime().UnixNano())
+		return nil
+	})
+
+	return fingerprint.String(), err
+}
@@ -0,0 +1,158 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/suite"
+)
+
+type runTestSuite struct {
+	suite.Suite
+	sourceDir  string
+	commandeer *runCommandeer
+}
+
+func (suite *runTestSuite) SetupTest() {
+	loggerInstance, err := nucliozap.NewNuclioZapTest("test")
+	suite.Require().NoError(err)
+
+	suite.sourceDir = suite.T().TempDir()
+	suite.commandeer = &runCommandeer{
+		loggerInstance: loggerInstance,
+		path:           suite.sourceDir,
+		port:           8080,
+	}
+}
+
+func (suite *runTestSuite) TestResolveFunctionConfig() {
+	suite.writeSourceFile("function.yaml", `metadata:
+  name: greeter
+spec:
+  runtime: python:3.9
+  handler: main:handler
+  triggers:
+    http:
+      kind: http
+      maxWorkers: 4
+    cron:
+      kind: cron
+      attributes:
+        interval: 10s
+`)
+	suite.commandeer.encodedEnv = stringSliceFlag{"GREETING=hello=world"}
+	suite.commandeer.port = 9090
+
+	functionConfig, err := suite.commandeer.resolveFunctionConfig("")
+	suite.Require().NoError(err, "Resolve function config should succeed")
+
+	suite.Require().Equal("greeter", functionConfig.Meta.Name)
+	suite.Require().Equal("python:3.9", functionConfig.Spec.Runtime)
+	suite.Require().Len(functionConfig.Spec.Env, 1)
+	suite.Require().Equal("hello=world", functionConfig.Spec.Env[0].Value)
+
+	// existing http trigger is kept, with the requested port
+	suite.Require().Len(functionConfig.Spec.Triggers, 2)
+	suite.Require().Equal(":9090", functionConfig.Spec.Triggers["http"].URL)
+	suite.Require().Equal(4, functionConfig.Spec.Triggers["http"].MaxWorkers)
+}
+
+func (suite *runTestSuite) TestResolveFunctionConfigFromFlags() {
+	suite.commandeer.runtime = "shell"
+	suite.commandeer.handler = "main.sh"
+
+	functionConfig, err := suite.commandeer.resolveFunctionConfig("")
+	suite.Require().NoError(err, "Resolve function config from flags should succeed")
+
+	suite.Require().Equal(filepath.Base(suite.sourceDir), functionConfig.Meta.Name)
+	suite.Require().Len(functionConfig.Spec.Triggers, 1)
+	for _, trigger := range functionConfig.Spec.Triggers {
+		suite.Require().Equal("http", trigger.Kind)
+		suite.Require().Equal(":8080", trigger.URL)
+	}
+
+	// handler is required
+	suite.commandeer.handler = ""
+	_, err = suite.commandeer.resolveFunctionConfig("")
+	suite.Require().Error(err, "Resolve function config without a handler should not succeed")
+}
+
+func (suite *runTestSuite) TestResolveRuntimeEnv() {
+	wrapperPath := filepath.Join(suite.T().TempDir(), "_nuclio_wrapper.py")
+	suite.Require().NoError(os.WriteFile(wrapperPath, []byte(""), 0600))
+	suite.commandeer.wrapperPath = wrapperPath
+
+	env, err := suite.commandeer.resolveRuntimeEnv("python:3.9")
+	suite.Require().NoError(err)
+	suite.Require().ElementsMatch([]string{
+		"NUCLIO_PYTHON_PATH=" + suite.sourceDir,
+		"NUCLIO_PYTHON_WRAPPER_PATH=" + wrapperPath,
+	}, env)
+
+	env, err = suite.commandeer.resolveRuntimeEnv("shell")
+	suite.Require().NoError(err)
+	suite.Require().Equal([]string{"NUCLIO_SHELL_HANDLER_DIR=" + suite.sourceDir}, env)
+
+	// missing wrapper
+	suite.commandeer.wrapperPath = filepath.Join(suite.T().TempDir(), "wrapper.js")
+	_, err = suite.commandeer.resolveRuntimeEnv("nodejs")
+	suite.Require().Error(err, "Resolve runtime env without a wrapper should not succeed")
+
+	// runtimes that must be built
+	_, err = suite.commandeer.resolveRuntimeEnv("golang")
+	suite.Require().Error(err, "Resolve runtime env of a compiled runtime should not succeed")
+}
+
+func (suite *runTestSuite) TestSourceFingerprint() {
+	suite.writeSourceFile("main.py", "def handler(context, event):\n    pass\n")
+
+	fingerprint, err := sourceFingerprint(suite.sourceDir)
+	suite.Require().NoError(err)
+
+	// bytecode caches and hidden files don't count as changes
+	suite.writeSourceFile(filepath.Join("__pycache__", "main.cpython-39.pyc"), "bytecode")
+	suite.writeSourceFile(".main.py.swp", "swap")
+
+	unchangedFingerprint, err := sourceFingerprint(suite.sourceDir)
+	suite.Require().NoError(err)
+	suite.Require().Equal(fingerprint, unchangedFingerprint)
+
+	// modifying a source file does
+	modificationTime := time.Now().Add(time.Minute)
+	suite.Require().NoError(os.Chtimes(filepath.Join(suite.sourceDir, "main.py"), modificationTime, modificationTime))
+
+	changedFingerprint, err := sourceFingerprint(suite.sourceDir)
+	suite.Require().NoError(err)
+	suite.Require().NotEqual(fingerprint, changedFingerprint)
+}
+
+func (suite *runTestSuite) writeSourceFile(name string, contents string) {
+	filePath := filepath.Join(suite.sourceDir, name)
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(filePath), 0755))
+	suite.Require().NoError(os.WriteFile(filePath, []byte(contents), 0600))
+}
+
+func TestRunTestSuite(t *testing.T) {
+	suite.Run(t, new(runTestSuite))
+}
@@ -0,0 +1,557 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/errgroup"
+	nuctlcommon "github.com/nuclio/nuclio/pkg/nuctl/command/common"
+	"github.com/nuclio/nuclio/pkg/platform"
+	"github.com/nuclio/nuclio/pkg/renderer"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+	"github.com/prometheus/common/expfmt"
+	"github.com/spf13/cobra"
+)
+
+const (
+
+	// clears the terminal and moves the cursor to its top left corner
+	terminalClearScreen = "\033[H\033[2J"
+
+	processorHandledEventsMetricName = "nuclio_processor_handled_events_total"
+
+	// the number of state transitions nuctl top shows
+	topMaxTransitions = 10
+
+	// the state of functions that were removed
+	functionDeletedState = "deleted"
+
+	// the number of replicas whose metrics are read at once
+	topMetricsConcurrency = 10
+)
+
+type topCommandeer struct {
+	cmd                 *cobra.Command
+	rootCommandeer      *RootCommandeer
+	getFunctionsOptions platform.GetFunctionsOptions
+	interval            time.Duration
+	iterations          int
+}
+
+func newTopCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *topCommandeer {
+	commandeer := &topCommandeer{
+		rootCommandeer: rootCommandeer,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "top [function-name]",
+		Short: "Display the state, replicas and invocation rates of functions, updated continuously",
+		Long: `Display the state, replicas and invocation rates of functions, updated continuously.
+
+The invocation and error rates are computed from the processor metrics of each
+of the function's replicas, between consecutive refreshes, and require the
+prometheus pull metric sink to be configured for functions (Kubernetes only).
+Rates that can't be computed are shown as "-".
+
+Below the functions, the latest state transitions (e.g. building -> ready) are
+shown. When writing to a terminal the view is updated in place, and otherwise
+each refresh is printed after the previous one.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return errors.New("Top accepts at most one function name")
+			}
+
+			if len(args) == 1 {
+				commandeer.getFunctionsOptions.Name = args[0]
+			}
+
+			if commandeer.interval <= 0 {
+				return errors.New("Interval must be positive")
+			}
+
+			// initialize root
+			if err := rootCommandeer.initialize(); err != nil {
+				return errors.Wrap(err, "Failed to initialize root")
+			}
+
+			commandeer.getFunctionsOptions.Namespace = rootCommandeer.namespace
+
+			topCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			stateTracker := newFunctionStateTracker()
+			metricsTracker := newFunctionMetricsTracker(rootCommandeer.loggerInstance, rootCommandeer.platform)
+
+			return pollFunctions(topCtx,
+				rootCommandeer,
+				&commandeer.getFunctionsOptions,
+				commandeer.interval,
+				commandeer.iterations,
+				func(functions []platform.Function, now time.Time) error {
+					stateTracker.update(functions, now)
+					functionRates := metricsTracker.update(topCtx, functions, now)
+
+					frame := bytes.Buffer{}
+					commandeer.renderTop(&frame, functions, stateTracker, functionRates, now)
+
+					return renderFrame(cmd.OutOrStdout(), frame.Bytes())
+				})
+		},
+	}
+
+	cmd.Flags().StringVarP(&commandeer.getFunctionsOptions.Labels, "labels", "l", "", "Function labels (lbl1=val1[,lbl2=val2,...])")
+	cmd.Flags().DurationVar(&commandeer.interval, "interval", 2*time.Second, "Time between refreshes")
+	cmd.Flags().IntVar(&commandeer.iterations, "iterations", 0, "Number of refreshes before exiting (0 - until interrupted)")
+
+	commandeer.cmd = cmd
+
+	return commandeer
+}
+
+func (t *topCommandeer) renderTop(writer io.Writer,
+	functions []platform.Function,
+	stateTracker *functionStateTracker,
+	functionRates map[string]*functionRates,
+	now time.Time) {
+	rendererInstance := renderer.NewRenderer(writer)
+
+	fmt.Fprintf(writer, "Functions at %s, refreshed every %s\n\n", now.Format(time.TimeOnly), t.interval) // nolint: errcheck
+
+	var functionRecords [][]string
+	for _, function := range functions {
+		key := getFunctionTrackingKey(function)
+		availableReplicas, specifiedReplicas := function.GetReplicas()
+		trackedState := stateTracker.states[key]
+
+		invocationRate, errorRate := "-", "-"
+		if rates, found := functionRates[key]; found {
+			invocationRate = fmt.Sprintf("%.1f", rates.invocationRate)
+			errorRate = fmt.Sprintf("%.1f%%", rates.errorRate*100)
+		}
+
+		functionRecords = append(functionRecords, []string{
+			function.GetConfig().Meta.Namespace,
+			function.GetConfig().Meta.Name,
+			trackedState.state,
+			now.Sub(trackedState.since).Round(time.Second).String(),
+			fmt.Sprintf("%d/%d", availableReplicas, specifiedReplicas),
+			invocationRate,
+			errorRate,
+		})
+	}
+
+	rendererInstance.RenderTable([]string{
+		"Namespace",
+		"Name",
+		"State",
+		"Since",
+		"Replicas",
+		"Invocations/s",
+		"Error Rate",
+	}, functionRecords)
+
+	if len(stateTracker.transitions) == 0 {
+		return
+	}
+
+	fmt.Fprintf(writer, "\nLatest state transitions\n\n") // nolint: errcheck
+
+	// newest first
+	var transitionRecords [][]string
+	for transitionIndex := len(stateTracker.transitions) - 1; transitionIndex >= 0; transitionIndex-- {
+		transition := stateTracker.transitions[transitionIndex]
+		transitionRecords = append(transitionRecords, []string{
+			transition.time.Format(time.TimeOnly),
+			transition.namespace,
+			transition.name,
+			transition.from,
+			transition.to,
+		})
+	}
+
+	rendererInstance.RenderTable([]string{"Time", "Namespace", "Name", "From", "To"}, transitionRecords)
+}
+
+// pollFunctions gets and initializes the functions every interval, until the context is done or the given number
+// of iterations (if positive) was reached. functions are passed to refresh sorted by namespace and name
+func pollFunctions(ctx context.Context,
+	rootCommandeer *RootCommandeer,
+	getFunctionsOptions *platform.GetFunctionsOptions,
+	interval time.Duration,
+	iterations int,
+	refresh func(functions []platform.Function, now time.Time) error) error {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for iteration := 1; ctx.Err() == nil; iteration++ {
+		functions, err := rootCommandeer.platform.GetFunctions(ctx, getFunctionsOptions)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			// the first failure is probably not transient
+			if iteration == 1 {
+				return errors.Wrap(err, "Failed to get functions")
+			}
+
+			rootCommandeer.loggerInstance.WarnWith("Failed to get functions, retrying", "err", err.Error())
+		} else {
+			if err := nuctlcommon.InitializeFunctions(ctx, rootCommandeer.loggerInstance, functions); err != nil {
+				return errors.Wrap(err, "Failed to initialize functions")
+			}
+
+			sort.Slice(functions, func(i, j int) bool {
+				return getFunctionTrackingKey(functions[i]) < getFunctionTrackingKey(functions[j])
+			})
+
+			if err := refresh(functions, time.Now()); err != nil {
+				return errors.Wrap(err, "Failed to refresh functions")
+			}
+		}
+
+		if iterations > 0 && iteration >= iterations {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+// renderFrame writes a frame of a continuously updated view. on a terminal the frame replaces the previous one,
+// and otherwise frames are separated by an empty line
+func renderFrame(writer io.Writer, frame []byte) error {
+	prefix := "\n"
+	if isTerminal(writer) {
+		prefix = terminalClearScreen
+	}
+
+	_, err := writer.Write(append([]byte(prefix), frame...))
+	return err
+}
+
+func isTerminal(writer io.Writer) bool {
+	file, isFile := writer.(*os.File)
+	if !isFile {
+		return false
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fileInfo.Mode()&os.ModeCharDevice != 0
+}
+
+func getFunctionTrackingKey(function platform.Function) string {
+	return function.GetConfig().Meta.Namespace + "/" + function.GetConfig().Meta.Name
+}
+
+type functionTrackedState struct {
+	namespace string
+	name      string
+	state     string
+	replicas  string
+
+	// when the state was first seen
+	since time.Time
+}
+
+type functionStateTransition struct {
+	time      time.Time
+	namespace string
+	name      string
+	from      string
+	to        string
+}
+
+// functionStateTracker keeps the states of functions across refreshes, and the transitions between them
+type functionStateTracker struct {
+	states      map[string]functionTrackedState
+	transitions []functionStateTransition
+	updated     bool
+}
+
+func newFunctionStateTracker() *functionStateTracker {
+	return &functionStateTracker{
+		states: map[string]functionTrackedState{},
+	}
+}
+
+// update records the states of the functions, returning the functions that were added or whose state or replicas
+// changed since the last update, and the last states of the functions that were removed. functions found on the
+// first update are all considered added, but aren't recorded as transitions
+func (fst *functionStateTracker) update(functions []platform.Function,
+	now time.Time) ([]platform.Function, []functionTrackedState) {
+	var changedFunctions []platform.Function
+
+	states := map[string]functionTrackedState{}
+	for _, function := range functions {
+		key := getFunctionTrackingKey(function)
+		availableReplicas, specifiedReplicas := function.GetReplicas()
+
+		state := functionTrackedState{
+			namespace: function.GetConfig().Meta.Namespace,
+			name:      function.GetConfig().Meta.Name,
+			state:     nuctlcommon.EncodeFunctionState(function),
+			replicas:  fmt.Sprintf("%d/%d", availableReplicas, specifiedReplicas),
+			since:     now,
+		}
+
+		previousState, found := fst.states[key]
+		switch {
+		case !found:
+			changedFunctions = append(changedFunctions, function)
+			if fst.updated {
+				fst.addTransition(now, function, "-", state.state)
+			}
+		case previousState.state != state.state:
+			changedFunctions = append(changedFunctions, function)
+			fst.addTransition(now, function, previousState.state, state.state)
+		default:
+			state.since = previousState.since
+			if previousState.replicas != state.replicas {
+				changedFunctions = append(changedFunctions, function)
+			}
+		}
+
+		states[key] = state
+	}
+
+	// whatever wasn't found was deleted
+	var removedKeys []string
+	for key := range fst.states {
+		if _, found := states[key]; !found {
+			removedKeys = append(removedKeys, key)
+		}
+	}
+	sort.Strings(removedKeys)
+
+	var removedStates []functionTrackedState
+	for _, key := range removedKeys {
+		previousState := fst.states[key]
+		removedStates = append(removedStates, previousState)
+		fst.transitions = append(fst.transitions, functionStateTransition{
+			time:      now,
+			namespace: previousState.namespace,
+			name:      previousState.name,
+			from:      previousState.state,
+			to:        functionDeletedState,
+		})
+	}
+
+	fst.states = states
+	fst.updated = true
+
+	if len(fst.transitions) > topMaxTransitions {
+		fst.transitions = fst.transitions[len(fst.transitions)-topMaxTransitions:]
+	}
+
+	return changedFunctions, removedStates
+}
+
+func (fst *functionStateTracker) addTransition(now time.Time, function platform.Function, from string, to string) {
+	fst.transitions = append(fst.transitions, functionStateTransition{
+		time:      now,
+		namespace: function.GetConfig().Meta.Namespace,
+		name:      function.GetConfig().Meta.Name,
+		from:      from,
+		to:        to,
+	})
+}
+
+type replicaEventCounters struct {
+	handled float64
+	failed  float64
+}
+
+type functionRates struct {
+	invocationRate float64
+
+	// the fraction of the invocations that failed
+	errorRate float64
+}
+
+// functionMetricsTracker computes the invocation and error rates of functions from the handled events counters of
+// their replicas, between consecutive updates
+type functionMetricsTracker struct {
+	logger          logger.Logger
+	platform        platform.Platform
+	replicaCounters map[string]replicaEventCounters
+	lastUpdateTime  time.Time
+}
+
+func newFunctionMetricsTracker(parentLogger logger.Logger, platformInstance platform.Platform) *functionMetricsTracker {
+	return &functionMetricsTracker{
+		logger:          parentLogger.GetChild("metrics"),
+		platform:        platformInstance,
+		replicaCounters: map[string]replicaEventCounters{},
+	}
+}
+
+// update reads the counters of the functions' replicas, and returns the rates of the functions that could be
+// computed - ones with at least one replica whose counters were also read on the previous update
+func (fmtr *functionMetricsTracker) update(ctx context.Context,
+	functions []platform.Function,
+	now time.Time) map[string]*functionRates {
+	replicaCounters := map[string]replicaEventCounters{}
+	replicaCountersLock := sync.Mutex{}
+
+	errGroup, errGroupCtx := errgroup.WithContextSemaphore(ctx, fmtr.logger, topMetricsConcurrency)
+
+	replicaFunctionKeys := map[string]string{}
+	for _, function := range functions {
+		replicaNames, err := fmtr.platform.GetFunctionReplicaNames(ctx, function.GetConfig())
+		if err != nil {
+			fmtr.logger.DebugWith("Failed to get function replicas",
+				"function", function.GetConfig().Meta.Name,
+				"err", err.Error())
+			continue
+		}
+
+		for _, replicaName := range replicaNames {
+			replicaName := replicaName
+			namespace := function.GetConfig().Meta.Namespace
+			replicaKey := namespace + "/" + replicaName
+			replicaFunctionKeys[replicaKey] = getFunctionTrackingKey(function)
+
+			errGroup.Go("read replica metrics", func() error {
+				counters, err := fmtr.readReplicaEventCounters(errGroupCtx, namespace, replicaName)
+				if err != nil {
+
+					// e.g. the replica isn't ready, or the metric sink isn't configured
+					fmtr.logger.DebugWith("Failed to read replica metrics",
+						"replica", replicaName,
+						"err", err.Error())
+					return nil
+				}
+
+				replicaCountersLock.Lock()
+				replicaCounters[replicaKey] = *counters
+				replicaCountersLock.Unlock()
+
+				return nil
+			})
+		}
+	}
+
+	errGroup.Wait() // nolint: errcheck
+
+	elapsedSeconds := now.Sub(fmtr.lastUpdateTime).Seconds()
+
+	type eventDeltas struct {
+		handled float64
+		failed  float64
+	}
+	functionEventDeltas := map[string]*eventDeltas{}
+
+	for replicaKey, counters := range replicaCounters {
+		previousCounters, found := fmtr.replicaCounters[replicaKey]
+		if !found {
+			continue
+		}
+
+		// counters restart with the processor
+		if counters.handled < previousCounters.handled {
+			previousCounters = replicaEventCounters{}
+		}
+
+		functionKey := replicaFunctionKeys[replicaKey]
+		if _, found := functionEventDeltas[functionKey]; !found {
+			functionEventDeltas[functionKey] = &eventDeltas{}
+		}
+
+		functionEventDeltas[functionKey].handled += counters.handled - previousCounters.handled
+		functionEventDeltas[functionKey].failed += counters.failed - previousCounters.failed
+	}
+
+	fmtr.replicaCounters = replicaCounters
+	fmtr.lastUpdateTime = now
+
+	rates := map[string]*functionRates{}
+	for functionKey, deltas := range functionEventDeltas {
+		rates[functionKey] = &functionRates{
+			invocationRate: deltas.handled / elapsedSeconds,
+		}
+
+		if deltas.handled > 0 {
+			rates[functionKey].errorRate = deltas.failed / deltas.handled
+		}
+	}
+
+	return rates
+}
+
+// readReplicaEventCounters sums the handled events counters of all the replica's triggers
+func (fmtr *functionMetricsTracker) readReplicaEventCounters(ctx context.Context,
+	namespace string,
+	replicaName string) (*replicaEventCounters, error) {
+	metricsReader, err := fmtr.platform.GetFunctionReplicaMetrics(ctx, &platform.GetFunctionReplicaMetricsOptions{
+		Name:      replicaName,
+		Namespace: namespace,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get replica metrics")
+	}
+	defer metricsReader.Close() // nolint: errcheck
+
+	return parseReplicaEventCounters(metricsReader)
+}
+
+func parseReplicaEventCounters(metricsReader io.Reader) (*replicaEventCounters, error) {
+	metricFamilies, err := (&expfmt.TextParser{}).TextToMetricFamilies(metricsReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse metrics")
+	}
+
+	counters := &replicaEventCounters{}
+	if metricFamily, found := metricFamilies[processorHandledEventsMetricName]; found {
+
+		// the counter is labeled by trigger and by result (success or failure)
+		for _, metric := range metricFamily.GetMetric() {
+			value := metric.GetCounter().GetValue()
+			counters.handled += value
+
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "result" && label.GetValue() == "failure" {
+					counters.failed += value
+				}
+			}
+		}
+	}
+
+	return counters, nil
+}
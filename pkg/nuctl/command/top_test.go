@@ -0,0 +1,386 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	nuctlcommon "github.com/nuclio/nuclio/pkg/nuctl/command/common"
+	"github.com/nuclio/nuclio/pkg/platform"
+	mockplatform "github.com/nuclio/nuclio/pkg/platform/mock"
+
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"sigs.k8s.io/yaml"
+)
+
+type topTestSuite struct {
+	suite.Suite
+	mockPlatform   *mockplatform.Platform
+	rootCommandeer *RootCommandeer
+	now            time.Time
+}
+
+func (suite *topTestSuite) SetupTest() {
+	loggerInstance, err := nucliozap.NewNuclioZapTest("test")
+	suite.Require().NoError(err, "Create logger should succeed")
+
+	suite.mockPlatform = &mockplatform.Platform{}
+	suite.rootCommandeer = &RootCommandeer{
+		loggerInstance: loggerInstance,
+		platform:       suite.mockPlatform,
+		namespace:      "default-namespace",
+	}
+	suite.now = time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+}
+
+func (suite *topTestSuite) TestTrackFunctionStates() {
+	stateTracker := newFunctionStateTracker()
+
+	// functions found on the first update aren't transitions
+	changedFunctions, removedStates := stateTracker.update([]platform.Function{
+		suite.newFunctionInState("f1", functionconfig.FunctionStateBuilding),
+		suite.newFunctionInState("f2", functionconfig.FunctionStateReady),
+	}, suite.now)
+	suite.Require().Len(changedFunctions, 2)
+	suite.Require().Empty(removedStates)
+	suite.Require().Empty(stateTracker.transitions)
+
+	changedFunctions, removedStates = stateTracker.update([]platform.Function{
+		suite.newFunctionInState("f1", functionconfig.FunctionStateReady),
+		suite.newFunctionInState("f2", functionconfig.FunctionStateReady),
+	}, suite.now.Add(time.Minute))
+	suite.Require().Len(changedFunctions, 1)
+	suite.Require().Equal("f1", changedFunctions[0].GetConfig().Meta.Name)
+	suite.Require().Empty(removedStates)
+	suite.Require().Equal(suite.now.Add(time.Minute), stateTracker.states["default-namespace/f1"].since)
+	suite.Require().Equal(suite.now, stateTracker.states["default-namespace/f2"].since)
+
+	changedFunctions, removedStates = stateTracker.update([]platform.Function{
+		suite.newFunctionInState("f1", functionconfig.FunctionStateReady),
+		suite.newFunctionInState("f3", functionconfig.FunctionStateScaledToZero),
+	}, suite.now.Add(2*time.Minute))
+	suite.Require().Len(changedFunctions, 1)
+	suite.Require().Equal("f3", changedFunctions[0].GetConfig().Meta.Name)
+	suite.Require().Len(removedStates, 1)
+	suite.Require().Equal("f2", removedStates[0].name)
+
+	var transitions []string
+	for _, transition := range stateTracker.transitions {
+		transitions = append(transitions, fmt.Sprintf("%s: %s -> %s", transition.name, transition.from, transition.to))
+	}
+	suite.Require().Equal([]string{
+		"f1: building -> ready",
+		"f3: - -> scaledToZero",
+		"f2: ready -> deleted",
+	}, transitions)
+
+	// only the latest transitions are kept
+	for transitionIndex := 0; transitionIndex < topMaxTransitions; transitionIndex++ {
+		state := functionconfig.FunctionStateReady
+		if transitionIndex%2 == 0 {
+			state = functionconfig.FunctionStateError
+		}
+		stateTracker.update([]platform.Function{
+			suite.newFunctionInState("f1", state),
+			suite.newFunctionInState("f3", functionconfig.FunctionStateScaledToZero),
+		}, suite.now.Add(time.Duration(3+transitionIndex)*time.Minute))
+	}
+	suite.Require().Len(stateTracker.transitions, topMaxTransitions)
+	suite.Require().Equal("f1", stateTracker.transitions[0].name)
+}
+
+func (suite *topTestSuite) TestComputeFunctionRates() {
+	functions := []platform.Function{
+		suite.newFunctionInState("f1", functionconfig.FunctionStateReady),
+		suite.newFunctionInState("f2", functionconfig.FunctionStateScaledToZero),
+	}
+
+	suite.mockReplicaNames("f1", "nuclio-f1-a", "nuclio-f1-b")
+	suite.mockReplicaNames("f2")
+
+	metricsTracker := newFunctionMetricsTracker(suite.rootCommandeer.loggerInstance, suite.mockPlatform)
+
+	// the first update only records the counters
+	suite.mockReplicaMetrics("nuclio-f1-a", 100, 10)
+	suite.mockReplicaMetrics("nuclio-f1-b", 50, 0)
+	suite.Require().Empty(metricsTracker.update(context.Background(), functions, suite.now))
+
+	// the second replica restarted
+	suite.mockReplicaMetrics("nuclio-f1-a", 190, 20)
+	suite.mockReplicaMetrics("nuclio-f1-b", 10, 0)
+	rates := metricsTracker.update(context.Background(), functions, suite.now.Add(10*time.Second))
+
+	suite.Require().Len(rates, 1)
+	suite.Require().InDelta(11, rates["default-namespace/f1"].invocationRate, 0.001)
+	suite.Require().InDelta(10.0/110, rates["default-namespace/f1"].errorRate, 0.001)
+
+	// replicas whose metrics can't be read are skipped
+	suite.mockPlatform.
+		On("GetFunctionReplicaMetrics", mock.Anything, mock.MatchedBy(func(options *platform.GetFunctionReplicaMetricsOptions) bool {
+			return options.Name == "nuclio-f1-a"
+		})).
+		Return(io.NopCloser(strings.NewReader("")), fmt.Errorf("connection refused")).
+		Once()
+	suite.mockReplicaMetrics("nuclio-f1-b", 30, 5)
+	rates = metricsTracker.update(context.Background(), functions, suite.now.Add(20*time.Second))
+
+	suite.Require().InDelta(2.5, rates["default-namespace/f1"].invocationRate, 0.001)
+	suite.Require().InDelta(0.2, rates["default-namespace/f1"].errorRate, 0.001)
+}
+
+func (suite *topTestSuite) TestRenderTop() {
+	topCommandeer := &topCommandeer{
+		rootCommandeer: suite.rootCommandeer,
+		interval:       2 * time.Second,
+	}
+
+	stateTracker := newFunctionStateTracker()
+	stateTracker.update([]platform.Function{
+		suite.newFunctionInState("f1", functionconfig.FunctionStateBuilding),
+		suite.newFunctionInState("f2", functionconfig.FunctionStateReady),
+	}, suite.now)
+
+	functions := []platform.Function{
+		suite.newFunctionInState("f1", functionconfig.FunctionStateReady),
+		suite.newFunctionInState("f2", functionconfig.FunctionStateReady),
+	}
+	stateTracker.update(functions, suite.now.Add(30*time.Second))
+
+	output := bytes.Buffer{}
+	topCommandeer.renderTop(&output, functions, stateTracker, map[string]*functionRates{
+		"default-namespace/f1": {invocationRate: 11, errorRate: 0.25},
+	}, suite.now.Add(time.Minute))
+
+	lines := strings.Split(output.String(), "\n")
+	suite.Require().Equal("Functions at 10:01:00, refreshed every 2s", lines[0])
+	suite.Require().Regexp(`f1 +\| ready +\| 30s +\| 0/0 +\| 11.0 +\| 25.0%`, output.String())
+	suite.Require().Regexp(`f2 +\| ready +\| 1m0s +\| 0/0 +\| - +\| -`, output.String())
+	suite.Require().Contains(output.String(), "Latest state transitions")
+	suite.Require().Regexp(`10:00:30 +\| default-namespace +\| f1 +\| building +\| ready`, output.String())
+}
+
+func (suite *topTestSuite) TestWatchFunctions() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	suite.mockFunctions([]platform.Function{
+		suite.newFunctionInState("f1", functionconfig.FunctionStateBuilding),
+		suite.newFunctionInState("f2", functionconfig.FunctionStateReady),
+	}, nil)
+
+	// nothing changed
+	suite.mockFunctions([]platform.Function{
+		suite.newFunctionInState("f1", functionconfig.FunctionStateBuilding),
+		suite.newFunctionInState("f2", functionconfig.FunctionStateReady),
+	}, nil)
+
+	suite.mockFunctions([]platform.Function{
+		suite.newFunctionInState("f1", functionconfig.FunctionStateReady),
+		suite.newFunctionInState("f2", functionconfig.FunctionStateReady),
+	}, nil)
+
+	suite.mockFunctions([]platform.Function{
+		suite.newFunctionInState("f1", functionconfig.FunctionStateReady),
+	}, cancel)
+
+	getFunctionCommandeer := &getFunctionCommandeer{
+		getCommandeer: &getCommandeer{
+			rootCommandeer: suite.rootCommandeer,
+		},
+		getFunctionsOptions: platform.GetFunctionsOptions{
+			Namespace: "default-namespace",
+		},
+		output:        nuctlcommon.OutputFormatJSON,
+		watch:         true,
+		watchInterval: time.Millisecond,
+	}
+
+	output := bytes.Buffer{}
+	suite.Require().NoError(getFunctionCommandeer.watchFunctions(ctx, &output), "Watch functions should succeed")
+	suite.mockPlatform.AssertExpectations(suite.T())
+
+	// all functions are rendered first, followed by the ones that changed and records of the ones removed, all
+	// sharing the same metadata and status envelope
+	var renderedStates []string
+	decoder := json.NewDecoder(&output)
+	for decoder.More() {
+		functionConfigWithStatus := functionconfig.ConfigWithStatus{}
+		suite.Require().NoError(decoder.Decode(&functionConfigWithStatus), "Decode rendered function should succeed")
+		suite.Require().Equal("default-namespace", functionConfigWithStatus.Meta.Namespace)
+		renderedStates = append(renderedStates, fmt.Sprintf("%s:%s",
+			functionConfigWithStatus.Meta.Name,
+			functionConfigWithStatus.Status.State))
+	}
+	suite.Require().Equal([]string{"f1:building", "f2:ready", "f1:ready", "f2:deleted"}, renderedStates)
+}
+
+func (suite *topTestSuite) TestWatchFunctionsYAML() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	suite.mockFunctions([]platform.Function{
+		suite.newFunctionInState("f1", functionconfig.FunctionStateReady),
+		suite.newFunctionInState("f2", functionconfig.FunctionStateReady),
+	}, nil)
+	suite.mockFunctions([]platform.Function{
+		suite.newFunctionInState("f1", functionconfig.FunctionStateReady),
+	}, cancel)
+
+	getFunctionCommandeer := &getFunctionCommandeer{
+		getCommandeer: &getCommandeer{
+			rootCommandeer: suite.rootCommandeer,
+		},
+		getFunctionsOptions: platform.GetFunctionsOptions{
+			Namespace: "default-namespace",
+		},
+		output:        nuctlcommon.OutputFormatYAML,
+		watch:         true,
+		watchInterval: time.Millisecond,
+	}
+
+	output := bytes.Buffer{}
+	suite.Require().NoError(getFunctionCommandeer.watchFunctions(ctx, &output), "Watch functions should succeed")
+
+	// each function is rendered as its own YAML document
+	var renderedStates []string
+	for _, document := range strings.Split(output.String(), "---\n")[1:] {
+		functionConfigWithStatus := functionconfig.ConfigWithStatus{}
+		suite.Require().NoError(yaml.Unmarshal([]byte(document), &functionConfigWithStatus),
+			"Unmarshal rendered function should succeed")
+		renderedStates = append(renderedStates, fmt.Sprintf("%s:%s",
+			functionConfigWithStatus.Meta.Name,
+			functionConfigWithStatus.Status.State))
+	}
+	suite.Require().Equal([]string{"f1:ready", "f2:ready", "f2:deleted"}, renderedStates)
+	suite.Require().True(strings.HasPrefix(output.String(), "---\n"))
+}
+
+func (suite *topTestSuite) TestWatchFunctionsText() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	suite.mockFunctions([]platform.Function{}, nil)
+	suite.mockFunctions([]platform.Function{
+		suite.newFunctionInState("f1", functionconfig.FunctionStateBuilding),
+	}, cancel)
+
+	getFunctionCommandeer := &getFunctionCommandeer{
+		getCommandeer: &getCommandeer{
+			rootCommandeer: suite.rootCommandeer,
+		},
+		getFunctionsOptions: platform.GetFunctionsOptions{
+			Namespace: "default-namespace",
+		},
+		output:        nuctlcommon.OutputFormatText,
+		watch:         true,
+		watchInterval: time.Millisecond,
+	}
+
+	output := bytes.Buffer{}
+	suite.Require().NoError(getFunctionCommandeer.watchFunctions(ctx, &output), "Watch functions should succeed")
+
+	// frames are appended when not writing to a terminal
+	frames := strings.Split(strings.TrimPrefix(output.String(), "\n"), "\n\n")
+	suite.Require().Len(frames, 2)
+	suite.Require().Equal("No functions found", strings.TrimSpace(frames[0]))
+	suite.Require().Regexp(`default-namespace +\| f1 +\| +\| building`, frames[1])
+}
+
+func (suite *topTestSuite) TestParseReplicaEventCounters() {
+	counters, err := parseReplicaEventCounters(strings.NewReader(`# HELP nuclio_processor_handled_events_total Total number of handled events
+# TYPE nuclio_processor_handled_events_total counter
+nuclio_processor_handled_events_total{function="f1",result="success",trigger_id="http"} 90
+nuclio_processor_handled_events_total{function="f1",result="failure",trigger_id="http"} 7
+nuclio_processor_handled_events_total{function="f1",result="success",trigger_id="cron"} 3
+# HELP nuclio_processor_worker_allocation_count Total number of worker_allocations
+# TYPE nuclio_processor_worker_allocation_count counter
+nuclio_processor_worker_allocation_count{function="f1",trigger_id="http"} 97
+`))
+	suite.Require().NoError(err)
+	suite.Require().Equal(&replicaEventCounters{handled: 100, failed: 7}, counters)
+
+	_, err = parseReplicaEventCounters(strings.NewReader("not metrics {"))
+	suite.Require().Error(err)
+}
+
+func (suite *topTestSuite) newFunctionInState(name string, state functionconfig.FunctionState) platform.Function {
+	functionConfig := functionconfig.NewConfig()
+	functionConfig.Meta.Name = name
+	functionConfig.Meta.Namespace = "default-namespace"
+
+	function, err := platform.NewAbstractFunction(suite.rootCommandeer.loggerInstance,
+		suite.mockPlatform,
+		functionConfig,
+		&functionconfig.Status{State: state},
+		nil)
+	suite.Require().NoError(err, "Create function should succeed")
+
+	return function
+}
+
+func (suite *topTestSuite) mockFunctions(functions []platform.Function, onGet context.CancelFunc) {
+	call := suite.mockPlatform.
+		On("GetFunctions", mock.Anything, mock.MatchedBy(func(getFunctionsOptions *platform.GetFunctionsOptions) bool {
+			return getFunctionsOptions.Namespace == "default-namespace"
+		})).
+		Return(functions, nil).
+		Once()
+
+	if onGet != nil {
+		call.Run(func(args mock.Arguments) {
+			onGet()
+		})
+	}
+}
+
+func (suite *topTestSuite) mockReplicaNames(functionName string, replicaNames ...string) {
+	suite.mockPlatform.
+		On("GetFunctionReplicaNames", mock.Anything, mock.MatchedBy(func(functionConfig *functionconfig.Config) bool {
+			return functionConfig.Meta.Name == functionName
+		})).
+		Return(replicaNames, nil)
+}
+
+func (suite *topTestSuite) mockReplicaMetrics(replicaName string, successes int, failures int) {
+	metrics := fmt.Sprintf(`# TYPE nuclio_processor_handled_events_total counter
+nuclio_processor_handled_events_total{result="success",trigger_id="http"} %d
+nuclio_processor_handled_events_total{result="failure",trigger_id="http"} %d
+`, successes, failures)
+
+	suite.mockPlatform.
+		On("GetFunctionReplicaMetrics", mock.Anything, mock.MatchedBy(func(options *platform.GetFunctionReplicaMetricsOptions) bool {
+			return options.Name == replicaName && options.Namespace == "default-namespace"
+		})).
+		Return(io.NopCloser(strings.NewReader(metrics)), nil).
+		Once()
+}
+
+func TestTopTestSuite(t *testing.T) {
+	suite.Run(t, new(topTestSuite))
+}
@@ -18,7 +18,8 @@ package command
 
 import (
 	"context"
-	"encoding/json"
+	"sort"
+	"strings"
 
 	"github.com/nuclio/nuclio/pkg/common"
 	"github.com/nuclio/nuclio/pkg/functionconfig"
@@ -32,7 +33,6 @@ import (
 type updateCommandeer struct {
 	cmd            *cobra.Command
 	rootCommandeer *RootCommandeer
-	commands       stringSliceFlag
 }
 
 func newUpdateCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *updateCommandeer {
@@ -57,28 +57,57 @@ func newUpdateCommandeer(ctx context.Context, rootCommandeer *RootCommandeer) *u
 
 type updateFunctionCommandeer struct {
 	*updateCommandeer
-	functionConfig      functionconfig.Config
-	encodedDataBindings string
-	encodedTriggers     string
-	encodedLabels       string
-	encodedEnv          string
+	bulkFunctionCommandeer
+	encodedEnv            stringSliceFlag
+	unsetEnv              stringSliceFlag
+	encodedAnnotations    stringSliceFlag
+	unsetAnnotations      stringSliceFlag
+	resourceLimits        stringSliceFlag
+	unsetResourceLimits   stringSliceFlag
+	resourceRequests      stringSliceFlag
+	unsetResourceRequests stringSliceFlag
+	disable               bool
+	enable                bool
+
+	// resolved from the flags
+	env         map[string]string
+	annotations map[string]string
+	limits      v1.ResourceList
+	requests    v1.ResourceList
 }
 
 func newUpdateFunctionCommandeer(ctx context.Context, updateCommandeer *updateCommandeer) *updateFunctionCommandeer {
 	commandeer := &updateFunctionCommandeer{
 		updateCommandeer: updateCommandeer,
-		functionConfig:   *functionconfig.NewConfig(),
+		bulkFunctionCommandeer: bulkFunctionCommandeer{
+			rootCommandeer: updateCommandeer.rootCommandeer,
+		},
 	}
 
 	cmd := &cobra.Command{
-		Use:     "function [name[:version]]",
-		Aliases: []string{"fu", "fn"},
-		Short:   "Update functions",
+		Use:     "functions [name]",
+		Aliases: []string{"fu", "fn", "function"},
+		Short:   "(or function) Update the configuration of functions and redeploy them",
+		Long: `Update the configuration of functions and redeploy them.
+
+Functions are selected by name, or by project (--project) and labels (--selector).
+The changes are applied to each function's current configuration, and the
+function's current image is redeployed without building it again. Functions that
+the changes don't affect aren't redeployed.
+
+With --dry-run, the changes to each function are printed without deploying.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			var functionName string
+			switch len(args) {
+			case 0:
+			case 1:
+				functionName = args[0]
+			default:
+				return errors.New("Function update accepts at most one function name")
+			}
 
-			// if we got positional arguments
-			if len(args) != 1 {
-				return errors.New("Function update requires identifier")
+			if err := commandeer.resolveChanges(); err != nil {
+				return errors.Wrap(err, "Failed to resolve changes")
 			}
 
 			// initialize root
@@ -86,41 +115,173 @@ func newUpdateFunctionCommandeer(ctx context.Context, updateCommandeer *updateCo
 				return errors.Wrap(err, "Failed to initialize root")
 			}
 
-			// decode the JSON data bindings
-			if err := json.Unmarshal([]byte(commandeer.encodedDataBindings),
-				&commandeer.functionConfig.Spec.DataBindings); err != nil {
-				return errors.Wrap(err, "Failed to decode data bindings")
+			functions, err := commandeer.getFunctions(ctx, functionName)
+			if err != nil {
+				return errors.Wrap(err, "Failed to get functions")
 			}
 
-			// decode the JSON triggers
-			if err := json.Unmarshal([]byte(commandeer.encodedTriggers),
-				&commandeer.functionConfig.Spec.Triggers); err != nil {
-				return errors.Wrap(err, "Failed to decode triggers")
-			}
-
-			// decode labels
-			commandeer.functionConfig.Meta.Labels = common.StringToStringMap(commandeer.encodedLabels, "=")
-
-			// decode env
-			for envName, envValue := range common.StringToStringMap(commandeer.encodedEnv, "=") {
-				commandeer.functionConfig.Spec.Env = append(commandeer.functionConfig.Spec.Env, v1.EnvVar{
-					Name:  envName,
-					Value: envValue,
-				})
-			}
+			results := commandeer.run(ctx, functions, commandeer.updateFunction)
 
-			// update stuff
-			commandeer.functionConfig.Meta.Namespace = updateCommandeer.rootCommandeer.namespace
-			commandeer.functionConfig.Spec.Build.Commands = updateCommandeer.commands
-
-			return updateCommandeer.rootCommandeer.platform.UpdateFunction(ctx, &platform.UpdateFunctionOptions{
-				FunctionMeta: &commandeer.functionConfig.Meta,
-				FunctionSpec: &commandeer.functionConfig.Spec,
-			})
+			return renderBulkFunctionResults(results, cmd.OutOrStdout())
 		},
 	}
 
+	commandeer.addFlags(cmd)
+	cmd.Flags().VarP(&commandeer.encodedEnv, "env", "e", "Set an environment variable (name=value)")
+	cmd.Flags().Var(&commandeer.unsetEnv, "unset-env", "Remove an environment variable")
+	cmd.Flags().Var(&commandeer.encodedAnnotations, "annotation", "Set an annotation (key=value)")
+	cmd.Flags().Var(&commandeer.unsetAnnotations, "unset-annotation", "Remove an annotation")
+	cmd.Flags().Var(&commandeer.resourceLimits, "resource-limit", "Set a resource limit of the format '<resource name>=<quantity>' (for example, 'cpu=3')")
+	cmd.Flags().Var(&commandeer.unsetResourceLimits, "unset-resource-limit", "Remove a resource limit (for example, 'cpu')")
+	cmd.Flags().Var(&commandeer.resourceRequests, "resource-request", "Set a resource request of the format '<resource name>=<quantity>' (for example, 'cpu=3')")
+	cmd.Flags().Var(&commandeer.unsetResourceRequests, "unset-resource-request", "Remove a resource request (for example, 'cpu')")
+	cmd.Flags().BoolVar(&commandeer.disable, "disable", false, "Disable the functions (scale them to zero replicas)")
+	cmd.Flags().BoolVar(&commandeer.enable, "enable", false, "Enable disabled functions")
+
 	commandeer.cmd = cmd
 
 	return commandeer
 }
+
+func (u *updateFunctionCommandeer) resolveChanges() error {
+	if u.disable && u.enable {
+		return errors.New("Functions may be either disabled or enabled, not both")
+	}
+
+	if len(u.encodedEnv)+len(u.unsetEnv)+len(u.encodedAnnotations)+len(u.unsetAnnotations)+
+		len(u.resourceLimits)+len(u.unsetResourceLimits)+len(u.resourceRequests)+len(u.unsetResourceRequests) == 0 &&
+		!u.disable && !u.enable {
+		return errors.New("No changes were given")
+	}
+
+	var err error
+
+	if u.env, err = parseKeyValues(u.encodedEnv, "Environment variable"); err != nil {
+		return err
+	}
+
+	if u.annotations, err = parseKeyValues(u.encodedAnnotations, "Annotation"); err != nil {
+		return err
+	}
+
+	if err := parseResourceAllocations(u.resourceLimits, &u.limits); err != nil {
+		return errors.Wrap(err, "Failed to parse resource limits")
+	}
+
+	if err := parseResourceAllocations(u.resourceRequests, &u.requests); err != nil {
+		return errors.Wrap(err, "Failed to parse resource requests")
+	}
+
+	return nil
+}
+
+func (u *updateFunctionCommandeer) updateFunction(ctx context.Context, function platform.Function) *bulkFunctionResult {
+	functionConfig, skippedResult := u.getDeployableFunctionConfig(function)
+	if skippedResult != nil {
+		return skippedResult
+	}
+
+	u.applyChanges(functionConfig)
+
+	differences, err := functionconfig.DiffConfigs(function.GetConfig(), functionConfig)
+	if err != nil {
+		return &bulkFunctionResult{err: errors.Wrap(err, "Failed to compare configurations")}
+	}
+
+	switch {
+	case len(differences) == 0:
+		return &bulkFunctionResult{kind: bulkFunctionResultUnchanged}
+	case u.dryRun:
+		return &bulkFunctionResult{kind: bulkFunctionResultWouldUpdate, differences: differences}
+	}
+
+	if err := u.deployExistingImage(ctx, function, functionConfig); err != nil {
+		return &bulkFunctionResult{err: err, differences: differences}
+	}
+
+	return &bulkFunctionResult{kind: bulkFunctionResultUpdated, differences: differences}
+}
+
+// applyChanges applies the changes to a function configuration, leaving everything else as is
+func (u *updateFunctionCommandeer) applyChanges(functionConfig *functionconfig.Config) {
+	var env []v1.EnvVar
+	replacedEnvNames := map[string]bool{}
+	for _, envVar := range functionConfig.Spec.Env {
+		if common.StringSliceContainsString(u.unsetEnv, envVar.Name) {
+			continue
+		}
+
+		// replaced in place, so the order of the variables is kept
+		if value, found := u.env[envVar.Name]; found {
+			envVar = v1.EnvVar{Name: envVar.Name, Value: value}
+			replacedEnvNames[envVar.Name] = true
+		}
+
+		env = append(env, envVar)
+	}
+
+	var addedEnvNames []string
+	for envName := range u.env {
+		if !replacedEnvNames[envName] {
+			addedEnvNames = append(addedEnvNames, envName)
+		}
+	}
+	sort.Strings(addedEnvNames)
+
+	for _, envName := range addedEnvNames {
+		env = append(env, v1.EnvVar{Name: envName, Value: u.env[envName]})
+	}
+	functionConfig.Spec.Env = env
+
+	if len(u.annotations) > 0 && functionConfig.Meta.Annotations == nil {
+		functionConfig.Meta.Annotations = map[string]string{}
+	}
+	for _, annotation := range u.unsetAnnotations {
+		delete(functionConfig.Meta.Annotations, annotation)
+	}
+	for annotation, value := range u.annotations {
+		functionConfig.Meta.Annotations[annotation] = value
+	}
+
+	updateResourceList(&functionConfig.Spec.Resources.Limits, u.limits, u.unsetResourceLimits)
+	updateResourceList(&functionConfig.Spec.Resources.Requests, u.requests, u.unsetResourceRequests)
+
+	if u.disable {
+		functionConfig.Spec.Disable = true
+	}
+	if u.enable {
+		functionConfig.Spec.Disable = false
+	}
+}
+
+func updateResourceList(resourceList *v1.ResourceList, setResources v1.ResourceList, unsetResources stringSliceFlag) {
+	for _, resourceName := range unsetResources {
+		delete(*resourceList, v1.ResourceName(resourceName))
+	}
+
+	if len(setResources) == 0 {
+		return
+	}
+
+	if *resourceList == nil {
+		*resourceList = v1.ResourceList{}
+	}
+
+	for resourceName, quantity := range setResources {
+		(*resourceList)[resourceName] = quantity
+	}
+}
+
+func parseKeyValues(encodedKeyValues stringSliceFlag, kind string) (map[string]string, error) {
+	keyValues := map[string]string{}
+	for _, encodedKeyValue := range encodedKeyValues {
+		keyAndValue := strings.SplitN(encodedKeyValue, "=", 2)
+		if len(keyAndValue) != 2 || keyAndValue[0] == "" {
+			return nil, errors.Errorf("%s must be in the form of name=value: %s", kind, encodedKeyValue)
+		}
+
+		keyValues[keyAndValue[0]] = keyAndValue[1]
+	}
+
+	return keyValues, nil
+}
@@ -59,6 +59,7 @@ import (
 //
 
 const (
+	FunctionContainerName                = "nuclio"
 	FunctionContainerHTTPPort            = 8080
 	FunctionContainerWebAdminHTTPPort    = 8081
 	FunctionContainerHealthCheckHTTPPort = 8082
@@ -432,6 +433,10 @@ func (ap *Platform) ValidateFunctionConfig(ctx context.Context, functionConfig *
 		return errors.Wrap(err, "Volumes validation failed")
 	}
 
+	if err := ap.validateExtraContainers(ctx, functionConfig); err != nil {
+		return errors.Wrap(err, "Sidecars and init containers validation failed")
+	}
+
 	if err := ap.validatePriorityClassName(functionConfig); err != nil {
 		return errors.Wrap(err, "Priority class name validation failed")
 	}
@@ -697,6 +702,81 @@ func (ap *Platform) CreateFunctionInvocation(ctx context.Context,
 	return ap.invoker.invoke(ctx, createFunctionInvocationOptions)
 }
 
+// GetFunctionRevisions returns the revisions of a function, newest first
+func (ap *Platform) GetFunctionRevisions(ctx context.Context,
+	getFunctionRevisionsOptions *platform.GetFunctionRevisionsOptions) ([]*platform.FunctionRevision, error) {
+	return nil, platform.ErrUnsupportedMethod
+}
+
+// GetDependencyGraph returns the graph of dependencies between functions, api gateways and function events
+func (ap *Platform) GetDependencyGraph(ctx context.Context,
+	getDependencyGraphOptions *platform.GetDependencyGraphOptions) (*platform.DependencyGraph, error) {
+
+	var labelSelector string
+	if getDependencyGraphOptions.ProjectName != "" {
+		labelSelector = fmt.Sprintf("%s=%s",
+			common.NuclioResourceLabelKeyProjectName,
+			getDependencyGraphOptions.ProjectName)
+	}
+
+	functions, err := ap.platform.GetFunctions(ctx, &platform.GetFunctionsOptions{
+		Namespace:         getDependencyGraphOptions.Namespace,
+		Labels:            labelSelector,
+		PermissionOptions: getDependencyGraphOptions.PermissionOptions,
+		AuthSession:       getDependencyGraphOptions.AuthSession,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get functions")
+	}
+
+	// platforms without api gateways simply have none
+	apiGateways, err := ap.platform.GetAPIGateways(ctx, &platform.GetAPIGatewaysOptions{
+		Namespace:   getDependencyGraphOptions.Namespace,
+		Labels:      labelSelector,
+		AuthSession: getDependencyGraphOptions.AuthSession,
+	})
+	if err != nil && err != platform.ErrUnsupportedMethod {
+		return nil, errors.Wrap(err, "Failed to get api gateways")
+	}
+
+	var functionConfigs []*functionconfig.Config
+	var functionNames []string
+	for _, function := range functions {
+		functionConfigs = append(functionConfigs, function.GetConfig())
+		functionNames = append(functionNames, function.GetConfig().Meta.Name)
+	}
+
+	var apiGatewayConfigs []*platform.APIGatewayConfig
+	for _, apiGateway := range apiGateways {
+		apiGatewayConfigs = append(apiGatewayConfigs, apiGateway.GetConfig())
+	}
+
+	var functionEventConfigs []*platform.FunctionEventConfig
+	if len(functionNames) > 0 {
+		functionEvents, err := ap.platform.GetFunctionEvents(ctx, &platform.GetFunctionEventsOptions{
+			Meta: platform.FunctionEventMeta{
+				Namespace: getDependencyGraphOptions.Namespace,
+			},
+			FunctionNames:     functionNames,
+			PermissionOptions: getDependencyGraphOptions.PermissionOptions,
+			AuthSession:       getDependencyGraphOptions.AuthSession,
+		})
+		if err != nil && err != platform.ErrUnsupportedMethod {
+			return nil, errors.Wrap(err, "Failed to get function events")
+		}
+
+		// not all platforms filter function events by function names
+		for _, functionEvent := range functionEvents {
+			functionName := functionEvent.GetConfig().Meta.Labels[common.NuclioResourceLabelKeyFunctionName]
+			if common.StringInSlice(functionName, functionNames) {
+				functionEventConfigs = append(functionEventConfigs, functionEvent.GetConfig())
+			}
+		}
+	}
+
+	return platform.NewDependencyGraph(functionConfigs, apiGatewayConfigs, functionEventConfigs), nil
+}
+
 // GetHealthCheckMode returns the healthcheck mode the platform requires
 func (ap *Platform) GetHealthCheckMode() platform.HealthCheckMode {
 
@@ -1401,10 +1481,19 @@ func (ap *Platform) validateAutoScaleMetrics(functionConfig *functionconfig.Conf
 			string(autosv2.ExternalMetricSourceType),
 			string(autosv2.ObjectMetricSourceType),
 			string(autosv2.ContainerResourceMetricSourceType),
+			string(functionconfig.AutoScaleMetricSourceTypeTriggerBacklog),
 		}, string(metric.SourceType)) {
 			return nuclio.NewErrBadRequest(fmt.Sprintf("Auto scale metric kind is invalid - %+v", metric))
 		}
 
+		if metric.SourceType == functionconfig.AutoScaleMetricSourceTypeTriggerBacklog &&
+			!common.StringSliceContainsString([]string{
+				functionconfig.TriggerBacklogMessagesMetricName,
+				functionconfig.TriggerBacklogOldestUnprocessedAgeMetricName,
+			}, metric.MetricName) {
+			return nuclio.NewErrBadRequest(fmt.Sprintf("Trigger backlog metric name is invalid - %+v", metric))
+		}
+
 		// validate metric value
 		if metric.Threshold == 0 {
 			return nuclio.NewErrBadRequest(fmt.Sprintf("Auto scale metric value is missing - %+v", metric))
@@ -1467,6 +1556,133 @@ func (ap *Platform) validateVolumes(ctx context.Context, functionConfig *functio
 	return nil
 }
 
+// validateExtraContainers makes sure sidecars and init containers can run in the function pod alongside the
+// processor container - unique names, valid images, no processor ports, mounts of function volumes only and
+// security contexts the platform allows
+func (ap *Platform) validateExtraContainers(ctx context.Context, functionConfig *functionconfig.Config) error {
+	functionVolumeNames := map[string]bool{}
+	for _, configVolume := range functionConfig.Spec.Volumes {
+		functionVolumeNames[configVolume.Volume.Name] = true
+	}
+
+	containerNames := map[string]bool{
+		FunctionContainerName: true,
+	}
+
+	reservedPorts := []int32{
+		FunctionContainerHTTPPort,
+		FunctionContainerWebAdminHTTPPort,
+		FunctionContainerHealthCheckHTTPPort,
+	}
+
+	for _, extraContainers := range []struct {
+		kind       string
+		containers []v1.Container
+	}{
+		{"Sidecar", functionConfig.Spec.Sidecars},
+		{"Init container", functionConfig.Spec.InitContainers},
+	} {
+		for _, container := range extraContainers.containers {
+			if errs := validation.IsDNS1123Label(container.Name); len(errs) > 0 {
+				return nuclio.NewErrBadRequest(fmt.Sprintf("%s name '%s' is invalid: %s",
+					extraContainers.kind,
+					container.Name,
+					strings.Join(errs, ", ")))
+			}
+
+			if containerNames[container.Name] {
+				return nuclio.NewErrBadRequest(fmt.Sprintf("%s name '%s' is already used by another container",
+					extraContainers.kind,
+					container.Name))
+			}
+			containerNames[container.Name] = true
+
+			if container.Image == "" {
+				return nuclio.NewErrBadRequest(fmt.Sprintf("%s '%s' must have an image",
+					extraContainers.kind,
+					container.Name))
+			}
+
+			if _, err := reference.Parse(container.Image); err != nil {
+				ap.Logger.WarnWithCtx(ctx,
+					"Invalid docker image ref passed in extra container - this may be malicious",
+					"err", err,
+					"containerName", container.Name)
+				return nuclio.NewErrBadRequest(fmt.Sprintf("Invalid image passed to %s '%s'",
+					strings.ToLower(extraContainers.kind),
+					container.Name))
+			}
+
+			// containers share the pod network namespace
+			for _, port := range container.Ports {
+				for _, reservedPort := range reservedPorts {
+					if port.ContainerPort == reservedPort {
+						return nuclio.NewErrBadRequest(fmt.Sprintf("%s '%s' cannot use port %d, which is reserved for the processor",
+							extraContainers.kind,
+							container.Name,
+							reservedPort))
+					}
+				}
+			}
+
+			// volumes are declared once on the function and shared between all of its containers
+			for _, volumeMount := range container.VolumeMounts {
+				if !functionVolumeNames[volumeMount.Name] {
+					return nuclio.NewErrBadRequest(fmt.Sprintf("%s '%s' mounts volume '%s', which is not a function volume",
+						extraContainers.kind,
+						container.Name,
+						volumeMount.Name))
+				}
+			}
+
+			if err := ap.validateExtraContainerSecurityContext(functionConfig, container); err != nil {
+				return nuclio.NewErrBadRequest(fmt.Sprintf("%s '%s' security context is not allowed: %s",
+					extraContainers.kind,
+					container.Name,
+					err.Error()))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ap *Platform) validateExtraContainerSecurityContext(functionConfig *functionconfig.Config,
+	container v1.Container) error {
+	securityContext := container.SecurityContext
+	if securityContext == nil {
+		return nil
+	}
+
+	if !ap.Config.Kube.AllowPrivilegedFunctionContainers {
+		if securityContext.Privileged != nil && *securityContext.Privileged {
+			return errors.New("Privileged containers are not allowed")
+		}
+
+		if securityContext.AllowPrivilegeEscalation != nil && *securityContext.AllowPrivilegeEscalation {
+			return errors.New("Privilege escalation is not allowed")
+		}
+
+		if securityContext.Capabilities != nil && len(securityContext.Capabilities.Add) > 0 {
+			return errors.New("Adding capabilities is not allowed")
+		}
+	}
+
+	// containers may not loosen the function's pod security context
+	podSecurityContext := functionConfig.Spec.SecurityContext
+	if podSecurityContext != nil && podSecurityContext.RunAsNonRoot != nil && *podSecurityContext.RunAsNonRoot {
+		if securityContext.RunAsNonRoot != nil && !*securityContext.RunAsNonRoot {
+			return errors.New("The function runs as non root")
+		}
+
+		if securityContext.RunAsUser != nil && *securityContext.RunAsUser == 0 {
+			return errors.New("The function runs as non root, but the container runs as root")
+		}
+	}
+
+	return nil
+}
+
 func (ap *Platform) validateProjectExists(ctx context.Context, functionConfig *functionconfig.Config) error {
 
 	// validate the project exists
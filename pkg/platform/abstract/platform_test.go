@@ -1683,6 +1683,220 @@ func (suite *AbstractPlatformTestSuite) TestValidateFunctionConfigAutoScaleMetri
 	}
 }
 
+func (suite *AbstractPlatformTestSuite) TestValidateFunctionConfigExtraContainers() {
+	sharedVolume := functionconfig.Volume{
+		Volume: v1.Volume{
+			Name: "shared",
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{},
+			},
+		},
+		VolumeMount: v1.VolumeMount{
+			Name:      "shared",
+			MountPath: "/shared",
+		},
+	}
+
+	trueValue := true
+	rootUser := int64(0)
+
+	for idx, testCase := range []struct {
+		name                 string
+		sidecars             []v1.Container
+		initContainers       []v1.Container
+		podSecurityContext   *v1.PodSecurityContext
+		allowPrivileged      bool
+		shouldFailValidation bool
+	}{
+
+		// happy flows
+		{
+			name: "Sanity",
+		},
+		{
+			name: "PrivilegedAllowedByPlatform",
+			sidecars: []v1.Container{
+				{
+					Name:            "helper",
+					Image:           "busybox",
+					SecurityContext: &v1.SecurityContext{Privileged: &trueValue},
+				},
+			},
+			allowPrivileged: true,
+		},
+		{
+			name: "SharedVolume",
+			sidecars: []v1.Container{
+				{
+					Name:  "log-shipper",
+					Image: "fluent/fluent-bit:2.1",
+					Ports: []v1.ContainerPort{{ContainerPort: 2020}},
+					VolumeMounts: []v1.VolumeMount{
+						{Name: "shared", MountPath: "/var/log/function"},
+					},
+				},
+			},
+			initContainers: []v1.Container{
+				{
+					Name:  "fetch-model",
+					Image: "busybox",
+					VolumeMounts: []v1.VolumeMount{
+						{Name: "shared", MountPath: "/model"},
+					},
+				},
+			},
+		},
+
+		// bad flows
+		{
+			name: "ProcessorContainerName",
+			sidecars: []v1.Container{
+				{Name: FunctionContainerName, Image: "busybox"},
+			},
+			shouldFailValidation: true,
+		},
+		{
+			name: "DuplicateName",
+			sidecars: []v1.Container{
+				{Name: "helper", Image: "busybox"},
+			},
+			initContainers: []v1.Container{
+				{Name: "helper", Image: "busybox"},
+			},
+			shouldFailValidation: true,
+		},
+		{
+			name: "InvalidName",
+			sidecars: []v1.Container{
+				{Name: "Helper_1", Image: "busybox"},
+			},
+			shouldFailValidation: true,
+		},
+		{
+			name: "NoImage",
+			initContainers: []v1.Container{
+				{Name: "helper"},
+			},
+			shouldFailValidation: true,
+		},
+		{
+			name: "InvalidImage",
+			sidecars: []v1.Container{
+				{Name: "helper", Image: "busybox;rm -rf /"},
+			},
+			shouldFailValidation: true,
+		},
+		{
+			name: "ReservedPort",
+			sidecars: []v1.Container{
+				{
+					Name:  "proxy",
+					Image: "envoyproxy/envoy",
+					Ports: []v1.ContainerPort{{ContainerPort: FunctionContainerHTTPPort}},
+				},
+			},
+			shouldFailValidation: true,
+		},
+		{
+			name: "Privileged",
+			sidecars: []v1.Container{
+				{
+					Name:            "helper",
+					Image:           "busybox",
+					SecurityContext: &v1.SecurityContext{Privileged: &trueValue},
+				},
+			},
+			shouldFailValidation: true,
+		},
+		{
+			name: "PrivilegeEscalation",
+			initContainers: []v1.Container{
+				{
+					Name:            "helper",
+					Image:           "busybox",
+					SecurityContext: &v1.SecurityContext{AllowPrivilegeEscalation: &trueValue},
+				},
+			},
+			shouldFailValidation: true,
+		},
+		{
+			name: "AddedCapabilities",
+			sidecars: []v1.Container{
+				{
+					Name:  "helper",
+					Image: "busybox",
+					SecurityContext: &v1.SecurityContext{
+						Capabilities: &v1.Capabilities{Add: []v1.Capability{"NET_ADMIN"}},
+					},
+				},
+			},
+			shouldFailValidation: true,
+		},
+		{
+			name: "RootInNonRootFunction",
+			sidecars: []v1.Container{
+				{
+					Name:            "helper",
+					Image:           "busybox",
+					SecurityContext: &v1.SecurityContext{RunAsUser: &rootUser},
+				},
+			},
+			podSecurityContext:   &v1.PodSecurityContext{RunAsNonRoot: &trueValue},
+			allowPrivileged:      true,
+			shouldFailValidation: true,
+		},
+		{
+			name: "UnknownVolume",
+			sidecars: []v1.Container{
+				{
+					Name:  "helper",
+					Image: "busybox",
+					VolumeMounts: []v1.VolumeMount{
+						{Name: "not-a-function-volume", MountPath: "/data"},
+					},
+				},
+			},
+			shouldFailValidation: true,
+		},
+	} {
+		suite.Run(testCase.name, func() {
+			suite.mockedPlatform.On("GetProjects", suite.ctx, &platform.GetProjectsOptions{
+				Meta: platform.ProjectMeta{
+					Name:      platform.DefaultProjectName,
+					Namespace: "default",
+				},
+			}).Return([]platform.Project{
+				&platform.AbstractProject{},
+			}, nil).Once()
+
+			functionConfig := *functionconfig.NewConfig()
+			functionConfig.Meta.Name = fmt.Sprintf("extra-containers-%d", idx)
+			functionConfig.Meta.Labels = map[string]string{
+				"nuclio.io/project-name": platform.DefaultProjectName,
+			}
+			functionConfig.Spec.Volumes = []functionconfig.Volume{sharedVolume}
+			functionConfig.Spec.Sidecars = testCase.sidecars
+			functionConfig.Spec.InitContainers = testCase.initContainers
+			functionConfig.Spec.SecurityContext = testCase.podSecurityContext
+
+			suite.Platform.Config.Kube.AllowPrivilegedFunctionContainers = testCase.allowPrivileged
+			defer func() {
+				suite.Platform.Config.Kube.AllowPrivilegedFunctionContainers = false
+			}()
+
+			err := suite.Platform.EnrichFunctionConfig(suite.ctx, &functionConfig)
+			suite.Require().NoError(err, "Failed to enrich function")
+
+			err = suite.Platform.ValidateFunctionConfig(suite.ctx, &functionConfig)
+			if testCase.shouldFailValidation {
+				suite.Require().Error(err, "Validation passed unexpectedly")
+			} else {
+				suite.Require().NoError(err, "Validation failed unexpectedly")
+			}
+		})
+	}
+}
+
 // Test that GetProcessorLogs() generates the expected formattedPodLogs and briefErrorsMessage
 // Expects 3 files inside functionLogsFilePath: (kept in these constants)
 // - FunctionLogsFile
@@ -0,0 +1,363 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package platform
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+)
+
+type DependencyGraphNodeKind string
+
+const (
+	DependencyGraphNodeKindFunction         DependencyGraphNodeKind = "function"
+	DependencyGraphNodeKindAPIGateway       DependencyGraphNodeKind = "apiGateway"
+	DependencyGraphNodeKindFunctionEvent    DependencyGraphNodeKind = "functionEvent"
+	DependencyGraphNodeKindKafkaTopic       DependencyGraphNodeKind = "kafkaTopic"
+	DependencyGraphNodeKindNATSSubject      DependencyGraphNodeKind = "natsSubject"
+	DependencyGraphNodeKindRabbitMQExchange DependencyGraphNodeKind = "rabbitMQExchange"
+)
+
+type DependencyGraphEdgeKind string
+
+const (
+
+	// a channel feeds a function through one of its triggers
+	DependencyGraphEdgeKindTrigger DependencyGraphEdgeKind = "trigger"
+
+	// a function publishes to a channel (or another function) through one of its output bindings
+	DependencyGraphEdgeKindOutputBinding DependencyGraphEdgeKind = "outputBinding"
+
+	// a function invokes another function it may call
+	DependencyGraphEdgeKindInvocation DependencyGraphEdgeKind = "invocation"
+
+	// an api gateway routes traffic to a function
+	DependencyGraphEdgeKindUpstream DependencyGraphEdgeKind = "upstream"
+
+	// a function event is used to invoke a function
+	DependencyGraphEdgeKindFunctionEvent DependencyGraphEdgeKind = "functionEvent"
+)
+
+// DependencyGraphNode is a resource (or a channel resources communicate through) in a dependency graph
+type DependencyGraphNode struct {
+	ID      string                  `json:"id"`
+	Kind    DependencyGraphNodeKind `json:"kind"`
+	Name    string                  `json:"name"`
+	Project string                  `json:"project,omitempty"`
+}
+
+// DependencyGraphEdge points in the direction the events flow, from the node sending them to the node receiving them
+type DependencyGraphEdge struct {
+	From string                  `json:"from"`
+	To   string                  `json:"to"`
+	Kind DependencyGraphEdgeKind `json:"kind"`
+
+	// the trigger / output binding the edge was derived from
+	Name string `json:"name,omitempty"`
+}
+
+// DependencyGraph is derived from the configuration of functions, api gateways and function events. functions
+// are connected through the channels they consume and publish to, which are identified by name alone (e.g. the
+// same kafka topic on different clusters is considered the same channel)
+type DependencyGraph struct {
+	Nodes []*DependencyGraphNode `json:"nodes"`
+	Edges []*DependencyGraphEdge `json:"edges"`
+
+	nodesByID map[string]*DependencyGraphNode
+}
+
+// FunctionDependent is a resource that would be affected by deleting a function
+type FunctionDependent struct {
+	Kind DependencyGraphNodeKind `json:"kind"`
+	Name string                  `json:"name"`
+
+	// the channel through which the dependent receives the function's output, if any
+	Via string `json:"via,omitempty"`
+}
+
+func (fd FunctionDependent) String() string {
+	if fd.Via != "" {
+		return fmt.Sprintf("%s %s (via %s)", fd.Kind, fd.Name, fd.Via)
+	}
+
+	return fmt.Sprintf("%s %s", fd.Kind, fd.Name)
+}
+
+// NewDependencyGraph builds the dependency graph of the given resources
+func NewDependencyGraph(functionConfigs []*functionconfig.Config,
+	apiGatewayConfigs []*APIGatewayConfig,
+	functionEventConfigs []*FunctionEventConfig) *DependencyGraph {
+
+	graph := &DependencyGraph{
+		Nodes:     []*DependencyGraphNode{},
+		Edges:     []*DependencyGraphEdge{},
+		nodesByID: map[string]*DependencyGraphNode{},
+	}
+
+	for _, functionConfig := range functionConfigs {
+		graph.addNode(DependencyGraphNodeKindFunction,
+			functionConfig.Meta.Name,
+			functionConfig.Meta.Labels[common.NuclioResourceLabelKeyProjectName])
+	}
+
+	for _, functionConfig := range functionConfigs {
+		graph.addFunctionEdges(functionConfig)
+	}
+
+	for _, apiGatewayConfig := range apiGatewayConfigs {
+		apiGatewayNodeID := graph.addNode(DependencyGraphNodeKindAPIGateway,
+			apiGatewayConfig.Meta.Name,
+			apiGatewayConfig.Meta.Labels[common.NuclioResourceLabelKeyProjectName])
+
+		for _, upstream := range apiGatewayConfig.Spec.Upstreams {
+			if upstream.Kind != APIGatewayUpstreamKindNuclioFunction || upstream.NuclioFunction == nil {
+				continue
+			}
+
+			graph.addEdge(apiGatewayNodeID,
+				graph.addNode(DependencyGraphNodeKindFunction, upstream.NuclioFunction.Name, ""),
+				DependencyGraphEdgeKindUpstream,
+				"")
+		}
+	}
+
+	for _, functionEventConfig := range functionEventConfigs {
+		functionName := functionEventConfig.Meta.Labels[common.NuclioResourceLabelKeyFunctionName]
+		if functionName == "" {
+			continue
+		}
+
+		graph.addEdge(graph.addNode(DependencyGraphNodeKindFunctionEvent,
+			functionEventConfig.Meta.Name,
+			functionEventConfig.Meta.Labels[common.NuclioResourceLabelKeyProjectName]),
+			graph.addNode(DependencyGraphNodeKindFunction, functionName, ""),
+			DependencyGraphEdgeKindFunctionEvent,
+			functionEventConfig.Spec.TriggerName)
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].ID < graph.Nodes[j].ID
+	})
+
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		if graph.Edges[i].To != graph.Edges[j].To {
+			return graph.Edges[i].To < graph.Edges[j].To
+		}
+		return graph.Edges[i].Name < graph.Edges[j].Name
+	})
+
+	return graph
+}
+
+// GetFunctionDependents returns the resources that route to, invoke or consume the output of the given function.
+// function events are not dependents, since they belong to the function
+func (dg *DependencyGraph) GetFunctionDependents(functionName string) []FunctionDependent {
+	functionNodeID := dependencyGraphNodeID(DependencyGraphNodeKindFunction, functionName)
+	dependents := map[FunctionDependent]bool{}
+
+	for _, edge := range dg.Edges {
+		if edge.To == functionNodeID && edge.From != functionNodeID && edge.Kind != DependencyGraphEdgeKindFunctionEvent {
+			dependentNode := dg.nodesByID[edge.From]
+			dependents[FunctionDependent{Kind: dependentNode.Kind, Name: dependentNode.Name}] = true
+		}
+
+		// consumers of the channels the function publishes to
+		if edge.From == functionNodeID && edge.Kind == DependencyGraphEdgeKindOutputBinding {
+			channelNode := dg.nodesByID[edge.To]
+			if channelNode.Kind == DependencyGraphNodeKindFunction {
+				continue
+			}
+
+			for _, channelEdge := range dg.Edges {
+				if channelEdge.From == channelNode.ID && channelEdge.To != functionNodeID {
+					consumerNode := dg.nodesByID[channelEdge.To]
+					dependents[FunctionDependent{
+						Kind: consumerNode.Kind,
+						Name: consumerNode.Name,
+						Via:  channelNode.ID,
+					}] = true
+				}
+			}
+		}
+	}
+
+	var sortedDependents []FunctionDependent
+	for dependent := range dependents {
+		sortedDependents = append(sortedDependents, dependent)
+	}
+
+	sort.Slice(sortedDependents, func(i, j int) bool {
+		return sortedDependents[i].String() < sortedDependents[j].String()
+	})
+
+	return sortedDependents
+}
+
+func (dg *DependencyGraph) addFunctionEdges(functionConfig *functionconfig.Config) {
+	functionNodeID := dependencyGraphNodeID(DependencyGraphNodeKindFunction, functionConfig.Meta.Name)
+
+	for triggerName, trigger := range functionConfig.Spec.Triggers {
+		if trigger.Disabled {
+			continue
+		}
+
+		for _, channel := range getTriggerChannels(trigger) {
+			dg.addEdge(dg.addNode(channel.kind, channel.name, ""),
+				functionNodeID,
+				DependencyGraphEdgeKindTrigger,
+				triggerName)
+		}
+	}
+
+	for outputBindingName, outputBinding := range functionConfig.Spec.OutputBindings {
+		if channel := getOutputBindingChannel(outputBinding); channel != nil {
+			dg.addEdge(functionNodeID,
+				dg.addNode(channel.kind, channel.name, ""),
+				DependencyGraphEdgeKindOutputBinding,
+				outputBindingName)
+		}
+	}
+
+	for _, callableFunction := range functionConfig.Spec.CallableFunctions {
+		dg.addEdge(functionNodeID,
+			dg.addNode(DependencyGraphNodeKindFunction, callableFunction.Name, callableFunction.Project),
+			DependencyGraphEdgeKindInvocation,
+			"")
+	}
+}
+
+// addNode adds a node unless it already exists, and returns its id
+func (dg *DependencyGraph) addNode(kind DependencyGraphNodeKind, name string, project string) string {
+	nodeID := dependencyGraphNodeID(kind, name)
+
+	if node, nodeFound := dg.nodesByID[nodeID]; nodeFound {
+		if node.Project == "" {
+			node.Project = project
+		}
+		return nodeID
+	}
+
+	node := &DependencyGraphNode{
+		ID:      nodeID,
+		Kind:    kind,
+		Name:    name,
+		Project: project,
+	}
+
+	dg.Nodes = append(dg.Nodes, node)
+	dg.nodesByID[nodeID] = node
+
+	return nodeID
+}
+
+func (dg *DependencyGraph) addEdge(from string, to string, kind DependencyGraphEdgeKind, name string) {
+	dg.Edges = append(dg.Edges, &DependencyGraphEdge{
+		From: from,
+		To:   to,
+		Kind: kind,
+		Name: name,
+	})
+}
+
+type dependencyGraphChannel struct {
+	kind DependencyGraphNodeKind
+	name string
+}
+
+func getTriggerChannels(trigger functionconfig.Trigger) []dependencyGraphChannel {
+	var channels []dependencyGraphChannel
+
+	switch trigger.Kind {
+	case "kafka-cluster", "kafka":
+		for _, topic := range getStringSliceAttribute(trigger.Attributes, "topics") {
+			channels = append(channels, dependencyGraphChannel{kind: DependencyGraphNodeKindKafkaTopic, name: topic})
+		}
+	case "nats":
+		if topic := getStringAttribute(trigger.Attributes, "topic"); topic != "" {
+			channels = append(channels, dependencyGraphChannel{kind: DependencyGraphNodeKindNATSSubject, name: topic})
+		}
+	case "rabbit-mq", "rabbitMq":
+		if exchangeName := getStringAttribute(trigger.Attributes, "exchangeName"); exchangeName != "" {
+			channels = append(channels, dependencyGraphChannel{
+				kind: DependencyGraphNodeKindRabbitMQExchange,
+				name: exchangeName,
+			})
+		}
+	}
+
+	return channels
+}
+
+func getOutputBindingChannel(outputBinding functionconfig.OutputBinding) *dependencyGraphChannel {
+	switch outputBinding.Kind {
+	case "kafka":
+		return &dependencyGraphChannel{kind: DependencyGraphNodeKindKafkaTopic, name: outputBinding.Target}
+	case "nats":
+		return &dependencyGraphChannel{kind: DependencyGraphNodeKindNATSSubject, name: outputBinding.Target}
+	case "rabbit-mq":
+		return &dependencyGraphChannel{kind: DependencyGraphNodeKindRabbitMQExchange, name: outputBinding.Target}
+	case "http":
+
+		// publishing to the service of another function (e.g. http://nuclio-other.default.svc:8080)
+		parsedURL, err := url.Parse(outputBinding.URL)
+		if err != nil {
+			return nil
+		}
+
+		serviceName := strings.Split(parsedURL.Hostname(), ".")[0]
+		if functionName := strings.TrimPrefix(serviceName, "nuclio-"); functionName != serviceName && functionName != "" {
+			return &dependencyGraphChannel{kind: DependencyGraphNodeKindFunction, name: functionName}
+		}
+	}
+
+	return nil
+}
+
+func getStringAttribute(attributes map[string]interface{}, key string) string {
+	value, _ := attributes[key].(string)
+	return value
+}
+
+// getStringSliceAttribute handles both typed slices and slices decoded from json / yaml
+func getStringSliceAttribute(attributes map[string]interface{}, key string) []string {
+	switch typedValue := attributes[key].(type) {
+	case []string:
+		return typedValue
+	case []interface{}:
+		var values []string
+		for _, item := range typedValue {
+			if stringItem, isString := item.(string); isString {
+				values = append(values, stringItem)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
+
+func dependencyGraphNodeID(kind DependencyGraphNodeKind, name string) string {
+	return fmt.Sprintf("%s/%s", kind, name)
+}
@@ -0,0 +1,167 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package platform
+
+import (
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DependencyGraphTestSuite struct {
+	suite.Suite
+}
+
+func (suite *DependencyGraphTestSuite) TestNewDependencyGraph() {
+	graph := suite.createDependencyGraph()
+
+	suite.Require().Equal([]string{
+		"apiGateway/gateway",
+		"function/auditor",
+		"function/consumer",
+		"function/enricher",
+		"function/producer",
+		"function/unrelated",
+		"functionEvent/sample-order",
+		"kafkaTopic/enriched",
+		"kafkaTopic/orders",
+		"natsSubject/alerts",
+	}, suite.getNodeIDs(graph))
+
+	var encodedEdges []string
+	for _, edge := range graph.Edges {
+		encodedEdges = append(encodedEdges, edge.From+" -"+string(edge.Kind)+"-> "+edge.To)
+	}
+
+	suite.Require().Equal([]string{
+		"apiGateway/gateway -upstream-> function/producer",
+		"function/consumer -invocation-> function/enricher",
+		"function/consumer -outputBinding-> natsSubject/alerts",
+		"function/producer -outputBinding-> function/auditor",
+		"function/producer -outputBinding-> kafkaTopic/orders",
+		"functionEvent/sample-order -functionEvent-> function/producer",
+		"kafkaTopic/enriched -trigger-> function/auditor",
+		"kafkaTopic/orders -trigger-> function/consumer",
+	}, encodedEdges)
+}
+
+func (suite *DependencyGraphTestSuite) TestGetFunctionDependents() {
+	graph := suite.createDependencyGraph()
+
+	var encodedDependents []string
+	for _, dependent := range graph.GetFunctionDependents("producer") {
+		encodedDependents = append(encodedDependents, dependent.String())
+	}
+
+	// the function event belongs to the function, so it isn't a dependent
+	suite.Require().Equal([]string{
+		"apiGateway gateway",
+		"function consumer (via kafkaTopic/orders)",
+	}, encodedDependents)
+
+	suite.Require().Equal([]FunctionDependent{
+		{Kind: DependencyGraphNodeKindFunction, Name: "consumer"},
+	}, graph.GetFunctionDependents("enricher"))
+
+	suite.Require().Empty(graph.GetFunctionDependents("unrelated"))
+}
+
+func (suite *DependencyGraphTestSuite) createDependencyGraph() *DependencyGraph {
+	producer := suite.createFunctionConfig("producer")
+	producer.Spec.OutputBindings = map[string]functionconfig.OutputBinding{
+		"orders": {Kind: "kafka", URL: "kafka:9092", Target: "orders"},
+		"audit":  {Kind: "http", URL: "http://nuclio-auditor.default.svc:8080"},
+	}
+
+	consumer := suite.createFunctionConfig("consumer")
+	consumer.Spec.Triggers = map[string]functionconfig.Trigger{
+		"orders": {
+			Kind:       "kafka-cluster",
+			Attributes: map[string]interface{}{"topics": []interface{}{"orders"}},
+		},
+		"disabled": {
+			Kind:       "nats",
+			Disabled:   true,
+			Attributes: map[string]interface{}{"topic": "ignored"},
+		},
+	}
+	consumer.Spec.OutputBindings = map[string]functionconfig.OutputBinding{
+		"alerts": {Kind: "nats", URL: "nats://nats:4222", Target: "alerts"},
+	}
+	consumer.Spec.CallableFunctions = []functionconfig.FunctionReference{{Name: "enricher"}}
+
+	auditor := suite.createFunctionConfig("auditor")
+	auditor.Spec.Triggers = map[string]functionconfig.Trigger{
+		"enriched": {
+			Kind:       "kafka-cluster",
+			Attributes: map[string]interface{}{"topics": []string{"enriched"}},
+		},
+	}
+
+	apiGateway := &APIGatewayConfig{
+		Meta: APIGatewayMeta{Name: "gateway"},
+		Spec: APIGatewaySpec{
+			Upstreams: []APIGatewayUpstreamSpec{
+				{
+					Kind:           APIGatewayUpstreamKindNuclioFunction,
+					NuclioFunction: &NuclioFunctionAPIGatewaySpec{Name: "producer"},
+				},
+			},
+		},
+	}
+
+	functionEvent := &FunctionEventConfig{
+		Meta: FunctionEventMeta{
+			Name:   "sample-order",
+			Labels: map[string]string{common.NuclioResourceLabelKeyFunctionName: "producer"},
+		},
+	}
+
+	return NewDependencyGraph([]*functionconfig.Config{
+		producer,
+		consumer,
+		auditor,
+		suite.createFunctionConfig("enricher"),
+		suite.createFunctionConfig("unrelated"),
+	},
+		[]*APIGatewayConfig{apiGateway},
+		[]*FunctionEventConfig{functionEvent})
+}
+
+func (suite *DependencyGraphTestSuite) createFunctionConfig(name string) *functionconfig.Config {
+	functionConfig := functionconfig.NewConfig()
+	functionConfig.Meta.Name = name
+	functionConfig.Meta.Labels = map[string]string{common.NuclioResourceLabelKeyProjectName: "default"}
+	return functionConfig
+}
+
+func (suite *DependencyGraphTestSuite) getNodeIDs(graph *DependencyGraph) []string {
+	var nodeIDs []string
+	for _, node := range graph.Nodes {
+		nodeIDs = append(nodeIDs, node.ID)
+	}
+	return nodeIDs
+}
+
+func TestDependencyGraphTestSuite(t *testing.T) {
+	suite.Run(t, new(DependencyGraphTestSuite))
+}
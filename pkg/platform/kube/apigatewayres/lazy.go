@@ -29,7 +29,9 @@ import (
 	"github.com/nuclio/nuclio/pkg/platform/kube"
 	nuclioio "github.com/nuclio/nuclio/pkg/platform/kube/apis/nuclio.io/v1beta1"
 	nuclioio_client "github.com/nuclio/nuclio/pkg/platform/kube/client/clientset/versioned"
+	"github.com/nuclio/nuclio/pkg/platform/kube/gatewayapi"
 	"github.com/nuclio/nuclio/pkg/platform/kube/ingress"
+	"github.com/nuclio/nuclio/pkg/platformconfig"
 
 	"github.com/nuclio/errors"
 	"github.com/nuclio/logger"
@@ -43,22 +45,28 @@ import (
 //
 
 type lazyClient struct {
-	logger          logger.Logger
-	kubeClientSet   kubernetes.Interface
-	nuclioClientSet nuclioio_client.Interface
-	ingressManager  *ingress.Manager
+	logger                logger.Logger
+	kubeClientSet         kubernetes.Interface
+	nuclioClientSet       nuclioio_client.Interface
+	ingressManager        *ingress.Manager
+	httpRouteManager      *gatewayapi.Manager
+	platformConfiguration *platformconfig.Config
 }
 
 func NewLazyClient(loggerInstance logger.Logger,
 	kubeClientSet kubernetes.Interface,
 	nuclioClientSet nuclioio_client.Interface,
-	ingressManager *ingress.Manager) (Client, error) {
+	ingressManager *ingress.Manager,
+	httpRouteManager *gatewayapi.Manager,
+	platformConfiguration *platformconfig.Config) (Client, error) {
 
 	newClient := lazyClient{
-		logger:          loggerInstance.GetChild("apigatewayres"),
-		kubeClientSet:   kubeClientSet,
-		nuclioClientSet: nuclioClientSet,
-		ingressManager:  ingressManager,
+		logger:                loggerInstance.GetChild("apigatewayres"),
+		kubeClientSet:         kubeClientSet,
+		nuclioClientSet:       nuclioClientSet,
+		ingressManager:        ingressManager,
+		httpRouteManager:      httpRouteManager,
+		platformConfiguration: platformConfiguration,
 	}
 
 	return &newClient, nil
@@ -79,6 +87,17 @@ func (lc *lazyClient) CreateOrUpdate(ctx context.Context, apiGateway *nuclioio.N
 		return nil, errors.Wrap(err, "Api gateway spec validation failed")
 	}
 
+	if lc.exposedByGatewayAPI() {
+		return lc.createOrUpdateHTTPRoute(ctx, apiGateway)
+	}
+
+	if len(apiGateway.Spec.MatchHeaders) > 0 {
+		return nil, errors.New("Header matches are only supported when exposing api gateways with the gateway API")
+	}
+
+	// the api gateway may have been exposed with the gateway API before
+	lc.tryRemoveHTTPRoute(ctx, apiGateway.Namespace, apiGateway.Name)
+
 	// always try to remove previous canary ingress first, because
 	// nginx returns 503 on all requests if primary service == secondary service. (happens on every promotion)
 	// so during promotion all requests will be sent to the primary ingress
@@ -138,6 +157,12 @@ func (lc *lazyClient) CreateOrUpdate(ctx context.Context, apiGateway *nuclioio.N
 }
 
 func (lc *lazyClient) WaitAvailable(ctx context.Context, namespace string, name string) {
+
+	// routes are reconciled by the gateway implementation, there's no nginx to wait for
+	if lc.exposedByGatewayAPI() {
+		return
+	}
+
 	lc.logger.DebugWithCtx(ctx, "Sleeping for 4 seconds so nginx controller will stabilize")
 
 	// sleep 4 seconds as a safety, so nginx will finish updating the ingresses properly (it takes time)
@@ -163,6 +188,195 @@ func (lc *lazyClient) Delete(ctx context.Context, namespace string, name string)
 		lc.logger.WarnWithCtx(ctx, "Failed to delete canary ingress. Continuing with deletion",
 			"err", errors.Cause(err).Error())
 	}
+
+	lc.tryRemoveHTTPRoute(ctx, namespace, name)
+}
+
+func (lc *lazyClient) exposedByGatewayAPI() bool {
+	return lc.platformConfiguration != nil &&
+		lc.platformConfiguration.Kube.Exposure.Kind == platformconfig.ExposureKindGatewayAPI
+}
+
+func (lc *lazyClient) createOrUpdateHTTPRoute(ctx context.Context,
+	apiGateway *nuclioio.NuclioAPIGateway) (Resources, error) {
+
+	if lc.httpRouteManager == nil {
+		return nil, errors.New("Gateway API exposure is configured but no route manager is available")
+	}
+
+	primaryUpstream, canaryUpstream, err := lc.resolveBaseAndCanaryUpstreamsFromSpec(apiGateway.Spec.Upstreams)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to resolve base and canary upstreams")
+	}
+
+	httpRoute, err := lc.generateHTTPRoute(apiGateway, primaryUpstream, canaryUpstream)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to generate route")
+	}
+
+	appliedHTTPRoute, err := lc.httpRouteManager.CreateOrUpdate(ctx, httpRoute)
+	if err != nil {
+		lc.logger.WarnWithCtx(ctx, "Failed to create/update api gateway route",
+			"err", errors.Cause(err),
+			"routeName", httpRoute.Name)
+		return nil, errors.New("Failed to create/update api gateway route")
+	}
+
+	// the api gateway may have been exposed with ingresses before
+	for _, canary := range []bool{false, true} {
+		if err := lc.ingressManager.DeleteByName(ctx,
+			kube.IngressNameFromAPIGatewayName(apiGateway.Name, canary),
+			apiGateway.Namespace,
+			true); err != nil {
+			lc.logger.WarnWithCtx(ctx, "Failed to delete api gateway ingress replaced by route",
+				"apiGatewayName", apiGateway.Name,
+				"canary", canary,
+				"err", errors.Cause(err))
+		}
+	}
+
+	return &lazyResources{
+		ingressResourcesMap: map[string]*ingress.Resources{},
+		httpRoute:           appliedHTTPRoute,
+	}, nil
+}
+
+// generateHTTPRoute renders a single route for the api gateway - the canary upstream is a weighted backend
+// of the same rule rather than a separate resource
+func (lc *lazyClient) generateHTTPRoute(apiGateway *nuclioio.NuclioAPIGateway,
+	primaryUpstream *platform.APIGatewayUpstreamSpec,
+	canaryUpstream *platform.APIGatewayUpstreamSpec) (*gatewayapi.HTTPRoute, error) {
+
+	gatewayAPIConfig := lc.platformConfiguration.Kube.Exposure.GatewayAPI
+
+	// add "/" as path prefix if not already there
+	if !strings.HasPrefix(apiGateway.Spec.Path, "/") {
+		apiGateway.Spec.Path = fmt.Sprintf("/%s", apiGateway.Spec.Path)
+	}
+
+	ruleSpec := gatewayapi.RuleSpec{
+		Path:          apiGateway.Spec.Path,
+		PathType:      gatewayapi.PathMatchTypePathPrefix,
+		MatchHeaders:  apiGateway.Spec.MatchHeaders,
+		RewriteTarget: primaryUpstream.RewriteTarget,
+		RequestHeaders: map[string]string{
+			"X-Nuclio-Target": primaryUpstream.NuclioFunction.Name,
+		},
+	}
+
+	switch apiGateway.Spec.AuthenticationMode {
+	case "", ingress.AuthenticationModeNone:
+	case ingress.AuthenticationModeBasicAuth,
+		ingress.AuthenticationModeOauth2,
+		ingress.AuthenticationModeAccessKey:
+
+		// authentication is implementation specific in the gateway API
+		authenticationFilter, found := gatewayAPIConfig.AuthenticationFilters[string(apiGateway.Spec.AuthenticationMode)]
+		if !found {
+			return nil, errors.Errorf("No gateway API filter is configured for authentication mode %s",
+				apiGateway.Spec.AuthenticationMode)
+		}
+
+		ruleSpec.Filters = append(ruleSpec.Filters, gatewayapi.HTTPRouteFilter{
+			Type:         gatewayapi.FilterTypeExtensionRef,
+			ExtensionRef: &authenticationFilter,
+		})
+	default:
+		return nil, errors.New("Unsupported ApiGateway authentication mode provided")
+	}
+
+	primaryBackend, err := lc.generateHTTPRouteBackend(primaryUpstream)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to generate primary backend")
+	}
+	ruleSpec.Backends = append(ruleSpec.Backends, *primaryBackend)
+
+	if canaryUpstream != nil {
+
+		// percentage range
+		if canaryUpstream.Percentage > 100 || canaryUpstream.Percentage < 1 {
+			return nil, errors.New("The canary upstream percentage must be between 1 and 100")
+		}
+
+		// both upstreams are served by the same rule
+		if canaryUpstream.RewriteTarget != primaryUpstream.RewriteTarget {
+			return nil, errors.New("The canary and primary upstreams must have the same rewrite target")
+		}
+
+		canaryBackend, err := lc.generateHTTPRouteBackend(canaryUpstream)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to generate canary backend")
+		}
+
+		primaryWeight := int32(100 - canaryUpstream.Percentage)
+		canaryWeight := int32(canaryUpstream.Percentage)
+		primaryBackend.Weight = &primaryWeight
+		canaryBackend.Weight = &canaryWeight
+		ruleSpec.Backends = []gatewayapi.BackendSpec{*primaryBackend, *canaryBackend}
+
+		ruleSpec.RequestHeaders["X-Nuclio-Target"] = fmt.Sprintf("%s,%s",
+			primaryUpstream.NuclioFunction.Name,
+			canaryUpstream.NuclioFunction.Name)
+	}
+
+	routeLabels := map[string]string{}
+	for _, upstream := range []*platform.APIGatewayUpstreamSpec{primaryUpstream, canaryUpstream} {
+		if upstream == nil {
+			continue
+		}
+		for labelKey, labelValue := range upstream.ExtraLabels {
+			routeLabels[labelKey] = labelValue
+		}
+	}
+	routeLabels["nuclio.io/class"] = "apigateway"
+	routeLabels[common.NuclioResourceLabelKeyApiGatewayName] = apiGateway.Name
+	routeLabels[common.NuclioResourceLabelKeyProjectName] = apiGateway.Labels[common.NuclioResourceLabelKeyProjectName]
+
+	routeAnnotations := map[string]string{}
+	for annotationKey, annotationValue := range apiGateway.Annotations {
+		routeAnnotations[annotationKey] = annotationValue
+	}
+	for annotationKey, annotationValue := range primaryUpstream.ExtraAnnotations {
+		routeAnnotations[annotationKey] = annotationValue
+	}
+
+	return lc.httpRouteManager.GenerateHTTPRoute(gatewayapi.Spec{
+		Name:        kube.HTTPRouteNameFromAPIGatewayName(apiGateway.Name),
+		Namespace:   apiGateway.Namespace,
+		Labels:      routeLabels,
+		Annotations: routeAnnotations,
+		ParentRefs:  gatewayAPIConfig.ParentRefs,
+		Hostnames:   []string{apiGateway.Spec.Host},
+		Rules:       []gatewayapi.RuleSpec{ruleSpec},
+	})
+}
+
+func (lc *lazyClient) generateHTTPRouteBackend(upstream *platform.APIGatewayUpstreamSpec) (
+	*gatewayapi.BackendSpec, error) {
+
+	serviceName, servicePort, err := lc.getServiceNameAndPort(upstream)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get service name")
+	}
+
+	return &gatewayapi.BackendSpec{
+		ServiceName: serviceName,
+		ServicePort: servicePort,
+	}, nil
+}
+
+func (lc *lazyClient) tryRemoveHTTPRoute(ctx context.Context, namespace string, name string) {
+	if lc.httpRouteManager == nil {
+		return
+	}
+
+	if err := lc.httpRouteManager.DeleteByName(ctx,
+		kube.HTTPRouteNameFromAPIGatewayName(name),
+		namespace); err != nil {
+		lc.logger.WarnWithCtx(ctx, "Failed to delete api gateway route",
+			"apiGatewayName", name,
+			"err", errors.Cause(err))
+	}
 }
 
 func (lc *lazyClient) tryRemovePreviousCanaryIngress(ctx context.Context, apiGateway *nuclioio.NuclioAPIGateway) {
@@ -396,9 +610,15 @@ func (lc *lazyClient) enrichPrimaryIngressResources(primaryIngressResources *ing
 
 type lazyResources struct {
 	ingressResourcesMap map[string]*ingress.Resources
+	httpRoute           *gatewayapi.HTTPRoute
 }
 
 // Deployment returns the deployment
 func (lr *lazyResources) IngressResourcesMap() map[string]*ingress.Resources {
 	return lr.ingressResourcesMap
 }
+
+// HTTPRoute returns the gateway API route, if the api gateway is exposed with one
+func (lr *lazyResources) HTTPRoute() *gatewayapi.HTTPRoute {
+	return lr.httpRoute
+}
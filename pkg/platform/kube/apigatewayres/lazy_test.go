@@ -28,6 +28,7 @@ import (
 	"github.com/nuclio/nuclio/pkg/platform"
 	nuclioio "github.com/nuclio/nuclio/pkg/platform/kube/apis/nuclio.io/v1beta1"
 	"github.com/nuclio/nuclio/pkg/platform/kube/client/clientset/versioned/fake"
+	"github.com/nuclio/nuclio/pkg/platform/kube/gatewayapi"
 	"github.com/nuclio/nuclio/pkg/platform/kube/ingress"
 	"github.com/nuclio/nuclio/pkg/platformconfig"
 
@@ -35,7 +36,11 @@ import (
 	"github.com/nuclio/zap"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 )
 
@@ -45,24 +50,37 @@ type lazyTestSuite struct {
 	client         Client
 	ingressManager *ingress.Manager
 	mockCmdRunner  *cmdrunner.MockRunner
+	platformConfig *platformconfig.Config
+	dynamicClient  *dynamicfake.FakeDynamicClient
 }
 
 func (suite *lazyTestSuite) SetupTest() {
+	var err error
+
 	suite.logger, _ = nucliozap.NewNuclioZapTest("test")
 
-	platformConfig, err := platformconfig.NewPlatformConfig("")
+	suite.platformConfig, err = platformconfig.NewPlatformConfig("")
 	suite.Require().NoError(err)
 
 	suite.mockCmdRunner = cmdrunner.NewMockRunner()
 
 	kubeClientset := k8sfake.NewSimpleClientset()
-	suite.ingressManager, err = ingress.NewManager(suite.logger, kubeClientset, suite.mockCmdRunner, platformConfig)
+	suite.ingressManager, err = ingress.NewManager(suite.logger, kubeClientset, suite.mockCmdRunner, suite.platformConfig)
+	suite.Require().NoError(err)
+
+	suite.dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			gatewayapi.HTTPRouteGroupVersionResource: "HTTPRouteList",
+		})
+	httpRouteManager, err := gatewayapi.NewManager(suite.logger, suite.dynamicClient)
 	suite.Require().NoError(err)
 
 	suite.client, err = NewLazyClient(suite.logger,
 		kubeClientset,
 		fake.NewSimpleClientset(),
-		suite.ingressManager)
+		suite.ingressManager,
+		httpRouteManager,
+		suite.platformConfig)
 	suite.Require().NoError(err)
 }
 
@@ -125,6 +143,148 @@ func (suite *lazyTestSuite) TestEnsurePrimaryIngressHasXNuclioTargetHeader() {
 		primaryIngressResources.Ingress.Annotations["nginx.ingress.kubernetes.io/configuration-snippet"])
 }
 
+func (suite *lazyTestSuite) TestHTTPRoute() {
+	ctx := context.Background()
+	suite.platformConfig.Kube.Exposure.Kind = platformconfig.ExposureKindGatewayAPI
+	suite.platformConfig.Kube.Exposure.GatewayAPI = platformconfig.GatewayAPIConfig{
+		ParentRefs: []platformconfig.GatewayAPIParentReference{
+			{Name: "nuclio-gateway", Namespace: "gateways", SectionName: "https"},
+		},
+		AuthenticationFilters: map[string]platformconfig.GatewayAPILocalObjectReference{
+			string(ingress.AuthenticationModeBasicAuth): {
+				Group: "gateway.example.com",
+				Kind:  "AuthPolicy",
+				Name:  "basic-auth",
+			},
+		},
+	}
+
+	apiGateway := &nuclioio.NuclioAPIGateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-name",
+			Namespace: "test-namespace",
+			Labels: map[string]string{
+				"nuclio.io/project-name": "test-project",
+			},
+		},
+		Spec: platform.APIGatewaySpec{
+			Host:               "some-host.com",
+			Name:               "test-name",
+			Path:               "api",
+			AuthenticationMode: ingress.AuthenticationModeBasicAuth,
+			MatchHeaders: map[string]string{
+				"X-Tenant": "a",
+			},
+			Upstreams: []platform.APIGatewayUpstreamSpec{
+				{
+					Kind: platform.APIGatewayUpstreamKindNuclioFunction,
+					NuclioFunction: &platform.NuclioFunctionAPIGatewaySpec{
+						Name: "primary-function-name",
+					},
+					RewriteTarget: "/",
+				},
+				{
+					Kind: platform.APIGatewayUpstreamKindNuclioFunction,
+					NuclioFunction: &platform.NuclioFunctionAPIGatewaySpec{
+						Name: "canary-function-name",
+					},
+					RewriteTarget: "/",
+					Percentage:    20,
+				},
+			},
+		},
+	}
+
+	resources, err := suite.client.CreateOrUpdate(ctx, apiGateway)
+	suite.Require().NoError(err)
+	suite.Require().Empty(resources.IngressResourcesMap())
+
+	// read the route back from the cluster
+	object, err := suite.dynamicClient.Resource(gatewayapi.HTTPRouteGroupVersionResource).
+		Namespace("test-namespace").
+		Get(ctx, "nuclio-agw-test-name", metav1.GetOptions{})
+	suite.Require().NoError(err)
+	httpRoute, err := gatewayapi.FromUnstructured(object)
+	suite.Require().NoError(err)
+	suite.Require().Equal(resources.HTTPRoute().Spec, httpRoute.Spec)
+
+	suite.Require().Equal("test-project", httpRoute.Labels["nuclio.io/project-name"])
+	suite.Require().Equal("nuclio-gateway", httpRoute.Spec.ParentRefs[0].Name)
+	suite.Require().Equal([]string{"some-host.com"}, httpRoute.Spec.Hostnames)
+	suite.Require().Len(httpRoute.Spec.Rules, 1)
+
+	rule := httpRoute.Spec.Rules[0]
+	suite.Require().Equal(gatewayapi.HTTPRouteMatch{
+		Path: &gatewayapi.HTTPPathMatch{
+			Type:  gatewayapi.PathMatchTypePathPrefix,
+			Value: "/api",
+		},
+		Headers: []gatewayapi.HTTPHeaderMatch{
+			{Type: gatewayapi.HeaderMatchTypeExact, Name: "X-Tenant", Value: "a"},
+		},
+	}, rule.Matches[0])
+
+	// target header, rewrite and authentication
+	suite.Require().Len(rule.Filters, 3)
+	suite.Require().Equal([]gatewayapi.HTTPHeader{
+		{Name: "X-Nuclio-Target", Value: "primary-function-name,canary-function-name"},
+	}, rule.Filters[0].RequestHeaderModifier.Set)
+	suite.Require().Equal("/", rule.Filters[1].URLRewrite.Path.ReplacePrefixMatch)
+	suite.Require().Equal("basic-auth", rule.Filters[2].ExtensionRef.Name)
+
+	// the canary gets its percentage, the primary gets the rest
+	suite.Require().Len(rule.BackendRefs, 2)
+	suite.Require().Equal("nuclio-primary-function-name", rule.BackendRefs[0].Name)
+	suite.Require().Equal(int32(80), *rule.BackendRefs[0].Weight)
+	suite.Require().Equal("nuclio-canary-function-name", rule.BackendRefs[1].Name)
+	suite.Require().Equal(int32(20), *rule.BackendRefs[1].Weight)
+	suite.Require().Equal(int32(8080), *rule.BackendRefs[1].Port)
+
+	// promoting the canary leaves a single unweighted backend
+	apiGateway.Spec.Upstreams = apiGateway.Spec.Upstreams[:1]
+	resources, err = suite.client.CreateOrUpdate(ctx, apiGateway)
+	suite.Require().NoError(err)
+	suite.Require().Len(resources.HTTPRoute().Spec.Rules[0].BackendRefs, 1)
+	suite.Require().Nil(resources.HTTPRoute().Spec.Rules[0].BackendRefs[0].Weight)
+
+	// authentication modes must have a configured filter
+	apiGateway.Spec.AuthenticationMode = ingress.AuthenticationModeOauth2
+	_, err = suite.client.CreateOrUpdate(ctx, apiGateway)
+	suite.Require().Error(err)
+
+	suite.client.Delete(ctx, "test-namespace", "test-name")
+	_, err = suite.dynamicClient.Resource(gatewayapi.HTTPRouteGroupVersionResource).
+		Namespace("test-namespace").
+		Get(ctx, "nuclio-agw-test-name", metav1.GetOptions{})
+	suite.Require().True(apierrors.IsNotFound(err))
+}
+
+func (suite *lazyTestSuite) TestMatchHeadersRequireGatewayAPI() {
+	_, err := suite.client.CreateOrUpdate(context.Background(), &nuclioio.NuclioAPIGateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-name",
+			Namespace: "test-namespace",
+		},
+		Spec: platform.APIGatewaySpec{
+			Host:               "some-host.com",
+			Name:               "test-name",
+			AuthenticationMode: ingress.AuthenticationModeNone,
+			MatchHeaders: map[string]string{
+				"X-Tenant": "a",
+			},
+			Upstreams: []platform.APIGatewayUpstreamSpec{
+				{
+					Kind: platform.APIGatewayUpstreamKindNuclioFunction,
+					NuclioFunction: &platform.NuclioFunctionAPIGatewaySpec{
+						Name: "function-name",
+					},
+				},
+			},
+		},
+	})
+	suite.Require().Error(err)
+}
+
 func TestLazyTestSuite(t *testing.T) {
 	suite.Run(t, new(lazyTestSuite))
 }
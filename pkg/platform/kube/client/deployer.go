@@ -79,6 +79,10 @@ func (d *Deployer) CreateOrUpdateFunction(ctx context.Context,
 		functionStatus.InternalInvocationURLs = functionInstance.Status.InternalInvocationURLs
 		functionStatus.ExternalInvocationURLs = functionInstance.Status.ExternalInvocationURLs
 		functionStatus.HTTPPort = functionInstance.Status.HTTPPort
+		functionStatus.ResourceRecommendation = functionInstance.Status.ResourceRecommendation
+
+		// keep the rollout status, from which the controller knows whether there is a canary to remove
+		functionStatus.Rollout = functionInstance.Status.Rollout
 	}
 
 	// scrub the function config if enabled
@@ -491,7 +495,10 @@ func (d *Deployer) getFunctionPodLogsAndEvents(ctx context.Context, namespace st
 	maxLogLines := int64(MaxLogLines)
 	if logsRequest, getLogsErr := d.consumer.KubeClientSet.CoreV1().
 		Pods(namespace).
-		GetLogs(pod.Name, &v1.PodLogOptions{TailLines: &maxLogLines}).
+		GetLogs(pod.Name, &v1.PodLogOptions{
+			Container: FunctionContainerName,
+			TailLines: &maxLogLines,
+		}).
 		Stream(ctx); getLogsErr != nil {
 		podLogsMessage += "Failed to read logs: " + getLogsErr.Error() + "\n"
 	} else {
@@ -32,6 +32,7 @@ import (
 	"github.com/nuclio/logger"
 	"github.com/v3io/version-go"
 	"k8s.io/client-go/kubernetes"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 type Controller struct {
@@ -56,6 +57,8 @@ type Controller struct {
 	// monitors
 	cronJobMonitoring          *CronJobMonitoring
 	evictedPodsMonitoring      *EvictedPodsMonitoring
+	rolloutMonitoring          *RolloutMonitoring
+	resourceRecommendation     *ResourceRecommendationMonitoring
 	functionMonitoring         *monitoring.FunctionMonitor
 	functionMonitoringInterval time.Duration
 }
@@ -67,10 +70,14 @@ func NewController(parentLogger logger.Logger,
 	nuclioClientSet nuclioioclient.Interface,
 	functionresClient functionres.Client,
 	apigatewayresClient apigatewayres.Client,
+	metricsClientSet metricsclientset.Interface,
 	resyncInterval time.Duration,
 	functionMonitoringInterval time.Duration,
 	cronJobStaleResourcesCleanupInterval time.Duration,
 	evictedPodsCleanupInterval time.Duration,
+	rolloutMonitoringInterval time.Duration,
+	resourceRecommendationInterval time.Duration,
+	resourceRecommendationWindow time.Duration,
 	platformConfiguration *platformconfig.Config,
 	platformConfigurationName string,
 	functionOperatorNumWorkers int,
@@ -175,6 +182,22 @@ func NewController(parentLogger logger.Logger,
 		newController,
 		&evictedPodsCleanupInterval)
 
+	// create rollout monitoring
+	newController.rolloutMonitoring = NewRolloutMonitoring(ctx,
+		parentLogger,
+		newController,
+		&rolloutMonitoringInterval)
+
+	// create resource recommendation monitoring, if usage metrics are available
+	if metricsClientSet != nil {
+		newController.resourceRecommendation = NewResourceRecommendationMonitoring(ctx,
+			parentLogger,
+			newController,
+			metricsClientSet,
+			&resourceRecommendationInterval,
+			&resourceRecommendationWindow)
+	}
+
 	return newController, nil
 }
 
@@ -210,6 +233,16 @@ func (c *Controller) Stop(ctx context.Context) error {
 		c.evictedPodsMonitoring.stop(ctx)
 	}
 
+	// stop rollout monitoring
+	if c.rolloutMonitoring != nil {
+		c.rolloutMonitoring.stop(ctx)
+	}
+
+	// stop resource recommendation monitoring
+	if c.resourceRecommendation != nil {
+		c.resourceRecommendation.stop(ctx)
+	}
+
 	// stop function monitor
 	c.functionMonitoring.Stop(ctx)
 	return nil
@@ -292,5 +325,17 @@ func (c *Controller) startMonitors(ctx context.Context) error {
 		c.evictedPodsMonitoring.start(ctx)
 	}
 
+	if c.rolloutMonitoring != nil {
+
+		// start rollout monitoring
+		c.rolloutMonitoring.start(ctx)
+	}
+
+	if c.resourceRecommendation != nil {
+
+		// start resource recommendation monitoring
+		c.resourceRecommendation.start(ctx)
+	}
+
 	return nil
 }
@@ -51,6 +51,7 @@ func (suite *ControllerTestSuite) SetupTest() {
 	functionMonitoringInterval := 10 * time.Second
 	evictedPodsCleanupInterval := 30 * time.Minute
 	cronJobInterval := 10 * time.Second
+	rolloutMonitoringInterval := 10 * time.Second
 	defaultNumWorkers := 1
 
 	// create logger
@@ -67,7 +68,8 @@ func (suite *ControllerTestSuite) SetupTest() {
 
 	functionresClient, err := functionres.NewLazyClient(suite.logger,
 		suite.k8sClientSet,
-		suite.functionClientSet)
+		suite.functionClientSet,
+		nil)
 	suite.Require().NoError(err)
 
 	// create controller
@@ -78,10 +80,14 @@ func (suite *ControllerTestSuite) SetupTest() {
 		suite.functionClientSet,
 		functionresClient,
 		nil,
+		nil,
 		resyncInterval,
 		functionMonitoringInterval,
 		evictedPodsCleanupInterval,
 		cronJobInterval,
+		rolloutMonitoringInterval,
+		0,
+		0,
 		platformConfig,
 		"configuration-name",
 		defaultNumWorkers,
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/nuclio/nuclio/pkg/common"
@@ -29,6 +30,7 @@ import (
 	"github.com/nuclio/nuclio/pkg/platform/kube/client"
 	"github.com/nuclio/nuclio/pkg/platform/kube/functionres"
 	"github.com/nuclio/nuclio/pkg/platform/kube/operator"
+	"github.com/nuclio/nuclio/pkg/platformconfig"
 
 	"github.com/nuclio/errors"
 	"github.com/nuclio/logger"
@@ -42,11 +44,15 @@ import (
 )
 
 type functionOperator struct {
-	logger            logger.Logger
-	controller        *Controller
-	operator          operator.Operator
-	imagePullSecrets  string
-	functionresClient functionres.Client
+	logger              logger.Logger
+	controller          *Controller
+	operator            operator.Operator
+	imagePullSecrets    string
+	functionresClient   functionres.Client
+	rolloutEventCounter eventCounter
+
+	// functions whose rollouts are due to be advanced, by namespace/name
+	dueRollouts sync.Map
 }
 
 func newFunctionOperator(ctx context.Context,
@@ -61,10 +67,11 @@ func newFunctionOperator(ctx context.Context,
 	loggerInstance := parentLogger.GetChild("function")
 
 	newFunctionOperator := &functionOperator{
-		logger:            loggerInstance,
-		controller:        controller,
-		imagePullSecrets:  imagePullSecrets,
-		functionresClient: functionresClient,
+		logger:              loggerInstance,
+		controller:          controller,
+		imagePullSecrets:    imagePullSecrets,
+		functionresClient:   functionresClient,
+		rolloutEventCounter: newProcessorMetricsEventCounter(controller.kubeClientSet),
 	}
 
 	// create a function operator
@@ -109,6 +116,13 @@ func (fo *functionOperator) CreateOrUpdate(ctx context.Context, object runtime.O
 			},
 		})
 
+	// the rollout monitoring enqueued the function to advance its rollout, unless it was deployed again since
+	if fo.takeDueRollout(function) &&
+		fo.rolloutInProgress(function) &&
+		function.Status.State != functionconfig.FunctionStateWaitingForResourceConfiguration {
+		return fo.advanceRollout(ctx, function)
+	}
+
 	// validate function name is according to k8s convention
 	errorMessages := validation.IsQualifiedName(function.Name)
 	if len(errorMessages) != 0 {
@@ -192,6 +206,41 @@ func (fo *functionOperator) CreateOrUpdate(ctx context.Context, object runtime.O
 
 	}
 
+	// a rollout in progress owns the function's resources until the new version is promoted or rolled back
+	if fo.rolloutInProgress(function) &&
+		function.Status.State != functionconfig.FunctionStateWaitingForResourceConfiguration {
+		fo.logger.DebugWithCtx(ctx,
+			"Function is being rolled out, skipping create/update",
+			"name", function.Name,
+			"state", function.Status.State,
+			"namespace", function.Namespace)
+		return nil
+	}
+
+	if function.Status.State == functionconfig.FunctionStateWaitingForResourceConfiguration {
+		requiresRollout, err := fo.functionresClient.RequiresRollout(ctx, function)
+		if err != nil {
+			return fo.setFunctionError(ctx,
+				function,
+				functionconfig.FunctionStateError,
+				errors.Wrap(err, "Failed to resolve whether function requires rollout"))
+		}
+
+		if requiresRollout {
+			return fo.startRollout(ctx, function)
+		}
+
+		// a deploy that isn't rolled out replaces the new version of any ongoing rollout
+		if fo.rolloutInProgress(function) {
+			if err := fo.functionresClient.DeleteCanary(ctx, function.Namespace, function.Name); err != nil {
+				return fo.setFunctionError(ctx,
+					function,
+					functionconfig.FunctionStateError,
+					errors.Wrap(err, "Failed to delete canary resources"))
+			}
+		}
+	}
+
 	// wait for up to the default readiness timeout or whatever was set in the spec
 	readinessTimeout := fo.
 		controller.
@@ -258,15 +307,20 @@ func (fo *functionOperator) CreateOrUpdate(ctx context.Context, object runtime.O
 		// NOTE: this reconstructs function status and hence omits all other function status fields
 		// ... such as message and logs.
 		functionStatus := &functionconfig.Status{
-			State:          finalState,
-			Logs:           function.Status.Logs,
-			ContainerImage: function.Spec.Image,
+			State:                  finalState,
+			Logs:                   function.Status.Logs,
+			ContainerImage:         function.Spec.Image,
+			ResourceRecommendation: function.Status.ResourceRecommendation,
 		}
 
 		if err := fo.populateFunctionInvocationStatus(function, functionStatus, resources); err != nil {
 			return errors.Wrap(err, "Failed to populate function invocation status")
 		}
 
+		if err := fo.populateFunctionAutoScalerStatus(functionStatus, resources); err != nil {
+			return errors.Wrap(err, "Failed to populate function auto scaler status")
+		}
+
 		if err := fo.setFunctionScaleToZeroStatus(ctx, functionStatus, scaleEvent); err != nil {
 			return errors.Wrap(err, "Failed setting function scale to zero status")
 		}
@@ -329,6 +383,7 @@ func (fo *functionOperator) setFunctionError(ctx context.Context,
 		Message:                errors.GetErrorStackString(err, 10),
 		InternalInvocationURLs: []string{},
 		ExternalInvocationURLs: []string{},
+		ResourceRecommendation: function.Status.ResourceRecommendation,
 	}); setStatusErr != nil {
 		fo.logger.WarnWithCtx(detachedContext,
 			"Failed to update function on error",
@@ -385,6 +440,34 @@ func (fo *functionOperator) getFunctionHTTPPort(functionResources functionres.Re
 	return httpPort, nil
 }
 
+func (fo *functionOperator) populateFunctionAutoScalerStatus(functionStatus *functionconfig.Status,
+	functionResources functionres.Resources) error {
+
+	scaledObject, err := functionResources.ScaledObject()
+	if err != nil {
+		return errors.Wrap(err, "Failed to get function scaled object")
+	}
+
+	if scaledObject != nil {
+		functionStatus.AutoScaler = scaledObject.CompileAutoScalerStatus()
+		return nil
+	}
+
+	horizontalPodAutoscaler, err := functionResources.HorizontalPodAutoscaler()
+	if err != nil {
+		return errors.Wrap(err, "Failed to get function hpa")
+	}
+
+	if horizontalPodAutoscaler != nil {
+		functionStatus.AutoScaler = &functionconfig.AutoScalerStatus{
+			Kind: string(platformconfig.AutoScalerKindHPA),
+			Name: horizontalPodAutoscaler.Name,
+		}
+	}
+
+	return nil
+}
+
 func (fo *functionOperator) populateFunctionInvocationStatus(function *nuclioio.NuclioFunction,
 	functionStatus *functionconfig.Status,
 	functionResources functionres.Resources) error {
@@ -438,6 +521,25 @@ func (fo *functionOperator) populateFunctionInvocationStatus(function *nuclioio.
 				fmt.Sprintf("%s%s", host, path))
 		}
 	}
+
+	// add gateway API routes to external invocation urls
+	httpRoute, err := functionResources.HTTPRoute()
+	if err != nil {
+		return errors.Wrap(err, "Failed to get function route")
+	}
+
+	if httpRoute != nil {
+		for _, hostname := range httpRoute.Spec.Hostnames {
+			for _, rule := range httpRoute.Spec.Rules {
+				path := "/"
+				if len(rule.Matches) > 0 && rule.Matches[0].Path != nil {
+					path = rule.Matches[0].Path.Value
+				}
+				functionStatus.ExternalInvocationURLs = append(functionStatus.ExternalInvocationURLs,
+					fmt.Sprintf("%s%s", hostname, path))
+			}
+		}
+	}
 	return nil
 
 }
@@ -29,9 +29,12 @@ import (
 	"github.com/nuclio/nuclio/pkg/platform/kube/functionres"
 	"github.com/nuclio/nuclio/pkg/platformconfig"
 
+	"github.com/nuclio/errors"
 	"github.com/nuclio/logger"
 	nucliozap "github.com/nuclio/zap"
 	"github.com/stretchr/testify/suite"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 	k8stesting "k8s.io/client-go/testing"
@@ -53,6 +56,7 @@ func (suite *NuclioFunctionTestSuite) SetupTest() {
 	functionMonitoringInterval := 10 * time.Second
 	evictedPodsCleanupInterval := 30 * time.Minute
 	cronJobInterval := 10 * time.Second
+	rolloutMonitoringInterval := 10 * time.Second
 	defaultNumWorkers := 1
 
 	suite.logger, err = nucliozap.NewNuclioZapTest("test")
@@ -68,7 +72,8 @@ func (suite *NuclioFunctionTestSuite) SetupTest() {
 
 	functionresClient, err := functionres.NewLazyClient(suite.logger,
 		suite.k8sClientSet,
-		suite.functionClientSet)
+		suite.functionClientSet,
+		nil)
 	suite.Require().NoError(err)
 
 	suite.controller, err = NewController(suite.logger,
@@ -78,10 +83,14 @@ func (suite *NuclioFunctionTestSuite) SetupTest() {
 		suite.functionClientSet,
 		functionresClient,
 		nil,
+		nil,
 		resyncInterval,
 		functionMonitoringInterval,
 		evictedPodsCleanupInterval,
 		cronJobInterval,
+		rolloutMonitoringInterval,
+		0,
+		0,
 		platformConfig,
 		"configuration-name",
 		defaultNumWorkers,
@@ -136,6 +145,313 @@ func (suite *NuclioFunctionTestSuite) TestRecoverFromPanic() {
 	suite.Assert().Equal(functionconfig.FunctionStateError, functionInstance.Status.State)
 }
 
+func (suite *NuclioFunctionTestSuite) TestAdvanceRolloutNextStep() {
+	functionInstance := suite.createRollingOutFunction(time.Now().Add(-2 * time.Minute))
+	suite.controller.functionOperator.rolloutEventCounter = &mockEventCounter{
+		handledEvents: 100,
+		failedEvents:  1,
+	}
+
+	err := suite.controller.functionOperator.advanceRollout(suite.ctx, functionInstance)
+	suite.Require().NoError(err)
+
+	suite.Require().Equal(functionconfig.FunctionStateReady, functionInstance.Status.State)
+	suite.Require().Equal(functionconfig.RolloutPhaseProgressing, functionInstance.Status.Rollout.Phase)
+	suite.Require().Equal(1, functionInstance.Status.Rollout.Step)
+	suite.Require().Equal(50, functionInstance.Status.Rollout.Weight)
+	suite.Require().Equal(uint64(100), functionInstance.Status.Rollout.HandledEvents)
+	suite.Require().InDelta(0.01, functionInstance.Status.Rollout.ErrorRate, 0.0001)
+}
+
+func (suite *NuclioFunctionTestSuite) TestAdvanceRolloutStepNotDone() {
+	functionInstance := suite.createRollingOutFunction(time.Now())
+	suite.controller.functionOperator.rolloutEventCounter = &mockEventCounter{
+		handledEvents: 5,
+	}
+
+	err := suite.controller.functionOperator.advanceRollout(suite.ctx, functionInstance)
+	suite.Require().NoError(err)
+
+	suite.Require().Equal(functionconfig.RolloutPhaseProgressing, functionInstance.Status.Rollout.Phase)
+	suite.Require().Equal(0, functionInstance.Status.Rollout.Step)
+	suite.Require().Equal(10, functionInstance.Status.Rollout.Weight)
+	suite.Require().Equal(uint64(5), functionInstance.Status.Rollout.HandledEvents)
+}
+
+func (suite *NuclioFunctionTestSuite) TestAdvanceRolloutRollBack() {
+	for _, testCase := range []struct {
+		name             string
+		stepAge          time.Duration
+		eventCounter     *mockEventCounter
+		expectedRollBack bool
+	}{
+		{
+			name: "HighErrorRate",
+			eventCounter: &mockEventCounter{
+				handledEvents: 20,
+				failedEvents:  5,
+			},
+			expectedRollBack: true,
+		},
+		{
+			name: "TooFewEventsToJudge",
+			eventCounter: &mockEventCounter{
+				handledEvents: 4,
+				failedEvents:  4,
+			},
+		},
+		{
+			name:    "TooFewEventsAfterStep",
+			stepAge: 2 * time.Minute,
+			eventCounter: &mockEventCounter{
+				handledEvents: 4,
+			},
+			expectedRollBack: true,
+		},
+		{
+			name: "NoReadyReplicas",
+			eventCounter: &mockEventCounter{
+				err: errNoReadyCanaryPods,
+			},
+			expectedRollBack: true,
+		},
+	} {
+		suite.Run(testCase.name, func() {
+			functionInstance := suite.createRollingOutFunction(time.Now().Add(-testCase.stepAge))
+			suite.controller.functionOperator.rolloutEventCounter = testCase.eventCounter
+
+			err := suite.controller.functionOperator.advanceRollout(suite.ctx, functionInstance)
+			suite.Require().NoError(err)
+
+			if !testCase.expectedRollBack {
+				suite.Require().Equal(functionconfig.FunctionStateReady, functionInstance.Status.State)
+				suite.Require().Equal(functionconfig.RolloutPhaseProgressing, functionInstance.Status.Rollout.Phase)
+				return
+			}
+
+			suite.Require().Equal(functionconfig.FunctionStateError, functionInstance.Status.State)
+			suite.Require().Contains(functionInstance.Status.Message, "The new version was rolled back")
+			suite.Require().Equal(functionconfig.RolloutPhaseRolledBack, functionInstance.Status.Rollout.Phase)
+			suite.Require().Equal(0, functionInstance.Status.Rollout.Weight)
+			suite.Require().NotNil(functionInstance.Status.Rollout.CompletedAt)
+		})
+	}
+}
+
+func (suite *NuclioFunctionTestSuite) TestAdvanceRolloutThroughOperator() {
+	functionInstance := suite.createRollingOutFunction(time.Now().Add(-2 * time.Minute))
+	suite.controller.functionOperator.rolloutEventCounter = &mockEventCounter{
+		handledEvents: 100,
+	}
+
+	// changes to the function don't advance its rollout
+	err := suite.controller.functionOperator.CreateOrUpdate(suite.ctx, functionInstance)
+	suite.Require().NoError(err)
+	suite.Require().Equal(0, functionInstance.Status.Rollout.Step)
+
+	// the rollout monitoring has the operator advance it
+	suite.controller.rolloutMonitoring.advanceRollouts(suite.ctx)
+	err = suite.controller.functionOperator.CreateOrUpdate(suite.ctx, functionInstance)
+	suite.Require().NoError(err)
+	suite.Require().Equal(1, functionInstance.Status.Rollout.Step)
+	suite.Require().Equal(50, functionInstance.Status.Rollout.Weight)
+
+	// once
+	err = suite.controller.functionOperator.CreateOrUpdate(suite.ctx, functionInstance)
+	suite.Require().NoError(err)
+	suite.Require().Equal(1, functionInstance.Status.Rollout.Step)
+}
+
+func (suite *NuclioFunctionTestSuite) TestDeleteCanaryOnlyDuringRollout() {
+	for _, testCase := range []struct {
+		name                string
+		rolloutStatus       *functionconfig.RolloutStatus
+		expectCanaryDeleted bool
+	}{
+		{
+			name: "NoRollout",
+		},
+		{
+			name: "RolloutPromoted",
+			rolloutStatus: &functionconfig.RolloutStatus{
+				Phase: functionconfig.RolloutPhasePromoted,
+			},
+		},
+		{
+			name: "RolloutInProgress",
+			rolloutStatus: &functionconfig.RolloutStatus{
+				Phase: functionconfig.RolloutPhaseProgressing,
+			},
+			expectCanaryDeleted: true,
+		},
+	} {
+		suite.Run(testCase.name, func() {
+			suite.SetupTest()
+
+			// stop right after the canary is handled
+			suite.k8sClientSet.PrependReactor("create",
+				"configmaps",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errors.New("Stop here")
+				})
+
+			functionInstance := &nuclioio.NuclioFunction{}
+			functionInstance.Name = "func-name"
+			functionInstance.Namespace = suite.namespace
+			functionInstance.Status.State = functionconfig.FunctionStateWaitingForResourceConfiguration
+			functionInstance.Status.Rollout = testCase.rolloutStatus
+
+			err := suite.controller.functionOperator.CreateOrUpdate(suite.ctx, functionInstance)
+			suite.Require().Error(err)
+
+			canaryDeleted := false
+			for _, action := range suite.k8sClientSet.Actions() {
+				if deleteAction, isDelete := action.(k8stesting.DeleteAction); isDelete &&
+					deleteAction.GetName() == "nuclio-func-name-canary" {
+					canaryDeleted = true
+				}
+			}
+			suite.Require().Equal(testCase.expectCanaryDeleted, canaryDeleted)
+		})
+	}
+}
+
+func (suite *NuclioFunctionTestSuite) TestComputeResourceRecommendation() {
+	var samples []resourceUsageSample
+	for sampleIndex := int64(1); sampleIndex <= 20; sampleIndex++ {
+		samples = append(samples, resourceUsageSample{
+			cpuMillis:   sampleIndex * 10,
+			memoryBytes: sampleIndex * 10 * 1024 * 1024,
+		})
+	}
+
+	recommendation := computeResourceRecommendation(samples)
+
+	// 90th percentile plus 15%, rounded up
+	suite.Require().Equal("210m", recommendation.Requests.Cpu().String())
+	suite.Require().Equal("207Mi", recommendation.Requests.Memory().String())
+
+	// peak plus 30%, no cpu limit
+	suite.Require().Equal("260Mi", recommendation.Limits.Memory().String())
+	suite.Require().NotContains(recommendation.Limits, v1.ResourceCPU)
+	suite.Require().Equal(20, recommendation.Samples)
+}
+
+func (suite *NuclioFunctionTestSuite) TestRecommendResources() {
+	functionInstance := &nuclioio.NuclioFunction{}
+	functionInstance.Name = "func-name"
+	functionInstance.Namespace = suite.namespace
+	functionInstance.Status.State = functionconfig.FunctionStateReady
+	_, err := suite.functionClientSet.
+		NuclioV1beta1().
+		NuclioFunctions(functionInstance.Namespace).
+		Create(suite.ctx, functionInstance, metav1.CreateOptions{})
+	suite.Require().NoError(err)
+
+	interval := time.Minute
+	window := time.Hour
+	resourceRecommendation := NewResourceRecommendationMonitoring(suite.ctx,
+		suite.logger,
+		suite.controller,
+		nil,
+		&interval,
+		&window)
+	resourceRecommendation.usageSource = &mockPodUsageSource{
+		podUsages: []podUsage{
+			{namespace: suite.namespace, functionName: "func-name", cpuMillis: 100, memoryBytes: 100 * 1024 * 1024},
+			{namespace: suite.namespace, functionName: "deleted-func", cpuMillis: 100, memoryBytes: 100 * 1024 * 1024},
+		},
+	}
+
+	getRecommendation := func() *functionconfig.ResourceRecommendation {
+		function, err := suite.functionClientSet.
+			NuclioV1beta1().
+			NuclioFunctions(functionInstance.Namespace).
+			Get(suite.ctx, functionInstance.Name, metav1.GetOptions{})
+		suite.Require().NoError(err)
+		return function.Status.ResourceRecommendation
+	}
+
+	// samples that fell out of the window are dropped
+	startTime := time.Now()
+	resourceRecommendation.collectUsageSamples(suite.ctx, startTime.Add(-2*window))
+	for sampleIndex := 0; sampleIndex < minResourceRecommendationSamples-1; sampleIndex++ {
+		resourceRecommendation.collectUsageSamples(suite.ctx, startTime.Add(time.Duration(sampleIndex)*interval))
+	}
+
+	// not enough samples yet
+	resourceRecommendation.recommendResources(suite.ctx, startTime)
+	suite.Require().Nil(getRecommendation())
+
+	resourceRecommendation.collectUsageSamples(suite.ctx, startTime.Add(minResourceRecommendationSamples*interval))
+	resourceRecommendation.recommendResources(suite.ctx, startTime)
+
+	recommendation := getRecommendation()
+	suite.Require().NotNil(recommendation)
+	suite.Require().Equal("120m", recommendation.Requests.Cpu().String())
+	suite.Require().Equal("115Mi", recommendation.Requests.Memory().String())
+	suite.Require().Equal(minResourceRecommendationSamples, recommendation.Samples)
+	suite.Require().Equal(window.String(), recommendation.Window)
+
+	// samples of functions that no longer exist are forgotten
+	suite.Require().NotContains(resourceRecommendation.samples, resourceRecommendationKey(suite.namespace, "deleted-func"))
+
+	// an unchanged recommendation is not rewritten
+	resourceRecommendation.collectUsageSamples(suite.ctx, startTime.Add((minResourceRecommendationSamples+1)*interval))
+	resourceRecommendation.recommendResources(suite.ctx, startTime.Add(time.Hour))
+	suite.Require().Equal(recommendation.ComputedAt.Unix(), getRecommendation().ComputedAt.Unix())
+}
+
+func (suite *NuclioFunctionTestSuite) createRollingOutFunction(stepStartedAt time.Time) *nuclioio.NuclioFunction {
+	functionInstance := &nuclioio.NuclioFunction{}
+	functionInstance.Name = "func-name"
+	functionInstance.Namespace = suite.namespace
+	functionInstance.Spec.Rollout = &functionconfig.Rollout{
+		Strategy: functionconfig.RolloutStrategyCanary,
+	}
+	functionInstance.Status.State = functionconfig.FunctionStateReady
+	functionInstance.Status.Rollout = &functionconfig.RolloutStatus{
+		Phase:         functionconfig.RolloutPhaseProgressing,
+		Strategy:      functionconfig.RolloutStrategyCanary,
+		Weight:        functionconfig.DefaultRolloutCanarySteps[0],
+		StartedAt:     &stepStartedAt,
+		StepStartedAt: &stepStartedAt,
+	}
+
+	// start over for every test case
+	_ = suite.functionClientSet.
+		NuclioV1beta1().
+		NuclioFunctions(functionInstance.Namespace).
+		Delete(suite.ctx, functionInstance.Name, metav1.DeleteOptions{})
+
+	_, err := suite.functionClientSet.
+		NuclioV1beta1().
+		NuclioFunctions(functionInstance.Namespace).
+		Create(suite.ctx, functionInstance, metav1.CreateOptions{})
+	suite.Require().NoError(err)
+
+	return functionInstance
+}
+
+type mockEventCounter struct {
+	handledEvents uint64
+	failedEvents  uint64
+	err           error
+}
+
+func (mec *mockEventCounter) countCanaryEvents(ctx context.Context,
+	function *nuclioio.NuclioFunction) (uint64, uint64, error) {
+	return mec.handledEvents, mec.failedEvents, mec.err
+}
+
+type mockPodUsageSource struct {
+	podUsages []podUsage
+}
+
+func (mpus *mockPodUsageSource) getPodUsages(ctx context.Context, namespace string) ([]podUsage, error) {
+	return mpus.podUsages, nil
+}
+
 func TestTestSuite(t *testing.T) {
 	suite.Run(t, new(NuclioFunctionTestSuite))
 }
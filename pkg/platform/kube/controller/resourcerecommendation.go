@@ -0,0 +1,353 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	nuclioio "github.com/nuclio/nuclio/pkg/platform/kube/apis/nuclio.io/v1beta1"
+	"github.com/nuclio/nuclio/pkg/platform/kube/client"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+const (
+
+	// the minimal number of usage samples a recommendation is computed from
+	minResourceRecommendationSamples = 10
+
+	// the percentile of the observed usage that requests cover, and the headroom added on top of it
+	resourceRecommendationRequestPercentile = 0.9
+	resourceRecommendationRequestMargin     = 1.15
+
+	// the headroom added on top of the peak memory usage for the memory limit
+	resourceRecommendationMemoryLimitMargin = 1.3
+
+	resourceRecommendationCPUStepMillis = 10
+	resourceRecommendationMemoryStep    = 1024 * 1024
+)
+
+// podUsage is the cpu and memory used by the processor container of a single function replica
+type podUsage struct {
+	namespace    string
+	functionName string
+	cpuMillis    int64
+	memoryBytes  int64
+}
+
+// podUsageSource provides the current usage of function replicas
+type podUsageSource interface {
+	getPodUsages(ctx context.Context, namespace string) ([]podUsage, error)
+}
+
+// metricsAPIPodUsageSource reads the usage of function replicas from the kubernetes metrics API (metrics-server)
+type metricsAPIPodUsageSource struct {
+	metricsClientSet metricsclientset.Interface
+}
+
+func (s *metricsAPIPodUsageSource) getPodUsages(ctx context.Context, namespace string) ([]podUsage, error) {
+	podMetricsList, err := s.metricsClientSet.
+		MetricsV1beta1().
+		PodMetricses(namespace).
+		List(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%s,nuclio.io/function-cron-job-pod!=true",
+				common.NuclioResourceLabelKeyFunctionName),
+		})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to list pod metrics")
+	}
+
+	var podUsages []podUsage
+	for _, podMetrics := range podMetricsList.Items {
+		for _, container := range podMetrics.Containers {
+
+			// sidecars have resources of their own
+			if container.Name != client.FunctionContainerName {
+				continue
+			}
+
+			podUsages = append(podUsages, podUsage{
+				namespace:    podMetrics.Namespace,
+				functionName: podMetrics.Labels[common.NuclioResourceLabelKeyFunctionName],
+				cpuMillis:    container.Usage.Cpu().MilliValue(),
+				memoryBytes:  container.Usage.Memory().Value(),
+			})
+		}
+	}
+
+	return podUsages, nil
+}
+
+type resourceUsageSample struct {
+	collectedAt time.Time
+	cpuMillis   int64
+	memoryBytes int64
+}
+
+// ResourceRecommendationMonitoring periodically samples the usage of function replicas and records the resources
+// recommended for each function in its status
+type ResourceRecommendationMonitoring struct {
+	logger                         logger.Logger
+	controller                     *Controller
+	usageSource                    podUsageSource
+	resourceRecommendationInterval *time.Duration
+	resourceRecommendationWindow   *time.Duration
+	samples                        map[string][]resourceUsageSample
+	stopChan                       chan struct{}
+}
+
+func NewResourceRecommendationMonitoring(ctx context.Context,
+	parentLogger logger.Logger,
+	controller *Controller,
+	metricsClientSet metricsclientset.Interface,
+	resourceRecommendationInterval *time.Duration,
+	resourceRecommendationWindow *time.Duration) *ResourceRecommendationMonitoring {
+
+	loggerInstance := parentLogger.GetChild("resource_recommendation_monitoring")
+
+	newResourceRecommendationMonitoring := &ResourceRecommendationMonitoring{
+		logger:                         loggerInstance,
+		controller:                     controller,
+		usageSource:                    &metricsAPIPodUsageSource{metricsClientSet: metricsClientSet},
+		resourceRecommendationInterval: resourceRecommendationInterval,
+		resourceRecommendationWindow:   resourceRecommendationWindow,
+		samples:                        map[string][]resourceUsageSample{},
+	}
+
+	parentLogger.DebugWithCtx(ctx, "Successfully created resource recommendation monitoring instance",
+		"resourceRecommendationInterval", resourceRecommendationInterval,
+		"resourceRecommendationWindow", resourceRecommendationWindow)
+
+	return newResourceRecommendationMonitoring
+}
+
+func (rrm *ResourceRecommendationMonitoring) start(ctx context.Context) {
+
+	if rrm.resourceRecommendationInterval == nil || *rrm.resourceRecommendationInterval == 0 {
+		rrm.logger.DebugWithCtx(ctx, "Resource recommendation is disabled")
+		return
+	}
+
+	// create stop channel
+	rrm.stopChan = make(chan struct{}, 1)
+
+	// spawn a goroutine for resource recommendation
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				callStack := debug.Stack()
+				rrm.logger.ErrorWithCtx(ctx, "Panic caught while recommending function resources",
+					"err", err,
+					"stack", string(callStack))
+			}
+		}()
+		rrm.logger.InfoWithCtx(ctx, "Starting resource recommendation loop",
+			"resourceRecommendationInterval", rrm.resourceRecommendationInterval,
+			"resourceRecommendationWindow", rrm.resourceRecommendationWindow)
+		for {
+			select {
+			case <-time.After(*rrm.resourceRecommendationInterval):
+				rrm.collectUsageSamples(ctx, time.Now())
+				rrm.recommendResources(ctx, time.Now())
+
+			case <-rrm.stopChan:
+				rrm.logger.DebugCtx(ctx, "Stopped resource recommendation monitoring")
+				return
+			}
+		}
+	}()
+}
+
+func (rrm *ResourceRecommendationMonitoring) stop(ctx context.Context) {
+	rrm.logger.InfoCtx(ctx, "Stopping resource recommendation monitoring")
+
+	// post to channel
+	if rrm.stopChan != nil {
+		rrm.stopChan <- struct{}{}
+	}
+}
+
+func (rrm *ResourceRecommendationMonitoring) collectUsageSamples(ctx context.Context, now time.Time) {
+	podUsages, err := rrm.usageSource.getPodUsages(ctx, rrm.controller.namespace)
+	if err != nil {
+		rrm.logger.WarnWithCtx(ctx, "Failed to get function pods usage",
+			"namespace", rrm.controller.namespace,
+			"err", err)
+		return
+	}
+
+	for _, usage := range podUsages {
+		key := resourceRecommendationKey(usage.namespace, usage.functionName)
+		rrm.samples[key] = append(rrm.samples[key], resourceUsageSample{
+			collectedAt: now,
+			cpuMillis:   usage.cpuMillis,
+			memoryBytes: usage.memoryBytes,
+		})
+	}
+
+	// drop samples that fell out of the window
+	for key, samples := range rrm.samples {
+		firstInWindow := 0
+		for firstInWindow < len(samples) && now.Sub(samples[firstInWindow].collectedAt) > *rrm.resourceRecommendationWindow {
+			firstInWindow++
+		}
+
+		if firstInWindow == len(samples) {
+			delete(rrm.samples, key)
+		} else {
+			rrm.samples[key] = samples[firstInWindow:]
+		}
+	}
+}
+
+func (rrm *ResourceRecommendationMonitoring) recommendResources(ctx context.Context, now time.Time) {
+	functions, err := rrm.controller.nuclioClientSet.
+		NuclioV1beta1().
+		NuclioFunctions(rrm.controller.namespace).
+		List(ctx, metav1.ListOptions{})
+	if err != nil {
+		rrm.logger.WarnWithCtx(ctx, "Failed to list functions",
+			"namespace", rrm.controller.namespace,
+			"err", err)
+		return
+	}
+
+	existingFunctionKeys := map[string]bool{}
+	for functionIndex := range functions.Items {
+		function := &functions.Items[functionIndex]
+		key := resourceRecommendationKey(function.Namespace, function.Name)
+		existingFunctionKeys[key] = true
+
+		// leave functions that are being deployed or rolled out to the function operator
+		if function.Status.State != functionconfig.FunctionStateReady ||
+			rrm.controller.functionOperator.rolloutInProgress(function) {
+			continue
+		}
+
+		samples := rrm.samples[key]
+		if len(samples) < minResourceRecommendationSamples {
+			continue
+		}
+
+		recommendation := computeResourceRecommendation(samples)
+		if recommendation.IsEqual(function.Status.ResourceRecommendation) {
+			continue
+		}
+
+		recommendation.Window = rrm.resourceRecommendationWindow.String()
+		recommendation.ComputedAt = &now
+		if err := rrm.setResourceRecommendation(ctx, function, recommendation); err != nil {
+			rrm.logger.WarnWithCtx(ctx, "Failed to set function resource recommendation",
+				"name", function.Name,
+				"namespace", function.Namespace,
+				"err", err)
+		}
+	}
+
+	// forget functions that were deleted
+	for key := range rrm.samples {
+		if !existingFunctionKeys[key] {
+			delete(rrm.samples, key)
+		}
+	}
+}
+
+func (rrm *ResourceRecommendationMonitoring) setResourceRecommendation(ctx context.Context,
+	function *nuclioio.NuclioFunction,
+	recommendation *functionconfig.ResourceRecommendation) error {
+
+	rrm.logger.DebugWithCtx(ctx, "Setting function resource recommendation",
+		"name", function.Name,
+		"namespace", function.Namespace,
+		"recommendation", recommendation)
+
+	functionStatus := function.Status
+	functionStatus.ResourceRecommendation = recommendation
+
+	return rrm.controller.functionOperator.setFunctionStatus(ctx, function, &functionStatus)
+}
+
+// computeResourceRecommendation recommends requests that cover most of the observed usage, and a memory limit
+// above its peak. cpu is not limited, since exceeding a cpu limit throttles the function rather than evicting it
+func computeResourceRecommendation(samples []resourceUsageSample) *functionconfig.ResourceRecommendation {
+	cpuMillis := make([]int64, 0, len(samples))
+	memoryBytes := make([]int64, 0, len(samples))
+	for _, sample := range samples {
+		cpuMillis = append(cpuMillis, sample.cpuMillis)
+		memoryBytes = append(memoryBytes, sample.memoryBytes)
+	}
+
+	cpuRequest := roundUp(int64(float64(percentile(cpuMillis, resourceRecommendationRequestPercentile))*
+		resourceRecommendationRequestMargin), resourceRecommendationCPUStepMillis)
+	memoryRequest := roundUp(int64(float64(percentile(memoryBytes, resourceRecommendationRequestPercentile))*
+		resourceRecommendationRequestMargin), resourceRecommendationMemoryStep)
+	memoryLimit := roundUp(int64(float64(percentile(memoryBytes, 1))*
+		resourceRecommendationMemoryLimitMargin), resourceRecommendationMemoryStep)
+
+	if memoryLimit < memoryRequest {
+		memoryLimit = memoryRequest
+	}
+
+	return &functionconfig.ResourceRecommendation{
+		Requests: v1.ResourceList{
+			v1.ResourceCPU:    *resource.NewMilliQuantity(cpuRequest, resource.DecimalSI),
+			v1.ResourceMemory: *resource.NewQuantity(memoryRequest, resource.BinarySI),
+		},
+		Limits: v1.ResourceList{
+			v1.ResourceMemory: *resource.NewQuantity(memoryLimit, resource.BinarySI),
+		},
+		Samples: len(samples),
+	}
+}
+
+// percentile returns the nearest-rank percentile (0 < p <= 1) of the given values
+func percentile(values []int64, p float64) int64 {
+	sorted := append([]int64{}, values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+
+	return sorted[rank]
+}
+
+// roundUp rounds the value up to a (non-zero) multiple of step
+func roundUp(value int64, step int64) int64 {
+	if value <= 0 {
+		return step
+	}
+
+	return ((value + step - 1) / step) * step
+}
+
+func resourceRecommendationKey(namespace string, functionName string) string {
+	return fmt.Sprintf("%s/%s", namespace, functionName)
+}
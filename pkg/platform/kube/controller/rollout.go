@@ -0,0 +1,443 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	nuclioio "github.com/nuclio/nuclio/pkg/platform/kube/apis/nuclio.io/v1beta1"
+	"github.com/nuclio/nuclio/pkg/platform/kube/client"
+	"github.com/nuclio/nuclio/pkg/platform/kube/functionres"
+	"github.com/nuclio/nuclio/pkg/platform/kube/functionrevision"
+
+	"github.com/nuclio/errors"
+	"github.com/prometheus/common/expfmt"
+	"github.com/v3io/scaler/pkg/scalertypes"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const handledEventsMetricName = "nuclio_processor_handled_events_total"
+
+var errNoReadyCanaryPods = errors.New("No canary pod is ready")
+
+// eventCounter counts the events handled by the canary pods of a function
+type eventCounter interface {
+	countCanaryEvents(ctx context.Context, function *nuclioio.NuclioFunction) (uint64, uint64, error)
+}
+
+// processorMetricsEventCounter reads the handled events counters of the canary pods from the processor's
+// prometheus pull endpoint
+type processorMetricsEventCounter struct {
+	kubeClientSet kubernetes.Interface
+	httpClient    *http.Client
+}
+
+func newProcessorMetricsEventCounter(kubeClientSet kubernetes.Interface) *processorMetricsEventCounter {
+	return &processorMetricsEventCounter{
+		kubeClientSet: kubeClientSet,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+// countCanaryEvents returns the number of handled events and the number of failed ones
+func (pmec *processorMetricsEventCounter) countCanaryEvents(ctx context.Context,
+	function *nuclioio.NuclioFunction) (uint64, uint64, error) {
+
+	pods, err := pmec.kubeClientSet.CoreV1().Pods(function.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s,nuclio.io/function-version=%s",
+			common.NuclioResourceLabelKeyFunctionName,
+			function.Name,
+			functionres.CanaryFunctionVersion),
+	})
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "Failed to list canary pods")
+	}
+
+	var handledEvents, failedEvents uint64
+	readyPods := 0
+
+	for _, pod := range pods.Items {
+		if !pmec.podIsReady(&pod) {
+			continue
+		}
+		readyPods++
+
+		podHandledEvents, podFailedEvents, err := pmec.countPodEvents(ctx, &pod)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "Failed to count the events of pod %s", pod.Name)
+		}
+
+		handledEvents += podHandledEvents
+		failedEvents += podFailedEvents
+	}
+
+	if readyPods == 0 {
+		return 0, 0, errNoReadyCanaryPods
+	}
+
+	return handledEvents, failedEvents, nil
+}
+
+func (pmec *processorMetricsEventCounter) countPodEvents(ctx context.Context, pod *v1.Pod) (uint64, uint64, error) {
+	request, err := http.NewRequestWithContext(ctx,
+		http.MethodGet,
+		fmt.Sprintf("http://%s:%d/metrics", pod.Status.PodIP, functionres.ContainerMetricPort),
+		nil)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "Failed to create metrics request")
+	}
+
+	response, err := pmec.httpClient.Do(request)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "Failed to get metrics")
+	}
+	defer response.Body.Close() // nolint: errcheck
+
+	if response.StatusCode != http.StatusOK {
+		return 0, 0, errors.Errorf("Got unexpected metrics status code %d", response.StatusCode)
+	}
+
+	metricFamilies, err := (&expfmt.TextParser{}).TextToMetricFamilies(response.Body)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "Failed to parse metrics")
+	}
+
+	var handledEvents, failedEvents uint64
+	if metricFamily, found := metricFamilies[handledEventsMetricName]; found {
+
+		// the counter is labeled by trigger and by result (success or failure)
+		for _, metric := range metricFamily.GetMetric() {
+			value := uint64(metric.GetCounter().GetValue())
+			handledEvents += value
+
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "result" && label.GetValue() == "failure" {
+					failedEvents += value
+				}
+			}
+		}
+	}
+
+	return handledEvents, failedEvents, nil
+}
+
+func (pmec *processorMetricsEventCounter) podIsReady(pod *v1.Pod) bool {
+	if pod.Status.Phase != v1.PodRunning || pod.Status.PodIP == "" {
+		return false
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// startRollout deploys the new version of the function alongside the current one and shifts the traffic of
+// the first step to it. the rollout monitoring takes it from there
+func (fo *functionOperator) startRollout(ctx context.Context, function *nuclioio.NuclioFunction) error {
+	rollout := function.Spec.Rollout
+	steps := rollout.GetSteps()
+
+	fo.logger.InfoWithCtx(ctx,
+		"Starting function rollout",
+		"name", function.Name,
+		"namespace", function.Namespace,
+		"strategy", rollout.Strategy,
+		"steps", steps)
+
+	now := time.Now()
+	rolloutStatus := &functionconfig.RolloutStatus{
+		Phase:         functionconfig.RolloutPhaseProgressing,
+		Strategy:      rollout.Strategy,
+		StartedAt:     &now,
+		StepStartedAt: &now,
+	}
+
+	if _, err := fo.functionresClient.CreateOrUpdateCanary(ctx, function, fo.imagePullSecrets); err != nil {
+		return fo.rollBackRollout(ctx,
+			function,
+			rolloutStatus,
+			errors.Wrap(err, "Failed to create/update canary resources").Error())
+	}
+
+	readinessTimeout := fo.
+		controller.
+		GetPlatformConfiguration().
+		GetFunctionReadinessTimeoutOrDefault(function.Spec.ReadinessTimeoutSeconds)
+	if readinessTimeout != 0 {
+		waitContext, cancel := context.WithDeadline(ctx, time.Now().Add(time.Duration(readinessTimeout)*time.Second))
+		defer cancel()
+
+		if err, _ := fo.functionresClient.WaitCanaryAvailable(waitContext, function, now); err != nil {
+			return fo.rollBackRollout(ctx,
+				function,
+				rolloutStatus,
+				errors.Wrap(err, "Failed to wait for canary resources to be available").Error())
+		}
+	}
+
+	if err := fo.functionresClient.SetCanaryWeight(ctx, function, steps[0]); err != nil {
+		return fo.rollBackRollout(ctx,
+			function,
+			rolloutStatus,
+			errors.Wrap(err, "Failed to shift traffic to canary").Error())
+	}
+
+	rolloutStatus.Weight = steps[0]
+	rolloutStatus.Message = fmt.Sprintf("Shifted %d%% of the traffic to the new version", steps[0])
+
+	// the current version keeps serving, so the function is ready
+	functionStatus := function.Status
+	functionStatus.State = functionconfig.FunctionStateReady
+	functionStatus.Message = ""
+	functionStatus.Rollout = rolloutStatus
+	if err := fo.setFunctionScaleToZeroStatus(ctx, &functionStatus, scalertypes.ResourceUpdatedScaleEvent); err != nil {
+		return errors.Wrap(err, "Failed setting function scale to zero status")
+	}
+
+	return fo.setFunctionStatus(ctx, function, &functionStatus)
+}
+
+// advanceRollout judges the health of the new version by its error rate, and either rolls it back, moves on to
+// the next step once the current one is done, or promotes it after the last step. a new version that didn't handle
+// enough events by the end of a step (e.g. no traffic was routed to it) has no evidence of its health, so it's
+// rolled back rather than promoted
+func (fo *functionOperator) advanceRollout(ctx context.Context, function *nuclioio.NuclioFunction) error {
+	rolloutStatus := *function.Status.Rollout
+
+	// the rollout settings may have been removed since the rollout started
+	rollout := function.Spec.Rollout
+	if rollout == nil {
+		rollout = &functionconfig.Rollout{
+			Strategy: rolloutStatus.Strategy,
+		}
+	}
+
+	handledEvents, failedEvents, err := fo.rolloutEventCounter.countCanaryEvents(ctx, function)
+	if err != nil {
+		if err == errNoReadyCanaryPods {
+			return fo.rollBackRollout(ctx, function, &rolloutStatus, "The new version has no ready replicas")
+		}
+		return errors.Wrap(err, "Failed to count canary events")
+	}
+
+	rolloutStatus.HandledEvents = handledEvents
+	rolloutStatus.FailedEvents = failedEvents
+	if handledEvents > 0 {
+		rolloutStatus.ErrorRate = float64(failedEvents) / float64(handledEvents)
+	}
+
+	if handledEvents >= uint64(rollout.GetMinEvents()) && rolloutStatus.ErrorRate > rollout.GetMaxErrorRate() {
+		return fo.rollBackRollout(ctx,
+			function,
+			&rolloutStatus,
+			fmt.Sprintf("The error rate of the new version (%.3f) exceeded %.3f after %d events",
+				rolloutStatus.ErrorRate,
+				rollout.GetMaxErrorRate(),
+				handledEvents))
+	}
+
+	// the step isn't done yet, just record the progress
+	if rolloutStatus.StepStartedAt != nil && time.Since(*rolloutStatus.StepStartedAt) < rollout.GetStepDuration() {
+		return fo.setRolloutStatus(ctx, function, &rolloutStatus)
+	}
+
+	if handledEvents < uint64(rollout.GetMinEvents()) {
+		return fo.rollBackRollout(ctx,
+			function,
+			&rolloutStatus,
+			fmt.Sprintf("The new version handled %d events by the end of the step, fewer than the %d needed to judge it",
+				handledEvents,
+				rollout.GetMinEvents()))
+	}
+
+	steps := rollout.GetSteps()
+	if rolloutStatus.Step+1 >= len(steps) {
+		return fo.promoteRollout(ctx, function, &rolloutStatus)
+	}
+
+	nextWeight := steps[rolloutStatus.Step+1]
+	if err := fo.functionresClient.SetCanaryWeight(ctx, function, nextWeight); err != nil {
+		return errors.Wrap(err, "Failed to shift traffic to canary")
+	}
+
+	now := time.Now()
+	rolloutStatus.Step++
+	rolloutStatus.Weight = nextWeight
+	rolloutStatus.StepStartedAt = &now
+	rolloutStatus.Message = fmt.Sprintf("Shifted %d%% of the traffic to the new version", nextWeight)
+
+	fo.logger.InfoWithCtx(ctx,
+		"Function rollout moved to the next step",
+		"name", function.Name,
+		"namespace", function.Namespace,
+		"step", rolloutStatus.Step,
+		"weight", nextWeight)
+
+	return fo.setRolloutStatus(ctx, function, &rolloutStatus)
+}
+
+// promoteRollout rolls the function's resources forward to the new version, and only then removes the canary
+func (fo *functionOperator) promoteRollout(ctx context.Context,
+	function *nuclioio.NuclioFunction,
+	rolloutStatus *functionconfig.RolloutStatus) error {
+
+	fo.logger.InfoWithCtx(ctx,
+		"Promoting function rollout",
+		"name", function.Name,
+		"namespace", function.Namespace)
+
+	functionResourcesCreateOrUpdateTimestamp := time.Now()
+	resources, err := fo.functionresClient.CreateOrUpdate(ctx, function, fo.imagePullSecrets)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create/update function")
+	}
+
+	readinessTimeout := fo.
+		controller.
+		GetPlatformConfiguration().
+		GetFunctionReadinessTimeoutOrDefault(function.Spec.ReadinessTimeoutSeconds)
+	if readinessTimeout != 0 {
+		waitContext, cancel := context.WithDeadline(ctx, time.Now().Add(time.Duration(readinessTimeout)*time.Second))
+		defer cancel()
+
+		if err, _ := fo.functionresClient.WaitAvailable(waitContext,
+			function,
+			functionResourcesCreateOrUpdateTimestamp); err != nil {
+			return errors.Wrap(err, "Failed to wait for function resources to be available")
+		}
+	}
+
+	if err := fo.functionresClient.DeleteCanary(ctx, function.Namespace, function.Name); err != nil {
+		return errors.Wrap(err, "Failed to delete canary resources")
+	}
+
+	now := time.Now()
+	rolloutStatus.Phase = functionconfig.RolloutPhasePromoted
+	rolloutStatus.Weight = 100
+	rolloutStatus.CompletedAt = &now
+	rolloutStatus.Message = "The new version was promoted"
+
+	functionStatus := &functionconfig.Status{
+		State:                  functionconfig.FunctionStateReady,
+		Logs:                   function.Status.Logs,
+		ContainerImage:         function.Spec.Image,
+		Rollout:                rolloutStatus,
+		ResourceRecommendation: function.Status.ResourceRecommendation,
+	}
+
+	if err := fo.populateFunctionInvocationStatus(function, functionStatus, resources); err != nil {
+		return errors.Wrap(err, "Failed to populate function invocation status")
+	}
+
+	if err := fo.populateFunctionAutoScalerStatus(functionStatus, resources); err != nil {
+		return errors.Wrap(err, "Failed to populate function auto scaler status")
+	}
+
+	if err := fo.setFunctionScaleToZeroStatus(ctx, functionStatus, scalertypes.ResourceUpdatedScaleEvent); err != nil {
+		return errors.Wrap(err, "Failed setting function scale to zero status")
+	}
+
+	if err := fo.setFunctionStatus(ctx, function, functionStatus); err != nil {
+		return err
+	}
+
+	// the platform leaves recording the revision of a rollout to its promotion. the function is promoted
+	// either way, a missing revision shouldn't fail it
+	if err := functionrevision.NewStore(fo.logger, fo.controller.kubeClientSet).Record(ctx,
+		*client.NuclioioToFunctionConfig(function),
+		"",
+		fo.controller.GetPlatformConfiguration().GetFunctionRevisionHistoryLimit()); err != nil {
+		fo.logger.WarnWithCtx(ctx,
+			"Failed to record function revision",
+			"name", function.Name,
+			"namespace", function.Namespace,
+			"err", errors.GetErrorStackString(err, 10))
+	}
+
+	return nil
+}
+
+// rollBackRollout sends all traffic back to the current version and removes the new one. the function is left
+// in error state, since its spec describes the version that was rolled back
+func (fo *functionOperator) rollBackRollout(ctx context.Context,
+	function *nuclioio.NuclioFunction,
+	rolloutStatus *functionconfig.RolloutStatus,
+	reason string) error {
+
+	fo.logger.WarnWithCtx(ctx,
+		"Rolling back function rollout",
+		"name", function.Name,
+		"namespace", function.Namespace,
+		"reason", reason)
+
+	if err := fo.functionresClient.DeleteCanary(ctx, function.Namespace, function.Name); err != nil {
+		return errors.Wrap(err, "Failed to delete canary resources")
+	}
+
+	now := time.Now()
+	rolloutStatus.Phase = functionconfig.RolloutPhaseRolledBack
+	rolloutStatus.Weight = 0
+	rolloutStatus.CompletedAt = &now
+	rolloutStatus.Message = reason
+
+	functionStatus := function.Status
+	functionStatus.State = functionconfig.FunctionStateError
+	functionStatus.Message = fmt.Sprintf("The new version was rolled back: %s", reason)
+	functionStatus.Rollout = rolloutStatus
+
+	return fo.setFunctionStatus(ctx, function, &functionStatus)
+}
+
+func (fo *functionOperator) setRolloutStatus(ctx context.Context,
+	function *nuclioio.NuclioFunction,
+	rolloutStatus *functionconfig.RolloutStatus) error {
+
+	functionStatus := function.Status
+	functionStatus.Rollout = rolloutStatus
+
+	return fo.setFunctionStatus(ctx, function, &functionStatus)
+}
+
+// enqueueRolloutAdvance has the operator advance the rollout of the function, so that it's serialized with
+// the other changes to the function
+func (fo *functionOperator) enqueueRolloutAdvance(function *nuclioio.NuclioFunction) {
+	fo.dueRollouts.Store(fmt.Sprintf("%s/%s", function.Namespace, function.Name), struct{}{})
+	fo.operator.Enqueue(function.Namespace, function.Name)
+}
+
+// takeDueRollout returns whether the rollout of the function is due to be advanced, clearing it
+func (fo *functionOperator) takeDueRollout(function *nuclioio.NuclioFunction) bool {
+	_, due := fo.dueRollouts.LoadAndDelete(fmt.Sprintf("%s/%s", function.Namespace, function.Name))
+	return due
+}
+
+func (fo *functionOperator) rolloutInProgress(function *nuclioio.NuclioFunction) bool {
+	return function.Status.Rollout != nil && function.Status.Rollout.Phase == functionconfig.RolloutPhaseProgressing
+}
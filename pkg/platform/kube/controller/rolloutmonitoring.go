@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/nuclio/logger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RolloutMonitoring periodically advances the rollouts of functions, until they're promoted or rolled back
+type RolloutMonitoring struct {
+	logger                    logger.Logger
+	controller                *Controller
+	rolloutMonitoringInterval *time.Duration
+	stopChan                  chan struct{}
+}
+
+func NewRolloutMonitoring(ctx context.Context,
+	parentLogger logger.Logger,
+	controller *Controller,
+	rolloutMonitoringInterval *time.Duration) *RolloutMonitoring {
+
+	loggerInstance := parentLogger.GetChild("rollout_monitoring")
+
+	newRolloutMonitoring := &RolloutMonitoring{
+		logger:                    loggerInstance,
+		controller:                controller,
+		rolloutMonitoringInterval: rolloutMonitoringInterval,
+	}
+
+	parentLogger.DebugWithCtx(ctx, "Successfully created rollout monitoring instance",
+		"rolloutMonitoringInterval", rolloutMonitoringInterval)
+
+	return newRolloutMonitoring
+}
+
+func (rm *RolloutMonitoring) start(ctx context.Context) {
+
+	// create stop channel
+	rm.stopChan = make(chan struct{}, 1)
+
+	// spawn a goroutine for rollout monitoring
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				callStack := debug.Stack()
+				rm.logger.ErrorWithCtx(ctx, "Panic caught while monitoring rollouts",
+					"err", err,
+					"stack", string(callStack))
+			}
+		}()
+		rm.logger.InfoWithCtx(ctx, "Starting rollout monitoring loop",
+			"rolloutMonitoringInterval", rm.rolloutMonitoringInterval)
+		for {
+			select {
+			case <-time.After(*rm.rolloutMonitoringInterval):
+				rm.advanceRollouts(ctx)
+
+			case <-rm.stopChan:
+				rm.logger.DebugCtx(ctx, "Stopped rollout monitoring")
+				return
+			}
+		}
+	}()
+}
+
+func (rm *RolloutMonitoring) stop(ctx context.Context) {
+	rm.logger.InfoCtx(ctx, "Stopping rollout monitoring")
+
+	// post to channel
+	if rm.stopChan != nil {
+		rm.stopChan <- struct{}{}
+	}
+}
+
+func (rm *RolloutMonitoring) advanceRollouts(ctx context.Context) {
+	functions, err := rm.controller.nuclioClientSet.
+		NuclioV1beta1().
+		NuclioFunctions(rm.controller.namespace).
+		List(ctx, metav1.ListOptions{})
+	if err != nil {
+		rm.logger.WarnWithCtx(ctx, "Failed to list functions",
+			"namespace", rm.controller.namespace,
+			"err", err)
+		return
+	}
+
+	for functionIndex := range functions.Items {
+		function := &functions.Items[functionIndex]
+		if !rm.controller.functionOperator.rolloutInProgress(function) {
+			continue
+		}
+
+		// advancing the rollout changes the function, so leave it to the operator rather than race it
+		rm.controller.functionOperator.enqueueRolloutAdvance(function)
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package functionres
+
+import (
+	"context"
+	"sort"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/platform/abstract"
+	"github.com/nuclio/nuclio/pkg/platform/kube"
+	nuclioio "github.com/nuclio/nuclio/pkg/platform/kube/apis/nuclio.io/v1beta1"
+	"github.com/nuclio/nuclio/pkg/platform/kube/gatewayapi"
+	"github.com/nuclio/nuclio/pkg/platformconfig"
+
+	"github.com/nuclio/errors"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func (lc *lazyClient) exposedByGatewayAPI() bool {
+	return lc.platformConfigurationProvider.GetPlatformConfiguration().Kube.Exposure.Kind ==
+		platformconfig.ExposureKindGatewayAPI
+}
+
+// createOrUpdateHTTPRoute exposes the function's http trigger ingresses with a gateway API route. a route has
+// a single set of hostnames, so the paths of all ingresses are matched on all of their hosts
+func (lc *lazyClient) createOrUpdateHTTPRoute(ctx context.Context,
+	functionLabels labels.Set,
+	function *nuclioio.NuclioFunction) (*gatewayapi.HTTPRoute, error) {
+
+	// without a dynamic client there's no way to manage custom resources
+	if lc.httpRouteManager == nil {
+		return nil, nil
+	}
+
+	httpRouteName := kube.HTTPRouteNameFromFunctionName(function.Name)
+
+	if !lc.exposedByGatewayAPI() {
+
+		// the function may have been exposed with the gateway API before
+		return nil, lc.deleteExistingHTTPRoute(ctx, httpRouteName, function.Namespace)
+	}
+
+	// render the ingress the function would have gotten, and translate its rules
+	ingressMeta := metav1.ObjectMeta{}
+	ingressSpec := networkingv1.IngressSpec{}
+	if err := lc.populateIngressConfig(ctx, functionLabels, function, &ingressMeta, &ingressSpec); err != nil {
+		return nil, errors.Wrap(err, "Failed to populate ingress spec")
+	}
+
+	// nothing to expose
+	if len(ingressSpec.Rules) == 0 {
+		return nil, lc.deleteExistingHTTPRoute(ctx, httpRouteName, function.Namespace)
+	}
+
+	httpRoute, err := lc.httpRouteManager.GenerateHTTPRoute(gatewayapi.Spec{
+		Name:       httpRouteName,
+		Namespace:  function.Namespace,
+		Labels:     functionLabels,
+		ParentRefs: lc.platformConfigurationProvider.GetPlatformConfiguration().Kube.Exposure.GatewayAPI.ParentRefs,
+		Hostnames:  lc.getHTTPRouteHostnames(ingressSpec.Rules),
+		Rules:      lc.getHTTPRouteRules(function, ingressSpec.Rules),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to generate route")
+	}
+
+	return lc.httpRouteManager.CreateOrUpdate(ctx, httpRoute)
+}
+
+func (lc *lazyClient) deleteHTTPRoute(ctx context.Context, namespace string, name string) error {
+	if lc.httpRouteManager == nil {
+		return nil
+	}
+
+	return lc.httpRouteManager.DeleteByName(ctx, kube.HTTPRouteNameFromFunctionName(name), namespace)
+}
+
+// deleteExistingHTTPRoute deletes a route left over from a previous reconciliation. functions that aren't exposed
+// with the gateway API are reconciled often, so the route is only deleted if it exists
+func (lc *lazyClient) deleteExistingHTTPRoute(ctx context.Context, name string, namespace string) error {
+	if _, err := lc.httpRouteManager.Get(ctx, name, namespace); err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			return nil
+		}
+		return errors.Wrap(err, "Failed to get route")
+	}
+
+	return lc.httpRouteManager.DeleteByName(ctx, name, namespace)
+}
+
+// getHTTPRouteHostnames returns the hosts of all ingress rules. a rule without a host matches any host,
+// in which case the route must not be restricted to hostnames either
+func (lc *lazyClient) getHTTPRouteHostnames(ingressRules []networkingv1.IngressRule) []string {
+	var hostnames []string
+	for _, ingressRule := range ingressRules {
+		if ingressRule.Host == "" {
+			return nil
+		}
+
+		if !common.StringSliceContainsString(hostnames, ingressRule.Host) {
+			hostnames = append(hostnames, ingressRule.Host)
+		}
+	}
+
+	// keep the route stable across reconciliations
+	sort.Strings(hostnames)
+	return hostnames
+}
+
+func (lc *lazyClient) getHTTPRouteRules(function *nuclioio.NuclioFunction,
+	ingressRules []networkingv1.IngressRule) []gatewayapi.RuleSpec {
+	var ruleSpecs []gatewayapi.RuleSpec
+	var paths []string
+
+	for _, ingressRule := range ingressRules {
+		if ingressRule.HTTP == nil {
+			continue
+		}
+
+		for _, ingressPath := range ingressRule.HTTP.Paths {
+			if common.StringSliceContainsString(paths, ingressPath.Path) {
+				continue
+			}
+			paths = append(paths, ingressPath.Path)
+
+			pathType := gatewayapi.PathMatchTypePathPrefix
+			if ingressPath.PathType != nil && *ingressPath.PathType == networkingv1.PathTypeExact {
+				pathType = gatewayapi.PathMatchTypeExact
+			}
+
+			ruleSpecs = append(ruleSpecs, gatewayapi.RuleSpec{
+				Path:     ingressPath.Path,
+				PathType: pathType,
+				RequestHeaders: map[string]string{
+					"X-Nuclio-Target": function.Name,
+				},
+				Backends: []gatewayapi.BackendSpec{
+					{
+						ServiceName: kube.ServiceNameFromFunctionName(function.Name),
+						ServicePort: abstract.FunctionContainerHTTPPort,
+					},
+				},
+			})
+		}
+	}
+
+	sort.Slice(ruleSpecs, func(i, j int) bool {
+		return ruleSpecs[i].Path < ruleSpecs[j].Path
+	})
+	return ruleSpecs
+}
+
+// waitFunctionHTTPRouteReadiness returns successfully once a gateway accepted the function's route
+func (lc *lazyClient) waitFunctionHTTPRouteReadiness(ctx context.Context,
+	function *nuclioio.NuclioFunction) error {
+	if lc.httpRouteManager == nil {
+		return nil
+	}
+
+	httpRoute, err := lc.httpRouteManager.Get(ctx,
+		kube.HTTPRouteNameFromFunctionName(function.Name),
+		function.Namespace)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get function route")
+	}
+
+	for _, parentStatus := range httpRoute.Status.Parents {
+		for _, condition := range parentStatus.Conditions {
+			if condition.Type == "Accepted" && condition.Status == metav1.ConditionTrue {
+				return nil
+			}
+		}
+	}
+
+	return errors.New("Function route was not accepted by any gateway yet")
+}
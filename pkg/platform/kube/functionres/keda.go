@@ -0,0 +1,496 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package functionres
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/platform/abstract"
+	"github.com/nuclio/nuclio/pkg/platform/kube"
+	nuclioio "github.com/nuclio/nuclio/pkg/platform/kube/apis/nuclio.io/v1beta1"
+	"github.com/nuclio/nuclio/pkg/platformconfig"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/nuclio/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+
+	// trigger annotations prefixed with this key override the metadata of the scaler derived from the trigger
+	// e.g. "keda.nuclio.io/lagThreshold: 50"
+	KEDAScalerMetadataAnnotationPrefix = "keda.nuclio.io/"
+
+	kedaPausedReplicasAnnotation = "autoscaling.keda.sh/paused-replicas"
+)
+
+var ScaledObjectGroupVersionResource = schema.GroupVersionResource{
+	Group:    "keda.sh",
+	Version:  "v1alpha1",
+	Resource: "scaledobjects",
+}
+
+// ScaledObject holds the subset of the KEDA ScaledObject resource nuclio manages
+type ScaledObject struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScaledObjectSpec   `json:"spec"`
+	Status ScaledObjectStatus `json:"status,omitempty"`
+}
+
+type ScaledObjectSpec struct {
+	ScaleTargetRef  ScaledObjectTarget   `json:"scaleTargetRef"`
+	PollingInterval *int32               `json:"pollingInterval,omitempty"`
+	CooldownPeriod  *int32               `json:"cooldownPeriod,omitempty"`
+	MinReplicaCount *int32               `json:"minReplicaCount,omitempty"`
+	MaxReplicaCount *int32               `json:"maxReplicaCount,omitempty"`
+	Triggers        []ScaledObjectScaler `json:"triggers"`
+}
+
+type ScaledObjectTarget struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Name       string `json:"name"`
+}
+
+type ScaledObjectScaler struct {
+	Type       string            `json:"type"`
+	Name       string            `json:"name,omitempty"`
+	MetricType string            `json:"metricType,omitempty"`
+	Metadata   map[string]string `json:"metadata"`
+}
+
+type ScaledObjectStatus struct {
+	Conditions     []ScaledObjectCondition `json:"conditions,omitempty"`
+	LastActiveTime *metav1.Time            `json:"lastActiveTime,omitempty"`
+}
+
+type ScaledObjectCondition struct {
+	Type    string                 `json:"type"`
+	Status  metav1.ConditionStatus `json:"status"`
+	Reason  string                 `json:"reason,omitempty"`
+	Message string                 `json:"message,omitempty"`
+}
+
+// GetCondition returns the condition of the given type (e.g. Ready, Active), or nil if it wasn't reported yet
+func (so *ScaledObject) GetCondition(conditionType string) *ScaledObjectCondition {
+	for conditionIndex := range so.Status.Conditions {
+		if so.Status.Conditions[conditionIndex].Type == conditionType {
+			return &so.Status.Conditions[conditionIndex]
+		}
+	}
+	return nil
+}
+
+// CompileAutoScalerStatus summarizes the scaled object state for the function status
+func (so *ScaledObject) CompileAutoScalerStatus() *functionconfig.AutoScalerStatus {
+	status := &functionconfig.AutoScalerStatus{
+		Kind: string(platformconfig.AutoScalerKindKEDA),
+		Name: so.Name,
+	}
+
+	if readyCondition := so.GetCondition("Ready"); readyCondition != nil {
+		status.Ready = readyCondition.Status == metav1.ConditionTrue
+		if !status.Ready {
+			status.Message = readyCondition.Message
+		}
+	}
+
+	if activeCondition := so.GetCondition("Active"); activeCondition != nil {
+		status.Active = activeCondition.Status == metav1.ConditionTrue
+	}
+
+	return status
+}
+
+func scaledObjectFromUnstructured(object *unstructured.Unstructured) (*ScaledObject, error) {
+	scaledObject := ScaledObject{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.UnstructuredContent(),
+		&scaledObject); err != nil {
+		return nil, errors.Wrap(err, "Failed to convert scaled object")
+	}
+
+	return &scaledObject, nil
+}
+
+func scaledObjectToUnstructured(scaledObject *ScaledObject) (*unstructured.Unstructured, error) {
+	scaledObject.APIVersion = ScaledObjectGroupVersionResource.GroupVersion().String()
+	scaledObject.Kind = "ScaledObject"
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(scaledObject)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to convert scaled object")
+	}
+
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+func (lc *lazyClient) createOrUpdateScaledObject(ctx context.Context,
+	functionLabels labels.Set,
+	function *nuclioio.NuclioFunction) (*ScaledObject, error) {
+
+	// without a dynamic client there's no way to manage custom resources
+	if lc.dynamicClient == nil {
+		return nil, nil
+	}
+
+	scaledObjects := lc.dynamicClient.Resource(ScaledObjectGroupVersionResource).Namespace(function.Namespace)
+	scaledObjectName := kube.ScaledObjectNameFromFunctionName(function.Name)
+	kedaEnabled := lc.autoScalerKind() == platformconfig.AutoScalerKindKEDA
+	minReplicas := function.GetComputedMinReplicas()
+	maxReplicas := function.GetComputedMaxReplicas()
+
+	populateScaledObject := func(scaledObject *ScaledObject) error {
+		scalers, err := lc.compileScaledObjectScalers(ctx, function)
+		if err != nil {
+			return errors.Wrap(err, "Failed to compile scalers")
+		}
+
+		// keda can only scale to zero on event driven scalers
+		resolvedMinReplicas := minReplicas
+		if resolvedMinReplicas == 0 && !lc.hasEventScalers(scalers) {
+			resolvedMinReplicas = 1
+		}
+
+		kedaConfig := lc.platformConfigurationProvider.GetPlatformConfiguration().Kube.AutoScaler.KEDA
+
+		scaledObject.Labels = functionLabels
+		scaledObject.Spec = ScaledObjectSpec{
+			ScaleTargetRef: ScaledObjectTarget{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       kube.DeploymentNameFromFunctionName(function.Name),
+			},
+			PollingInterval: kedaConfig.PollingIntervalSeconds,
+			CooldownPeriod:  kedaConfig.CooldownPeriodSeconds,
+			MinReplicaCount: &resolvedMinReplicas,
+			MaxReplicaCount: &maxReplicas,
+			Triggers:        scalers,
+		}
+
+		// hold the function at zero replicas while it is disabled or scaled to zero by nuclio
+		if scaledObject.Annotations == nil {
+			scaledObject.Annotations = map[string]string{}
+		}
+		if replicas := function.GetComputedReplicas(); replicas != nil && *replicas == 0 {
+			scaledObject.Annotations[kedaPausedReplicasAnnotation] = "0"
+		} else {
+			delete(scaledObject.Annotations, kedaPausedReplicasAnnotation)
+		}
+
+		return nil
+	}
+
+	getScaledObject := func() (interface{}, error) {
+		object, err := scaledObjects.Get(ctx, scaledObjectName, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return scaledObjectFromUnstructured(object)
+	}
+
+	scaledObjectIsDeleting := func(resource interface{}) bool {
+		return (resource).(*ScaledObject).ObjectMeta.DeletionTimestamp != nil
+	}
+
+	createScaledObject := func() (interface{}, error) {
+		if !kedaEnabled || minReplicas == maxReplicas {
+			return nil, nil
+		}
+
+		scaledObject := &ScaledObject{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      scaledObjectName,
+				Namespace: function.Namespace,
+			},
+		}
+
+		if err := populateScaledObject(scaledObject); err != nil {
+			return nil, err
+		}
+
+		object, err := scaledObjectToUnstructured(scaledObject)
+		if err != nil {
+			return nil, err
+		}
+
+		createdObject, err := scaledObjects.Create(ctx, object, metav1.CreateOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		return scaledObjectFromUnstructured(createdObject)
+	}
+
+	updateScaledObject := func(resourceToUpdate interface{}) (interface{}, error) {
+		scaledObject := resourceToUpdate.(*ScaledObject)
+
+		// the scaled object is no longer needed - either keda was turned off or the replicas are fixed
+		if !kedaEnabled || minReplicas == maxReplicas {
+			propagationPolicy := metav1.DeletePropagationForeground
+			deleteOptions := metav1.DeleteOptions{
+				PropagationPolicy: &propagationPolicy,
+			}
+
+			lc.logger.DebugWithCtx(ctx,
+				"Deleting scaled object",
+				"functionName", function.Name,
+				"name", scaledObject.Name,
+				"kedaEnabled", kedaEnabled)
+
+			return nil, scaledObjects.Delete(ctx, scaledObject.Name, deleteOptions)
+		}
+
+		if err := populateScaledObject(scaledObject); err != nil {
+			return nil, err
+		}
+
+		object, err := scaledObjectToUnstructured(scaledObject)
+		if err != nil {
+			return nil, err
+		}
+
+		updatedObject, err := scaledObjects.Update(ctx, object, metav1.UpdateOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		return scaledObjectFromUnstructured(updatedObject)
+	}
+
+	resource, err := lc.createOrUpdateResource(ctx,
+		"scaledObject",
+		getScaledObject,
+		scaledObjectIsDeleting,
+		createScaledObject,
+		updateScaledObject)
+
+	// a resource can be nil if it didn't meet preconditions and wasn't created
+	if err != nil || resource == nil {
+		return nil, err
+	}
+
+	return resource.(*ScaledObject), err
+}
+
+func (lc *lazyClient) deleteScaledObject(ctx context.Context,
+	namespace string,
+	name string,
+	deleteOptions metav1.DeleteOptions) error {
+	if lc.dynamicClient == nil {
+		return nil
+	}
+
+	scaledObjectName := kube.ScaledObjectNameFromFunctionName(name)
+	if err := lc.dynamicClient.Resource(ScaledObjectGroupVersionResource).
+		Namespace(namespace).
+		Delete(ctx, scaledObjectName, deleteOptions); err != nil {
+		return err
+	}
+
+	lc.logger.DebugWithCtx(ctx,
+		"Deleted scaled object",
+		"namespace", namespace,
+		"scaledObjectName", scaledObjectName)
+	return nil
+}
+
+func (lc *lazyClient) autoScalerKind() platformconfig.AutoScalerKind {
+	return lc.platformConfigurationProvider.GetPlatformConfiguration().Kube.AutoScaler.Kind
+}
+
+// compileScaledObjectScalers derives a keda scaler per supported trigger, falling back to cpu utilization
+// when no trigger can be scaled on
+func (lc *lazyClient) compileScaledObjectScalers(ctx context.Context,
+	function *nuclioio.NuclioFunction) ([]ScaledObjectScaler, error) {
+	var scalers []ScaledObjectScaler
+
+	// iterate in a stable order so that updates don't reorder the scalers
+	var triggerNames []string
+	for triggerName := range function.Spec.Triggers {
+		triggerNames = append(triggerNames, triggerName)
+	}
+	sort.Strings(triggerNames)
+
+	for _, triggerName := range triggerNames {
+		trigger := function.Spec.Triggers[triggerName]
+		if trigger.Disabled {
+			continue
+		}
+
+		scaler, err := lc.compileTriggerScaler(function, triggerName, trigger)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to compile scaler for trigger %s", triggerName)
+		}
+
+		if scaler == nil {
+			lc.logger.DebugWithCtx(ctx,
+				"Trigger has no keda scaler, skipping",
+				"functionName", function.Name,
+				"triggerName", triggerName,
+				"triggerKind", trigger.Kind)
+			continue
+		}
+
+		scalers = append(scalers, *scaler)
+	}
+
+	if len(scalers) == 0 {
+		targetCPU := function.Spec.TargetCPU
+		if targetCPU == 0 {
+			targetCPU = abstract.DefaultTargetCPU
+		}
+
+		scalers = append(scalers, ScaledObjectScaler{
+			Type:       "cpu",
+			MetricType: "Utilization",
+			Metadata: map[string]string{
+				"value": strconv.Itoa(targetCPU),
+			},
+		})
+	}
+
+	return scalers, nil
+}
+
+func (lc *lazyClient) compileTriggerScaler(function *nuclioio.NuclioFunction,
+	triggerName string,
+	trigger functionconfig.Trigger) (*ScaledObjectScaler, error) {
+
+	var attributes struct {
+		Brokers       []string
+		Topics        []string
+		ConsumerGroup string
+		QueueName     string
+		Schedule      string
+	}
+
+	if err := mapstructure.Decode(trigger.Attributes, &attributes); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode trigger attributes")
+	}
+
+	scaler := ScaledObjectScaler{
+		Name:     triggerName,
+		Metadata: map[string]string{},
+	}
+
+	backlogTarget, hasBacklogTarget := lc.getBacklogMessagesTarget(function)
+
+	switch trigger.Kind {
+	case "kafka-cluster", "kafka":
+		brokers := attributes.Brokers
+		if len(brokers) == 0 && trigger.URL != "" {
+			brokers = strings.Split(trigger.URL, ",")
+		}
+
+		scaler.Type = "kafka"
+		scaler.Metadata["bootstrapServers"] = strings.Join(brokers, ",")
+		scaler.Metadata["consumerGroup"] = attributes.ConsumerGroup
+
+		// with several topics, keda sums the lag of all the topics the consumer group consumes
+		if len(attributes.Topics) == 1 {
+			scaler.Metadata["topic"] = attributes.Topics[0]
+		}
+
+		if hasBacklogTarget {
+			scaler.Metadata["lagThreshold"] = strconv.Itoa(backlogTarget)
+		}
+
+	case "rabbit-mq", "rabbitMq":
+		if attributes.QueueName == "" {
+			return nil, nil
+		}
+
+		scaler.Type = "rabbitmq"
+		scaler.Metadata["host"] = trigger.URL
+		scaler.Metadata["queueName"] = attributes.QueueName
+		scaler.Metadata["mode"] = "QueueLength"
+
+		// without a target, a replica is given as many messages as it can process at once
+		if !hasBacklogTarget {
+			backlogTarget = trigger.MaxWorkers
+			if backlogTarget < 1 {
+				backlogTarget = 1
+			}
+		}
+		scaler.Metadata["value"] = strconv.Itoa(backlogTarget)
+
+	case "cron":
+
+		// an interval trigger fires continuously, and a window needs its end given explicitly
+		if attributes.Schedule == "" ||
+			trigger.Annotations[KEDAScalerMetadataAnnotationPrefix+"end"] == "" {
+			return nil, nil
+		}
+
+		schedule, err := lc.normalizeCronTriggerScheduleInput(attributes.Schedule)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to normalize cron schedule")
+		}
+
+		scaler.Type = "cron"
+		scaler.Metadata["timezone"] = "Etc/UTC"
+		scaler.Metadata["start"] = schedule
+		scaler.Metadata["desiredReplicas"] = strconv.Itoa(int(function.GetComputedMaxReplicas()))
+
+	// nats triggers consume core nats queue subscriptions, which have no jetstream consumer to read the lag of,
+	// and http traffic doesn't pass through the keda http add-on interceptor, so neither is scaled on
+	default:
+		return nil, nil
+	}
+
+	// let the user tune or complete the derived metadata
+	for annotationKey, annotationValue := range trigger.Annotations {
+		if metadataKey := strings.TrimPrefix(annotationKey,
+			KEDAScalerMetadataAnnotationPrefix); metadataKey != annotationKey && metadataKey != "" {
+			scaler.Metadata[metadataKey] = annotationValue
+		}
+	}
+
+	return &scaler, nil
+}
+
+// getBacklogMessagesTarget returns the per replica backlog the function autoscales on, if it declares one
+func (lc *lazyClient) getBacklogMessagesTarget(function *nuclioio.NuclioFunction) (int, bool) {
+	for _, autoScaleMetric := range function.Spec.AutoScaleMetrics {
+		if autoScaleMetric.SourceType == functionconfig.AutoScaleMetricSourceTypeTriggerBacklog &&
+			autoScaleMetric.MetricName == functionconfig.TriggerBacklogMessagesMetricName &&
+			autoScaleMetric.Threshold > 0 {
+			return autoScaleMetric.Threshold, true
+		}
+	}
+
+	return 0, false
+}
+
+func (lc *lazyClient) hasEventScalers(scalers []ScaledObjectScaler) bool {
+	for _, scaler := range scalers {
+		if scaler.Type != "cpu" && scaler.Type != "memory" {
+			return true
+		}
+	}
+	return false
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"sort"
 	"strconv"
 	"strings"
@@ -36,6 +37,7 @@ import (
 	nuclioio "github.com/nuclio/nuclio/pkg/platform/kube/apis/nuclio.io/v1beta1"
 	"github.com/nuclio/nuclio/pkg/platform/kube/client"
 	nuclioioclient "github.com/nuclio/nuclio/pkg/platform/kube/client/clientset/versioned"
+	"github.com/nuclio/nuclio/pkg/platform/kube/gatewayapi"
 	"github.com/nuclio/nuclio/pkg/platformconfig"
 	"github.com/nuclio/nuclio/pkg/processor"
 	"github.com/nuclio/nuclio/pkg/processor/config"
@@ -55,18 +57,21 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apiresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 )
 
 const (
 	ContainerHTTPPortName   = "http"
-	containerMetricPort     = 8090
+	ContainerMetricPort     = kube.FunctionContainerMetricPort
 	containerMetricPortName = "metrics"
 )
 
@@ -85,6 +90,8 @@ type lazyClient struct {
 	logger                        logger.Logger
 	kubeClientSet                 kubernetes.Interface
 	nuclioClientSet               nuclioioclient.Interface
+	dynamicClient                 dynamic.Interface
+	httpRouteManager              *gatewayapi.Manager
 	classLabels                   labels.Set
 	platformConfigurationProvider PlatformConfigurationProvider
 	nodeScaleUpSleepTimeout       time.Duration
@@ -92,12 +99,14 @@ type lazyClient struct {
 
 func NewLazyClient(parentLogger logger.Logger,
 	kubeClientSet kubernetes.Interface,
-	nuclioClientSet nuclioioclient.Interface) (Client, error) {
+	nuclioClientSet nuclioioclient.Interface,
+	dynamicClient dynamic.Interface) (Client, error) {
 
 	newClient := lazyClient{
 		logger:          parentLogger.GetChild("functionres"),
 		kubeClientSet:   kubeClientSet,
 		nuclioClientSet: nuclioClientSet,
+		dynamicClient:   dynamicClient,
 		classLabels:     make(labels.Set),
 
 		// TODO: make this value configurable
@@ -107,6 +116,14 @@ func NewLazyClient(parentLogger logger.Logger,
 		nodeScaleUpSleepTimeout: 60 * time.Second,
 	}
 
+	if dynamicClient != nil {
+		httpRouteManager, err := gatewayapi.NewManager(newClient.logger, dynamicClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to create gateway API route manager")
+		}
+		newClient.httpRouteManager = httpRouteManager
+	}
+
 	newClient.initClassLabels()
 
 	return &newClient, nil
@@ -169,40 +186,11 @@ func (lc *lazyClient) Get(ctx context.Context, namespace string, name string) (R
 func (lc *lazyClient) CreateOrUpdate(ctx context.Context,
 	function *nuclioio.NuclioFunction,
 	imagePullSecrets string) (Resources, error) {
-	var err error
-
-	// get labels from the function and add class labels
-	functionLabels := lc.getFunctionLabels(function)
-
-	// set a few constants
-	functionLabels["nuclio.io/function-name"] = function.Name
-
-	// TODO: remove when versioning is back in
-	function.Spec.Version = -1
-	function.Spec.Alias = "latest"
-	functionLabels["nuclio.io/function-version"] = "latest"
-
 	resources := lazyResources{}
 
-	platformConfig := lc.platformConfigurationProvider.GetPlatformConfiguration()
-	for _, augmentedConfig := range platformConfig.FunctionAugmentedConfigs {
-
-		selector, err := metav1.LabelSelectorAsSelector(&augmentedConfig.LabelSelector)
-		if err != nil {
-			return nil, errors.Wrap(err, "Failed to get selector from label selector")
-		}
-
-		// if the label matches any of the function labels, augment the function with provided function config
-		if selector.Matches(functionLabels) {
-			encodedFunctionConfig, err := yaml.Marshal(augmentedConfig.FunctionConfig)
-			if err != nil {
-				return nil, errors.Wrap(err, "Failed to marshal augmented function config")
-			}
-
-			if err := yaml.Unmarshal(encodedFunctionConfig, function); err != nil {
-				return nil, errors.Wrap(err, "Failed to join augmented function config into target function")
-			}
-		}
+	functionLabels, err := lc.enrichFunctionForDeploy(function)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to enrich function")
 	}
 
 	// create or update the applicable configMap
@@ -230,11 +218,40 @@ func (lc *lazyClient) CreateOrUpdate(ctx context.Context,
 		return nil, errors.Wrap(err, "Failed to create/update HPA")
 	}
 
+	// create, update or delete the keda scaled object
+	if resources.scaledObject, err = lc.createOrUpdateScaledObject(ctx,
+		functionLabels,
+		function); err != nil {
+		return nil, errors.Wrap(err, "Failed to create/update scaled object")
+	}
+
 	// create or update ingress
 	if resources.ingress, err = lc.createOrUpdateIngress(ctx, functionLabels, function); err != nil {
 		return nil, errors.Wrap(err, "Failed to create/update ingress")
 	}
 
+	// create, update or delete the gateway API route
+	if resources.httpRoute, err = lc.createOrUpdateHTTPRoute(ctx, functionLabels, function); err != nil {
+		return nil, errors.Wrap(err, "Failed to create/update route")
+	}
+
+	// create, update or delete the PDB
+	if resources.podDisruptionBudget, err = lc.createOrUpdatePodDisruptionBudget(ctx,
+		functionLabels,
+		function); err != nil {
+		return nil, errors.Wrap(err, "Failed to create/update PDB")
+	}
+
+	// create, update or delete the network policy
+	if resources.networkPolicy, err = lc.createOrUpdateNetworkPolicy(ctx, function); err != nil {
+		return nil, errors.Wrap(err, "Failed to create/update network policy")
+	}
+
+	// create, update or delete the role allowing the function to look up the functions it invokes
+	if err = lc.createOrUpdateInvokerRole(ctx, functionLabels, function); err != nil {
+		return nil, errors.Wrap(err, "Failed to create/update invoker role")
+	}
+
 	// whether to use kubernetes cron job to invoke nuclio function cron trigger
 	if lc.platformConfigurationProvider.GetPlatformConfiguration().CronTriggerCreationMode == platformconfig.KubeCronTriggerCreationMode {
 		if resources.cronJobs, err = lc.createOrUpdateCronJobs(ctx, functionLabels, function, &resources); err != nil {
@@ -249,6 +266,45 @@ func (lc *lazyClient) CreateOrUpdate(ctx context.Context,
 	return &resources, nil
 }
 
+// enrichFunctionForDeploy applies the constants and augmented configs the function's resources are rendered with,
+// returning the labels of its resources
+func (lc *lazyClient) enrichFunctionForDeploy(function *nuclioio.NuclioFunction) (labels.Set, error) {
+
+	// get labels from the function and add class labels
+	functionLabels := lc.getFunctionLabels(function)
+
+	// set a few constants
+	functionLabels["nuclio.io/function-name"] = function.Name
+
+	// TODO: remove when versioning is back in
+	function.Spec.Version = -1
+	function.Spec.Alias = "latest"
+	functionLabels["nuclio.io/function-version"] = "latest"
+
+	platformConfig := lc.platformConfigurationProvider.GetPlatformConfiguration()
+	for _, augmentedConfig := range platformConfig.FunctionAugmentedConfigs {
+
+		selector, err := metav1.LabelSelectorAsSelector(&augmentedConfig.LabelSelector)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to get selector from label selector")
+		}
+
+		// if the label matches any of the function labels, augment the function with provided function config
+		if selector.Matches(functionLabels) {
+			encodedFunctionConfig, err := yaml.Marshal(augmentedConfig.FunctionConfig)
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed to marshal augmented function config")
+			}
+
+			if err := yaml.Unmarshal(encodedFunctionConfig, function); err != nil {
+				return nil, errors.Wrap(err, "Failed to join augmented function config into target function")
+			}
+		}
+	}
+
+	return functionLabels, nil
+}
+
 func (lc *lazyClient) WaitAvailable(ctx context.Context,
 	function *nuclioio.NuclioFunction,
 	functionResourcesCreateOrUpdateTimestamp time.Time) (error, functionconfig.FunctionState) {
@@ -370,7 +426,12 @@ func (lc *lazyClient) WaitAvailable(ctx context.Context,
 					continue
 				}
 
-				if err := lc.waitFunctionIngressReadiness(ctx, function); err != nil {
+				waitReadiness := lc.waitFunctionIngressReadiness
+				if lc.exposedByGatewayAPI() {
+					waitReadiness = lc.waitFunctionHTTPRouteReadiness
+				}
+
+				if err := waitReadiness(ctx, function); err != nil {
 					lc.logger.WarnWithCtx(ctx,
 						"Function ingress is not ready yet, continuing",
 						"err", err.Error(),
@@ -394,6 +455,11 @@ func (lc *lazyClient) Delete(ctx context.Context, namespace string, name string)
 		PropagationPolicy: &propagationPolicy,
 	}
 
+	// Delete canary resources of an ongoing rollout if exist
+	if err := lc.DeleteCanary(ctx, namespace, name); err != nil {
+		return errors.Wrap(err, "Failed to delete canary resources")
+	}
+
 	// Delete ingress
 	ingressName := kube.IngressNameFromFunctionName(name)
 	err := lc.kubeClientSet.NetworkingV1().Ingresses(namespace).Delete(ctx, ingressName, deleteOptions)
@@ -416,6 +482,65 @@ func (lc *lazyClient) Delete(ctx context.Context, namespace string, name string)
 		lc.logger.DebugWithCtx(ctx, "Deleted HPA", "namespace", namespace, "hpaName", hpaName)
 	}
 
+	// Delete scaled object if exists
+	if err := lc.deleteScaledObject(ctx, namespace, name, deleteOptions); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "Failed to delete scaled object")
+		}
+	}
+
+	// Delete route if exists
+	if err := lc.deleteHTTPRoute(ctx, namespace, name); err != nil {
+		return errors.Wrap(err, "Failed to delete route")
+	}
+
+	// Delete network policy if exists
+	networkPolicyName := kube.NetworkPolicyNameFromFunctionName(name)
+	err = lc.kubeClientSet.NetworkingV1().NetworkPolicies(namespace).Delete(ctx, networkPolicyName, deleteOptions)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "Failed to delete network policy")
+		}
+	} else {
+		lc.logger.DebugWithCtx(ctx,
+			"Deleted network policy",
+			"namespace", namespace,
+			"networkPolicyName", networkPolicyName)
+	}
+
+	// Delete invoker role and role binding if exist
+	invokerRoleName := kube.InvokerRoleNameFromFunctionName(name)
+	err = lc.kubeClientSet.RbacV1().RoleBindings(namespace).Delete(ctx, invokerRoleName, deleteOptions)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "Failed to delete invoker role binding")
+	}
+
+	err = lc.kubeClientSet.RbacV1().Roles(namespace).Delete(ctx, invokerRoleName, deleteOptions)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "Failed to delete invoker role")
+		}
+	} else {
+		lc.logger.DebugWithCtx(ctx,
+			"Deleted invoker role",
+			"namespace", namespace,
+			"invokerRoleName", invokerRoleName)
+	}
+
+	// Delete PDB if exists
+	podDisruptionBudgetName := kube.PodDisruptionBudgetNameFromFunctionName(name)
+	err = lc.kubeClientSet.PolicyV1().PodDisruptionBudgets(namespace).Delete(ctx, podDisruptionBudgetName, deleteOptions)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "Failed to delete PDB")
+		}
+	} else {
+		lc.logger.DebugWithCtx(ctx,
+			"Deleted PDB",
+			"namespace", namespace,
+			"podDisruptionBudgetName", podDisruptionBudgetName)
+	}
+
 	// Delete Service if exists
 	serviceName := kube.ServiceNameFromFunctionName(name)
 	err = lc.kubeClientSet.CoreV1().Services(namespace).Delete(ctx, serviceName, deleteOptions)
@@ -940,6 +1065,7 @@ func (lc *lazyClient) createOrUpdateDeployment(ctx context.Context,
 		container := v1.Container{Name: client.FunctionContainerName}
 		lc.populateDeploymentContainer(ctx, functionLabels, function, &container)
 		container.VolumeMounts = volumeMounts
+		sidecars, initContainers := lc.getExtraContainers(function)
 
 		deploymentSpec := appsv1.DeploymentSpec{
 			Selector: &metav1.LabelSelector{
@@ -954,9 +1080,8 @@ func (lc *lazyClient) createOrUpdateDeployment(ctx context.Context,
 					Annotations: podAnnotations,
 				},
 				Spec: v1.PodSpec{
-					Containers: []v1.Container{
-						container,
-					},
+					Containers:         append([]v1.Container{container}, sidecars...),
+					InitContainers:     initContainers,
 					Volumes:            volumes,
 					ServiceAccountName: function.Spec.ServiceAccount,
 					SecurityContext:    function.Spec.SecurityContext,
@@ -1033,6 +1158,11 @@ func (lc *lazyClient) createOrUpdateDeployment(ctx context.Context,
 		lc.populateDeploymentContainer(ctx, functionLabels, function, &deployment.Spec.Template.Spec.Containers[0])
 		deployment.Spec.Template.Spec.Volumes = volumes
 		deployment.Spec.Template.Spec.Containers[0].VolumeMounts = volumeMounts
+
+		// the processor container is always first, followed by the sidecars
+		sidecars, initContainers := lc.getExtraContainers(function)
+		deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers[:1], sidecars...)
+		deployment.Spec.Template.Spec.InitContainers = initContainers
 		deployment.Spec.Template.Spec.SecurityContext = function.Spec.SecurityContext
 
 		if function.Spec.ServiceAccount != "" {
@@ -1206,8 +1336,11 @@ func (lc *lazyClient) createOrUpdateHorizontalPodAutoscaler(ctx context.Context,
 		return (resource).(*autosv2.HorizontalPodAutoscaler).ObjectMeta.DeletionTimestamp != nil
 	}
 
+	// keda creates and owns its own hpa
+	kedaEnabled := lc.autoScalerKind() == platformconfig.AutoScalerKindKEDA
+
 	createHorizontalPodAutoscaler := func() (interface{}, error) {
-		if minReplicas == maxReplicas {
+		if minReplicas == maxReplicas || kedaEnabled {
 			return nil, nil
 		}
 
@@ -1251,14 +1384,14 @@ func (lc *lazyClient) createOrUpdateHorizontalPodAutoscaler(ctx context.Context,
 		hpa.Spec.MaxReplicas = maxReplicas
 
 		// when the min replicas equal the max replicas, there's no need for hpa resourceToUpdate
-		if minReplicas == maxReplicas {
+		if minReplicas == maxReplicas || kedaEnabled {
 			propogationPolicy := metav1.DeletePropagationForeground
 			deleteOptions := &metav1.DeleteOptions{
 				PropagationPolicy: &propogationPolicy,
 			}
 
 			lc.logger.DebugWithCtx(ctx,
-				"Deleting hpa - min replicas and max replicas are equal",
+				"Deleting hpa - min replicas and max replicas are equal or keda is enabled",
 				"functionName", function.Name,
 				"name", hpa.Name)
 
@@ -1286,6 +1419,604 @@ func (lc *lazyClient) createOrUpdateHorizontalPodAutoscaler(ctx context.Context,
 	return resource.(*autosv2.HorizontalPodAutoscaler), err
 }
 
+func (lc *lazyClient) createOrUpdatePodDisruptionBudget(ctx context.Context,
+	functionLabels labels.Set,
+	function *nuclioio.NuclioFunction) (*policyv1.PodDisruptionBudget, error) {
+
+	disruptionBudget, err := lc.resolveDisruptionBudget(function)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to resolve disruption budget")
+	}
+
+	// a function without replicas (disabled or scaled to zero) has nothing to protect - a budget would
+	// only report that no disruptions are allowed
+	if computedReplicas := function.GetComputedReplicas(); computedReplicas != nil && *computedReplicas == 0 {
+		disruptionBudget = nil
+	}
+
+	lc.logger.DebugWithCtx(ctx,
+		"Create/Update PDB",
+		"functionName", function.Name,
+		"disruptionBudget", disruptionBudget)
+
+	getPodDisruptionBudget := func() (interface{}, error) {
+		return lc.kubeClientSet.PolicyV1().
+			PodDisruptionBudgets(function.Namespace).
+			Get(ctx, kube.PodDisruptionBudgetNameFromFunctionName(function.Name), metav1.GetOptions{})
+	}
+
+	podDisruptionBudgetIsDeleting := func(resource interface{}) bool {
+		return (resource).(*policyv1.PodDisruptionBudget).ObjectMeta.DeletionTimestamp != nil
+	}
+
+	createPodDisruptionBudget := func() (interface{}, error) {
+		if disruptionBudget == nil {
+			return nil, nil
+		}
+
+		podDisruptionBudget := policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      kube.PodDisruptionBudgetNameFromFunctionName(function.Name),
+				Namespace: function.Namespace,
+				Labels:    functionLabels,
+			},
+		}
+		lc.populatePodDisruptionBudgetSpec(functionLabels, disruptionBudget, &podDisruptionBudget.Spec)
+
+		return lc.kubeClientSet.PolicyV1().
+			PodDisruptionBudgets(function.Namespace).
+			Create(ctx, &podDisruptionBudget, metav1.CreateOptions{})
+	}
+
+	updatePodDisruptionBudget := func(resourceToUpdate interface{}) (interface{}, error) {
+		podDisruptionBudget := resourceToUpdate.(*policyv1.PodDisruptionBudget)
+
+		// the budget was removed, or the function no longer has replicas
+		if disruptionBudget == nil {
+			propagationPolicy := metav1.DeletePropagationForeground
+			deleteOptions := metav1.DeleteOptions{
+				PropagationPolicy: &propagationPolicy,
+			}
+
+			lc.logger.DebugWithCtx(ctx,
+				"Deleting PDB",
+				"functionName", function.Name,
+				"name", podDisruptionBudget.Name)
+
+			err := lc.kubeClientSet.PolicyV1().
+				PodDisruptionBudgets(function.Namespace).
+				Delete(ctx, podDisruptionBudget.Name, deleteOptions)
+			return nil, err
+		}
+
+		podDisruptionBudget.Labels = functionLabels
+		lc.populatePodDisruptionBudgetSpec(functionLabels, disruptionBudget, &podDisruptionBudget.Spec)
+
+		return lc.kubeClientSet.PolicyV1().
+			PodDisruptionBudgets(function.Namespace).
+			Update(ctx, podDisruptionBudget, metav1.UpdateOptions{})
+	}
+
+	resource, err := lc.createOrUpdateResource(ctx,
+		"pdb",
+		getPodDisruptionBudget,
+		podDisruptionBudgetIsDeleting,
+		createPodDisruptionBudget,
+		updatePodDisruptionBudget)
+
+	// a resource can be nil if it didn't meet preconditions and wasn't created
+	if err != nil || resource == nil {
+		return nil, err
+	}
+
+	return resource.(*policyv1.PodDisruptionBudget), err
+}
+
+// resolveDisruptionBudget returns the function's disruption budget, falling back to the platform default.
+// returns nil if the function should not have a budget
+func (lc *lazyClient) resolveDisruptionBudget(function *nuclioio.NuclioFunction) (*functionconfig.DisruptionBudget, error) {
+	disruptionBudget := function.Spec.DisruptionBudget
+	if disruptionBudget == nil {
+		disruptionBudget = lc.platformConfigurationProvider.GetPlatformConfiguration().Kube.DefaultFunctionDisruptionBudget
+	}
+
+	if disruptionBudget == nil || disruptionBudget.Disable {
+		return nil, nil
+	}
+
+	if disruptionBudget.MinAvailable != nil && disruptionBudget.MaxUnavailable != nil {
+		return nil, errors.New("Disruption budget may specify either minAvailable or maxUnavailable, not both")
+	}
+
+	if disruptionBudget.MinAvailable == nil && disruptionBudget.MaxUnavailable == nil {
+		return nil, errors.New("Disruption budget must specify either minAvailable or maxUnavailable")
+	}
+
+	return disruptionBudget, nil
+}
+
+func (lc *lazyClient) populatePodDisruptionBudgetSpec(functionLabels labels.Set,
+	disruptionBudget *functionconfig.DisruptionBudget,
+	spec *policyv1.PodDisruptionBudgetSpec) {
+
+	spec.MinAvailable = disruptionBudget.MinAvailable
+	spec.MaxUnavailable = disruptionBudget.MaxUnavailable
+	spec.Selector = &metav1.LabelSelector{
+		MatchLabels: functionLabels,
+	}
+}
+
+// createOrUpdateInvokerRole allows the function's service account to get the services of the functions it may
+// invoke, through which the invoker verifies their project before invoking them. the role and its binding are
+// deleted once the function may no longer invoke other functions
+func (lc *lazyClient) createOrUpdateInvokerRole(ctx context.Context,
+	functionLabels labels.Set,
+	function *nuclioio.NuclioFunction) error {
+
+	invokerRoleName := kube.InvokerRoleNameFromFunctionName(function.Name)
+	invokesFunctions := len(function.Spec.CallableFunctions) > 0
+
+	propagationPolicy := metav1.DeletePropagationForeground
+	deleteOptions := metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	}
+
+	getRole := func() (interface{}, error) {
+		return lc.kubeClientSet.RbacV1().
+			Roles(function.Namespace).
+			Get(ctx, invokerRoleName, metav1.GetOptions{})
+	}
+
+	roleIsDeleting := func(resource interface{}) bool {
+		return (resource).(*rbacv1.Role).ObjectMeta.DeletionTimestamp != nil
+	}
+
+	createRole := func() (interface{}, error) {
+		if !invokesFunctions {
+			return nil, nil
+		}
+
+		role := rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      invokerRoleName,
+				Namespace: function.Namespace,
+				Labels:    functionLabels,
+			},
+			Rules: lc.getInvokerRoleRules(function),
+		}
+
+		return lc.kubeClientSet.RbacV1().
+			Roles(function.Namespace).
+			Create(ctx, &role, metav1.CreateOptions{})
+	}
+
+	updateRole := func(resourceToUpdate interface{}) (interface{}, error) {
+		role := resourceToUpdate.(*rbacv1.Role)
+
+		if !invokesFunctions {
+			lc.logger.DebugWithCtx(ctx,
+				"Deleting invoker role",
+				"functionName", function.Name,
+				"name", role.Name)
+
+			err := lc.kubeClientSet.RbacV1().
+				Roles(function.Namespace).
+				Delete(ctx, role.Name, deleteOptions)
+			return nil, err
+		}
+
+		role.Labels = functionLabels
+		role.Rules = lc.getInvokerRoleRules(function)
+
+		return lc.kubeClientSet.RbacV1().
+			Roles(function.Namespace).
+			Update(ctx, role, metav1.UpdateOptions{})
+	}
+
+	if _, err := lc.createOrUpdateResource(ctx,
+		"invokerRole",
+		getRole,
+		roleIsDeleting,
+		createRole,
+		updateRole); err != nil {
+		return errors.Wrap(err, "Failed to create/update role")
+	}
+
+	getRoleBinding := func() (interface{}, error) {
+		return lc.kubeClientSet.RbacV1().
+			RoleBindings(function.Namespace).
+			Get(ctx, invokerRoleName, metav1.GetOptions{})
+	}
+
+	roleBindingIsDeleting := func(resource interface{}) bool {
+		return (resource).(*rbacv1.RoleBinding).ObjectMeta.DeletionTimestamp != nil
+	}
+
+	createRoleBinding := func() (interface{}, error) {
+		if !invokesFunctions {
+			return nil, nil
+		}
+
+		roleBinding := rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      invokerRoleName,
+				Namespace: function.Namespace,
+				Labels:    functionLabels,
+			},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "Role",
+				Name:     invokerRoleName,
+			},
+			Subjects: lc.getInvokerRoleBindingSubjects(function),
+		}
+
+		return lc.kubeClientSet.RbacV1().
+			RoleBindings(function.Namespace).
+			Create(ctx, &roleBinding, metav1.CreateOptions{})
+	}
+
+	updateRoleBinding := func(resourceToUpdate interface{}) (interface{}, error) {
+		roleBinding := resourceToUpdate.(*rbacv1.RoleBinding)
+
+		if !invokesFunctions {
+			lc.logger.DebugWithCtx(ctx,
+				"Deleting invoker role binding",
+				"functionName", function.Name,
+				"name", roleBinding.Name)
+
+			err := lc.kubeClientSet.RbacV1().
+				RoleBindings(function.Namespace).
+				Delete(ctx, roleBinding.Name, deleteOptions)
+			return nil, err
+		}
+
+		// the role reference is immutable, only the service account may change
+		roleBinding.Labels = functionLabels
+		roleBinding.Subjects = lc.getInvokerRoleBindingSubjects(function)
+
+		return lc.kubeClientSet.RbacV1().
+			RoleBindings(function.Namespace).
+			Update(ctx, roleBinding, metav1.UpdateOptions{})
+	}
+
+	if _, err := lc.createOrUpdateResource(ctx,
+		"invokerRoleBinding",
+		getRoleBinding,
+		roleBindingIsDeleting,
+		createRoleBinding,
+		updateRoleBinding); err != nil {
+		return errors.Wrap(err, "Failed to create/update role binding")
+	}
+
+	return nil
+}
+
+// getInvokerRoleRules allows getting the services of the callable functions, or of all functions if any name
+// may be invoked
+func (lc *lazyClient) getInvokerRoleRules(function *nuclioio.NuclioFunction) []rbacv1.PolicyRule {
+	var serviceNames []string
+
+	for _, callableFunction := range function.Spec.CallableFunctions {
+		if callableFunction.Name == "*" {
+			serviceNames = nil
+			break
+		}
+
+		serviceNames = append(serviceNames, kube.ServiceNameFromFunctionName(callableFunction.Name))
+	}
+
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"services"},
+			Verbs:         []string{"get"},
+			ResourceNames: common.RemoveDuplicatesFromSliceString(serviceNames),
+		},
+	}
+}
+
+func (lc *lazyClient) getInvokerRoleBindingSubjects(function *nuclioio.NuclioFunction) []rbacv1.Subject {
+	serviceAccountName := function.Spec.ServiceAccount
+	if serviceAccountName == "" {
+		serviceAccountName = "default"
+	}
+
+	return []rbacv1.Subject{
+		{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      serviceAccountName,
+			Namespace: function.Namespace,
+		},
+	}
+}
+
+func (lc *lazyClient) createOrUpdateNetworkPolicy(ctx context.Context,
+	function *nuclioio.NuclioFunction) (*networkingv1.NetworkPolicy, error) {
+
+	functionNetworkPolicy, err := lc.resolveNetworkPolicy(ctx, function)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to resolve network policy")
+	}
+
+	lc.logger.DebugWithCtx(ctx,
+		"Create/Update network policy",
+		"functionName", function.Name,
+		"networkPolicy", functionNetworkPolicy)
+
+	// network policy labels are the function's identifying labels, not all of its labels
+	networkPolicyLabels := labels.Set{
+		"nuclio.io/class":         "function",
+		"nuclio.io/function-name": function.Name,
+	}
+
+	getNetworkPolicy := func() (interface{}, error) {
+		return lc.kubeClientSet.NetworkingV1().
+			NetworkPolicies(function.Namespace).
+			Get(ctx, kube.NetworkPolicyNameFromFunctionName(function.Name), metav1.GetOptions{})
+	}
+
+	networkPolicyIsDeleting := func(resource interface{}) bool {
+		return (resource).(*networkingv1.NetworkPolicy).ObjectMeta.DeletionTimestamp != nil
+	}
+
+	createNetworkPolicy := func() (interface{}, error) {
+		if functionNetworkPolicy == nil {
+			return nil, nil
+		}
+
+		networkPolicy := networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      kube.NetworkPolicyNameFromFunctionName(function.Name),
+				Namespace: function.Namespace,
+				Labels:    networkPolicyLabels,
+			},
+		}
+		lc.populateNetworkPolicySpec(function, functionNetworkPolicy, networkPolicyLabels, &networkPolicy.Spec)
+
+		return lc.kubeClientSet.NetworkingV1().
+			NetworkPolicies(function.Namespace).
+			Create(ctx, &networkPolicy, metav1.CreateOptions{})
+	}
+
+	updateNetworkPolicy := func(resourceToUpdate interface{}) (interface{}, error) {
+		networkPolicy := resourceToUpdate.(*networkingv1.NetworkPolicy)
+
+		// the network policy was removed from the function (and its project)
+		if functionNetworkPolicy == nil {
+			propagationPolicy := metav1.DeletePropagationForeground
+			deleteOptions := metav1.DeleteOptions{
+				PropagationPolicy: &propagationPolicy,
+			}
+
+			lc.logger.DebugWithCtx(ctx,
+				"Deleting network policy",
+				"functionName", function.Name,
+				"name", networkPolicy.Name)
+
+			err := lc.kubeClientSet.NetworkingV1().
+				NetworkPolicies(function.Namespace).
+				Delete(ctx, networkPolicy.Name, deleteOptions)
+			return nil, err
+		}
+
+		networkPolicy.Labels = networkPolicyLabels
+		networkPolicy.Spec = networkingv1.NetworkPolicySpec{}
+		lc.populateNetworkPolicySpec(function, functionNetworkPolicy, networkPolicyLabels, &networkPolicy.Spec)
+
+		return lc.kubeClientSet.NetworkingV1().
+			NetworkPolicies(function.Namespace).
+			Update(ctx, networkPolicy, metav1.UpdateOptions{})
+	}
+
+	resource, err := lc.createOrUpdateResource(ctx,
+		"networkPolicy",
+		getNetworkPolicy,
+		networkPolicyIsDeleting,
+		createNetworkPolicy,
+		updateNetworkPolicy)
+
+	// a resource can be nil if it didn't meet preconditions and wasn't created
+	if err != nil || resource == nil {
+		return nil, err
+	}
+
+	return resource.(*networkingv1.NetworkPolicy), err
+}
+
+// resolveNetworkPolicy returns the function's network policy, falling back to its project's default.
+// returns nil if the function should not have a network policy
+func (lc *lazyClient) resolveNetworkPolicy(ctx context.Context,
+	function *nuclioio.NuclioFunction) (*functionconfig.NetworkPolicy, error) {
+
+	functionNetworkPolicy := function.Spec.NetworkPolicy
+
+	if functionNetworkPolicy == nil {
+		projectName := function.Labels[common.NuclioResourceLabelKeyProjectName]
+		if projectName != "" {
+			project, err := lc.nuclioClientSet.NuclioV1beta1().
+				NuclioProjects(function.Namespace).
+				Get(ctx, projectName, metav1.GetOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, errors.Wrap(err, "Failed to get function project")
+			}
+
+			if err == nil {
+				functionNetworkPolicy = project.Spec.DefaultFunctionNetworkPolicy
+			}
+		}
+	}
+
+	if functionNetworkPolicy == nil || functionNetworkPolicy.Disable {
+		return nil, nil
+	}
+
+	cidrs := functionNetworkPolicy.Ingress.AllowCIDRs
+	if functionNetworkPolicy.Egress != nil {
+		cidrs = append(cidrs[:len(cidrs):len(cidrs)], functionNetworkPolicy.Egress.AllowCIDRs...)
+	}
+
+	for _, cidr := range cidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, errors.Wrapf(err, "Invalid network policy CIDR: %s", cidr)
+		}
+	}
+
+	return functionNetworkPolicy, nil
+}
+
+func (lc *lazyClient) populateNetworkPolicySpec(function *nuclioio.NuclioFunction,
+	functionNetworkPolicy *functionconfig.NetworkPolicy,
+	networkPolicyLabels labels.Set,
+	spec *networkingv1.NetworkPolicySpec) {
+
+	spec.PodSelector = metav1.LabelSelector{
+		MatchLabels: networkPolicyLabels,
+	}
+	spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+
+	// nuclio services (e.g. dashboard invocations, DLX forwarding requests to scaled to zero functions)
+	ingressPeers := []networkingv1.NetworkPolicyPeer{
+		{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"nuclio.io/class": "service",
+				},
+			},
+		},
+	}
+
+	ingressPeers = append(ingressPeers, lc.compileNetworkPolicyPeers(function,
+		functionNetworkPolicy.Ingress.AllowSameProject,
+		functionNetworkPolicy.Ingress.AllowFunctions,
+		functionNetworkPolicy.Ingress.AllowCIDRs)...)
+
+	if functionNetworkPolicy.Ingress.AllowIngressController {
+		ingressControllerPeers := lc.platformConfigurationProvider.GetPlatformConfiguration().
+			Kube.IngressControllerNetworkPolicyPeers
+
+		if len(ingressControllerPeers) == 0 {
+			ingressControllerPeers = []networkingv1.NetworkPolicyPeer{
+				{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"kubernetes.io/metadata.name": "ingress-nginx",
+						},
+					},
+				},
+			}
+		}
+
+		ingressPeers = append(ingressPeers, ingressControllerPeers...)
+	}
+
+	// the metrics port stays reachable by the monitoring system. unless configured otherwise, it is expected
+	// to run in the function's namespace or in the "monitoring" namespace
+	monitoringPeers := lc.platformConfigurationProvider.GetPlatformConfiguration().Kube.MonitoringNetworkPolicyPeers
+	if len(monitoringPeers) == 0 {
+		for _, monitoringNamespace := range []string{function.Namespace, "monitoring"} {
+			monitoringPeers = append(monitoringPeers, networkingv1.NetworkPolicyPeer{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"kubernetes.io/metadata.name": monitoringNamespace,
+					},
+				},
+			})
+		}
+	}
+
+	tcpProtocol := v1.ProtocolTCP
+	metricPort := intstr.FromInt(ContainerMetricPort)
+
+	spec.Ingress = []networkingv1.NetworkPolicyIngressRule{
+		{
+			From: ingressPeers,
+		},
+		{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &tcpProtocol, Port: &metricPort},
+			},
+			From: monitoringPeers,
+		},
+	}
+
+	if functionNetworkPolicy.Egress == nil {
+		return
+	}
+
+	spec.PolicyTypes = append(spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+
+	// DNS is always allowed, otherwise nothing can be resolved
+	udpProtocol := v1.ProtocolUDP
+	dnsPort := intstr.FromInt(53)
+
+	spec.Egress = []networkingv1.NetworkPolicyEgressRule{
+		{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &udpProtocol, Port: &dnsPort},
+				{Protocol: &tcpProtocol, Port: &dnsPort},
+			},
+		},
+	}
+
+	egressPeers := lc.compileNetworkPolicyPeers(function,
+		functionNetworkPolicy.Egress.AllowSameProject,
+		functionNetworkPolicy.Egress.AllowFunctions,
+		functionNetworkPolicy.Egress.AllowCIDRs)
+
+	if len(egressPeers) > 0 {
+		spec.Egress = append(spec.Egress, networkingv1.NetworkPolicyEgressRule{
+			To: egressPeers,
+		})
+	}
+}
+
+func (lc *lazyClient) compileNetworkPolicyPeers(function *nuclioio.NuclioFunction,
+	allowSameProject bool,
+	allowFunctions []string,
+	allowCIDRs []string) []networkingv1.NetworkPolicyPeer {
+
+	var peers []networkingv1.NetworkPolicyPeer
+
+	if allowSameProject {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"nuclio.io/class":                        "function",
+					common.NuclioResourceLabelKeyProjectName: function.Labels[common.NuclioResourceLabelKeyProjectName],
+				},
+			},
+		})
+	}
+
+	if len(allowFunctions) > 0 {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"nuclio.io/class": "function",
+				},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      "nuclio.io/function-name",
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   allowFunctions,
+					},
+				},
+			},
+		})
+	}
+
+	for _, cidr := range allowCIDRs {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			IPBlock: &networkingv1.IPBlock{
+				CIDR: cidr,
+			},
+		})
+	}
+
+	return peers
+}
+
 func (lc *lazyClient) createOrUpdateIngress(ctx context.Context,
 	functionLabels labels.Set,
 	function *nuclioio.NuclioFunction) (*networkingv1.Ingress, error) {
@@ -1301,6 +2032,12 @@ func (lc *lazyClient) createOrUpdateIngress(ctx context.Context,
 	}
 
 	createIngress := func() (interface{}, error) {
+
+		// the function is exposed with a gateway API route instead
+		if lc.exposedByGatewayAPI() {
+			return nil, nil
+		}
+
 		ingressMeta := metav1.ObjectMeta{
 			Name:      kube.IngressNameFromFunctionName(function.Name),
 			Namespace: function.Namespace,
@@ -1331,6 +2068,16 @@ func (lc *lazyClient) createOrUpdateIngress(ctx context.Context,
 	updateIngress := func(resource interface{}) (interface{}, error) {
 		ingress := resource.(*networkingv1.Ingress)
 
+		// the function is exposed with a gateway API route instead
+		if lc.exposedByGatewayAPI() {
+			propagationPolicy := metav1.DeletePropagationForeground
+			return nil, lc.kubeClientSet.NetworkingV1().
+				Ingresses(function.Namespace).
+				Delete(ctx, ingress.Name, metav1.DeleteOptions{
+					PropagationPolicy: &propagationPolicy,
+				})
+		}
+
 		// save to bool if there are current rules
 		ingressRulesExist := len(ingress.Spec.Rules) > 0
 
@@ -1544,6 +2291,16 @@ func (lc *lazyClient) getFunctionLabels(function *nuclioio.NuclioFunction) label
 	return result
 }
 
+// getFunctionName returns the name of the function the rendered resources belong to. canary resources are
+// rendered from a copy of the function named after them, labeled with the name of the function
+func (lc *lazyClient) getFunctionName(function *nuclioio.NuclioFunction) string {
+	if functionName := function.Labels[common.NuclioResourceLabelKeyFunctionName]; functionName != "" {
+		return functionName
+	}
+
+	return function.Name
+}
+
 func (lc *lazyClient) getPodAnnotations(function *nuclioio.NuclioFunction) (map[string]string, error) {
 	annotations := map[string]string{
 		"nuclio.io/image-hash": function.Spec.ImageHash,
@@ -1552,7 +2309,7 @@ func (lc *lazyClient) getPodAnnotations(function *nuclioio.NuclioFunction) (map[
 	// add annotations for prometheus pull
 	if lc.functionsHaveMetricSink(lc.platformConfigurationProvider.GetPlatformConfiguration(), "prometheusPull") {
 		annotations["nuclio.io/prometheus_pull"] = "true"
-		annotations["nuclio.io/prometheus_pull_port"] = strconv.Itoa(containerMetricPort)
+		annotations["nuclio.io/prometheus_pull_port"] = strconv.Itoa(ContainerMetricPort)
 	}
 
 	// add function annotations
@@ -1687,7 +2444,7 @@ func (lc *lazyClient) getServicePortsFromPlatform(platformConfiguration *platfor
 	if lc.functionsHaveMetricSink(platformConfiguration, "prometheusPull") {
 		servicePorts = append(servicePorts, v1.ServicePort{
 			Name: containerMetricPortName,
-			Port: int32(containerMetricPort),
+			Port: int32(ContainerMetricPort),
 		})
 	}
 
@@ -2083,11 +2840,11 @@ func (lc *lazyClient) populateDeploymentContainer(ctx context.Context,
 		},
 	}
 
-	// iterate through metric sinks. if prometheus pull is configured, add containerMetricPort
+	// iterate through metric sinks. if prometheus pull is configured, add ContainerMetricPort
 	if lc.functionsHaveMetricSink(lc.platformConfigurationProvider.GetPlatformConfiguration(), "prometheusPull") {
 		container.Ports = append(container.Ports, v1.ContainerPort{
 			Name:          containerMetricPortName,
-			ContainerPort: containerMetricPort,
+			ContainerPort: ContainerMetricPort,
 			Protocol:      v1.ProtocolTCP,
 		})
 	}
@@ -2126,6 +2883,22 @@ func (lc *lazyClient) populateDeploymentContainer(ctx context.Context,
 	}
 }
 
+// getExtraContainers returns copies of the function's sidecars and init containers. they mount function volumes
+// by name, which were validated to exist and are added to the pod along with the processor's
+func (lc *lazyClient) getExtraContainers(function *nuclioio.NuclioFunction) ([]v1.Container, []v1.Container) {
+	var sidecars []v1.Container
+	for _, sidecar := range function.Spec.Sidecars {
+		sidecars = append(sidecars, *sidecar.DeepCopy())
+	}
+
+	var initContainers []v1.Container
+	for _, initContainer := range function.Spec.InitContainers {
+		initContainers = append(initContainers, *initContainer.DeepCopy())
+	}
+
+	return sidecars, initContainers
+}
+
 func (lc *lazyClient) populateConfigMap(functionLabels labels.Set,
 	function *nuclioio.NuclioFunction,
 	configMap *v1.ConfigMap) error {
@@ -2143,7 +2916,7 @@ func (lc *lazyClient) populateConfigMap(functionLabels labels.Set,
 	if err := configWriter.Write(&configMapContents, &processor.Configuration{
 		Config: functionconfig.Config{
 			Meta: functionconfig.Meta{
-				Name:        function.Name,
+				Name:        lc.getFunctionName(function),
 				Namespace:   function.Namespace,
 				Labels:      functionLabels,
 				Annotations: function.Annotations,
@@ -2386,7 +3159,7 @@ func (lc *lazyClient) getFunctionSecrets(ctx context.Context, function *nuclioio
 
 	// get the function secrets
 	secretList, err := lc.kubeClientSet.CoreV1().Secrets(function.Namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("%s=%s", common.NuclioResourceLabelKeyFunctionName, function.Name),
+		LabelSelector: fmt.Sprintf("%s=%s", common.NuclioResourceLabelKeyFunctionName, lc.getFunctionName(function)),
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to list function secrets")
@@ -2577,6 +3350,22 @@ func (lc *lazyClient) generateMetricSpecFromAutoScaleMetrics(autoScaleMetrics []
 					TargetValue: &quantity,
 				},
 			}
+
+		case functionconfig.AutoScaleMetricSourceTypeTriggerBacklog:
+			quantity, err := apiresource.ParseQuantity(strconv.Itoa(autoscaleMetric.Threshold))
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed to parse quantity")
+			}
+
+			// the backlog is a gauge, so unlike other pod metrics it isn't windowed. triggers report their replica's
+			// share of the backlog (e.g. the partitions it claimed), so it's averaged over the pods
+			metricSpec = autosv2.MetricSpec{
+				Type: autosv2.PodsMetricSourceType,
+				Pods: &autosv2.PodsMetricSource{
+					MetricName:         autoscaleMetric.MetricName,
+					TargetAverageValue: quantity,
+				},
+			}
 		default:
 			return nil, errors.Errorf("Unknown metric type: %s", autoscaleMetric.SourceType)
 		}
@@ -2753,6 +3542,10 @@ type lazyResources struct {
 	horizontalPodAutoscaler *autosv2.HorizontalPodAutoscaler
 	ingress                 *networkingv1.Ingress
 	cronJobs                []*batchv1.CronJob
+	podDisruptionBudget     *policyv1.PodDisruptionBudget
+	networkPolicy           *networkingv1.NetworkPolicy
+	scaledObject            *ScaledObject
+	httpRoute               *gatewayapi.HTTPRoute
 }
 
 // Deployment returns the deployment
@@ -2784,3 +3577,23 @@ func (lr *lazyResources) Ingress() (*networkingv1.Ingress, error) {
 func (lr *lazyResources) CronJobs() ([]*batchv1.CronJob, error) {
 	return lr.cronJobs, nil
 }
+
+// PodDisruptionBudget returns the pdb
+func (lr *lazyResources) PodDisruptionBudget() (*policyv1.PodDisruptionBudget, error) {
+	return lr.podDisruptionBudget, nil
+}
+
+// NetworkPolicy returns the network policy
+func (lr *lazyResources) NetworkPolicy() (*networkingv1.NetworkPolicy, error) {
+	return lr.networkPolicy, nil
+}
+
+// ScaledObject returns the keda scaled object
+func (lr *lazyResources) ScaledObject() (*ScaledObject, error) {
+	return lr.scaledObject, nil
+}
+
+// HTTPRoute returns the gateway API route
+func (lr *lazyResources) HTTPRoute() (*gatewayapi.HTTPRoute, error) {
+	return lr.httpRoute, nil
+}
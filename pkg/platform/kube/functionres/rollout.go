@@ -0,0 +1,380 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package functionres
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/platform/abstract"
+	"github.com/nuclio/nuclio/pkg/platform/kube"
+	nuclioio "github.com/nuclio/nuclio/pkg/platform/kube/apis/nuclio.io/v1beta1"
+	"github.com/nuclio/nuclio/pkg/platform/kube/gatewayapi"
+
+	"github.com/nuclio/errors"
+	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	CanaryFunctionVersion = "canary"
+
+	nginxCanaryAnnotation       = "nginx.ingress.kubernetes.io/canary"
+	nginxCanaryWeightAnnotation = "nginx.ingress.kubernetes.io/canary-weight"
+)
+
+// RequiresRollout returns whether deploying the function should be rolled out progressively - the function
+// asks for it, and a different version of it is currently serving
+func (lc *lazyClient) RequiresRollout(ctx context.Context, function *nuclioio.NuclioFunction) (bool, error) {
+	if function.Spec.Rollout == nil || function.Spec.Disable {
+		return false, nil
+	}
+
+	deployment, err := lc.kubeClientSet.AppsV1().
+		Deployments(function.Namespace).
+		Get(ctx, kube.DeploymentNameFromFunctionName(function.Name), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "Failed to get function deployment")
+	}
+
+	// nothing is serving, so there's nothing to protect
+	if deployment.Status.AvailableReplicas == 0 {
+		return false, nil
+	}
+
+	// compare against the configuration the deployment was rendered from
+	enrichedFunction := function.DeepCopy()
+	if _, err := lc.enrichFunctionForDeploy(enrichedFunction); err != nil {
+		return false, errors.Wrap(err, "Failed to enrich function")
+	}
+
+	serializedFunctionConfigJSON, err := lc.serializeFunctionJSON(enrichedFunction)
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to get function as JSON")
+	}
+
+	return deployment.Annotations["nuclio.io/function-config"] != serializedFunctionConfigJSON, nil
+}
+
+// CreateOrUpdateCanary creates or updates the resources running the function's new version alongside its
+// current one. the canary gets no traffic until its weight is set
+func (lc *lazyClient) CreateOrUpdateCanary(ctx context.Context,
+	function *nuclioio.NuclioFunction,
+	imagePullSecrets string) (Resources, error) {
+	var err error
+
+	canaryFunction, canaryLabels, err := lc.getCanaryFunction(ctx, function)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get canary function")
+	}
+
+	resources := lazyResources{}
+
+	if resources.configMap, err = lc.createOrUpdateConfigMap(ctx, canaryLabels, canaryFunction); err != nil {
+		return nil, errors.Wrap(err, "Failed to create/update canary configMap")
+	}
+
+	if resources.service, err = lc.createOrUpdateService(ctx, canaryLabels, canaryFunction); err != nil {
+		return nil, errors.Wrap(err, "Failed to create/update canary service")
+	}
+
+	if resources.deployment, err = lc.createOrUpdateDeployment(ctx,
+		canaryLabels,
+		imagePullSecrets,
+		canaryFunction); err != nil {
+		return nil, errors.Wrap(err, "Failed to create/update canary deployment")
+	}
+
+	lc.logger.DebugWithCtx(ctx,
+		"Successfully created/updated canary resources",
+		"functionName", function.Name,
+		"functionNamespace", function.Namespace)
+	return &resources, nil
+}
+
+// WaitCanaryAvailable waits until the canary deployment is available
+func (lc *lazyClient) WaitCanaryAvailable(ctx context.Context,
+	function *nuclioio.NuclioFunction,
+	canaryResourcesCreateOrUpdateTimestamp time.Time) (error, functionconfig.FunctionState) {
+
+	canaryFunction := function.DeepCopy()
+	canaryFunction.Name = kube.CanaryNameFromFunctionName(function.Name)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err(), functionconfig.FunctionStateUnhealthy
+
+		case <-ticker.C:
+			err, functionState := lc.waitFunctionDeploymentReadiness(ctx,
+				canaryFunction,
+				canaryResourcesCreateOrUpdateTimestamp)
+
+			// an empty state indicates the canary isn't ready yet
+			if functionState == "" {
+				continue
+			}
+
+			return err, functionState
+		}
+	}
+}
+
+// SetCanaryWeight sends the given share of the function's external traffic (in percent) to its canary
+func (lc *lazyClient) SetCanaryWeight(ctx context.Context, function *nuclioio.NuclioFunction, weight int) error {
+	if lc.exposedByGatewayAPI() {
+		return lc.setHTTPRouteCanaryWeight(ctx, function.Namespace, function.Name, weight)
+	}
+
+	return lc.createOrUpdateCanaryIngress(ctx, function, weight)
+}
+
+// DeleteCanary sends all traffic back to the function's stable resources, and deletes its canary resources
+func (lc *lazyClient) DeleteCanary(ctx context.Context, namespace string, name string) error {
+	propagationPolicy := metav1.DeletePropagationForeground
+	deleteOptions := metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	}
+	canaryName := kube.CanaryNameFromFunctionName(name)
+
+	if err := lc.setHTTPRouteCanaryWeight(ctx, namespace, name, 0); err != nil {
+		return errors.Wrap(err, "Failed to remove canary from route")
+	}
+
+	ignoreNotFound := func(err error) error {
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	if err := ignoreNotFound(lc.kubeClientSet.NetworkingV1().
+		Ingresses(namespace).
+		Delete(ctx, kube.IngressNameFromFunctionName(canaryName), deleteOptions)); err != nil {
+		return errors.Wrap(err, "Failed to delete canary ingress")
+	}
+
+	if err := ignoreNotFound(lc.kubeClientSet.CoreV1().
+		Services(namespace).
+		Delete(ctx, kube.ServiceNameFromFunctionName(canaryName), deleteOptions)); err != nil {
+		return errors.Wrap(err, "Failed to delete canary service")
+	}
+
+	if err := ignoreNotFound(lc.kubeClientSet.AppsV1().
+		Deployments(namespace).
+		Delete(ctx, kube.DeploymentNameFromFunctionName(canaryName), deleteOptions)); err != nil {
+		return errors.Wrap(err, "Failed to delete canary deployment")
+	}
+
+	if err := ignoreNotFound(lc.kubeClientSet.CoreV1().
+		ConfigMaps(namespace).
+		Delete(ctx, kube.ConfigMapNameFromFunctionName(canaryName), deleteOptions)); err != nil {
+		return errors.Wrap(err, "Failed to delete canary configMap")
+	}
+
+	lc.logger.DebugWithCtx(ctx, "Deleted canary resources", "namespace", namespace, "name", name)
+	return nil
+}
+
+// getCanaryFunction returns a copy of the function named after its canary resources, and the labels of
+// these resources. canary pods are told apart from the stable ones by their function version label
+func (lc *lazyClient) getCanaryFunction(ctx context.Context,
+	function *nuclioio.NuclioFunction) (*nuclioio.NuclioFunction, labels.Set, error) {
+
+	canaryFunction := function.DeepCopy()
+	canaryLabels, err := lc.enrichFunctionForDeploy(canaryFunction)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Failed to enrich function")
+	}
+	canaryLabels["nuclio.io/function-version"] = CanaryFunctionVersion
+
+	canaryFunction.Name = kube.CanaryNameFromFunctionName(function.Name)
+	canaryFunction.Labels = canaryLabels
+	canaryFunction.Status = functionconfig.Status{
+		State: functionconfig.FunctionStateReady,
+	}
+
+	// the canary isn't exposed by itself
+	canaryFunction.Spec.ServiceType = v1.ServiceTypeClusterIP
+
+	// traffic is only split over http. other triggers (e.g. streams and cron) keep being served by the current
+	// version alone, rather than have the canary consume them in full
+	canaryFunction.Spec.Triggers = functionconfig.GetTriggersByKind(canaryFunction.Spec.Triggers, "http")
+
+	// run as many replicas as the stable version does, so the canary can take all of the traffic
+	stableDeployment, err := lc.kubeClientSet.AppsV1().
+		Deployments(function.Namespace).
+		Get(ctx, kube.DeploymentNameFromFunctionName(function.Name), metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Failed to get function deployment")
+	}
+
+	replicas := 1
+	if stableDeployment.Status.Replicas > 1 {
+		replicas = int(stableDeployment.Status.Replicas)
+	}
+	canaryFunction.Spec.Replicas = &replicas
+
+	return canaryFunction, canaryLabels, nil
+}
+
+// createOrUpdateCanaryIngress renders an nginx canary ingress for the rules of the function's ingress, which
+// sends the given share of their traffic to the canary service
+func (lc *lazyClient) createOrUpdateCanaryIngress(ctx context.Context,
+	function *nuclioio.NuclioFunction,
+	weight int) error {
+
+	stableIngress, err := lc.kubeClientSet.NetworkingV1().
+		Ingresses(function.Namespace).
+		Get(ctx, kube.IngressNameFromFunctionName(function.Name), metav1.GetOptions{})
+	if err != nil {
+
+		// the function isn't exposed by an ingress, so there's no traffic to shift. the canary handles no events,
+		// and the rollout is rolled back once its first step is done
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrap(err, "Failed to get function ingress")
+	}
+
+	canaryName := kube.CanaryNameFromFunctionName(function.Name)
+	populateCanaryIngress := func(ingress *networkingv1.Ingress) {
+		ingress.Labels = stableIngress.Labels
+		ingress.Annotations = map[string]string{}
+		for key, value := range stableIngress.Annotations {
+			ingress.Annotations[key] = value
+		}
+		ingress.Annotations[nginxCanaryAnnotation] = "true"
+		ingress.Annotations[nginxCanaryWeightAnnotation] = strconv.Itoa(weight)
+
+		ingress.Spec = *stableIngress.Spec.DeepCopy()
+		for ruleIndex := range ingress.Spec.Rules {
+			if ingress.Spec.Rules[ruleIndex].HTTP == nil {
+				continue
+			}
+
+			for pathIndex := range ingress.Spec.Rules[ruleIndex].HTTP.Paths {
+				backend := &ingress.Spec.Rules[ruleIndex].HTTP.Paths[pathIndex].Backend
+				if backend.Service != nil {
+					backend.Service.Name = kube.ServiceNameFromFunctionName(canaryName)
+				}
+			}
+		}
+	}
+
+	getIngress := func() (interface{}, error) {
+		return lc.kubeClientSet.NetworkingV1().
+			Ingresses(function.Namespace).
+			Get(ctx, kube.IngressNameFromFunctionName(canaryName), metav1.GetOptions{})
+	}
+
+	ingressIsDeleting := func(resource interface{}) bool {
+		return (resource).(*networkingv1.Ingress).ObjectMeta.DeletionTimestamp != nil
+	}
+
+	createIngress := func() (interface{}, error) {
+		ingress := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      kube.IngressNameFromFunctionName(canaryName),
+				Namespace: function.Namespace,
+			},
+		}
+		populateCanaryIngress(ingress)
+
+		return lc.kubeClientSet.NetworkingV1().
+			Ingresses(function.Namespace).
+			Create(ctx, ingress, metav1.CreateOptions{})
+	}
+
+	updateIngress := func(resource interface{}) (interface{}, error) {
+		ingress := resource.(*networkingv1.Ingress)
+		populateCanaryIngress(ingress)
+
+		return lc.kubeClientSet.NetworkingV1().
+			Ingresses(function.Namespace).
+			Update(ctx, ingress, metav1.UpdateOptions{})
+	}
+
+	_, err = lc.createOrUpdateResource(ctx,
+		"canaryIngress",
+		getIngress,
+		ingressIsDeleting,
+		createIngress,
+		updateIngress)
+	return err
+}
+
+// setHTTPRouteCanaryWeight splits the backends of the function's route between its stable and canary services.
+// a zero weight removes the canary backend
+func (lc *lazyClient) setHTTPRouteCanaryWeight(ctx context.Context,
+	namespace string,
+	name string,
+	weight int) error {
+	if lc.httpRouteManager == nil {
+		return nil
+	}
+
+	httpRoute, err := lc.httpRouteManager.Get(ctx, kube.HTTPRouteNameFromFunctionName(name), namespace)
+	if err != nil {
+
+		// the function isn't exposed by a route, so there's no traffic to shift
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			return nil
+		}
+		return errors.Wrap(err, "Failed to get function route")
+	}
+
+	stableServiceName := kube.ServiceNameFromFunctionName(name)
+	canaryServiceName := kube.ServiceNameFromFunctionName(kube.CanaryNameFromFunctionName(name))
+	servicePort := int32(abstract.FunctionContainerHTTPPort)
+
+	for ruleIndex := range httpRoute.Spec.Rules {
+		rule := &httpRoute.Spec.Rules[ruleIndex]
+		rule.BackendRefs = []gatewayapi.HTTPBackendRef{
+			{
+				Name: stableServiceName,
+				Port: &servicePort,
+			},
+		}
+
+		if weight > 0 {
+			stableWeight := int32(100 - weight)
+			canaryWeight := int32(weight)
+
+			rule.BackendRefs[0].Weight = &stableWeight
+			rule.BackendRefs = append(rule.BackendRefs, gatewayapi.HTTPBackendRef{
+				Name:   canaryServiceName,
+				Port:   &servicePort,
+				Weight: &canaryWeight,
+			})
+		}
+	}
+
+	_, err = lc.httpRouteManager.CreateOrUpdate(ctx, httpRoute)
+	return err
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/nuclio/nuclio/pkg/functionconfig"
 	nuclioio "github.com/nuclio/nuclio/pkg/platform/kube/apis/nuclio.io/v1beta1"
+	"github.com/nuclio/nuclio/pkg/platform/kube/gatewayapi"
 	"github.com/nuclio/nuclio/pkg/platformconfig"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -29,6 +30,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 )
 
 type PlatformConfigurationProvider interface {
@@ -57,6 +59,21 @@ type Client interface {
 	// Delete deletes resources
 	Delete(context.Context, string, string) error
 
+	// RequiresRollout returns whether deploying the function should be rolled out progressively
+	RequiresRollout(context.Context, *nuclioio.NuclioFunction) (bool, error)
+
+	// CreateOrUpdateCanary creates or updates the resources running a new function version alongside the current one
+	CreateOrUpdateCanary(context.Context, *nuclioio.NuclioFunction, string) (Resources, error)
+
+	// WaitCanaryAvailable waits until the canary resources are ready
+	WaitCanaryAvailable(context.Context, *nuclioio.NuclioFunction, time.Time) (error, functionconfig.FunctionState)
+
+	// SetCanaryWeight sets the share of traffic (in percent) the canary resources get
+	SetCanaryWeight(context.Context, *nuclioio.NuclioFunction, int) error
+
+	// DeleteCanary deletes the canary resources, sending all traffic back to the current version
+	DeleteCanary(context.Context, string, string) error
+
 	// SetPlatformConfigurationProvider sets the provider of the platform configuration for any future access
 	SetPlatformConfigurationProvider(PlatformConfigurationProvider)
 }
@@ -81,4 +98,16 @@ type Resources interface {
 
 	// CronJobs returns the cron job
 	CronJobs() ([]*batchv1.CronJob, error)
+
+	// PodDisruptionBudget returns the pdb
+	PodDisruptionBudget() (*policyv1.PodDisruptionBudget, error)
+
+	// NetworkPolicy returns the network policy
+	NetworkPolicy() (*networkingv1.NetworkPolicy, error)
+
+	// ScaledObject returns the keda scaled object
+	ScaledObject() (*ScaledObject, error)
+
+	// HTTPRoute returns the gateway API route
+	HTTPRoute() (*gatewayapi.HTTPRoute, error)
 }
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nuclio/nuclio/pkg/platform"
+	"github.com/nuclio/nuclio/pkg/platform/kube/functionrevision"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/nuclio-sdk-go"
+)
+
+// GetFunctionRevisions returns the revisions of a function, newest first
+func (p *Platform) GetFunctionRevisions(ctx context.Context,
+	getFunctionRevisionsOptions *platform.GetFunctionRevisionsOptions) ([]*platform.FunctionRevision, error) {
+
+	// make sure the function exists and can be read
+	functions, err := p.GetFunctions(ctx, &platform.GetFunctionsOptions{
+		Name:              getFunctionRevisionsOptions.Name,
+		Namespace:         getFunctionRevisionsOptions.Namespace,
+		PermissionOptions: getFunctionRevisionsOptions.PermissionOptions,
+		AuthSession:       getFunctionRevisionsOptions.AuthSession,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get function")
+	}
+
+	if len(functions) == 0 {
+		return nil, nuclio.NewErrNotFound(fmt.Sprintf("Function not found: %s @ %s",
+			getFunctionRevisionsOptions.Name,
+			getFunctionRevisionsOptions.Namespace))
+	}
+
+	functionRevisions, err := p.listFunctionRevisions(ctx,
+		getFunctionRevisionsOptions.Namespace,
+		getFunctionRevisionsOptions.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to list function revisions")
+	}
+
+	if getFunctionRevisionsOptions.Revision == 0 {
+		return functionRevisions, nil
+	}
+
+	for _, functionRevision := range functionRevisions {
+		if functionRevision.Revision == getFunctionRevisionsOptions.Revision {
+			return []*platform.FunctionRevision{functionRevision}, nil
+		}
+	}
+
+	return nil, nuclio.NewErrNotFound(fmt.Sprintf("Function revision not found: %d",
+		getFunctionRevisionsOptions.Revision))
+}
+
+// recordFunctionRevision stores the configuration of a successful deployment as the next revision of the function
+func (p *Platform) recordFunctionRevision(ctx context.Context,
+	createFunctionOptions *platform.CreateFunctionOptions) error {
+
+	deployedBy := ""
+	if createFunctionOptions.AuthSession != nil {
+		deployedBy = createFunctionOptions.AuthSession.GetUsername()
+	}
+
+	return p.getFunctionRevisionStore().Record(ctx,
+		createFunctionOptions.FunctionConfig,
+		deployedBy,
+		p.Config.GetFunctionRevisionHistoryLimit())
+}
+
+func (p *Platform) deleteFunctionRevisions(ctx context.Context, namespace string, name string) error {
+	return p.getFunctionRevisionStore().DeleteAll(ctx, namespace, name)
+}
+
+func (p *Platform) listFunctionRevisions(ctx context.Context,
+	namespace string,
+	name string) ([]*platform.FunctionRevision, error) {
+	return p.getFunctionRevisionStore().List(ctx, namespace, name)
+}
+
+func (p *Platform) getFunctionRevisionStore() *functionrevision.Store {
+	return functionrevision.NewStore(p.Logger, p.consumer.KubeClientSet)
+}
@@ -0,0 +1,370 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package functionrevision
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/platform"
+	"github.com/nuclio/nuclio/pkg/platform/kube/client"
+
+	"github.com/ghodss/yaml"
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	functionRevisionConfigKey      = "function.yaml"
+	functionRevisionImageKey       = "image"
+	functionRevisionImageDigestKey = "imageDigest"
+	functionRevisionDeployedByKey  = "deployedBy"
+	functionRevisionDeployedAtKey  = "deployedAt"
+	functionRevisionSecretHashKey  = "functionSecretHash"
+
+	// concurrent deployments of a function may race for the same revision number
+	maxRecordAttempts = 5
+)
+
+// Store keeps the revisions of functions as immutable config maps, one per successful deployment. both the
+// platform and the controller (which promotes rollouts) record revisions through it
+type Store struct {
+	logger        logger.Logger
+	kubeClientSet kubernetes.Interface
+}
+
+func NewStore(parentLogger logger.Logger, kubeClientSet kubernetes.Interface) *Store {
+	return &Store{
+		logger:        parentLogger.GetChild("functionrevision"),
+		kubeClientSet: kubeClientSet,
+	}
+}
+
+// Record stores the configuration of a successful deployment as the next revision of the function,
+// and removes the oldest revisions beyond the history limit
+func (s *Store) Record(ctx context.Context,
+	functionConfig functionconfig.Config,
+	deployedBy string,
+	historyLimit int) error {
+
+	functionConfig.Meta.ResourceVersion = ""
+	functionConfig.Meta.RemoveSkipBuildAnnotation()
+	functionConfig.Meta.RemoveSkipDeployAnnotation()
+
+	encodedFunctionConfig, err := yaml.Marshal(functionConfig)
+	if err != nil {
+		return errors.Wrap(err, "Failed to encode function config")
+	}
+
+	imageDigest, err := s.resolveFunctionImageDigest(ctx, &functionConfig)
+	if err != nil {
+
+		// the digest is nice to have, the revision is still useful without it
+		s.logger.WarnWithCtx(ctx,
+			"Failed to resolve function image digest",
+			"functionName", functionConfig.Meta.Name,
+			"err", errors.GetErrorStackString(err, 10))
+	}
+
+	// the config only references the sensitive values, which are kept in the function secret and may change
+	// by later deployments. the secret is hashed so that rolling back can tell whether they did
+	functionSecretHash, err := s.getFunctionSecretHash(ctx, functionConfig.Meta.Namespace, functionConfig.Meta.Name)
+	if err != nil {
+		return errors.Wrap(err, "Failed to hash function secret")
+	}
+
+	var functionRevisions []*platform.FunctionRevision
+	for attempt := 1; ; attempt++ {
+		functionRevisions, err = s.List(ctx, functionConfig.Meta.Namespace, functionConfig.Meta.Name)
+		if err != nil {
+			return errors.Wrap(err, "Failed to list function revisions")
+		}
+
+		revision := 1
+		if len(functionRevisions) > 0 {
+			revision = functionRevisions[0].Revision + 1
+		}
+
+		immutable := true
+		configMap := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      functionRevisionConfigMapName(functionConfig.Meta.Name, revision),
+				Namespace: functionConfig.Meta.Namespace,
+				Labels: map[string]string{
+					common.NuclioResourceLabelKeyFunctionName:     functionConfig.Meta.Name,
+					common.NuclioResourceLabelKeyFunctionRevision: strconv.Itoa(revision),
+					common.NuclioResourceLabelKeyProjectName: functionConfig.Meta.Labels[common.
+						NuclioResourceLabelKeyProjectName],
+				},
+			},
+			Immutable: &immutable,
+			Data: map[string]string{
+				functionRevisionConfigKey:      string(encodedFunctionConfig),
+				functionRevisionImageKey:       functionConfig.Spec.Image,
+				functionRevisionImageDigestKey: imageDigest,
+				functionRevisionDeployedByKey:  deployedBy,
+				functionRevisionDeployedAtKey:  time.Now().UTC().Format(time.RFC3339),
+				functionRevisionSecretHashKey:  functionSecretHash,
+			},
+		}
+
+		_, err = s.kubeClientSet.
+			CoreV1().
+			ConfigMaps(configMap.Namespace).
+			Create(ctx, configMap, metav1.CreateOptions{})
+
+		// another deployment took this revision number, take the next one
+		if apierrors.IsAlreadyExists(err) && attempt < maxRecordAttempts {
+			s.logger.DebugWithCtx(ctx,
+				"Function revision already exists, retrying with the next one",
+				"functionName", functionConfig.Meta.Name,
+				"revision", revision)
+			continue
+		}
+		if err != nil {
+			return errors.Wrap(err, "Failed to create function revision")
+		}
+
+		s.logger.InfoWithCtx(ctx,
+			"Recorded function revision",
+			"functionName", functionConfig.Meta.Name,
+			"revision", revision)
+		break
+	}
+
+	// revisions are sorted newest first, and the new one isn't among them
+	if len(functionRevisions) < historyLimit {
+		return nil
+	}
+
+	for _, functionRevision := range functionRevisions[historyLimit-1:] {
+		if err := s.delete(ctx,
+			functionConfig.Meta.Namespace,
+			functionConfig.Meta.Name,
+			functionRevision.Revision); err != nil {
+			return errors.Wrap(err, "Failed to delete old function revision")
+		}
+	}
+
+	return nil
+}
+
+// DeleteAll removes all the revisions of a function
+func (s *Store) DeleteAll(ctx context.Context, namespace string, name string) error {
+	functionRevisions, err := s.List(ctx, namespace, name)
+	if err != nil {
+		return errors.Wrap(err, "Failed to list function revisions")
+	}
+
+	for _, functionRevision := range functionRevisions {
+		if err := s.delete(ctx, namespace, name, functionRevision.Revision); err != nil {
+			return errors.Wrap(err, "Failed to delete function revision")
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) delete(ctx context.Context, namespace string, name string, revision int) error {
+	if err := s.kubeClientSet.
+		CoreV1().
+		ConfigMaps(namespace).
+		Delete(ctx, functionRevisionConfigMapName(name, revision), metav1.DeleteOptions{}); err != nil &&
+		!apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "Failed to delete function revision config map")
+	}
+
+	return nil
+}
+
+// List returns the revisions of a function, newest first
+func (s *Store) List(ctx context.Context,
+	namespace string,
+	name string) ([]*platform.FunctionRevision, error) {
+
+	configMaps, err := s.kubeClientSet.
+		CoreV1().
+		ConfigMaps(namespace).
+		List(ctx, metav1.ListOptions{
+			LabelSelector: compileListFunctionRevisionsLabelSelector(name),
+		})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to list function revision config maps")
+	}
+
+	if len(configMaps.Items) == 0 {
+		return nil, nil
+	}
+
+	functionSecretHash, err := s.getFunctionSecretHash(ctx, namespace, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to hash function secret")
+	}
+
+	var functionRevisions []*platform.FunctionRevision
+	for _, configMap := range configMaps.Items {
+		functionRevision, err := functionRevisionFromConfigMap(&configMap)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to read function revision %s", configMap.Name)
+		}
+
+		functionRevision.SensitiveValuesChanged =
+			strings.Contains(configMap.Data[functionRevisionConfigKey], functionconfig.ReferencePrefix) &&
+				configMap.Data[functionRevisionSecretHashKey] != functionSecretHash
+		functionRevisions = append(functionRevisions, functionRevision)
+	}
+
+	sort.Slice(functionRevisions, func(i, j int) bool {
+		return functionRevisions[i].Revision > functionRevisions[j].Revision
+	})
+
+	return functionRevisions, nil
+}
+
+// resolveFunctionImageDigest returns the digest of the function image as pulled by its running replicas
+func (s *Store) resolveFunctionImageDigest(ctx context.Context, functionConfig *functionconfig.Config) (string, error) {
+	pods, err := s.kubeClientSet.
+		CoreV1().
+		Pods(functionConfig.Meta.Namespace).
+		List(ctx, metav1.ListOptions{
+			LabelSelector: common.CompileListFunctionPodsLabelSelector(functionConfig.Meta.Name),
+		})
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to list function pods")
+	}
+
+	for _, pod := range pods.Items {
+
+		// during a rollout, the replicas of the current version run a different image
+		imageMatches := false
+		for _, container := range pod.Spec.Containers {
+			if container.Name == client.FunctionContainerName && container.Image == functionConfig.Spec.Image {
+				imageMatches = true
+			}
+		}
+
+		if !imageMatches {
+			continue
+		}
+
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			if containerStatus.Name != client.FunctionContainerName {
+				continue
+			}
+
+			// e.g. docker-pullable://registry/image@sha256:...
+			if digestIndex := strings.LastIndex(containerStatus.ImageID, "@"); digestIndex != -1 {
+				return containerStatus.ImageID[digestIndex+1:], nil
+			}
+			if strings.HasPrefix(containerStatus.ImageID, "sha256:") {
+				return containerStatus.ImageID, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// getFunctionSecretHash returns a hash of the data of the function secret, which holds the sensitive values
+// of its config, or an empty string if it has none
+func (s *Store) getFunctionSecretHash(ctx context.Context, namespace string, name string) (string, error) {
+	secrets, err := s.kubeClientSet.
+		CoreV1().
+		Secrets(namespace).
+		List(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%s=%s", common.NuclioResourceLabelKeyFunctionName, name),
+		})
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to list function secrets")
+	}
+
+	for _, secret := range secrets.Items {
+		if strings.HasPrefix(secret.Name, functionconfig.NuclioFlexVolumeSecretNamePrefix) {
+			continue
+		}
+
+		var secretKeys []string
+		for secretKey := range secret.Data {
+			secretKeys = append(secretKeys, secretKey)
+		}
+		sort.Strings(secretKeys)
+
+		var hashedData []byte
+		for _, secretKey := range secretKeys {
+			hashedData = append(hashedData, secretKey...)
+			hashedData = append(hashedData, 0)
+			hashedData = append(hashedData, secret.Data[secretKey]...)
+			hashedData = append(hashedData, 0)
+		}
+
+		hash := sha256.Sum256(hashedData)
+		return hex.EncodeToString(hash[:]), nil
+	}
+
+	return "", nil
+}
+
+func functionRevisionFromConfigMap(configMap *v1.ConfigMap) (*platform.FunctionRevision, error) {
+	revision, err := strconv.Atoi(configMap.Labels[common.NuclioResourceLabelKeyFunctionRevision])
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse revision")
+	}
+
+	functionRevision := &platform.FunctionRevision{
+		Revision:    revision,
+		Image:       configMap.Data[functionRevisionImageKey],
+		ImageDigest: configMap.Data[functionRevisionImageDigestKey],
+		DeployedBy:  configMap.Data[functionRevisionDeployedByKey],
+	}
+
+	if err := yaml.Unmarshal([]byte(configMap.Data[functionRevisionConfigKey]), &functionRevision.Config); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode function config")
+	}
+
+	if deployedAt := configMap.Data[functionRevisionDeployedAtKey]; deployedAt != "" {
+		functionRevision.DeployedAt, err = time.Parse(time.RFC3339, deployedAt)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to parse deployment time")
+		}
+	}
+
+	return functionRevision, nil
+}
+
+// the dot can't be part of a function name, so this never collides with the config map of another function
+func functionRevisionConfigMapName(functionName string, revision int) string {
+	return fmt.Sprintf("nuclio-%s.revision-%d", functionName, revision)
+}
+
+func compileListFunctionRevisionsLabelSelector(functionName string) string {
+	return fmt.Sprintf("%s=%s,%s",
+		common.NuclioResourceLabelKeyFunctionName,
+		functionName,
+		common.NuclioResourceLabelKeyFunctionRevision)
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewayapi
+
+import (
+	"context"
+	"sort"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+)
+
+type Manager struct {
+	logger        logger.Logger
+	dynamicClient dynamic.Interface
+}
+
+func NewManager(parentLogger logger.Logger, dynamicClient dynamic.Interface) (*Manager, error) {
+	return &Manager{
+		logger:        parentLogger.GetChild("gatewayapi"),
+		dynamicClient: dynamicClient,
+	}, nil
+}
+
+// GenerateHTTPRoute renders an HTTPRoute from a spec. header matches and request headers are sorted by name
+// so that the same spec always renders the same route
+func (m *Manager) GenerateHTTPRoute(spec Spec) (*HTTPRoute, error) {
+	if spec.Name == "" {
+		return nil, errors.New("Route name must be provided")
+	}
+
+	if len(spec.Rules) == 0 {
+		return nil, errors.New("Route must have at least one rule")
+	}
+
+	route := &HTTPRoute{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: HTTPRouteGroupVersionResource.GroupVersion().String(),
+			Kind:       "HTTPRoute",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        spec.Name,
+			Namespace:   spec.Namespace,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		Spec: HTTPRouteSpec{
+			ParentRefs: spec.ParentRefs,
+			Hostnames:  spec.Hostnames,
+		},
+	}
+
+	for _, ruleSpec := range spec.Rules {
+		rule, err := m.generateRule(ruleSpec)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to generate rule for path %s", ruleSpec.Path)
+		}
+
+		route.Spec.Rules = append(route.Spec.Rules, *rule)
+	}
+
+	return route, nil
+}
+
+// CreateOrUpdate creates the route, or updates it if it already exists
+func (m *Manager) CreateOrUpdate(ctx context.Context, route *HTTPRoute) (*HTTPRoute, error) {
+	routes := m.dynamicClient.Resource(HTTPRouteGroupVersionResource).Namespace(route.Namespace)
+
+	existingObject, err := routes.Get(ctx, route.Name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, errors.Wrap(err, "Failed to get route")
+		}
+
+		object, err := ToUnstructured(route)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to convert route")
+		}
+
+		createdObject, err := routes.Create(ctx, object, metav1.CreateOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to create route")
+		}
+
+		m.logger.DebugWithCtx(ctx, "Created route", "name", route.Name, "namespace", route.Namespace)
+		return FromUnstructured(createdObject)
+	}
+
+	existingRoute, err := FromUnstructured(existingObject)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to convert existing route")
+	}
+
+	// keep everything the route owner (e.g. the gateway controller) populated, override the desired state
+	existingRoute.Labels = route.Labels
+	existingRoute.Annotations = route.Annotations
+	existingRoute.Spec = route.Spec
+
+	object, err := ToUnstructured(existingRoute)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to convert route")
+	}
+
+	updatedObject, err := routes.Update(ctx, object, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to update route")
+	}
+
+	m.logger.DebugWithCtx(ctx, "Updated route", "name", route.Name, "namespace", route.Namespace)
+	return FromUnstructured(updatedObject)
+}
+
+// Get returns a route
+func (m *Manager) Get(ctx context.Context, name string, namespace string) (*HTTPRoute, error) {
+	object, err := m.dynamicClient.Resource(HTTPRouteGroupVersionResource).
+		Namespace(namespace).
+		Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get route")
+	}
+
+	return FromUnstructured(object)
+}
+
+// DeleteByName deletes a route, if it exists
+func (m *Manager) DeleteByName(ctx context.Context, name string, namespace string) error {
+	propagationPolicy := metav1.DeletePropagationForeground
+	if err := m.dynamicClient.Resource(HTTPRouteGroupVersionResource).
+		Namespace(namespace).
+		Delete(ctx, name, metav1.DeleteOptions{
+			PropagationPolicy: &propagationPolicy,
+		}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrap(err, "Failed to delete route")
+	}
+
+	m.logger.DebugWithCtx(ctx, "Deleted route", "name", name, "namespace", namespace)
+	return nil
+}
+
+// FromUnstructured converts an unstructured object to a route
+func FromUnstructured(object *unstructured.Unstructured) (*HTTPRoute, error) {
+	route := &HTTPRoute{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.UnstructuredContent(), route); err != nil {
+		return nil, errors.Wrap(err, "Failed to convert unstructured object to route")
+	}
+
+	return route, nil
+}
+
+// ToUnstructured converts a route to an unstructured object
+func ToUnstructured(route *HTTPRoute) (*unstructured.Unstructured, error) {
+	route.APIVersion = HTTPRouteGroupVersionResource.GroupVersion().String()
+	route.Kind = "HTTPRoute"
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(route)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to convert route to unstructured object")
+	}
+
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+func (m *Manager) generateRule(ruleSpec RuleSpec) (*HTTPRouteRule, error) {
+	if len(ruleSpec.Backends) == 0 {
+		return nil, errors.New("Rule must have at least one backend")
+	}
+
+	pathType := ruleSpec.PathType
+	if pathType == "" {
+		pathType = PathMatchTypePathPrefix
+	}
+
+	path := ruleSpec.Path
+	if path == "" {
+		path = "/"
+	}
+
+	match := HTTPRouteMatch{
+		Path: &HTTPPathMatch{
+			Type:  pathType,
+			Value: path,
+		},
+	}
+
+	for _, name := range m.sortedKeys(ruleSpec.MatchHeaders) {
+		match.Headers = append(match.Headers, HTTPHeaderMatch{
+			Type:  HeaderMatchTypeExact,
+			Name:  name,
+			Value: ruleSpec.MatchHeaders[name],
+		})
+	}
+
+	rule := &HTTPRouteRule{
+		Matches: []HTTPRouteMatch{match},
+	}
+
+	if len(ruleSpec.RequestHeaders) > 0 {
+		headerFilter := &HTTPHeaderFilter{}
+		for _, name := range m.sortedKeys(ruleSpec.RequestHeaders) {
+			headerFilter.Set = append(headerFilter.Set, HTTPHeader{
+				Name:  name,
+				Value: ruleSpec.RequestHeaders[name],
+			})
+		}
+
+		rule.Filters = append(rule.Filters, HTTPRouteFilter{
+			Type:                  FilterTypeRequestHeaderModifier,
+			RequestHeaderModifier: headerFilter,
+		})
+	}
+
+	if ruleSpec.RewriteTarget != "" {
+
+		// only prefix matches can be rewritten by prefix
+		if pathType != PathMatchTypePathPrefix {
+			return nil, errors.Errorf("Rewrite target requires a %s path match", PathMatchTypePathPrefix)
+		}
+
+		rule.Filters = append(rule.Filters, HTTPRouteFilter{
+			Type: FilterTypeURLRewrite,
+			URLRewrite: &HTTPURLRewriteFilter{
+				Path: &HTTPPathModifier{
+					Type:               PathModifierTypeReplacePrefixMatch,
+					ReplacePrefixMatch: ruleSpec.RewriteTarget,
+				},
+			},
+		})
+	}
+
+	rule.Filters = append(rule.Filters, ruleSpec.Filters...)
+
+	for _, backend := range ruleSpec.Backends {
+		port := int32(backend.ServicePort)
+		rule.BackendRefs = append(rule.BackendRefs, HTTPBackendRef{
+			Name:   backend.ServiceName,
+			Port:   &port,
+			Weight: backend.Weight,
+		})
+	}
+
+	return rule, nil
+}
+
+func (m *Manager) sortedKeys(values map[string]string) []string {
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewayapi
+
+import (
+	"github.com/nuclio/nuclio/pkg/platformconfig"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var HTTPRouteGroupVersionResource = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1beta1",
+	Resource: "httproutes",
+}
+
+type PathMatchType string
+
+const (
+	PathMatchTypeExact      PathMatchType = "Exact"
+	PathMatchTypePathPrefix PathMatchType = "PathPrefix"
+)
+
+type HeaderMatchType string
+
+const (
+	HeaderMatchTypeExact HeaderMatchType = "Exact"
+)
+
+type FilterType string
+
+const (
+	FilterTypeRequestHeaderModifier FilterType = "RequestHeaderModifier"
+	FilterTypeURLRewrite            FilterType = "URLRewrite"
+	FilterTypeExtensionRef          FilterType = "ExtensionRef"
+)
+
+type PathModifierType string
+
+const (
+	PathModifierTypeReplacePrefixMatch PathModifierType = "ReplacePrefixMatch"
+)
+
+// HTTPRoute holds the subset of the gateway API HTTPRoute resource that nuclio renders. the gateway API
+// has no client in our dependencies, so routes are converted to and from unstructured objects
+type HTTPRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HTTPRouteSpec   `json:"spec"`
+	Status HTTPRouteStatus `json:"status,omitempty"`
+}
+
+type HTTPRouteSpec struct {
+	ParentRefs []platformconfig.GatewayAPIParentReference `json:"parentRefs,omitempty"`
+	Hostnames  []string                                   `json:"hostnames,omitempty"`
+	Rules      []HTTPRouteRule                            `json:"rules,omitempty"`
+}
+
+type HTTPRouteRule struct {
+	Matches     []HTTPRouteMatch  `json:"matches,omitempty"`
+	Filters     []HTTPRouteFilter `json:"filters,omitempty"`
+	BackendRefs []HTTPBackendRef  `json:"backendRefs,omitempty"`
+}
+
+type HTTPRouteMatch struct {
+	Path    *HTTPPathMatch    `json:"path,omitempty"`
+	Headers []HTTPHeaderMatch `json:"headers,omitempty"`
+}
+
+type HTTPPathMatch struct {
+	Type  PathMatchType `json:"type,omitempty"`
+	Value string        `json:"value,omitempty"`
+}
+
+type HTTPHeaderMatch struct {
+	Type  HeaderMatchType `json:"type,omitempty"`
+	Name  string          `json:"name"`
+	Value string          `json:"value"`
+}
+
+type HTTPRouteFilter struct {
+	Type                  FilterType                                     `json:"type"`
+	RequestHeaderModifier *HTTPHeaderFilter                              `json:"requestHeaderModifier,omitempty"`
+	URLRewrite            *HTTPURLRewriteFilter                          `json:"urlRewrite,omitempty"`
+	ExtensionRef          *platformconfig.GatewayAPILocalObjectReference `json:"extensionRef,omitempty"`
+}
+
+type HTTPHeaderFilter struct {
+	Set    []HTTPHeader `json:"set,omitempty"`
+	Add    []HTTPHeader `json:"add,omitempty"`
+	Remove []string     `json:"remove,omitempty"`
+}
+
+type HTTPHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HTTPURLRewriteFilter struct {
+	Hostname string            `json:"hostname,omitempty"`
+	Path     *HTTPPathModifier `json:"path,omitempty"`
+}
+
+type HTTPPathModifier struct {
+	Type               PathModifierType `json:"type"`
+	ReplaceFullPath    string           `json:"replaceFullPath,omitempty"`
+	ReplacePrefixMatch string           `json:"replacePrefixMatch,omitempty"`
+}
+
+type HTTPBackendRef struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Port      *int32 `json:"port,omitempty"`
+	Weight    *int32 `json:"weight,omitempty"`
+}
+
+type HTTPRouteStatus struct {
+	Parents []RouteParentStatus `json:"parents,omitempty"`
+}
+
+type RouteParentStatus struct {
+	ParentRef      platformconfig.GatewayAPIParentReference `json:"parentRef"`
+	ControllerName string                                   `json:"controllerName"`
+	Conditions     []metav1.Condition                       `json:"conditions,omitempty"`
+}
+
+// Spec describes the route to generate
+type Spec struct {
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+	ParentRefs  []platformconfig.GatewayAPIParentReference
+	Hostnames   []string
+	Rules       []RuleSpec
+}
+
+// RuleSpec describes a single route rule - the requests it matches and the services it forwards them to
+type RuleSpec struct {
+	Path     string
+	PathType PathMatchType
+
+	// requests must carry all of these headers (exact match) to match the rule
+	MatchHeaders map[string]string
+
+	// replaces the matched path prefix before forwarding the request
+	RewriteTarget string
+
+	// headers set on requests forwarded by the rule
+	RequestHeaders map[string]string
+
+	// additional filters (e.g. implementation specific authentication)
+	Filters []HTTPRouteFilter
+
+	Backends []BackendSpec
+}
+
+type BackendSpec struct {
+	ServiceName string
+	ServicePort int
+
+	// the relative share of requests this backend gets. nil means an equal share
+	Weight *int32
+}
@@ -155,6 +155,12 @@ func (mw *MultiWorker) Stop() chan struct{} {
 	return nil
 }
 
+func (mw *MultiWorker) Enqueue(namespace string, name string) {
+
+	// the queue never hands out a key that is being processed, so this is serialized with the changes of the object
+	mw.queue.Add(fmt.Sprintf("%s/%s", namespace, name))
+}
+
 func (mw *MultiWorker) processItems(ctx context.Context) {
 	workerID := ctx.Value(WorkerIDKey)
 	for {
@@ -26,4 +26,7 @@ type Operator interface {
 
 	// Stop stops the operator, returning a completion channel
 	Stop() chan struct{}
+
+	// Enqueue has the object handled as if it changed. the handling is serialized with that of its changes
+	Enqueue(namespace string, name string)
 }
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -226,6 +227,16 @@ func (p *Platform) CreateFunction(ctx context.Context, createFunctionOptions *pl
 		return nil, errors.Wrap(err, "Failed to validate a function configuration against an existing configuration")
 	}
 
+	// replace the function resources with the ones recommended by the controller, if requested
+	if createFunctionOptions.FunctionConfig.Spec.AutoApplyResourceRecommendation &&
+		existingFunctionInstance != nil &&
+		existingFunctionInstance.Status.ResourceRecommendation != nil {
+		createFunctionOptions.Logger.InfoWithCtx(ctx, "Applying recommended function resources",
+			"resources", createFunctionOptions.FunctionConfig.Spec.Resources,
+			"recommendation", existingFunctionInstance.Status.ResourceRecommendation)
+		existingFunctionInstance.Status.ResourceRecommendation.ApplyTo(&createFunctionOptions.FunctionConfig.Spec.Resources)
+	}
+
 	// wrap logger
 	logStream, err := abstract.NewLogStream("deployer", nucliozap.InfoLevel, createFunctionOptions.Logger)
 	if err != nil {
@@ -429,6 +440,23 @@ func (p *Platform) CreateFunction(ctx context.Context, createFunctionOptions *pl
 			return nil, deployErr
 		}
 
+		// a rollout only started, the controller records the revision once the new version is promoted
+		if existingFunctionInstance != nil &&
+			existingFunctionInstance.Status.Rollout != nil &&
+			existingFunctionInstance.Status.Rollout.Phase == functionconfig.RolloutPhaseProgressing {
+			createFunctionOptions.Logger.InfoWithCtx(ctx,
+				"Function rollout in progress, skipping revision recording",
+				"functionName", createFunctionOptions.FunctionConfig.Meta.Name)
+			return createFunctionResult, nil
+		}
+
+		// the function is deployed either way, a missing revision shouldn't fail it
+		if err := p.recordFunctionRevision(ctx, createFunctionOptions); err != nil {
+			createFunctionOptions.Logger.WarnWithCtx(ctx,
+				"Failed to record function revision",
+				"err", errors.GetErrorStackString(err, 10))
+		}
+
 		return createFunctionResult, nil
 	}
 
@@ -555,7 +583,13 @@ func (p *Platform) DeleteFunction(ctx context.Context, deleteFunctionOptions *pl
 		return errors.Wrap(err, "Failed to validate that the function has no API gateways")
 	}
 
-	return p.deleter.Delete(ctx, p.consumer, deleteFunctionOptions)
+	if err := p.deleter.Delete(ctx, p.consumer, deleteFunctionOptions); err != nil {
+		return errors.Wrap(err, "Failed to delete function")
+	}
+
+	return p.deleteFunctionRevisions(ctx,
+		functionToDelete.GetConfig().Meta.Namespace,
+		functionToDelete.GetConfig().Meta.Name)
 }
 
 func (p *Platform) GetFunctionReplicaLogsStream(ctx context.Context,
@@ -591,6 +625,17 @@ func (p *Platform) GetFunctionReplicaNames(ctx context.Context,
 	return names, nil
 }
 
+// GetFunctionReplicaMetrics reads the metrics of a function pod through the API server's pod proxy, which
+// requires the prometheus pull metric sink to be configured for functions
+func (p *Platform) GetFunctionReplicaMetrics(ctx context.Context,
+	options *platform.GetFunctionReplicaMetricsOptions) (io.ReadCloser, error) {
+	return p.consumer.KubeClientSet.
+		CoreV1().
+		Pods(options.Namespace).
+		ProxyGet("http", options.Name, strconv.Itoa(FunctionContainerMetricPort), "metrics", nil).
+		Stream(ctx)
+}
+
 // GetName returns the platform name
 func (p *Platform) GetName() string {
 	return common.KubePlatformName
@@ -1633,10 +1678,19 @@ func (p *Platform) ValidateFunctionConfig(ctx context.Context, functionConfig *f
 		return err
 	}
 
+	if strings.HasSuffix(functionConfig.Meta.Name, CanaryNameSuffix) {
+		return nuclio.NewErrBadRequest(fmt.Sprintf("Function names ending with %s are reserved for rollout canaries",
+			CanaryNameSuffix))
+	}
+
 	if err := p.validateServiceType(functionConfig); err != nil {
 		return errors.Wrap(err, "Service type validation failed")
 	}
 
+	if err := p.validateRollout(functionConfig); err != nil {
+		return errors.Wrap(err, "Rollout validation failed")
+	}
+
 	return p.validateFunctionIngresses(ctx, functionConfig)
 }
 
@@ -1652,6 +1706,54 @@ func (p *Platform) enrichAndValidateFunctionConfig(ctx context.Context, function
 	return nil
 }
 
+// validateRollout makes sure the rollout steps shift traffic forward, and that the error rate of the new
+// version can be measured - the controller reads it from the processor's prometheus pull endpoint
+func (p *Platform) validateRollout(functionConfig *functionconfig.Config) error {
+	rollout := functionConfig.Spec.Rollout
+	if rollout == nil {
+		return nil
+	}
+
+	switch rollout.Strategy {
+	case functionconfig.RolloutStrategyCanary:
+		lastWeight := 0
+		for _, weight := range rollout.Steps {
+			if weight <= lastWeight || weight > 100 {
+				return nuclio.NewErrBadRequest(
+					"Rollout steps must be increasing traffic weights between 1 and 100")
+			}
+			lastWeight = weight
+		}
+	case functionconfig.RolloutStrategyBlueGreen:
+		if len(rollout.Steps) > 0 {
+			return nuclio.NewErrBadRequest("Blue/green rollouts shift all of the traffic at once and take no steps")
+		}
+	default:
+		return nuclio.NewErrBadRequest(fmt.Sprintf("Unsupported rollout strategy %s", rollout.Strategy))
+	}
+
+	if rollout.StepDurationSeconds < 0 || rollout.MinEvents < 0 {
+		return nuclio.NewErrBadRequest("Rollout step duration and minimum events must not be negative")
+	}
+
+	if rollout.MaxErrorRate < 0 || rollout.MaxErrorRate > 1 {
+		return nuclio.NewErrBadRequest("Rollout max error rate must be between 0 and 1")
+	}
+
+	metricSinks, err := p.Config.GetFunctionMetricSinks()
+	if err != nil {
+		return errors.Wrap(err, "Failed to get function metric sinks")
+	}
+
+	for _, metricSink := range metricSinks {
+		if metricSink.Kind == "prometheusPull" {
+			return nil
+		}
+	}
+
+	return nuclio.NewErrBadRequest("Rollouts require functions to expose their metrics with a prometheusPull metric sink")
+}
+
 func (p *Platform) validateServiceType(functionConfig *functionconfig.Config) error {
 	serviceType := functionconfig.ResolveFunctionServiceType(&functionConfig.Spec, p.Config.Kube.DefaultServiceType)
 	switch serviceType {
@@ -1750,8 +1852,10 @@ func (p *Platform) validateFunctionIngresses(ctx context.Context, functionConfig
 
 	listIngressesOptions := metav1.ListOptions{
 
-		// validate ingresses not created by this function
-		FieldSelector: fmt.Sprintf("metadata.name!=%s", IngressNameFromFunctionName(functionConfig.Meta.Name)),
+		// validate ingresses not created by this function (whether it's being rolled out or not)
+		FieldSelector: fmt.Sprintf("metadata.name!=%s,metadata.name!=%s",
+			IngressNameFromFunctionName(functionConfig.Meta.Name),
+			IngressNameFromFunctionName(CanaryNameFromFunctionName(functionConfig.Meta.Name))),
 	}
 
 	ingresses := functionconfig.GetFunctionIngresses(functionConfig)
@@ -50,9 +50,11 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/cache"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 type KubePlatformTestSuite struct {
@@ -374,6 +376,364 @@ func (suite *FunctionKubePlatformTestSuite) TestValidateServiceType() {
 	}
 }
 
+func (suite *FunctionKubePlatformTestSuite) TestValidateRollout() {
+	prometheusPullMetrics := platformconfig.Metrics{
+		Sinks: map[string]platformconfig.MetricSink{
+			"myPrometheusPull": {
+				Kind: "prometheusPull",
+			},
+		},
+		Functions: []string{"myPrometheusPull"},
+	}
+
+	for idx, testCase := range []struct {
+		name                 string
+		rollout              *functionconfig.Rollout
+		metrics              platformconfig.Metrics
+		shouldFailValidation bool
+	}{
+
+		// happy flows
+		{
+			name: "noRollout",
+		},
+		{
+			name: "blueGreen",
+			rollout: &functionconfig.Rollout{
+				Strategy: functionconfig.RolloutStrategyBlueGreen,
+			},
+			metrics: prometheusPullMetrics,
+		},
+		{
+			name: "canaryDefaultSteps",
+			rollout: &functionconfig.Rollout{
+				Strategy: functionconfig.RolloutStrategyCanary,
+			},
+			metrics: prometheusPullMetrics,
+		},
+		{
+			name: "canaryCustomSteps",
+			rollout: &functionconfig.Rollout{
+				Strategy:            functionconfig.RolloutStrategyCanary,
+				Steps:               []int{5, 25, 100},
+				StepDurationSeconds: 30,
+				MaxErrorRate:        0.1,
+				MinEvents:           100,
+			},
+			metrics: prometheusPullMetrics,
+		},
+
+		// bad flows
+		{
+			name: "unsupportedStrategy",
+			rollout: &functionconfig.Rollout{
+				Strategy: "rolling",
+			},
+			metrics:              prometheusPullMetrics,
+			shouldFailValidation: true,
+		},
+		{
+			name: "blueGreenWithSteps",
+			rollout: &functionconfig.Rollout{
+				Strategy: functionconfig.RolloutStrategyBlueGreen,
+				Steps:    []int{50},
+			},
+			metrics:              prometheusPullMetrics,
+			shouldFailValidation: true,
+		},
+		{
+			name: "canaryDecreasingSteps",
+			rollout: &functionconfig.Rollout{
+				Strategy: functionconfig.RolloutStrategyCanary,
+				Steps:    []int{50, 10},
+			},
+			metrics:              prometheusPullMetrics,
+			shouldFailValidation: true,
+		},
+		{
+			name: "canaryStepAbove100",
+			rollout: &functionconfig.Rollout{
+				Strategy: functionconfig.RolloutStrategyCanary,
+				Steps:    []int{10, 150},
+			},
+			metrics:              prometheusPullMetrics,
+			shouldFailValidation: true,
+		},
+		{
+			name: "invalidMaxErrorRate",
+			rollout: &functionconfig.Rollout{
+				Strategy:     functionconfig.RolloutStrategyCanary,
+				MaxErrorRate: 1.5,
+			},
+			metrics:              prometheusPullMetrics,
+			shouldFailValidation: true,
+		},
+		{
+			name: "noPrometheusPullMetricSink",
+			rollout: &functionconfig.Rollout{
+				Strategy: functionconfig.RolloutStrategyCanary,
+			},
+			shouldFailValidation: true,
+		},
+	} {
+		suite.Run(testCase.name, func() {
+			suite.mockedPlatform.
+				On("GetProjects", suite.ctx, &platform.GetProjectsOptions{
+					Meta: platform.ProjectMeta{
+						Name:      platform.DefaultProjectName,
+						Namespace: "default",
+					},
+				}).
+				Return([]platform.Project{
+					&platform.AbstractProject{},
+				}, nil).
+				Once()
+
+			previousMetrics := suite.platform.Config.Metrics
+			suite.platform.Config.Metrics = testCase.metrics
+			defer func() {
+				suite.platform.Config.Metrics = previousMetrics
+			}()
+
+			functionConfig := *functionconfig.NewConfig()
+			functionConfig.Meta.Name = fmt.Sprintf("func-%d", idx)
+			functionConfig.Meta.Labels = map[string]string{
+				"nuclio.io/project-name": platform.DefaultProjectName,
+			}
+			functionConfig.Spec.Rollout = testCase.rollout
+
+			err := suite.platform.ValidateFunctionConfig(suite.ctx, &functionConfig)
+			if testCase.shouldFailValidation {
+				suite.Require().Error(err, "Validation passed unexpectedly")
+			} else {
+				suite.Require().NoError(err, "Validation failed unexpectedly")
+			}
+		})
+	}
+}
+
+func (suite *FunctionKubePlatformTestSuite) TestValidateCanaryFunctionName() {
+	for _, testCase := range []struct {
+		name                 string
+		functionName         string
+		shouldFailValidation bool
+	}{
+		{
+			name:         "canaryInName",
+			functionName: "canary-reader",
+		},
+		{
+			name:                 "canarySuffix",
+			functionName:         "reader-canary",
+			shouldFailValidation: true,
+		},
+	} {
+		suite.Run(testCase.name, func() {
+			suite.mockedPlatform.
+				On("GetProjects", suite.ctx, &platform.GetProjectsOptions{
+					Meta: platform.ProjectMeta{
+						Name:      platform.DefaultProjectName,
+						Namespace: "default",
+					},
+				}).
+				Return([]platform.Project{
+					&platform.AbstractProject{},
+				}, nil).
+				Once()
+
+			functionConfig := *functionconfig.NewConfig()
+			functionConfig.Meta.Name = testCase.functionName
+			functionConfig.Meta.Labels = map[string]string{
+				"nuclio.io/project-name": platform.DefaultProjectName,
+			}
+
+			err := suite.platform.ValidateFunctionConfig(suite.ctx, &functionConfig)
+			if testCase.shouldFailValidation {
+				suite.Require().Error(err, "Validation passed unexpectedly")
+			} else {
+				suite.Require().NoError(err, "Validation failed unexpectedly")
+			}
+		})
+	}
+}
+
+func (suite *FunctionKubePlatformTestSuite) TestRecordFunctionRevisions() {
+	functionName := "revisioned-function"
+	suite.platform.Config.Kube.FunctionRevisionHistoryLimit = 2
+	defer func() {
+		suite.platform.Config.Kube.FunctionRevisionHistoryLimit = 0
+	}()
+
+	// a replica running the second image
+	_, err := suite.kubeClientSet.CoreV1().Pods(suite.Namespace).Create(suite.ctx, &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "revisioned-function-pod",
+			Namespace: suite.Namespace,
+			Labels: map[string]string{
+				common.NuclioResourceLabelKeyFunctionName: functionName,
+			},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:  client.FunctionContainerName,
+					Image: "my-image:2",
+				},
+			},
+		},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					Name:    client.FunctionContainerName,
+					ImageID: "docker-pullable://my-image@sha256:1234",
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	suite.Require().NoError(err)
+
+	for _, image := range []string{"my-image:1", "my-image:2", "my-image:3"} {
+		functionConfig := *functionconfig.NewConfig()
+		functionConfig.Meta.Name = functionName
+		functionConfig.Meta.Namespace = suite.Namespace
+		functionConfig.Meta.ResourceVersion = "1234"
+		functionConfig.Meta.Labels = map[string]string{
+			common.NuclioResourceLabelKeyProjectName: platform.DefaultProjectName,
+		}
+		functionConfig.Spec.Image = image
+
+		err := suite.platform.recordFunctionRevision(suite.ctx, &platform.CreateFunctionOptions{
+			Logger:         suite.Logger,
+			FunctionConfig: functionConfig,
+			AuthSession: &auth.IguazioSession{
+				Username: "some-user",
+			},
+		})
+		suite.Require().NoError(err)
+	}
+
+	// only the newest revisions are kept, newest first
+	functionRevisions, err := suite.platform.listFunctionRevisions(suite.ctx, suite.Namespace, functionName)
+	suite.Require().NoError(err)
+	suite.Require().Len(functionRevisions, 2)
+	suite.Require().Equal(3, functionRevisions[0].Revision)
+	suite.Require().Equal("my-image:3", functionRevisions[0].Image)
+	suite.Require().Equal("my-image:3", functionRevisions[0].Config.Spec.Image)
+	suite.Require().Empty(functionRevisions[0].Config.Meta.ResourceVersion)
+	suite.Require().Equal("some-user", functionRevisions[0].DeployedBy)
+	suite.Require().False(functionRevisions[0].DeployedAt.IsZero())
+	suite.Require().Empty(functionRevisions[0].ImageDigest)
+
+	suite.Require().Equal(2, functionRevisions[1].Revision)
+	suite.Require().Equal("sha256:1234", functionRevisions[1].ImageDigest)
+
+	configMap, err := suite.kubeClientSet.CoreV1().
+		ConfigMaps(suite.Namespace).
+		Get(suite.ctx, "nuclio-revisioned-function.revision-3", metav1.GetOptions{})
+	suite.Require().NoError(err)
+	suite.Require().True(*configMap.Immutable)
+
+	// deleting the revisions of the function removes all of them
+	err = suite.platform.deleteFunctionRevisions(suite.ctx, suite.Namespace, functionName)
+	suite.Require().NoError(err)
+	functionRevisions, err = suite.platform.listFunctionRevisions(suite.ctx, suite.Namespace, functionName)
+	suite.Require().NoError(err)
+	suite.Require().Empty(functionRevisions)
+}
+
+func (suite *FunctionKubePlatformTestSuite) TestRecordFunctionRevisionsConcurrently() {
+	functionName := "raced-function"
+
+	// another deployment records the first revision right before this one does
+	raced := false
+	suite.kubeClientSet.PrependReactor("create", "configmaps",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			configMap := action.(k8stesting.CreateAction).GetObject().(*v1.ConfigMap)
+			if raced || configMap.Name != "nuclio-raced-function.revision-1" {
+				return false, nil, nil
+			}
+			raced = true
+
+			concurrentConfigMap := configMap.DeepCopy()
+			concurrentConfigMap.Data["image"] = "concurrent-image"
+			suite.Require().NoError(suite.kubeClientSet.Tracker().Add(concurrentConfigMap))
+			return true, nil, apierrors.NewAlreadyExists(schema.GroupResource{Resource: "configmaps"}, configMap.Name)
+		})
+
+	functionConfig := *functionconfig.NewConfig()
+	functionConfig.Meta.Name = functionName
+	functionConfig.Meta.Namespace = suite.Namespace
+	functionConfig.Spec.Image = "my-image:1"
+
+	err := suite.platform.recordFunctionRevision(suite.ctx, &platform.CreateFunctionOptions{
+		Logger:         suite.Logger,
+		FunctionConfig: functionConfig,
+	})
+	suite.Require().NoError(err)
+
+	// both revisions are kept
+	functionRevisions, err := suite.platform.listFunctionRevisions(suite.ctx, suite.Namespace, functionName)
+	suite.Require().NoError(err)
+	suite.Require().Len(functionRevisions, 2)
+	suite.Require().Equal(2, functionRevisions[0].Revision)
+	suite.Require().Equal("my-image:1", functionRevisions[0].Image)
+	suite.Require().Equal("concurrent-image", functionRevisions[1].Image)
+}
+
+func (suite *FunctionKubePlatformTestSuite) TestFunctionRevisionsSensitiveValuesChanged() {
+	functionName := "sensitive-function"
+
+	setFunctionSecretData := func(password string) {
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "nuclio-sensitive-function-abcd1234",
+				Namespace: suite.Namespace,
+				Labels: map[string]string{
+					common.NuclioResourceLabelKeyFunctionName: functionName,
+				},
+			},
+			Data: map[string][]byte{
+				"some-key": []byte(password),
+			},
+		}
+		_, err := suite.kubeClientSet.CoreV1().Secrets(suite.Namespace).Update(suite.ctx, secret, metav1.UpdateOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err = suite.kubeClientSet.CoreV1().Secrets(suite.Namespace).Create(suite.ctx, secret, metav1.CreateOptions{})
+		}
+		suite.Require().NoError(err)
+	}
+
+	recordFunctionRevision := func(password string) {
+		functionConfig := *functionconfig.NewConfig()
+		functionConfig.Meta.Name = functionName
+		functionConfig.Meta.Namespace = suite.Namespace
+		functionConfig.Spec.Image = "my-image:1"
+		functionConfig.Spec.Env = []v1.EnvVar{{Name: "PASSWORD", Value: password}}
+
+		err := suite.platform.recordFunctionRevision(suite.ctx, &platform.CreateFunctionOptions{
+			Logger:         suite.Logger,
+			FunctionConfig: functionConfig,
+		})
+		suite.Require().NoError(err)
+	}
+
+	// the first revision references its password in the function secret, the second doesn't reference any
+	setFunctionSecretData("first")
+	recordFunctionRevision(functionconfig.ReferencePrefix + "spec.env[0].value")
+	recordFunctionRevision("plain")
+
+	functionRevisions, err := suite.platform.listFunctionRevisions(suite.ctx, suite.Namespace, functionName)
+	suite.Require().NoError(err)
+	suite.Require().False(functionRevisions[1].SensitiveValuesChanged)
+
+	// a later deployment changed the password
+	setFunctionSecretData("second")
+	functionRevisions, err = suite.platform.listFunctionRevisions(suite.ctx, suite.Namespace, functionName)
+	suite.Require().NoError(err)
+	suite.Require().True(functionRevisions[1].SensitiveValuesChanged)
+	suite.Require().False(functionRevisions[0].SensitiveValuesChanged)
+}
+
 func (suite *FunctionKubePlatformTestSuite) TestFunctionTriggersEnrichmentAndValidation() {
 
 	// return empty api gateways list on enrichFunctionsWithAPIGateways (not tested here)
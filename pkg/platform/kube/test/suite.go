@@ -39,6 +39,7 @@ import (
 	nuclioioclient "github.com/nuclio/nuclio/pkg/platform/kube/client/clientset/versioned"
 	"github.com/nuclio/nuclio/pkg/platform/kube/controller"
 	"github.com/nuclio/nuclio/pkg/platform/kube/functionres"
+	"github.com/nuclio/nuclio/pkg/platform/kube/gatewayapi"
 	"github.com/nuclio/nuclio/pkg/platform/kube/ingress"
 	"github.com/nuclio/nuclio/pkg/platform/kube/test/kubectlclient"
 	"github.com/nuclio/nuclio/pkg/platformconfig"
@@ -52,6 +53,7 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	kubeapierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -625,8 +627,14 @@ func (suite *KubeTestSuite) createController() *controller.Controller {
 	suite.FunctionClientSet, err = nuclioioclient.NewForConfig(restConfig)
 	suite.Require().NoError(err)
 
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	suite.Require().NoError(err)
+
 	// create a client for function deployments
-	suite.FunctionClient, err = functionres.NewLazyClient(suite.Logger, suite.KubeClientSet, suite.FunctionClientSet)
+	suite.FunctionClient, err = functionres.NewLazyClient(suite.Logger,
+		suite.KubeClientSet,
+		suite.FunctionClientSet,
+		dynamicClient)
 	suite.Require().NoError(err)
 
 	// create cmd runner
@@ -637,11 +645,17 @@ func (suite *KubeTestSuite) createController() *controller.Controller {
 	ingressManager, err := ingress.NewManager(suite.Logger, suite.KubeClientSet, cmdRunner, suite.PlatformConfiguration)
 	suite.Require().NoError(err)
 
+	// create gateway API route manager
+	httpRouteManager, err := gatewayapi.NewManager(suite.Logger, dynamicClient)
+	suite.Require().NoError(err)
+
 	// create api-gateway provisioner
 	apigatewayresClient, err := apigatewayres.NewLazyClient(suite.Logger,
 		suite.KubeClientSet,
 		suite.FunctionClientSet,
-		ingressManager)
+		ingressManager,
+		httpRouteManager,
+		suite.PlatformConfiguration)
 	suite.Require().NoError(err)
 
 	controllerInstance, err := controller.NewController(suite.Logger,
@@ -651,10 +665,14 @@ func (suite *KubeTestSuite) createController() *controller.Controller {
 		suite.FunctionClientSet,
 		suite.FunctionClient,
 		apigatewayresClient,
+		nil,            // no usage metrics
 		0,              // disable resync interval
 		time.Second*5,  // monitor interval
 		time.Second*30, // cronjob stale duration
 		time.Minute*30, // evicted pods cleanup duration
+		time.Second*10, // rollout monitor interval
+		0,              // disable resource recommendation
+		0,              // resource recommendation window
 		suite.PlatformConfiguration,
 		"nuclio-platform-config",
 		1,
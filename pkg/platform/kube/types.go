@@ -22,6 +22,9 @@ import (
 	"github.com/rs/xid"
 )
 
+// FunctionContainerMetricPort is the port of the processor's prometheus pull endpoint in function pods
+const FunctionContainerMetricPort = 8090
+
 type DeployOptions struct {
 }
 
@@ -33,6 +36,10 @@ func IngressNameFromAPIGatewayName(apiGatewayName string, canary bool) string {
 	return resourceName
 }
 
+func HTTPRouteNameFromAPIGatewayName(apiGatewayName string) string {
+	return fmt.Sprintf("nuclio-agw-%s", apiGatewayName)
+}
+
 func BasicAuthNameFromAPIGatewayName(apiGatewayName string) string {
 	return fmt.Sprintf("nuclio-agw-%s", apiGatewayName)
 }
@@ -53,14 +60,45 @@ func HPANameFromFunctionName(functionName string) string {
 	return fmt.Sprintf("nuclio-%s", functionName)
 }
 
+func PodDisruptionBudgetNameFromFunctionName(functionName string) string {
+	return fmt.Sprintf("nuclio-%s", functionName)
+}
+
+func NetworkPolicyNameFromFunctionName(functionName string) string {
+	return fmt.Sprintf("nuclio-%s", functionName)
+}
+
+func ScaledObjectNameFromFunctionName(functionName string) string {
+	return fmt.Sprintf("nuclio-%s", functionName)
+}
+
 func IngressNameFromFunctionName(functionName string) string {
 	return fmt.Sprintf("nuclio-%s", functionName)
 }
 
+func HTTPRouteNameFromFunctionName(functionName string) string {
+	return fmt.Sprintf("nuclio-%s", functionName)
+}
+
 func ServiceNameFromFunctionName(functionName string) string {
 	return fmt.Sprintf("nuclio-%s", functionName)
 }
 
+// InvokerRoleNameFromFunctionName returns the name of the role (and role binding) allowing a function to look up
+// the functions it may invoke
+func InvokerRoleNameFromFunctionName(functionName string) string {
+	return fmt.Sprintf("nuclio-%s-invoker", functionName)
+}
+
+// CanaryNameSuffix suffixes the name of a function's canary resources. function names ending with it are
+// reserved, so that the canary resources of one function can't be those of another
+const CanaryNameSuffix = "-canary"
+
+// CanaryNameFromFunctionName returns the name a function's canary resources are named after, during a rollout
+func CanaryNameFromFunctionName(functionName string) string {
+	return functionName + CanaryNameSuffix
+}
+
 func CronJobName() string {
 	return fmt.Sprintf("nuclio-cron-job-%s", xid.New().String())
 }
@@ -44,6 +44,7 @@ import (
 	"github.com/nuclio/nuclio/pkg/platform/local/client"
 	"github.com/nuclio/nuclio/pkg/platformconfig"
 	"github.com/nuclio/nuclio/pkg/processor"
+	"github.com/nuclio/nuclio/pkg/processor/invoker"
 
 	"github.com/ghodss/yaml"
 	"github.com/nuclio/errors"
@@ -422,6 +423,12 @@ func (p *Platform) GetFunctionReplicaNames(ctx context.Context,
 	}, nil
 }
 
+// GetFunctionReplicaMetrics isn't supported, as the processor's metrics port isn't published by the local platform
+func (p *Platform) GetFunctionReplicaMetrics(ctx context.Context,
+	options *platform.GetFunctionReplicaMetricsOptions) (io.ReadCloser, error) {
+	return nil, nuclio.NewErrBadRequest("Function replica metrics are not supported on the local platform")
+}
+
 // GetHealthCheckMode returns the healthcheck mode the platform requires
 func (p *Platform) GetHealthCheckMode() platform.HealthCheckMode {
 
@@ -1253,9 +1260,35 @@ func (p *Platform) enrichAndValidateFunctionConfig(ctx context.Context, function
 		return errors.Wrap(err, "Failed to validate a function configuration")
 	}
 
+	// the processor runs alone in its docker container
+	if len(functionConfig.Spec.Sidecars) > 0 || len(functionConfig.Spec.InitContainers) > 0 {
+		return nuclio.NewErrBadRequest("Sidecars and init containers are not supported on the local platform")
+	}
+
+	if functionConfig.Spec.Rollout != nil {
+		return nuclio.NewErrBadRequest("Rollouts are not supported on the local platform")
+	}
+
+	// the invoker looks up the functions it invokes through the docker socket
+	if len(functionConfig.Spec.CallableFunctions) > 0 && !p.mountsDockerSocket(functionConfig) {
+		return nuclio.NewErrBadRequest(fmt.Sprintf(
+			"Functions that invoke other functions must mount the docker socket (%s) on the local platform",
+			invoker.DockerSocketPath))
+	}
+
 	return nil
 }
 
+func (p *Platform) mountsDockerSocket(functionConfig *functionconfig.Config) bool {
+	for _, volume := range functionConfig.Spec.Volumes {
+		if volume.Volume.HostPath != nil && volume.VolumeMount.MountPath == invoker.DockerSocketPath {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (p *Platform) populateFunctionInvocationStatus(functionInvocation *functionconfig.Status,
 	createFunctionResults *platform.CreateFunctionResult) error {
 
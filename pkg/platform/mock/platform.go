@@ -135,6 +135,26 @@ func (mp *Platform) GetFunctionReplicaNames(ctx context.Context, functionConfig
 	return args.Get(0).([]string), args.Error(1)
 }
 
+// GetFunctionReplicaMetrics returns the processor metrics of a function replica, in the prometheus text format
+func (mp *Platform) GetFunctionReplicaMetrics(ctx context.Context, options *platform.GetFunctionReplicaMetricsOptions) (io.ReadCloser, error) {
+	args := mp.Called(ctx, options)
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+// GetFunctionRevisions returns the revisions of a function, newest first
+func (mp *Platform) GetFunctionRevisions(ctx context.Context,
+	getFunctionRevisionsOptions *platform.GetFunctionRevisionsOptions) ([]*platform.FunctionRevision, error) {
+	args := mp.Called(ctx, getFunctionRevisionsOptions)
+	return args.Get(0).([]*platform.FunctionRevision), args.Error(1)
+}
+
+// GetDependencyGraph returns the graph of dependencies between functions, api gateways and function events
+func (mp *Platform) GetDependencyGraph(ctx context.Context,
+	getDependencyGraphOptions *platform.GetDependencyGraphOptions) (*platform.DependencyGraph, error) {
+	args := mp.Called(ctx, getDependencyGraphOptions)
+	return args.Get(0).(*platform.DependencyGraph), args.Error(1)
+}
+
 //
 // Project
 //
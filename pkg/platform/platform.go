@@ -86,6 +86,15 @@ type Platform interface {
 	// GetFunctionReplicaNames returns function replica names (Pod / Container names)
 	GetFunctionReplicaNames(context.Context, *functionconfig.Config) ([]string, error)
 
+	// GetFunctionReplicaMetrics returns the processor metrics of a function replica, in the prometheus text format
+	GetFunctionReplicaMetrics(context.Context, *GetFunctionReplicaMetricsOptions) (io.ReadCloser, error)
+
+	// GetFunctionRevisions returns the revisions of a function, newest first
+	GetFunctionRevisions(ctx context.Context, getFunctionRevisionsOptions *GetFunctionRevisionsOptions) ([]*FunctionRevision, error)
+
+	// GetDependencyGraph returns the graph of dependencies between functions, api gateways and function events
+	GetDependencyGraph(ctx context.Context, getDependencyGraphOptions *GetDependencyGraphOptions) (*DependencyGraph, error)
+
 	//
 	// Project
 	//
@@ -118,6 +118,41 @@ type GetFunctionsOptions struct {
 	EnrichWithAPIGateways bool
 }
 
+// GetFunctionRevisionsOptions is the base for all platform get function revisions options
+type GetFunctionRevisionsOptions struct {
+	Name              string
+	Namespace         string
+	PermissionOptions opa.PermissionOptions
+	AuthSession       auth.Session
+
+	// a specific revision to get, or 0 for all of them
+	Revision int
+}
+
+// GetDependencyGraphOptions is the base for all platform get dependency graph options
+type GetDependencyGraphOptions struct {
+	Namespace         string
+	PermissionOptions opa.PermissionOptions
+	AuthSession       auth.Session
+
+	// limit the graph to the resources of a project, or empty for the whole namespace
+	ProjectName string
+}
+
+// FunctionRevision is an immutable record of a successful function deployment
+type FunctionRevision struct {
+	Revision    int                   `json:"revision"`
+	Config      functionconfig.Config `json:"config"`
+	Image       string                `json:"image,omitempty"`
+	ImageDigest string                `json:"imageDigest,omitempty"`
+	DeployedBy  string                `json:"deployedBy,omitempty"`
+	DeployedAt  time.Time             `json:"deployedAt"`
+
+	// the config references sensitive values in the function secret, which changed since the revision was
+	// deployed. rolling back to it would deploy the current values
+	SensitiveValuesChanged bool `json:"sensitiveValuesChanged,omitempty"`
+}
+
 // CreateFunctionInvocationOptions is the base for all platform invoke options
 type CreateFunctionInvocationOptions struct {
 	Name         string
@@ -213,10 +248,15 @@ func (pm ProjectMeta) IsEqual(other ProjectMeta) bool {
 type ProjectSpec struct {
 	Description string `json:"description,omitempty"`
 	Owner       string `json:"owner,omitempty"`
+
+	// applies to functions of the project that don't specify a network policy (k8s only)
+	DefaultFunctionNetworkPolicy *functionconfig.NetworkPolicy `json:"defaultFunctionNetworkPolicy,omitempty"`
 }
 
 func (ps ProjectSpec) IsEqual(other ProjectSpec) bool {
-	return ps == other
+	return ps.Description == other.Description &&
+		ps.Owner == other.Owner &&
+		reflect.DeepEqual(ps.DefaultFunctionNetworkPolicy, other.DefaultFunctionNetworkPolicy)
 }
 
 type ProjectStatus struct {
@@ -448,6 +488,10 @@ type APIGatewaySpec struct {
 	AuthenticationMode ingress.AuthenticationMode    `json:"authenticationMode,omitempty"`
 	Authentication     *APIGatewayAuthenticationSpec `json:"authentication,omitempty"`
 	Upstreams          []APIGatewayUpstreamSpec      `json:"upstreams,omitempty"`
+
+	// requests must carry all of these headers to be routed through the api gateway.
+	// only supported when api gateways are exposed with the gateway API
+	MatchHeaders map[string]string `json:"matchHeaders,omitempty"`
 }
 
 type APIGatewayConfig struct {
@@ -523,6 +567,15 @@ type GetFunctionReplicaLogsStreamOptions struct {
 	TailLines *int64
 }
 
+type GetFunctionReplicaMetricsOptions struct {
+
+	// The replica (pod / container) name
+	Name string
+
+	// The replica (pod / container) namespace
+	Namespace string
+}
+
 type FunctionSecret struct {
 	Kubernetes *v1.Secret
 	Local      *string
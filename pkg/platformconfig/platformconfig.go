@@ -116,6 +116,14 @@ func NewPlatformConfig(configurationPath string) (*Config, error) {
 		config.Kube.DefaultServiceType = DefaultServiceType
 	}
 
+	if config.Kube.AutoScaler.Kind == "" {
+		config.Kube.AutoScaler.Kind = AutoScalerKindHPA
+	}
+
+	if config.Kube.Exposure.Kind == "" {
+		config.Kube.Exposure.Kind = ExposureKindIngress
+	}
+
 	if config.Kube.PreemptibleNodes != nil {
 		if config.Kube.PreemptibleNodes.DefaultMode == "" {
 			config.Kube.PreemptibleNodes.DefaultMode = functionconfig.RunOnPreemptibleNodesPrevent
@@ -228,6 +236,13 @@ func (c *Config) GetFunctionReadinessTimeoutOrDefault(functionReadinessTimeoutSe
 	return functionReadinessTimeoutSeconds
 }
 
+func (c *Config) GetFunctionRevisionHistoryLimit() int {
+	if c.Kube.FunctionRevisionHistoryLimit <= 0 {
+		return DefaultFunctionRevisionHistoryLimit
+	}
+	return c.Kube.FunctionRevisionHistoryLimit
+}
+
 func (c *Config) GetSystemMetricSinks() (map[string]MetricSink, error) {
 	return c.getMetricSinks(c.Metrics.System)
 }
@@ -278,6 +293,22 @@ func (c *Config) GetDefaultSupportedAutoScaleMetrics() []functionconfig.AutoScal
 			DisplayType: functionconfig.AutoScaleMetricTypeInt,
 		},
 
+		// Trigger backlog metrics
+		{
+			ScaleResource: functionconfig.ScaleResource{
+				MetricName: functionconfig.TriggerBacklogMessagesMetricName,
+			},
+			SourceType:  functionconfig.AutoScaleMetricSourceTypeTriggerBacklog,
+			DisplayType: functionconfig.AutoScaleMetricTypeInt,
+		},
+		{
+			ScaleResource: functionconfig.ScaleResource{
+				MetricName: functionconfig.TriggerBacklogOldestUnprocessedAgeMetricName,
+			},
+			SourceType:  functionconfig.AutoScaleMetricSourceTypeTriggerBacklog,
+			DisplayType: functionconfig.AutoScaleMetricTypeInt,
+		},
+
 		// Event metrics
 		{
 			ScaleResource: functionconfig.ScaleResource{
@@ -28,12 +28,14 @@ import (
 	"github.com/v3io/scaler/pkg/scalertypes"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	machinarymetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
 	DefaultFunctionReadinessTimeoutSeconds  = 120
 	DefaultFunctionInvocationTimeoutSeconds = 60
+	DefaultFunctionRevisionHistoryLimit     = 10
 )
 
 type LoggerSinkKind string
@@ -180,6 +182,94 @@ type PlatformKubeConfig struct {
 	DefaultFunctionPodResources      PodResourceRequirements `json:"defaultFunctionPodResources,omitempty"`
 	DefaultFunctionTolerations       []corev1.Toleration     `json:"defaultFunctionTolerations,omitempty"`
 	PreemptibleNodes                 *PreemptibleNodes       `json:"preemptibleNodes,omitempty"`
+
+	// applies to functions that don't specify a disruption budget
+	DefaultFunctionDisruptionBudget *functionconfig.DisruptionBudget `json:"defaultFunctionDisruptionBudget,omitempty"`
+
+	// identifies the ingress controller pods for function network policies that allow it.
+	// defaults to all pods in the "ingress-nginx" namespace
+	IngressControllerNetworkPolicyPeers []networkingv1.NetworkPolicyPeer `json:"ingressControllerNetworkPolicyPeers,omitempty"`
+
+	// identifies the monitoring pods (e.g. prometheus) allowed to scrape the metrics port of functions with a
+	// network policy. defaults to all pods in the function's namespace and in the "monitoring" namespace
+	MonitoringNetworkPolicyPeers []networkingv1.NetworkPolicyPeer `json:"monitoringNetworkPolicyPeers,omitempty"`
+
+	// whether function sidecars and init containers may run privileged, escalate privileges or add
+	// capabilities. the processor container never does
+	AllowPrivilegedFunctionContainers bool `json:"allowPrivilegedFunctionContainers,omitempty"`
+
+	// the backend that scales function deployments
+	AutoScaler AutoScalerConfig `json:"autoScaler,omitempty"`
+
+	// the resources that expose functions and api gateways outside the cluster
+	Exposure ExposureConfig `json:"exposure,omitempty"`
+
+	// how many revisions of every function are kept, the oldest are removed first
+	FunctionRevisionHistoryLimit int `json:"functionRevisionHistoryLimit,omitempty"`
+}
+
+type ExposureKind string
+
+const (
+
+	// ExposureKindIngress exposes functions and api gateways with nginx ingresses
+	ExposureKindIngress ExposureKind = "ingress"
+
+	// ExposureKindGatewayAPI exposes functions and api gateways with gateway API HTTPRoutes
+	ExposureKindGatewayAPI ExposureKind = "gatewayAPI"
+)
+
+type ExposureConfig struct {
+	Kind       ExposureKind     `json:"kind,omitempty"`
+	GatewayAPI GatewayAPIConfig `json:"gatewayAPI,omitempty"`
+}
+
+type GatewayAPIConfig struct {
+
+	// the gateways that function and api gateway routes attach to
+	ParentRefs []GatewayAPIParentReference `json:"parentRefs,omitempty"`
+
+	// implementation specific filters (e.g. an auth policy) applied to api gateways, by authentication mode.
+	// api gateways whose authentication mode has no filter cannot be exposed
+	AuthenticationFilters map[string]GatewayAPILocalObjectReference `json:"authenticationFilters,omitempty"`
+}
+
+// GatewayAPIParentReference identifies the gateway (and optionally its listener) a route attaches to
+type GatewayAPIParentReference struct {
+	Group       string `json:"group,omitempty"`
+	Kind        string `json:"kind,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name"`
+	SectionName string `json:"sectionName,omitempty"`
+	Port        *int32 `json:"port,omitempty"`
+}
+
+// GatewayAPILocalObjectReference references an implementation specific resource in the route's namespace
+type GatewayAPILocalObjectReference struct {
+	Group string `json:"group"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+}
+
+type AutoScalerKind string
+
+const (
+
+	// AutoScalerKindHPA scales functions with a horizontal pod autoscaler on cpu / custom metrics
+	AutoScalerKindHPA AutoScalerKind = "hpa"
+
+	// AutoScalerKindKEDA scales functions with a KEDA ScaledObject, deriving scalers from the function triggers
+	AutoScalerKindKEDA AutoScalerKind = "keda"
+)
+
+type AutoScalerConfig struct {
+	Kind AutoScalerKind `json:"kind,omitempty"`
+	KEDA KEDAConfig     `json:"keda,omitempty"`
+}
+
+type KEDAConfig struct {
+	PollingIntervalSeconds *int32 `json:"pollingIntervalSeconds,omitempty"`
+	CooldownPeriodSeconds  *int32 `json:"cooldownPeriodSeconds,omitempty"`
 }
 
 // PreemptibleNodes Holds data needed when user decided to run his function pods on a preemptible node (aka Spot node)
@@ -33,9 +33,22 @@ func NewControlMessageEvent(message *ControlMessage) *ControlMessageEvent {
 		resolvedBody:  message,
 	}
 
+	// encoders expect every event to come from a trigger
+	event.SetTriggerInfoProvider(&controlTriggerInfoProvider{})
+
 	return event
 }
 
+// GetBody returns the JSON encoded control message
+func (cme *ControlMessageEvent) GetBody() []byte {
+	encodedMessage, err := json.Marshal(cme.resolvedBody)
+	if err != nil {
+		return nil
+	}
+
+	return encodedMessage
+}
+
 // GetID returns the ID of the event
 func (cme *ControlMessageEvent) GetID() nuclio.ID {
 	return nuclio.ID(cme.resolvedBody.Kind)
@@ -57,3 +70,17 @@ func (cme *ControlMessageEvent) GetBodyObject() interface{} {
 	cme.resolvedBody = message
 	return message
 }
+
+type controlTriggerInfoProvider struct{}
+
+func (ctip *controlTriggerInfoProvider) GetClass() string {
+	return "sync"
+}
+
+func (ctip *controlTriggerInfoProvider) GetKind() string {
+	return "control"
+}
+
+func (ctip *controlTriggerInfoProvider) GetName() string {
+	return "control"
+}
@@ -26,6 +26,10 @@ type ControlMessageKind string
 
 const (
 	StreamMessageAckKind ControlMessageKind = "streamMessageAck"
+
+	// sent by the wrapper to invoke another function, answered with a function invocation result
+	FunctionInvocationKind       ControlMessageKind = "functionInvocation"
+	FunctionInvocationResultKind ControlMessageKind = "functionInvocationResult"
 )
 
 // TODO: move to nuclio-sdk-go
@@ -40,6 +44,37 @@ type ControlMessageAttributesExplicitAck struct {
 	Offset    int64  `json:"offset"`
 }
 
+type ControlMessageAttributesFunctionInvocation struct {
+
+	// echoed in the result, so that the wrapper can match it to the invocation
+	RequestID   string                 `json:"request_id"`
+	Function    string                 `json:"function"`
+	Project     string                 `json:"project,omitempty"`
+	Method      string                 `json:"method,omitempty"`
+	Path        string                 `json:"path,omitempty"`
+	ContentType string                 `json:"content_type,omitempty"`
+	Headers     map[string]interface{} `json:"headers,omitempty"`
+
+	// base64 encoded
+	Body string `json:"body,omitempty"`
+
+	// a duration string (e.g. 30s)
+	Timeout string `json:"timeout,omitempty"`
+}
+
+type ControlMessageAttributesFunctionInvocationResult struct {
+	RequestID   string                 `json:"request_id"`
+	StatusCode  int                    `json:"status_code,omitempty"`
+	ContentType string                 `json:"content_type,omitempty"`
+	Headers     map[string]interface{} `json:"headers,omitempty"`
+
+	// base64 encoded
+	Body string `json:"body,omitempty"`
+
+	// set when the function could not be invoked
+	Error string `json:"error,omitempty"`
+}
+
 type ControlConsumer struct {
 	Channels []chan *ControlMessage
 	kind     ControlMessageKind
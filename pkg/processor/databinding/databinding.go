@@ -28,6 +28,10 @@ type DataBinding interface {
 
 	// GetContextObject will return the object that is injected into the context
 	GetContextObject() (interface{}, error)
+
+	// GetConnectionParameters will return the parameters that runtimes running out of process (e.g. python)
+	// need in order to connect to the remote resource themselves
+	GetConnectionParameters() map[string]string
 }
 
 type AbstractDataBinding struct {
@@ -48,3 +52,8 @@ func (adb *AbstractDataBinding) Stop() error {
 func (adb *AbstractDataBinding) GetContextObject() (interface{}, error) {
 	return nil, nil
 }
+
+// GetConnectionParameters will return the parameters that runtimes running out of process need to connect
+func (adb *AbstractDataBinding) GetConnectionParameters() map[string]string {
+	return nil
+}
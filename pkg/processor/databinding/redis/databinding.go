@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/nuclio/nuclio/pkg/processor/databinding"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type redis struct {
+	databinding.AbstractDataBinding
+	configuration *Configuration
+	options       *goredis.Options
+	client        *goredis.Client
+}
+
+func newDataBinding(parentLogger logger.Logger, configuration *Configuration) (databinding.DataBinding, error) {
+	var err error
+
+	newRedis := redis{
+		AbstractDataBinding: databinding.AbstractDataBinding{
+			Logger: parentLogger.GetChild("redis"),
+		},
+		configuration: configuration,
+	}
+
+	newRedis.options, err = goredis.ParseURL(configuration.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse URL")
+	}
+
+	// the secret holds the password, unless given in the url
+	if newRedis.options.Password == "" {
+		newRedis.options.Password = configuration.Secret
+	}
+
+	newRedis.options.PoolSize = configuration.PoolSize
+	newRedis.options.MinIdleConns = configuration.MinIdleConns
+
+	newRedis.Logger.InfoWith("Creating",
+		"address", newRedis.options.Addr,
+		"db", newRedis.options.DB,
+		"poolSize", newRedis.options.PoolSize)
+
+	return &newRedis, nil
+}
+
+// Start will start the data binding, connecting to the remote resource
+func (r *redis) Start() error {
+	r.Logger.InfoWith("Starting", "address", r.options.Addr)
+
+	r.client = goredis.NewClient(r.options)
+
+	// health check
+	if err := r.client.Ping(context.Background()).Err(); err != nil {
+		return errors.Wrap(err, "Failed to ping redis")
+	}
+
+	return nil
+}
+
+// Stop will stop the data binding, cleaning up resources and tearing down connections
+func (r *redis) Stop() error {
+	if r.client == nil {
+		return nil
+	}
+
+	return r.client.Close()
+}
+
+// GetContextObject will return the object that is injected into the context
+func (r *redis) GetContextObject() (interface{}, error) {
+	return r.client, nil
+}
+
+// GetConnectionParameters will return the parameters that runtimes running out of process need to connect
+func (r *redis) GetConnectionParameters() map[string]string {
+	return map[string]string{
+		"URL":       r.configuration.URL,
+		"ADDRESS":   r.options.Addr,
+		"DB":        strconv.Itoa(r.options.DB),
+		"USERNAME":  r.options.Username,
+		"PASSWORD":  r.options.Password,
+		"POOL_SIZE": strconv.Itoa(r.options.PoolSize),
+	}
+}
@@ -0,0 +1,76 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TypesTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TypesTestSuite) TestNewConfigurationDefaults() {
+	configuration, err := NewConfiguration("cache", &functionconfig.DataBinding{
+		Kind: "redis",
+		URL:  "redis://localhost:6379/0",
+	})
+	suite.Require().NoError(err)
+
+	suite.Require().Equal(4, configuration.PoolSize)
+	suite.Require().Zero(configuration.MinIdleConns)
+}
+
+func (suite *TypesTestSuite) TestNewConfigurationAttributes() {
+	configuration, err := NewConfiguration("cache", &functionconfig.DataBinding{
+		Kind: "redis",
+		URL:  "redis://localhost:6379/0",
+		Attributes: map[string]interface{}{
+			"poolSize":     16,
+			"minIdleConns": 2,
+		},
+	})
+	suite.Require().NoError(err)
+
+	suite.Require().Equal(16, configuration.PoolSize)
+	suite.Require().Equal(2, configuration.MinIdleConns)
+}
+
+func (suite *TypesTestSuite) TestNewConfigurationInvalid() {
+	for _, dataBinding := range []functionconfig.DataBinding{
+		{Kind: "redis"},
+		{
+			Kind:       "redis",
+			URL:        "redis://localhost:6379/0",
+			Attributes: map[string]interface{}{"poolSize": "many"},
+		},
+	} {
+		dataBinding := dataBinding
+		_, err := NewConfiguration("cache", &dataBinding)
+		suite.Require().Error(err)
+	}
+}
+
+func TestTypesTestSuite(t *testing.T) {
+	suite.Run(t, new(TypesTestSuite))
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3
+
+import (
+	"strconv"
+
+	"github.com/nuclio/nuclio/pkg/processor/databinding"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+)
+
+type s3DataBinding struct {
+	databinding.AbstractDataBinding
+	configuration *Configuration
+	client        *s3.S3
+}
+
+func newDataBinding(parentLogger logger.Logger, configuration *Configuration) (databinding.DataBinding, error) {
+	newS3 := s3DataBinding{
+		AbstractDataBinding: databinding.AbstractDataBinding{
+			Logger: parentLogger.GetChild("s3"),
+		},
+		configuration: configuration,
+	}
+
+	newS3.Logger.InfoWith("Creating",
+		"endpoint", configuration.URL,
+		"region", configuration.Region,
+		"bucket", configuration.Bucket)
+
+	return &newS3, nil
+}
+
+// Start will start the data binding, connecting to the remote resource
+func (s *s3DataBinding) Start() error {
+	s.Logger.InfoWith("Starting", "endpoint", s.configuration.URL)
+
+	awsConfig := aws.NewConfig().
+		WithRegion(s.configuration.Region).
+		WithS3ForcePathStyle(s.configuration.ForcePathStyle)
+
+	// an empty url means AWS itself, otherwise an S3 compatible store (e.g. minio)
+	if s.configuration.URL != "" {
+		awsConfig = awsConfig.WithEndpoint(s.configuration.URL)
+	}
+
+	// without explicit credentials, fall back to the default chain (env, instance role, etc)
+	if s.configuration.AccessKeyID != "" {
+		awsConfig = awsConfig.WithCredentials(credentials.NewStaticCredentials(s.configuration.AccessKeyID,
+			s.configuration.SecretAccessKey,
+			""))
+	}
+
+	awsSession, err := session.NewSession(awsConfig)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create session")
+	}
+
+	s.client = s3.New(awsSession)
+
+	// health check
+	if s.configuration.Bucket != "" {
+		_, err = s.client.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String(s.configuration.Bucket)})
+	} else {
+		_, err = s.client.ListBuckets(&s3.ListBucketsInput{})
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "Failed to connect to object store")
+	}
+
+	return nil
+}
+
+// GetContextObject will return the object that is injected into the context
+func (s *s3DataBinding) GetContextObject() (interface{}, error) {
+	return s.client, nil
+}
+
+// GetConnectionParameters will return the parameters that runtimes running out of process need to connect
+func (s *s3DataBinding) GetConnectionParameters() map[string]string {
+	return map[string]string{
+		"ENDPOINT":          s.configuration.URL,
+		"REGION":            s.configuration.Region,
+		"BUCKET":            s.configuration.Bucket,
+		"ACCESS_KEY_ID":     s.configuration.AccessKeyID,
+		"SECRET_ACCESS_KEY": s.configuration.SecretAccessKey,
+		"FORCE_PATH_STYLE":  strconv.FormatBool(s.configuration.ForcePathStyle),
+	}
+}
@@ -0,0 +1,151 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	"github.com/nuclio/logger"
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/suite"
+)
+
+type DataBindingTestSuite struct {
+	suite.Suite
+	logger        logger.Logger
+	objectStore   *httptest.Server
+	requests      []string
+	authorization string
+}
+
+func (suite *DataBindingTestSuite) SetupSuite() {
+	suite.logger, _ = nucliozap.NewNuclioZapTest("test")
+}
+
+func (suite *DataBindingTestSuite) SetupTest() {
+	suite.requests = nil
+	suite.authorization = ""
+
+	// an in-process stand-in for an S3 compatible object store, holding a single bucket
+	suite.objectStore = httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		suite.requests = append(suite.requests, request.Method+" "+request.URL.Path)
+		suite.authorization = request.Header.Get("Authorization")
+
+		switch request.URL.Path {
+		case "/", "/events":
+			responseWriter.WriteHeader(http.StatusOK)
+		default:
+			responseWriter.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func (suite *DataBindingTestSuite) TearDownTest() {
+	suite.objectStore.Close()
+}
+
+func (suite *DataBindingTestSuite) TestStart() {
+	for _, testCase := range []struct {
+		name             string
+		bucket           string
+		expectedRequests []string
+		expectedError    bool
+	}{
+		{
+			name:             "headBucket",
+			bucket:           "events",
+			expectedRequests: []string{"HEAD /events"},
+		},
+		{
+			name:             "listBuckets",
+			expectedRequests: []string{"GET /"},
+		},
+		{
+			name:             "missingBucket",
+			bucket:           "missing",
+			expectedRequests: []string{"HEAD /missing"},
+			expectedError:    true,
+		},
+	} {
+		suite.Run(testCase.name, func() {
+			suite.requests = nil
+
+			dataBinding := suite.createDataBinding(map[string]interface{}{
+				"bucket":         testCase.bucket,
+				"accessKeyID":    "access-key",
+				"forcePathStyle": true,
+			})
+
+			err := dataBinding.Start()
+			if testCase.expectedError {
+				suite.Require().Error(err)
+			} else {
+				suite.Require().NoError(err)
+
+				contextObject, err := dataBinding.GetContextObject()
+				suite.Require().NoError(err)
+				suite.Require().NotNil(contextObject)
+			}
+
+			// requests are signed with the static credentials
+			suite.Require().Equal(testCase.expectedRequests, suite.requests)
+			suite.Require().Contains(suite.authorization, "Credential=access-key/")
+		})
+	}
+}
+
+func (suite *DataBindingTestSuite) TestGetConnectionParameters() {
+	dataBinding := suite.createDataBinding(map[string]interface{}{
+		"bucket":         "events",
+		"accessKeyID":    "access-key",
+		"forcePathStyle": true,
+	})
+
+	suite.Require().Equal(map[string]string{
+		"ENDPOINT":          suite.objectStore.URL,
+		"REGION":            "us-east-1",
+		"BUCKET":            "events",
+		"ACCESS_KEY_ID":     "access-key",
+		"SECRET_ACCESS_KEY": "from-secret",
+		"FORCE_PATH_STYLE":  "true",
+	}, dataBinding.GetConnectionParameters())
+}
+
+func (suite *DataBindingTestSuite) createDataBinding(attributes map[string]interface{}) *s3DataBinding {
+	configuration, err := NewConfiguration("objects", &functionconfig.DataBinding{
+		Kind:       "s3",
+		URL:        suite.objectStore.URL,
+		Secret:     "from-secret",
+		Attributes: attributes,
+	})
+	suite.Require().NoError(err)
+
+	dataBinding, err := newDataBinding(suite.logger, configuration)
+	suite.Require().NoError(err)
+
+	return dataBinding.(*s3DataBinding)
+}
+
+func TestDataBindingTestSuite(t *testing.T) {
+	suite.Run(t, new(DataBindingTestSuite))
+}
@@ -0,0 +1,77 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3
+
+import (
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TypesTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TypesTestSuite) TestNewConfigurationDefaults() {
+	configuration, err := NewConfiguration("objects", &functionconfig.DataBinding{
+		Kind:   "s3",
+		Secret: "from-secret",
+	})
+	suite.Require().NoError(err)
+
+	suite.Require().Equal("us-east-1", configuration.Region)
+	suite.Require().Equal("from-secret", configuration.SecretAccessKey)
+	suite.Require().False(configuration.ForcePathStyle)
+}
+
+func (suite *TypesTestSuite) TestNewConfigurationAttributes() {
+	configuration, err := NewConfiguration("objects", &functionconfig.DataBinding{
+		Kind:   "s3",
+		URL:    "http://minio:9000",
+		Secret: "from-secret",
+		Attributes: map[string]interface{}{
+			"region":          "eu-west-1",
+			"bucket":          "events",
+			"accessKeyID":     "access-key",
+			"secretAccessKey": "from-attributes",
+			"forcePathStyle":  true,
+		},
+	})
+	suite.Require().NoError(err)
+
+	suite.Require().Equal("eu-west-1", configuration.Region)
+	suite.Require().Equal("events", configuration.Bucket)
+	suite.Require().Equal("access-key", configuration.AccessKeyID)
+	suite.Require().Equal("from-attributes", configuration.SecretAccessKey)
+	suite.Require().True(configuration.ForcePathStyle)
+}
+
+func (suite *TypesTestSuite) TestNewConfigurationInvalid() {
+	_, err := NewConfiguration("objects", &functionconfig.DataBinding{
+		Kind:       "s3",
+		Attributes: map[string]interface{}{"forcePathStyle": "sometimes"},
+	})
+	suite.Require().Error(err)
+}
+
+func TestTypesTestSuite(t *testing.T) {
+	suite.Run(t, new(TypesTestSuite))
+}
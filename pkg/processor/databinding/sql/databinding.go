@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/nuclio/nuclio/pkg/processor/databinding"
+
+	// register drivers
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+)
+
+type sqlDataBinding struct {
+	databinding.AbstractDataBinding
+	configuration *Configuration
+	db            *sql.DB
+}
+
+func newDataBinding(parentLogger logger.Logger, configuration *Configuration) (databinding.DataBinding, error) {
+	newSQL := sqlDataBinding{
+		AbstractDataBinding: databinding.AbstractDataBinding{
+			Logger: parentLogger.GetChild("sql"),
+		},
+		configuration: configuration,
+	}
+
+	newSQL.Logger.InfoWith("Creating",
+		"driver", configuration.driverName,
+		"maxOpenConnections", configuration.MaxOpenConnections)
+
+	return &newSQL, nil
+}
+
+// Start will start the data binding, connecting to the remote resource
+func (s *sqlDataBinding) Start() error {
+	var err error
+
+	s.Logger.InfoWith("Starting", "driver", s.configuration.driverName)
+
+	s.db, err = sql.Open(s.configuration.driverName, s.configuration.URL)
+	if err != nil {
+		return errors.Wrap(err, "Failed to open database")
+	}
+
+	s.db.SetMaxOpenConns(s.configuration.MaxOpenConnections)
+	s.db.SetMaxIdleConns(s.configuration.MaxIdleConnections)
+	s.db.SetConnMaxLifetime(s.configuration.connectionMaxLifetime)
+
+	// health check. open doesn't connect, ping does
+	ctx, cancel := context.WithTimeout(context.Background(), s.configuration.healthCheckTimeout)
+	defer cancel()
+
+	if err := s.db.PingContext(ctx); err != nil {
+		return errors.Wrap(err, "Failed to ping database")
+	}
+
+	return nil
+}
+
+// Stop will stop the data binding, cleaning up resources and tearing down connections
+func (s *sqlDataBinding) Stop() error {
+	if s.db == nil {
+		return nil
+	}
+
+	return s.db.Close()
+}
+
+// GetContextObject will return the object that is injected into the context
+func (s *sqlDataBinding) GetContextObject() (interface{}, error) {
+	return s.db, nil
+}
+
+// GetConnectionParameters will return the parameters that runtimes running out of process need to connect
+func (s *sqlDataBinding) GetConnectionParameters() map[string]string {
+	return map[string]string{
+		"DRIVER":               s.configuration.driverName,
+		"DSN":                  s.configuration.URL,
+		"MAX_OPEN_CONNECTIONS": strconv.Itoa(s.configuration.MaxOpenConnections),
+	}
+}
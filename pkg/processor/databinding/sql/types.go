@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sql
+
+import (
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/processor/databinding"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/nuclio/errors"
+)
+
+type Configuration struct {
+	databinding.Configuration
+	MaxOpenConnections    int
+	MaxIdleConnections    int
+	ConnectionMaxLifetime string
+	HealthCheckTimeout    string
+
+	// resolved fields
+	driverName            string
+	connectionMaxLifetime time.Duration
+	healthCheckTimeout    time.Duration
+}
+
+func NewConfiguration(id string,
+	driverName string,
+	databindingConfiguration *functionconfig.DataBinding) (*Configuration, error) {
+	var err error
+
+	newConfiguration := Configuration{
+		driverName: driverName,
+	}
+
+	// create base
+	newConfiguration.Configuration = *databinding.NewConfiguration(id, databindingConfiguration)
+
+	// parse attributes
+	if err := mapstructure.Decode(newConfiguration.Configuration.Attributes, &newConfiguration); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode attributes")
+	}
+
+	// the url is the driver's data source name
+	if newConfiguration.URL == "" {
+		return nil, errors.Errorf("URL must be set to the %s data source name", driverName)
+	}
+
+	// each worker has its own pool, so keep it small by default
+	if newConfiguration.MaxOpenConnections == 0 {
+		newConfiguration.MaxOpenConnections = 2
+	}
+
+	if newConfiguration.MaxIdleConnections == 0 {
+		newConfiguration.MaxIdleConnections = newConfiguration.MaxOpenConnections
+	}
+
+	if newConfiguration.ConnectionMaxLifetime != "" {
+		newConfiguration.connectionMaxLifetime, err = time.ParseDuration(newConfiguration.ConnectionMaxLifetime)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to parse connection max lifetime")
+		}
+	}
+
+	newConfiguration.healthCheckTimeout = 10 * time.Second
+	if newConfiguration.HealthCheckTimeout != "" {
+		newConfiguration.healthCheckTimeout, err = time.ParseDuration(newConfiguration.HealthCheckTimeout)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to parse health check timeout")
+		}
+	}
+
+	return &newConfiguration, nil
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package invoker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+	"github.com/nuclio/nuclio-sdk-go"
+)
+
+// invokers by the context handed to handlers, so that go handlers can reach them
+var invokersByContext sync.Map
+
+// Invoker invokes other functions on behalf of the function the processor runs. it resolves the
+// target function through the platform the processor runs on, propagates tracing headers, identifies
+// the caller and only allows invoking the functions listed in the function's callableFunctions
+type Invoker struct {
+	logger             logger.Logger
+	platformKind       string
+	namespace          string
+	functionName       string
+	projectName        string
+	callableFunctions  []functionconfig.FunctionReference
+	httpClient         *http.Client
+	functionLookup     functionLookup
+	functionLookupErr  error
+	functionLookupOnce sync.Once
+}
+
+func NewInvoker(parentLogger logger.Logger, platformKind string, functionConfig *functionconfig.Config) *Invoker {
+	return &Invoker{
+		logger:            parentLogger.GetChild("invoker"),
+		platformKind:      platformKind,
+		namespace:         functionConfig.Meta.Namespace,
+		functionName:      functionConfig.Meta.Name,
+		projectName:       functionConfig.Meta.Labels[common.NuclioResourceLabelKeyProjectName],
+		callableFunctions: functionConfig.Spec.CallableFunctions,
+		httpClient:        &http.Client{},
+	}
+}
+
+// Register makes the invoker available to handlers given the context
+func Register(handlerContext *nuclio.Context, invokerInstance *Invoker) {
+	invokersByContext.Store(handlerContext, invokerInstance)
+}
+
+// Unregister releases the invoker registered given the context, once the worker holding it stops
+func Unregister(handlerContext *nuclio.Context) {
+	invokersByContext.Delete(handlerContext)
+}
+
+// FromContext returns the invoker of the processor running the handler given the context
+func FromContext(handlerContext *nuclio.Context) (*Invoker, error) {
+	invokerInstance, found := invokersByContext.Load(handlerContext)
+	if !found {
+		return nil, errors.New("Function invocation is not available in this context")
+	}
+
+	return invokerInstance.(*Invoker), nil
+}
+
+// CallFunction invokes a function in the project of the calling function with the given event.
+// parentEvent is the event being handled, from which tracing headers are propagated (may be nil)
+func CallFunction(handlerContext *nuclio.Context,
+	parentEvent nuclio.Event,
+	functionName string,
+	event nuclio.Event) (*nuclio.Response, error) {
+
+	invokerInstance, err := FromContext(handlerContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return invokerInstance.Invoke(parentEvent, &Request{
+		FunctionName: functionName,
+		Method:       event.GetMethod(),
+		Path:         event.GetPath(),
+		Body:         event.GetBody(),
+		ContentType:  event.GetContentType(),
+		Headers:      event.GetHeaders(),
+	})
+}
+
+// Invoke invokes a function, returning its response
+func (i *Invoker) Invoke(parentEvent nuclio.Event, request *Request) (*nuclio.Response, error) {
+	projectName := request.ProjectName
+	if projectName == "" {
+		projectName = i.projectName
+	}
+
+	if !i.isCallable(request.FunctionName, projectName) {
+		return nil, errors.Errorf("Function %s is not allowed to invoke function %s of project %s",
+			i.functionName,
+			request.FunctionName,
+			projectName)
+	}
+
+	functionURL, err := i.resolveFunctionURL(request.FunctionName, request.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to resolve function URL")
+	}
+
+	method := request.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	timeout := request.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	requestContext, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// functions are resolved by name only, so make sure the function reached is of the allowed project
+	if err := i.verifyFunctionProject(requestContext, request.FunctionName, projectName); err != nil {
+		return nil, err
+	}
+
+	httpRequest, err := http.NewRequestWithContext(requestContext, method, functionURL, bytes.NewReader(request.Body))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create request")
+	}
+
+	for headerKey, headerValue := range request.Headers {
+		httpRequest.Header.Set(headerKey, i.headerValueToString(headerValue))
+	}
+
+	if request.ContentType != "" {
+		httpRequest.Header.Set("Content-Type", request.ContentType)
+	}
+
+	i.propagateTracingHeaders(parentEvent, httpRequest.Header)
+
+	// identify the caller. these are always set by the invoker, so that they can't be spoofed by handlers
+	httpRequest.Header.Set(CallerFunctionHeaderKey, i.functionName)
+	httpRequest.Header.Set(CallerProjectHeaderKey, i.projectName)
+	httpRequest.Header.Set(CallerNamespaceHeaderKey, i.namespace)
+
+	i.logger.DebugWith("Invoking function",
+		"functionName", request.FunctionName,
+		"projectName", projectName,
+		"url", functionURL)
+
+	httpResponse, err := i.httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to invoke function %s", request.FunctionName)
+	}
+
+	defer httpResponse.Body.Close() // nolint: errcheck
+
+	responseBody, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read function response")
+	}
+
+	response := &nuclio.Response{
+		StatusCode:  httpResponse.StatusCode,
+		ContentType: httpResponse.Header.Get("Content-Type"),
+		Headers:     map[string]interface{}{},
+		Body:        responseBody,
+	}
+
+	for headerKey := range httpResponse.Header {
+		response.Headers[headerKey] = httpResponse.Header.Get(headerKey)
+	}
+
+	return response, nil
+}
+
+func (i *Invoker) isCallable(functionName string, projectName string) bool {
+	for _, callableFunction := range i.callableFunctions {
+		callableProjectName := callableFunction.Project
+		if callableProjectName == "" {
+			callableProjectName = i.projectName
+		}
+
+		if callableProjectName != projectName {
+			continue
+		}
+
+		if callableFunction.Name == "*" || callableFunction.Name == functionName {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (i *Invoker) verifyFunctionProject(ctx context.Context, functionName string, projectName string) error {
+	i.functionLookupOnce.Do(func() {
+		if i.functionLookup == nil {
+			i.functionLookup, i.functionLookupErr = newFunctionLookup(i.platformKind)
+		}
+	})
+
+	if i.functionLookupErr != nil {
+		return errors.Wrap(i.functionLookupErr, "Failed to create function lookup")
+	}
+
+	functionProjectName, err := i.functionLookup.getFunctionProjectName(ctx, i.namespace, functionName)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to look up function %s", functionName)
+	}
+
+	if functionProjectName != projectName {
+		return errors.Errorf("Function %s belongs to project %s, not to project %s",
+			functionName,
+			functionProjectName,
+			projectName)
+	}
+
+	return nil
+}
+
+func (i *Invoker) resolveFunctionURL(functionName string, path string) (string, error) {
+	if functionName == "" {
+		return "", errors.New("Function name must be specified")
+	}
+
+	var functionHost string
+
+	switch i.platformKind {
+
+	// function containers are reachable by name on the docker network
+	case common.LocalPlatformName:
+		functionHost = fmt.Sprintf("nuclio-%s-%s", i.namespace, functionName)
+
+	// functions are reachable through their service. function names are unique in a namespace, so
+	// the project doesn't take part in resolving the service
+	case common.KubePlatformName:
+		functionHost = fmt.Sprintf("nuclio-%s.%s.svc", functionName, i.namespace)
+
+	default:
+		return "", errors.Errorf("Function invocation is not supported on platform %s", i.platformKind)
+	}
+
+	return fmt.Sprintf("http://%s:%d/%s", functionHost, functionHTTPPort, strings.TrimPrefix(path, "/")), nil
+}
+
+func (i *Invoker) propagateTracingHeaders(parentEvent nuclio.Event, header http.Header) {
+	if parentEvent == nil {
+		return
+	}
+
+	// not all events implement header lookup by key, and header keys are cased differently across triggers
+	for headerKey, headerValue := range parentEvent.GetHeaders() {
+		for _, tracingHeaderKey := range tracingHeaderKeys {
+			if strings.EqualFold(headerKey, tracingHeaderKey) {
+				header.Set(tracingHeaderKey, i.headerValueToString(headerValue))
+			}
+		}
+	}
+}
+
+func (i *Invoker) headerValueToString(headerValue interface{}) string {
+	if typedHeaderValue, isByteSlice := headerValue.([]byte); isByteSlice {
+		return string(typedHeaderValue)
+	}
+
+	return fmt.Sprint(headerValue)
+}
@@ -0,0 +1,263 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package invoker
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/common"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	"github.com/nuclio/logger"
+	"github.com/nuclio/nuclio-sdk-go"
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/suite"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type InvokerTestSuite struct {
+	suite.Suite
+	logger          logger.Logger
+	function        *httptest.Server
+	receivedRequest *http.Request
+	receivedBody    string
+	invoker         *Invoker
+}
+
+func (suite *InvokerTestSuite) SetupSuite() {
+	suite.logger, _ = nucliozap.NewNuclioZapTest("test")
+}
+
+func (suite *InvokerTestSuite) SetupTest() {
+	suite.receivedRequest = nil
+	suite.function = httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		suite.receivedRequest = request
+		suite.receivedBody = string(body)
+
+		responseWriter.Header().Set("Content-Type", "text/plain")
+		responseWriter.WriteHeader(http.StatusCreated)
+		responseWriter.Write([]byte("invoked")) // nolint: errcheck
+	}))
+
+	suite.invoker = NewInvoker(suite.logger, common.KubePlatformName, &functionconfig.Config{
+		Meta: functionconfig.Meta{
+			Name:      "caller",
+			Namespace: "nuclio",
+			Labels: map[string]string{
+				common.NuclioResourceLabelKeyProjectName: "my-project",
+			},
+		},
+		Spec: functionconfig.Spec{
+			CallableFunctions: []functionconfig.FunctionReference{
+				{Name: "enricher"},
+				{Name: "impostor"},
+				{Name: "*", Project: "shared"},
+			},
+		},
+	})
+
+	// look up functions through their services, labeled with their projects
+	suite.invoker.functionLookup = &kubeFunctionLookup{
+		kubeClientSet: fake.NewSimpleClientset(
+			suite.newFunctionService("enricher", "my-project"),
+			suite.newFunctionService("impostor", "other-project"),
+			suite.newFunctionService("anything", "shared"),
+		),
+	}
+
+	// route all requests to the function stand-in, regardless of the resolved host
+	functionURL, _ := url.Parse(suite.function.URL)
+	suite.invoker.httpClient = &http.Client{
+		Transport: &rerouteTransport{host: functionURL.Host},
+	}
+}
+
+func (suite *InvokerTestSuite) TearDownTest() {
+	suite.function.Close()
+}
+
+func (suite *InvokerTestSuite) TestInvoke() {
+	parentEvent := &nuclio.MemoryEvent{
+		Headers: map[string]interface{}{
+			"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+			"X-Untraced":  "value",
+		},
+	}
+
+	response, err := suite.invoker.Invoke(parentEvent, &Request{
+		FunctionName: "enricher",
+		Path:         "/enrich",
+		Body:         []byte("payload"),
+		ContentType:  "application/json",
+		Headers: map[string]interface{}{
+			CallerFunctionHeaderKey: "spoofed",
+		},
+	})
+	suite.Require().NoError(err)
+
+	suite.Require().Equal(http.StatusCreated, response.StatusCode)
+	suite.Require().Equal("invoked", string(response.Body))
+	suite.Require().Equal("text/plain", response.ContentType)
+
+	suite.Require().Equal(http.MethodPost, suite.receivedRequest.Method)
+	suite.Require().Equal("/enrich", suite.receivedRequest.URL.Path)
+	suite.Require().Equal("payload", suite.receivedBody)
+	suite.Require().Equal("application/json", suite.receivedRequest.Header.Get("Content-Type"))
+	suite.Require().Equal(parentEvent.Headers["traceparent"], suite.receivedRequest.Header.Get("traceparent"))
+	suite.Require().Empty(suite.receivedRequest.Header.Get("X-Untraced"))
+	suite.Require().Equal("caller", suite.receivedRequest.Header.Get(CallerFunctionHeaderKey))
+	suite.Require().Equal("my-project", suite.receivedRequest.Header.Get(CallerProjectHeaderKey))
+	suite.Require().Equal("nuclio", suite.receivedRequest.Header.Get(CallerNamespaceHeaderKey))
+}
+
+func (suite *InvokerTestSuite) TestInvokeNotCallable() {
+	for _, request := range []*Request{
+		{FunctionName: "other"},
+		{FunctionName: "enricher", ProjectName: "other-project"},
+	} {
+		_, err := suite.invoker.Invoke(nil, request)
+		suite.Require().Error(err)
+	}
+
+	suite.Require().Nil(suite.receivedRequest)
+
+	// any function of the shared project
+	_, err := suite.invoker.Invoke(nil, &Request{FunctionName: "anything", ProjectName: "shared"})
+	suite.Require().NoError(err)
+}
+
+func (suite *InvokerTestSuite) TestInvokeProjectMismatch() {
+
+	// allowed by name in the caller's project, but the function reached belongs to another project
+	_, err := suite.invoker.Invoke(nil, &Request{FunctionName: "impostor"})
+	suite.Require().Error(err)
+	suite.Require().Contains(err.Error(), "belongs to project other-project")
+
+	// allowed by the shared project wildcard, but the function reached belongs to the caller's project
+	_, err = suite.invoker.Invoke(nil, &Request{FunctionName: "enricher", ProjectName: "shared"})
+	suite.Require().Error(err)
+
+	// allowed, but doesn't exist
+	_, err = suite.invoker.Invoke(nil, &Request{FunctionName: "missing", ProjectName: "shared"})
+	suite.Require().Error(err)
+
+	suite.Require().Nil(suite.receivedRequest)
+}
+
+func (suite *InvokerTestSuite) TestCachedFunctionLookup() {
+	kubeClientSet := fake.NewSimpleClientset(suite.newFunctionService("enricher", "my-project"))
+	cachedLookup := newCachedFunctionLookup(&kubeFunctionLookup{kubeClientSet: kubeClientSet}, time.Hour)
+
+	// the service is only fetched once
+	for i := 0; i < 3; i++ {
+		projectName, err := cachedLookup.getFunctionProjectName(context.Background(), "nuclio", "enricher")
+		suite.Require().NoError(err)
+		suite.Require().Equal("my-project", projectName)
+	}
+	suite.Require().Len(kubeClientSet.Actions(), 1)
+
+	// failed lookups aren't cached
+	for i := 0; i < 2; i++ {
+		_, err := cachedLookup.getFunctionProjectName(context.Background(), "nuclio", "missing")
+		suite.Require().Error(err)
+	}
+	suite.Require().Len(kubeClientSet.Actions(), 3)
+
+	// expired projects are looked up again
+	expiringLookup := newCachedFunctionLookup(&kubeFunctionLookup{kubeClientSet: kubeClientSet}, 0)
+	for i := 0; i < 2; i++ {
+		projectName, err := expiringLookup.getFunctionProjectName(context.Background(), "nuclio", "enricher")
+		suite.Require().NoError(err)
+		suite.Require().Equal("my-project", projectName)
+	}
+	suite.Require().Len(kubeClientSet.Actions(), 5)
+}
+
+func (suite *InvokerTestSuite) TestResolveFunctionURL() {
+	functionURL, err := suite.invoker.resolveFunctionURL("enricher", "/path")
+	suite.Require().NoError(err)
+	suite.Require().Equal("http://nuclio-enricher.nuclio.svc:8080/path", functionURL)
+
+	suite.invoker.platformKind = common.LocalPlatformName
+	functionURL, err = suite.invoker.resolveFunctionURL("enricher", "")
+	suite.Require().NoError(err)
+	suite.Require().Equal("http://nuclio-nuclio-enricher:8080/", functionURL)
+
+	suite.invoker.platformKind = "unknown"
+	_, err = suite.invoker.resolveFunctionURL("enricher", "")
+	suite.Require().Error(err)
+}
+
+func (suite *InvokerTestSuite) TestCallFunctionFromContext() {
+	handlerContext := &nuclio.Context{}
+
+	_, err := CallFunction(handlerContext, nil, "enricher", &nuclio.MemoryEvent{})
+	suite.Require().Error(err)
+
+	Register(handlerContext, suite.invoker)
+
+	response, err := CallFunction(handlerContext, nil, "enricher", &nuclio.MemoryEvent{
+		Method: http.MethodPut,
+		Body:   []byte("from context"),
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusCreated, response.StatusCode)
+	suite.Require().Equal(http.MethodPut, suite.receivedRequest.Method)
+	suite.Require().Equal("from context", suite.receivedBody)
+
+	Unregister(handlerContext)
+
+	_, err = FromContext(handlerContext)
+	suite.Require().Error(err)
+}
+
+func (suite *InvokerTestSuite) newFunctionService(functionName string, projectName string) *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nuclio-" + functionName,
+			Namespace: "nuclio",
+			Labels: map[string]string{
+				common.NuclioResourceLabelKeyFunctionName: functionName,
+				common.NuclioResourceLabelKeyProjectName:  projectName,
+			},
+		},
+	}
+}
+
+type rerouteTransport struct {
+	host string
+}
+
+func (rt *rerouteTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request.URL.Host = rt.host
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func TestInvokerTestSuite(t *testing.T) {
+	suite.Run(t, new(InvokerTestSuite))
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/common"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/nuclio-sdk-go"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// functionLookup looks up the functions the invoker reaches through the platform, so that the project
+// of the function actually reached can be verified before invoking it
+type functionLookup interface {
+
+	// getFunctionProjectName returns the project of a function, as labeled by the platform
+	getFunctionProjectName(ctx context.Context, namespace string, functionName string) (string, error)
+}
+
+func newFunctionLookup(platformKind string) (functionLookup, error) {
+	var platformFunctionLookup functionLookup
+	var err error
+
+	switch platformKind {
+	case common.LocalPlatformName:
+		platformFunctionLookup, err = newLocalFunctionLookup()
+	case common.KubePlatformName:
+		platformFunctionLookup, err = newKubeFunctionLookup()
+	default:
+		return nil, errors.Errorf("Function invocation is not supported on platform %s", platformKind)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newCachedFunctionLookup(platformFunctionLookup, functionProjectCacheTTL), nil
+}
+
+type cachedFunctionProject struct {
+	projectName string
+	expiration  time.Time
+}
+
+// cachedFunctionLookup caches the projects of the functions looked up through the platform, so that every
+// invocation doesn't look up the function it invokes again. failed lookups aren't cached
+type cachedFunctionLookup struct {
+	functionLookup   functionLookup
+	ttl              time.Duration
+	functionsLock    sync.Mutex
+	functionProjects map[string]cachedFunctionProject
+}
+
+func newCachedFunctionLookup(functionLookup functionLookup, ttl time.Duration) *cachedFunctionLookup {
+	return &cachedFunctionLookup{
+		functionLookup:   functionLookup,
+		ttl:              ttl,
+		functionProjects: map[string]cachedFunctionProject{},
+	}
+}
+
+func (cfl *cachedFunctionLookup) getFunctionProjectName(ctx context.Context,
+	namespace string,
+	functionName string) (string, error) {
+
+	cacheKey := fmt.Sprintf("%s/%s", namespace, functionName)
+
+	cfl.functionsLock.Lock()
+	functionProject, found := cfl.functionProjects[cacheKey]
+	cfl.functionsLock.Unlock()
+
+	if found && time.Now().Before(functionProject.expiration) {
+		return functionProject.projectName, nil
+	}
+
+	projectName, err := cfl.functionLookup.getFunctionProjectName(ctx, namespace, functionName)
+	if err != nil {
+		return "", err
+	}
+
+	cfl.functionsLock.Lock()
+	cfl.functionProjects[cacheKey] = cachedFunctionProject{
+		projectName: projectName,
+		expiration:  time.Now().Add(cfl.ttl),
+	}
+	cfl.functionsLock.Unlock()
+
+	return projectName, nil
+}
+
+// kubeFunctionLookup looks up functions through their services, which carry the function labels
+type kubeFunctionLookup struct {
+	kubeClientSet kubernetes.Interface
+}
+
+func newKubeFunctionLookup() (*kubeFunctionLookup, error) {
+	restConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get in-cluster config")
+	}
+
+	kubeClientSet, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create kube client set")
+	}
+
+	return &kubeFunctionLookup{
+		kubeClientSet: kubeClientSet,
+	}, nil
+}
+
+func (kfl *kubeFunctionLookup) getFunctionProjectName(ctx context.Context,
+	namespace string,
+	functionName string) (string, error) {
+
+	service, err := kfl.kubeClientSet.CoreV1().
+		Services(namespace).
+		Get(ctx, fmt.Sprintf("nuclio-%s", functionName), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nuclio.NewErrNotFound(fmt.Sprintf("Function %s not found", functionName))
+		}
+
+		return "", errors.Wrap(err, "Failed to get function service")
+	}
+
+	return service.Labels[common.NuclioResourceLabelKeyProjectName], nil
+}
+
+// localFunctionLookup looks up functions through their containers, which carry the function labels.
+// it requires the docker socket to be mounted into the calling function
+type localFunctionLookup struct {
+	httpClient *http.Client
+}
+
+func newLocalFunctionLookup() (*localFunctionLookup, error) {
+	if _, err := os.Stat(DockerSocketPath); err != nil {
+		return nil, errors.Wrapf(err,
+			"Functions that invoke other functions on the local platform must mount the docker socket (%s)",
+			DockerSocketPath)
+	}
+
+	return &localFunctionLookup{
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", DockerSocketPath)
+				},
+			},
+		},
+	}, nil
+}
+
+func (lfl *localFunctionLookup) getFunctionProjectName(ctx context.Context,
+	namespace string,
+	functionName string) (string, error) {
+
+	containerName := fmt.Sprintf("nuclio-%s-%s", namespace, functionName)
+
+	// the host is ignored, requests are always sent over the docker socket
+	request, err := http.NewRequestWithContext(ctx,
+		http.MethodGet,
+		fmt.Sprintf("http://docker/containers/%s/json", url.PathEscape(containerName)),
+		nil)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to create container inspect request")
+	}
+
+	response, err := lfl.httpClient.Do(request)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to inspect function container")
+	}
+
+	defer response.Body.Close() // nolint: errcheck
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", nuclio.NewErrNotFound(fmt.Sprintf("Function %s not found", functionName))
+	default:
+		return "", errors.Errorf("Unexpected status code inspecting function container: %d", response.StatusCode)
+	}
+
+	container := struct {
+		Config struct {
+			Labels map[string]string
+		}
+	}{}
+
+	if err := json.NewDecoder(response.Body).Decode(&container); err != nil {
+		return "", errors.Wrap(err, "Failed to decode function container")
+	}
+
+	return container.Config.Labels[common.NuclioResourceLabelKeyProjectName], nil
+}
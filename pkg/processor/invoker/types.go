@@ -0,0 +1,76 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package invoker
+
+import (
+	"time"
+)
+
+// headers the invoker sets on every invocation, identifying the calling function
+const (
+	CallerFunctionHeaderKey  = "X-Nuclio-Caller-Function"
+	CallerProjectHeaderKey   = "X-Nuclio-Caller-Project"
+	CallerNamespaceHeaderKey = "X-Nuclio-Caller-Namespace"
+)
+
+// functions are reached through their HTTP trigger, which listens on this port in the function's container
+const functionHTTPPort = 8080
+
+// on the local platform, function containers are looked up through the docker socket, which must be mounted
+// into the calling function
+const DockerSocketPath = "/var/run/docker.sock"
+
+// the projects of looked up functions are cached for this long. function names are unique in a namespace, so
+// a cached project only goes stale if the function is deleted and recreated in another project
+const functionProjectCacheTTL = 30 * time.Second
+
+const DefaultTimeout = time.Minute
+
+// tracingHeaderKeys are propagated from the event being handled to the invoked function, so that
+// the invocation is part of the same trace (w3c trace context, b3, jaeger)
+var tracingHeaderKeys = []string{
+	"traceparent",
+	"tracestate",
+	"baggage",
+	"b3",
+	"X-B3-TraceId",
+	"X-B3-SpanId",
+	"X-B3-ParentSpanId",
+	"X-B3-Sampled",
+	"X-B3-Flags",
+	"uber-trace-id",
+	"X-Request-Id",
+}
+
+// Request describes an invocation of another function
+type Request struct {
+
+	// the name of the function to invoke
+	FunctionName string
+
+	// the project of the function to invoke. defaults to the project of the calling function
+	ProjectName string
+
+	Method      string
+	Path        string
+	Body        []byte
+	ContentType string
+	Headers     map[string]interface{}
+
+	// defaults to DefaultTimeout
+	Timeout time.Duration
+}
@@ -51,6 +51,17 @@ func (esg *TriggerGatherer) Gather() error {
 	esg.track("EventsHandledSuccessTotal", float64(diffStatistics.EventsHandledSuccessTotal))
 	esg.track("EventsHandledFailureTotal", float64(diffStatistics.EventsHandledFailureTotal))
 
+	// stream triggers also report how far behind their source they are. a source that can't be queried
+	// right now is simply not tracked this period
+	if backlogReporter, isBacklogReporter := esg.trigger.(trigger.BacklogReporter); isBacklogReporter {
+		if backlog, err := backlogReporter.GetBacklog(); err == nil {
+			if backlog.Messages != nil {
+				esg.track("BacklogMessages", float64(*backlog.Messages))
+			}
+			esg.track("BacklogOldestUnprocessedAgeSeconds", backlog.OldestUnprocessedAge.Seconds())
+		}
+	}
+
 	return nil
 }
 
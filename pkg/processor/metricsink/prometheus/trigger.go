@@ -27,11 +27,15 @@ import (
 type TriggerGatherer struct {
 	trigger                                     trigger.Trigger
 	logger                                      logger.Logger
+	metricRegistry                              *prometheus.Registry
 	handledEventsTotal                          *prometheus.CounterVec
 	workerAllocationCount                       prometheus.Counter
 	workerAllocationTotal                       *prometheus.CounterVec
 	workerAllocationWaitDurationMilliSecondsSum prometheus.Counter
 	workerAllocationWorkersAvailablePercentage  prometheus.Counter
+	backlogMessages                             prometheus.Gauge
+	backlogOldestUnprocessedAgeSeconds          prometheus.Gauge
+	backlogMessagesRegistered                   bool
 	prevStatistics                              trigger.Statistics
 }
 
@@ -41,8 +45,9 @@ func NewTriggerGatherer(instanceName string,
 	metricRegistry *prometheus.Registry) (*TriggerGatherer, error) {
 
 	newTriggerGatherer := &TriggerGatherer{
-		trigger: trigger,
-		logger:  logger.GetChild("gatherer"),
+		trigger:        trigger,
+		logger:         logger.GetChild("gatherer"),
+		metricRegistry: metricRegistry,
 	}
 
 	// base labels for handle events
@@ -85,13 +90,34 @@ func NewTriggerGatherer(instanceName string,
 		ConstLabels: labels,
 	})
 
-	for _, collector := range []prometheus.Collector{
+	collectors := []prometheus.Collector{
 		newTriggerGatherer.handledEventsTotal,
 		newTriggerGatherer.workerAllocationTotal,
 		newTriggerGatherer.workerAllocationCount,
 		newTriggerGatherer.workerAllocationWaitDurationMilliSecondsSum,
 		newTriggerGatherer.workerAllocationWorkersAvailablePercentage,
-	} {
+	}
+
+	// stream triggers also report how far behind their source they are
+	if _, isBacklogReporter := newTriggerGatherer.getBacklogReporter(); isBacklogReporter {
+		newTriggerGatherer.backlogMessages = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "nuclio_processor_trigger_backlog_messages",
+			Help:        "Number of messages not yet processed",
+			ConstLabels: labels,
+		})
+
+		newTriggerGatherer.backlogOldestUnprocessedAgeSeconds = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "nuclio_processor_trigger_backlog_oldest_unprocessed_age_seconds",
+			Help:        "Age of the oldest message not yet processed",
+			ConstLabels: labels,
+		})
+
+		// the messages gauge is only registered once the source reports it, since some sources only report
+		// how far behind they are in time
+		collectors = append(collectors, newTriggerGatherer.backlogOldestUnprocessedAgeSeconds)
+	}
+
+	for _, collector := range collectors {
 		if err := metricRegistry.Register(collector); err != nil {
 			return nil, errors.Wrap(err, "Failed to register collector")
 		}
@@ -142,5 +168,34 @@ func (tg *TriggerGatherer) Gather() error {
 
 	tg.prevStatistics = currentStatistics
 
+	if backlogReporter, isBacklogReporter := tg.getBacklogReporter(); isBacklogReporter {
+
+		// failing to query the source shouldn't prevent the rest of the metrics from being gathered
+		backlog, err := backlogReporter.GetBacklog()
+		if err != nil {
+			tg.logger.WarnWith("Failed to get trigger backlog", "err", err.Error())
+			return nil
+		}
+
+		if backlog.Messages != nil {
+			if !tg.backlogMessagesRegistered {
+				if err := tg.metricRegistry.Register(tg.backlogMessages); err != nil {
+					return errors.Wrap(err, "Failed to register collector")
+				}
+
+				tg.backlogMessagesRegistered = true
+			}
+
+			tg.backlogMessages.Set(float64(*backlog.Messages))
+		}
+
+		tg.backlogOldestUnprocessedAgeSeconds.Set(backlog.OldestUnprocessedAge.Seconds())
+	}
+
 	return nil
 }
+
+func (tg *TriggerGatherer) getBacklogReporter() (trigger.BacklogReporter, bool) {
+	backlogReporter, isBacklogReporter := tg.trigger.(trigger.BacklogReporter)
+	return backlogReporter, isBacklogReporter
+}
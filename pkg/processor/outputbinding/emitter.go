@@ -0,0 +1,177 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package outputbinding
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+	"github.com/nuclio/nuclio-sdk-go"
+)
+
+// Emitter publishes handler responses to the function's output bindings
+type Emitter struct {
+	logger     logger.Logger
+	publishers map[string]Publisher
+}
+
+func NewEmitter(parentLogger logger.Logger,
+	outputBindings map[string]functionconfig.OutputBinding) (*Emitter, error) {
+
+	newEmitter := &Emitter{
+		logger:     parentLogger.GetChild("output-bindings"),
+		publishers: map[string]Publisher{},
+	}
+
+	for outputBindingName, outputBindingConfiguration := range outputBindings {
+		outputBindingConfiguration := outputBindingConfiguration
+
+		publisher, err := RegistrySingleton.NewPublisher(newEmitter.logger,
+			outputBindingConfiguration.Kind,
+			outputBindingName,
+			&outputBindingConfiguration)
+		if err != nil {
+			newEmitter.Close() // nolint: errcheck
+			return nil, errors.Wrapf(err, "Failed to create output binding %s", outputBindingName)
+		}
+
+		newEmitter.publishers[outputBindingName] = publisher
+	}
+
+	return newEmitter, nil
+}
+
+// Emit publishes a handler response to the output bindings it selects. an error is returned if
+// publishing to any of them failed, so that the event can be treated as failed
+func (e *Emitter) Emit(response interface{}) error {
+	message, selectedOutputBindings, err := e.resolveMessage(response)
+	if err != nil {
+		return errors.Wrap(err, "Failed to resolve message from response")
+	}
+
+	for _, outputBindingName := range selectedOutputBindings {
+		if err := e.publishers[outputBindingName].Publish(message); err != nil {
+			return errors.Wrapf(err, "Failed to publish response to output binding %s", outputBindingName)
+		}
+	}
+
+	return nil
+}
+
+// Close closes all publishers
+func (e *Emitter) Close() error {
+	var lastErr error
+
+	for outputBindingName, publisher := range e.publishers {
+		if err := publisher.Close(); err != nil {
+			e.logger.WarnWith("Failed to close output binding",
+				"name", outputBindingName,
+				"err", err.Error())
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+func (e *Emitter) resolveMessage(response interface{}) (*Message, []string, error) {
+	message := &Message{
+		Headers: map[string]string{},
+	}
+
+	var responseHeaders map[string]interface{}
+
+	switch typedResponse := response.(type) {
+	case nuclio.Response:
+		message.Body = typedResponse.Body
+		message.ContentType = typedResponse.ContentType
+		responseHeaders = typedResponse.Headers
+	case *nuclio.Response:
+		message.Body = typedResponse.Body
+		message.ContentType = typedResponse.ContentType
+		responseHeaders = typedResponse.Headers
+	case []byte:
+		message.Body = typedResponse
+	case string:
+		message.Body = []byte(typedResponse)
+	case nil:
+	default:
+		encodedResponse, err := json.Marshal(typedResponse)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "Failed to encode response")
+		}
+
+		message.Body = encodedResponse
+		message.ContentType = "application/json"
+	}
+
+	var selector *string
+
+	for headerKey, headerValue := range responseHeaders {
+		switch {
+		case strings.EqualFold(headerKey, SelectorHeaderKey):
+			selectorValue := fmt.Sprint(headerValue)
+			selector = &selectorValue
+		case strings.EqualFold(headerKey, KeyHeaderKey):
+			message.Key = fmt.Sprint(headerValue)
+		default:
+			message.Headers[headerKey] = fmt.Sprint(headerValue)
+		}
+	}
+
+	selectedOutputBindings, err := e.selectOutputBindings(selector)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Failed to select output bindings")
+	}
+
+	return message, selectedOutputBindings, nil
+}
+
+func (e *Emitter) selectOutputBindings(selector *string) ([]string, error) {
+	var selectedOutputBindings []string
+
+	// no selector - publish to all
+	if selector == nil {
+		for outputBindingName := range e.publishers {
+			selectedOutputBindings = append(selectedOutputBindings, outputBindingName)
+		}
+
+		sort.Strings(selectedOutputBindings)
+		return selectedOutputBindings, nil
+	}
+
+	for _, outputBindingName := range strings.Split(*selector, ",") {
+		outputBindingName = strings.TrimSpace(outputBindingName)
+		if outputBindingName == "" {
+			continue
+		}
+
+		if _, found := e.publishers[outputBindingName]; !found {
+			return nil, errors.Errorf("Unknown output binding: %s", outputBindingName)
+		}
+
+		selectedOutputBindings = append(selectedOutputBindings, outputBindingName)
+	}
+
+	return selectedOutputBindings, nil
+}
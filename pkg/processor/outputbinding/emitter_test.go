@@ -0,0 +1,168 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package outputbinding
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	"github.com/nuclio/logger"
+	"github.com/nuclio/nuclio-sdk-go"
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/suite"
+)
+
+type EmitterTestSuite struct {
+	suite.Suite
+	logger           logger.Logger
+	endpoint         *httptest.Server
+	endpointStatus   int
+	receivedRequests map[string][]*receivedRequest
+}
+
+type receivedRequest struct {
+	body   string
+	header http.Header
+}
+
+func (suite *EmitterTestSuite) SetupSuite() {
+	suite.logger, _ = nucliozap.NewNuclioZapTest("test")
+}
+
+func (suite *EmitterTestSuite) SetupTest() {
+	suite.endpointStatus = http.StatusOK
+	suite.receivedRequests = map[string][]*receivedRequest{}
+	suite.endpoint = httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		suite.receivedRequests[request.URL.Path] = append(suite.receivedRequests[request.URL.Path],
+			&receivedRequest{body: string(body), header: request.Header})
+
+		responseWriter.WriteHeader(suite.endpointStatus)
+	}))
+}
+
+func (suite *EmitterTestSuite) TearDownTest() {
+	suite.endpoint.Close()
+}
+
+func (suite *EmitterTestSuite) TestEmitToAll() {
+	emitter := suite.createEmitter("first", "second")
+
+	err := emitter.Emit(nuclio.Response{
+		Body:        []byte(`{"result": 1}`),
+		ContentType: "application/json",
+		Headers: map[string]interface{}{
+			"X-Custom": "value",
+		},
+	})
+	suite.Require().NoError(err)
+
+	for _, path := range []string{"/first", "/second"} {
+		suite.Require().Len(suite.receivedRequests[path], 1)
+		suite.Require().Equal(`{"result": 1}`, suite.receivedRequests[path][0].body)
+		suite.Require().Equal("application/json", suite.receivedRequests[path][0].header.Get("Content-Type"))
+		suite.Require().Equal("value", suite.receivedRequests[path][0].header.Get("X-Custom"))
+	}
+}
+
+func (suite *EmitterTestSuite) TestEmitToSelected() {
+	emitter := suite.createEmitter("first", "second")
+
+	err := emitter.Emit(&nuclio.Response{
+		Body: []byte("selected"),
+		Headers: map[string]interface{}{
+			"x-nuclio-output-bindings": "second",
+		},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Empty(suite.receivedRequests["/first"])
+	suite.Require().Len(suite.receivedRequests["/second"], 1)
+
+	// the selector header is not forwarded
+	suite.Require().Empty(suite.receivedRequests["/second"][0].header.Get(SelectorHeaderKey))
+
+	// an empty selector publishes nowhere
+	err = emitter.Emit(nuclio.Response{
+		Body:    []byte("dropped"),
+		Headers: map[string]interface{}{SelectorHeaderKey: ""},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Empty(suite.receivedRequests["/first"])
+	suite.Require().Len(suite.receivedRequests["/second"], 1)
+
+	// unknown output bindings fail the event
+	err = emitter.Emit(nuclio.Response{
+		Headers: map[string]interface{}{SelectorHeaderKey: "second, third"},
+	})
+	suite.Require().Error(err)
+}
+
+func (suite *EmitterTestSuite) TestEmitNonResponse() {
+	emitter := suite.createEmitter("first")
+
+	suite.Require().NoError(emitter.Emit("text"))
+	suite.Require().NoError(emitter.Emit(map[string]int{"a": 1}))
+
+	suite.Require().Len(suite.receivedRequests["/first"], 2)
+	suite.Require().Equal("text", suite.receivedRequests["/first"][0].body)
+	suite.Require().Equal(`{"a":1}`, suite.receivedRequests["/first"][1].body)
+	suite.Require().Equal("application/json", suite.receivedRequests["/first"][1].header.Get("Content-Type"))
+}
+
+func (suite *EmitterTestSuite) TestDeliveryFailure() {
+	emitter := suite.createEmitter("first")
+	suite.endpointStatus = http.StatusServiceUnavailable
+
+	suite.Require().Error(emitter.Emit("text"))
+}
+
+func (suite *EmitterTestSuite) TestCreateInvalid() {
+	_, err := NewEmitter(suite.logger, map[string]functionconfig.OutputBinding{
+		"unknown": {Kind: "carrier-pigeon"},
+	})
+	suite.Require().Error(err)
+
+	_, err = NewEmitter(suite.logger, map[string]functionconfig.OutputBinding{
+		"noTopic": {Kind: "kafka", URL: "localhost:9092"},
+	})
+	suite.Require().Error(err)
+}
+
+func (suite *EmitterTestSuite) createEmitter(names ...string) *Emitter {
+	outputBindings := map[string]functionconfig.OutputBinding{}
+	for _, name := range names {
+		outputBindings[name] = functionconfig.OutputBinding{
+			Kind: "http",
+			URL:  suite.endpoint.URL + "/" + name,
+		}
+	}
+
+	emitter, err := NewEmitter(suite.logger, outputBindings)
+	suite.Require().NoError(err)
+
+	return emitter
+}
+
+func TestEmitterTestSuite(t *testing.T) {
+	suite.Run(t, new(EmitterTestSuite))
+}
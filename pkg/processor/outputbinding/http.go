@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package outputbinding
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+)
+
+const defaultHTTPTimeout = 30 * time.Second
+
+type httpConfiguration struct {
+	Method  string
+	Headers map[string]string
+	Timeout string
+}
+
+type httpPublisher struct {
+	logger        logger.Logger
+	url           string
+	configuration httpConfiguration
+	client        *http.Client
+}
+
+type httpFactory struct{}
+
+func (f *httpFactory) Create(parentLogger logger.Logger,
+	name string,
+	configuration *functionconfig.OutputBinding) (Publisher, error) {
+
+	newPublisher := &httpPublisher{
+		logger: parentLogger.GetChild(name),
+		url:    configuration.URL,
+		configuration: httpConfiguration{
+			Method: http.MethodPost,
+		},
+	}
+
+	if err := mapstructure.Decode(configuration.Attributes, &newPublisher.configuration); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode http output binding attributes")
+	}
+
+	timeout := defaultHTTPTimeout
+	if newPublisher.configuration.Timeout != "" {
+		var err error
+
+		timeout, err = time.ParseDuration(newPublisher.configuration.Timeout)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to parse http output binding timeout")
+		}
+	}
+
+	newPublisher.client = &http.Client{Timeout: timeout}
+
+	return newPublisher, nil
+}
+
+func (hp *httpPublisher) Publish(message *Message) error {
+	request, err := http.NewRequest(hp.configuration.Method, hp.url, bytes.NewReader(message.Body))
+	if err != nil {
+		return errors.Wrap(err, "Failed to create request")
+	}
+
+	for headerKey, headerValue := range message.Headers {
+		request.Header.Set(headerKey, headerValue)
+	}
+
+	for headerKey, headerValue := range hp.configuration.Headers {
+		request.Header.Set(headerKey, headerValue)
+	}
+
+	if message.ContentType != "" {
+		request.Header.Set("Content-Type", message.ContentType)
+	}
+
+	response, err := hp.client.Do(request)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to send request to %s", hp.url)
+	}
+
+	defer response.Body.Close() // nolint: errcheck
+
+	if response.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf("Endpoint %s responded with status %d", hp.url, response.StatusCode)
+	}
+
+	return nil
+}
+
+func (hp *httpPublisher) Close() error {
+	hp.client.CloseIdleConnections()
+	return nil
+}
+
+// register factory
+func init() {
+	RegistrySingleton.Register("http", &httpFactory{})
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package outputbinding
+
+import (
+	"strings"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	"github.com/Shopify/sarama"
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+)
+
+type kafkaPublisher struct {
+	logger   logger.Logger
+	topic    string
+	producer sarama.SyncProducer
+}
+
+type kafkaFactory struct{}
+
+func (f *kafkaFactory) Create(parentLogger logger.Logger,
+	name string,
+	configuration *functionconfig.OutputBinding) (Publisher, error) {
+
+	if configuration.Target == "" {
+		return nil, errors.New("Kafka output binding requires a target topic")
+	}
+
+	producerConfig := sarama.NewConfig()
+	producerConfig.Producer.RequiredAcks = sarama.WaitForAll
+	producerConfig.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer(strings.Split(configuration.URL, ","), producerConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create kafka producer")
+	}
+
+	return &kafkaPublisher{
+		logger:   parentLogger.GetChild(name),
+		topic:    configuration.Target,
+		producer: producer,
+	}, nil
+}
+
+func (kp *kafkaPublisher) Publish(message *Message) error {
+	producerMessage := &sarama.ProducerMessage{
+		Topic: kp.topic,
+		Value: sarama.ByteEncoder(message.Body),
+	}
+
+	if message.Key != "" {
+		producerMessage.Key = sarama.StringEncoder(message.Key)
+	}
+
+	for headerKey, headerValue := range message.Headers {
+		producerMessage.Headers = append(producerMessage.Headers, sarama.RecordHeader{
+			Key:   []byte(headerKey),
+			Value: []byte(headerValue),
+		})
+	}
+
+	if _, _, err := kp.producer.SendMessage(producerMessage); err != nil {
+		return errors.Wrapf(err, "Failed to produce message to topic %s", kp.topic)
+	}
+
+	return nil
+}
+
+func (kp *kafkaPublisher) Close() error {
+	return kp.producer.Close()
+}
+
+// register factory
+func init() {
+	RegistrySingleton.Register("kafka", &kafkaFactory{})
+}
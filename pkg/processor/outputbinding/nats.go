@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package outputbinding
+
+import (
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	natsio "github.com/nats-io/nats.go"
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+)
+
+// nats has no publish acknowledgements - flushing makes sure the server received the message
+const natsFlushTimeout = 10 * time.Second
+
+type natsPublisher struct {
+	logger     logger.Logger
+	subject    string
+	connection *natsio.Conn
+}
+
+type natsFactory struct{}
+
+func (f *natsFactory) Create(parentLogger logger.Logger,
+	name string,
+	configuration *functionconfig.OutputBinding) (Publisher, error) {
+
+	if configuration.Target == "" {
+		return nil, errors.New("NATS output binding requires a target subject")
+	}
+
+	connection, err := natsio.Connect(configuration.URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to connect to NATS server %s", configuration.URL)
+	}
+
+	return &natsPublisher{
+		logger:     parentLogger.GetChild(name),
+		subject:    configuration.Target,
+		connection: connection,
+	}, nil
+}
+
+func (np *natsPublisher) Publish(message *Message) error {
+	natsMessage := natsio.NewMsg(np.subject)
+	natsMessage.Data = message.Body
+
+	for headerKey, headerValue := range message.Headers {
+		natsMessage.Header.Set(headerKey, headerValue)
+	}
+
+	if err := np.connection.PublishMsg(natsMessage); err != nil {
+		return errors.Wrapf(err, "Failed to publish message to subject %s", np.subject)
+	}
+
+	if err := np.connection.FlushTimeout(natsFlushTimeout); err != nil {
+		return errors.Wrap(err, "Failed to flush NATS connection")
+	}
+
+	return nil
+}
+
+func (np *natsPublisher) Close() error {
+	np.connection.Close()
+	return nil
+}
+
+// register factory
+func init() {
+	RegistrySingleton.Register("nats", &natsFactory{})
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package outputbinding
+
+import (
+	"context"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type rabbitMQConfiguration struct {
+
+	// the routing key to publish with, unless the response sets one in KeyHeaderKey
+	RoutingKey string
+}
+
+type rabbitMQPublisher struct {
+	logger        logger.Logger
+	exchange      string
+	configuration rabbitMQConfiguration
+	connection    *amqp.Connection
+	channel       *amqp.Channel
+}
+
+type rabbitMQFactory struct{}
+
+func (f *rabbitMQFactory) Create(parentLogger logger.Logger,
+	name string,
+	configuration *functionconfig.OutputBinding) (Publisher, error) {
+	var err error
+
+	newPublisher := &rabbitMQPublisher{
+		logger:   parentLogger.GetChild(name),
+		exchange: configuration.Target,
+	}
+
+	if err := mapstructure.Decode(configuration.Attributes, &newPublisher.configuration); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode rabbitmq output binding attributes")
+	}
+
+	newPublisher.connection, err = amqp.Dial(configuration.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to connect to rabbitmq broker")
+	}
+
+	newPublisher.channel, err = newPublisher.connection.Channel()
+	if err != nil {
+		newPublisher.connection.Close() // nolint: errcheck
+		return nil, errors.Wrap(err, "Failed to create rabbitmq channel")
+	}
+
+	// have the broker confirm every published message
+	if err := newPublisher.channel.Confirm(false); err != nil {
+		newPublisher.connection.Close() // nolint: errcheck
+		return nil, errors.Wrap(err, "Failed to put rabbitmq channel in confirm mode")
+	}
+
+	return newPublisher, nil
+}
+
+func (rp *rabbitMQPublisher) Publish(message *Message) error {
+	routingKey := rp.configuration.RoutingKey
+	if message.Key != "" {
+		routingKey = message.Key
+	}
+
+	headers := amqp.Table{}
+	for headerKey, headerValue := range message.Headers {
+		headers[headerKey] = headerValue
+	}
+
+	confirmation, err := rp.channel.PublishWithDeferredConfirmWithContext(context.Background(),
+		rp.exchange,
+		routingKey,
+		false,
+		false,
+		amqp.Publishing{
+			Headers:     headers,
+			ContentType: message.ContentType,
+			Body:        message.Body,
+		})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to publish message to exchange %s", rp.exchange)
+	}
+
+	if !confirmation.Wait() {
+		return errors.Errorf("Broker nacked message published to exchange %s", rp.exchange)
+	}
+
+	return nil
+}
+
+func (rp *rabbitMQPublisher) Close() error {
+	return rp.connection.Close()
+}
+
+// register factory
+func init() {
+	RegistrySingleton.Register("rabbit-mq", &rabbitMQFactory{})
+}
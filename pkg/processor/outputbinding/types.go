@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package outputbinding
+
+import (
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/registry"
+
+	"github.com/nuclio/logger"
+)
+
+const (
+
+	// SelectorHeaderKey is a response header holding a comma separated list of the output bindings the
+	// response should be published to. when it is not set, the response is published to all output bindings
+	SelectorHeaderKey = "X-Nuclio-Output-Bindings"
+
+	// KeyHeaderKey is a response header holding the kafka key / rabbitmq routing key of the published message
+	KeyHeaderKey = "X-Nuclio-Output-Key"
+)
+
+// Message is what gets published to an output binding
+type Message struct {
+	Body        []byte
+	ContentType string
+	Key         string
+	Headers     map[string]string
+}
+
+// Publisher publishes messages to an output binding. a publisher is owned by a single worker
+type Publisher interface {
+
+	// Publish publishes a message, returning once the destination has acknowledged it
+	Publish(message *Message) error
+
+	// Close releases the connection to the destination
+	Close() error
+}
+
+// Creator creates a publisher instance
+type Creator interface {
+
+	// Create creates a publisher instance
+	Create(logger.Logger, string, *functionconfig.OutputBinding) (Publisher, error)
+}
+
+type Registry struct {
+	registry.Registry
+}
+
+// global singleton
+var RegistrySingleton = Registry{
+	Registry: *registry.NewRegistry("outputbinding"),
+}
+
+func (r *Registry) NewPublisher(logger logger.Logger,
+	kind string,
+	name string,
+	outputBindingConfiguration *functionconfig.OutputBinding) (Publisher, error) {
+
+	registree, err := r.Get(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	return registree.(Creator).Create(logger,
+		name,
+		outputBindingConfiguration)
+}
@@ -26,11 +26,13 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/nuclio/nuclio/pkg/common"
 	"github.com/nuclio/nuclio/pkg/common/status"
+	"github.com/nuclio/nuclio/pkg/processor/controlcommunication"
 	"github.com/nuclio/nuclio/pkg/processor/runtime"
 	"github.com/nuclio/nuclio/pkg/processwaiter"
 
@@ -78,6 +80,13 @@ type AbstractRuntime struct {
 	cancelHandlerChan chan struct{}
 	socketType        SocketType
 	processWaiter     *processwaiter.ProcessWaiter
+
+	// the event the wrapper is currently handling, from which function invocations propagate tracing headers
+	eventInProcessLock sync.RWMutex
+	eventInProcess     nuclio.Event
+
+	// control messages may be written by concurrent function invocations
+	controlWriteLock sync.Mutex
 }
 
 type rpcLogRecord struct {
@@ -127,15 +136,18 @@ func (r *AbstractRuntime) ProcessEvent(event nuclio.Event, functionLogger logger
 	}
 
 	r.functionLogger = functionLogger
+	r.setEventInProcess(event)
 
 	// We don't use defer to reset r.functionLogger since it decreases performance
 	if err := r.eventEncoder.Encode(event); err != nil {
 		r.functionLogger = nil
+		r.setEventInProcess(nil)
 		return nil, errors.Wrapf(err, "Can't encode event: %+v", event)
 	}
 
 	result, ok := <-r.resultChan
 	r.functionLogger = nil
+	r.setEventInProcess(nil)
 	if !ok {
 		msg := "Client disconnected"
 		r.Logger.Error(msg)
@@ -527,6 +539,12 @@ func (r *AbstractRuntime) controlOutputHandler(conn io.Reader) {
 
 			r.Logger.DebugWith("Received control message", "messageKind", controlMessage.Kind)
 
+			// function invocations are served by the processor rather than by control consumers
+			if controlMessage.Kind == controlcommunication.FunctionInvocationKind {
+				go r.handleFunctionInvocation(controlMessage)
+				continue
+			}
+
 			// send message to control consumers
 			if err := r.GetControlMessageBroker().SendToConsumers(controlMessage); err != nil {
 				r.Logger.WarnWith("Failed to send control message to consumers", "err", err.Error())
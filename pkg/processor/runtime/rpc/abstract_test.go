@@ -204,6 +204,46 @@ func (suite *RuntimeSuite) TestReadControlMessage() {
 	suite.Require().Equal(controlMessage, reslovedControlMessage, "Read control message doesn't match")
 }
 
+func (suite *RuntimeSuite) TestFunctionInvocationNotAllowed() {
+	var err error
+
+	loggerInstance := suite.createLogger()
+	configInstance := suite.createConfig(loggerInstance)
+
+	suite.testRuntimeInstance, err = newTestRuntime(loggerInstance, configInstance)
+	suite.Require().NoError(err, "Can't create runtime")
+
+	// capture what's written to the wrapper
+	controlOutput := &bytes.Buffer{}
+	suite.testRuntimeInstance.ControlMessageBroker = NewRpcControlMessageBroker(
+		NewEventJSONEncoder(loggerInstance, controlOutput),
+		loggerInstance,
+		nil)
+
+	suite.testRuntimeInstance.handleFunctionInvocation(&controlcommunication.ControlMessage{
+		Kind: controlcommunication.FunctionInvocationKind,
+		Attributes: map[string]interface{}{
+			"request_id": "some-request",
+			"function":   "not-callable",
+		},
+	})
+
+	// the result is written as an event whose body is the control message
+	encodedEvent := struct {
+		Body []byte `json:"body"`
+	}{}
+	err = json.Unmarshal(controlOutput.Bytes(), &encodedEvent)
+	suite.Require().NoError(err, "Can't decode control event")
+
+	resultControlMessage := &controlcommunication.ControlMessage{}
+	err = json.Unmarshal(encodedEvent.Body, resultControlMessage)
+	suite.Require().NoError(err, "Can't decode control message")
+
+	suite.Require().Equal(controlcommunication.FunctionInvocationResultKind, resultControlMessage.Kind)
+	suite.Require().Equal("some-request", resultControlMessage.Attributes["request_id"])
+	suite.Require().Contains(resultControlMessage.Attributes["error"], "not allowed")
+}
+
 func (suite *RuntimeSuite) TearDownTest() {
 	if suite.testRuntimeInstance != nil && suite.testRuntimeInstance.wrapperProcess != nil {
 		suite.testRuntimeInstance.Stop() // nolint: errcheck
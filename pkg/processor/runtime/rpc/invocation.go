@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/processor/controlcommunication"
+	"github.com/nuclio/nuclio/pkg/processor/invoker"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/nuclio-sdk-go"
+)
+
+// handleFunctionInvocation invokes a function on behalf of the wrapper and writes back the result
+func (r *AbstractRuntime) handleFunctionInvocation(controlMessage *controlcommunication.ControlMessage) {
+	invocation := controlcommunication.ControlMessageAttributesFunctionInvocation{}
+	invocationResult := controlcommunication.ControlMessageAttributesFunctionInvocationResult{}
+
+	response, err := r.invokeFunction(controlMessage, &invocation)
+	if err != nil {
+		r.Logger.WarnWith("Failed to invoke function",
+			"function", invocation.Function,
+			"err", errors.Cause(err).Error())
+		invocationResult.Error = errors.Cause(err).Error()
+	} else {
+		invocationResult.StatusCode = response.StatusCode
+		invocationResult.ContentType = response.ContentType
+		invocationResult.Headers = response.Headers
+		invocationResult.Body = base64.StdEncoding.EncodeToString(response.Body)
+	}
+
+	invocationResult.RequestID = invocation.RequestID
+
+	if err := r.writeFunctionInvocationResult(&invocationResult); err != nil {
+		r.Logger.WarnWith("Failed to write function invocation result",
+			"requestID", invocation.RequestID,
+			"err", err.Error())
+	}
+}
+
+func (r *AbstractRuntime) invokeFunction(controlMessage *controlcommunication.ControlMessage,
+	invocation *controlcommunication.ControlMessageAttributesFunctionInvocation) (*nuclio.Response, error) {
+
+	// attributes are decoded into a generic map, round trip them into the typed invocation
+	encodedAttributes, err := json.Marshal(controlMessage.Attributes)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to encode function invocation attributes")
+	}
+
+	if err := json.Unmarshal(encodedAttributes, invocation); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode function invocation attributes")
+	}
+
+	body, err := base64.StdEncoding.DecodeString(invocation.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to decode function invocation body")
+	}
+
+	var timeout time.Duration
+	if invocation.Timeout != "" {
+		if timeout, err = time.ParseDuration(invocation.Timeout); err != nil {
+			return nil, errors.Wrap(err, "Failed to parse function invocation timeout")
+		}
+	}
+
+	return r.Invoker.Invoke(r.getEventInProcess(), &invoker.Request{
+		FunctionName: invocation.Function,
+		ProjectName:  invocation.Project,
+		Method:       invocation.Method,
+		Path:         invocation.Path,
+		Body:         body,
+		ContentType:  invocation.ContentType,
+		Headers:      invocation.Headers,
+		Timeout:      timeout,
+	})
+}
+
+func (r *AbstractRuntime) writeFunctionInvocationResult(
+	invocationResult *controlcommunication.ControlMessageAttributesFunctionInvocationResult) error {
+
+	encodedInvocationResult, err := json.Marshal(invocationResult)
+	if err != nil {
+		return errors.Wrap(err, "Failed to encode function invocation result")
+	}
+
+	controlMessage := &controlcommunication.ControlMessage{
+		Kind:       controlcommunication.FunctionInvocationResultKind,
+		Attributes: map[string]interface{}{},
+	}
+
+	if err := json.Unmarshal(encodedInvocationResult, &controlMessage.Attributes); err != nil {
+		return errors.Wrap(err, "Failed to decode function invocation result")
+	}
+
+	r.controlWriteLock.Lock()
+	defer r.controlWriteLock.Unlock()
+
+	return r.ControlMessageBroker.WriteControlMessage(controlMessage)
+}
+
+func (r *AbstractRuntime) setEventInProcess(event nuclio.Event) {
+	r.eventInProcessLock.Lock()
+	r.eventInProcess = event
+	r.eventInProcessLock.Unlock()
+}
+
+func (r *AbstractRuntime) getEventInProcess() nuclio.Event {
+	r.eventInProcessLock.RLock()
+	defer r.eventInProcessLock.RUnlock()
+
+	return r.eventInProcess
+}
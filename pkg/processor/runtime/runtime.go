@@ -19,10 +19,13 @@ package runtime
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/nuclio/nuclio/pkg/common/status"
 	"github.com/nuclio/nuclio/pkg/processor/controlcommunication"
 	"github.com/nuclio/nuclio/pkg/processor/databinding"
+	"github.com/nuclio/nuclio/pkg/processor/invoker"
 
 	"github.com/nuclio/errors"
 	"github.com/nuclio/logger"
@@ -38,6 +41,9 @@ type Runtime interface {
 	// GetFunctionLogger returns the function logger
 	GetFunctionLogger() logger.Logger
 
+	// GetContext returns the context handed to handlers
+	GetContext() *nuclio.Context
+
 	// GetStatistics returns statistics gathered by the runtime
 	GetStatistics() *Statistics
 
@@ -76,6 +82,7 @@ type AbstractRuntime struct {
 	Context              *nuclio.Context
 	Statistics           Statistics
 	ControlMessageBroker controlcommunication.ControlMessageBroker
+	Invoker              *invoker.Invoker
 	databindings         map[string]databinding.DataBinding
 	configuration        *Configuration
 	status               status.Status
@@ -102,6 +109,10 @@ func NewAbstractRuntime(logger logger.Logger, configuration *Configuration) (*Ab
 		return nil, errors.Wrap(err, "Failed to create data bindings")
 	}
 
+	newAbstractRuntime.Invoker = invoker.NewInvoker(logger,
+		configuration.PlatformConfig.Kind,
+		&configuration.Config)
+
 	newAbstractRuntime.Context, err = newAbstractRuntime.createContext(newAbstractRuntime.FunctionLogger,
 		configuration,
 		newAbstractRuntime.databindings)
@@ -163,12 +174,19 @@ func (ar *AbstractRuntime) SupportsControlCommunication() bool {
 }
 
 func (ar *AbstractRuntime) GetEnvFromConfiguration() []string {
-	return []string{
+	env := []string{
 		fmt.Sprintf("NUCLIO_FUNCTION_NAME=%s", ar.configuration.Meta.Name),
 		fmt.Sprintf("NUCLIO_FUNCTION_DESCRIPTION=%s", ar.configuration.Spec.Description),
 		fmt.Sprintf("NUCLIO_FUNCTION_VERSION=%d", ar.configuration.Spec.Version),
 		fmt.Sprintf("NUCLIO_FUNCTION_HANDLER=%s", ar.configuration.Spec.Handler),
 	}
+
+	return append(env, ar.getDataBindingsEnv()...)
+}
+
+// GetContext returns the context handed to handlers
+func (ar *AbstractRuntime) GetContext() *nuclio.Context {
+	return ar.Context
 }
 
 // GetControlMessageBroker returns the control message broker
@@ -213,6 +231,37 @@ func (ar *AbstractRuntime) createAndStartDataBindings(parentLogger logger.Logger
 	return databindings, nil
 }
 
+// getDataBindingsEnv returns the connection parameters of the data bindings, for wrappers to connect to the
+// remote resources themselves. parameters are named NUCLIO_DATA_BINDING_<NAME>_<PARAMETER>
+func (ar *AbstractRuntime) getDataBindingsEnv() []string {
+	var env []string
+
+	for databindingName, databindingInstance := range ar.databindings {
+		for parameterName, parameterValue := range databindingInstance.GetConnectionParameters() {
+			env = append(env, fmt.Sprintf("NUCLIO_DATA_BINDING_%s_%s=%s",
+				ar.toEnvVarName(databindingName),
+				parameterName,
+				parameterValue))
+		}
+	}
+
+	// map iteration order is random, keep the env stable
+	sort.Strings(env)
+
+	return env
+}
+
+// toEnvVarName upper cases the name and replaces characters that are not allowed in env var names
+func (ar *AbstractRuntime) toEnvVarName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+
+		return '_'
+	}, strings.ToUpper(name))
+}
+
 func (ar *AbstractRuntime) createContext(parentLogger logger.Logger,
 	configuration *Configuration,
 	databindings map[string]databinding.DataBinding) (*nuclio.Context, error) {
@@ -244,6 +293,9 @@ func (ar *AbstractRuntime) createContext(parentLogger logger.Logger,
 		}
 	}
 
+	// let go handlers invoke other functions through the context
+	invoker.Register(newContext, ar.Invoker)
+
 	return newContext, nil
 }
 
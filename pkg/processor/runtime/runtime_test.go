@@ -0,0 +1,92 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/processor/databinding"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// connectionParametersDataBinding is a data binding that only reports connection parameters
+type connectionParametersDataBinding struct {
+	databinding.AbstractDataBinding
+	connectionParameters map[string]string
+}
+
+func (cpdb *connectionParametersDataBinding) GetConnectionParameters() map[string]string {
+	return cpdb.connectionParameters
+}
+
+type RuntimeTestSuite struct {
+	suite.Suite
+}
+
+func (suite *RuntimeTestSuite) TestGetDataBindingsEnv() {
+	abstractRuntime := &AbstractRuntime{
+		databindings: map[string]databinding.DataBinding{
+			"cache": &connectionParametersDataBinding{
+				connectionParameters: map[string]string{
+					"ADDRESS": "redis:6379",
+					"DB":      "0",
+				},
+			},
+			"raw-events.v2": &connectionParametersDataBinding{
+				connectionParameters: map[string]string{
+					"BUCKET": "events",
+				},
+			},
+
+			// a data binding without connection parameters adds nothing
+			"legacy": &connectionParametersDataBinding{},
+		},
+	}
+
+	suite.Require().Equal([]string{
+		"NUCLIO_DATA_BINDING_CACHE_ADDRESS=redis:6379",
+		"NUCLIO_DATA_BINDING_CACHE_DB=0",
+		"NUCLIO_DATA_BINDING_RAW_EVENTS_V2_BUCKET=events",
+	}, abstractRuntime.getDataBindingsEnv())
+
+	// no data bindings, no env
+	suite.Require().Empty((&AbstractRuntime{}).getDataBindingsEnv())
+}
+
+func (suite *RuntimeTestSuite) TestToEnvVarName() {
+	abstractRuntime := &AbstractRuntime{}
+
+	for _, testCase := range []struct {
+		name     string
+		expected string
+	}{
+		{name: "db", expected: "DB"},
+		{name: "DB2", expected: "DB2"},
+		{name: "my-db", expected: "MY_DB"},
+		{name: "my.db_1", expected: "MY_DB_1"},
+		{name: "dé jà", expected: "D__J_"},
+	} {
+		suite.Require().Equal(testCase.expected, abstractRuntime.toEnvVarName(testCase.name), testCase.name)
+	}
+}
+
+func TestRuntimeTestSuite(t *testing.T) {
+	suite.Run(t, new(RuntimeTestSuite))
+}
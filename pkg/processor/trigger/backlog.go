@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"sync"
+	"time"
+)
+
+// BacklogReporter is implemented by stream triggers that know how far behind their source they are
+type BacklogReporter interface {
+
+	// GetBacklog returns the backlog of the partitions / shards currently consumed by the trigger
+	GetBacklog() (*Backlog, error)
+}
+
+type Backlog struct {
+
+	// number of messages not yet processed, nil if the source only reports how far behind it is in time
+	Messages *uint64
+
+	// how long the oldest message not yet processed has been waiting, zero if unknown
+	OldestUnprocessedAge time.Duration
+}
+
+type partitionBacklog struct {
+	messages              uint64
+	oldestUnprocessedTime time.Time
+}
+
+// BacklogTracker aggregates the backlog reported by the consumers of each partition
+type BacklogTracker struct {
+	lock       sync.Mutex
+	partitions map[string]partitionBacklog
+}
+
+func NewBacklogTracker() *BacklogTracker {
+	return &BacklogTracker{
+		partitions: map[string]partitionBacklog{},
+	}
+}
+
+// Update sets the number of messages not yet processed in a partition and the time at which the oldest
+// of them was produced (zero if unknown)
+func (bt *BacklogTracker) Update(partitionID string, messages uint64, oldestUnprocessedTime time.Time) {
+	bt.lock.Lock()
+	defer bt.lock.Unlock()
+
+	bt.partitions[partitionID] = partitionBacklog{
+		messages:              messages,
+		oldestUnprocessedTime: oldestUnprocessedTime,
+	}
+}
+
+// Remove stops accounting for a partition, e.g. when it is no longer claimed
+func (bt *BacklogTracker) Remove(partitionID string) {
+	bt.lock.Lock()
+	defer bt.lock.Unlock()
+
+	delete(bt.partitions, partitionID)
+}
+
+// GetBacklog sums the messages of all partitions, and returns the age of the oldest of them
+func (bt *BacklogTracker) GetBacklog() (*Backlog, error) {
+	bt.lock.Lock()
+	defer bt.lock.Unlock()
+
+	backlog := Backlog{
+		Messages: new(uint64),
+	}
+	now := time.Now()
+
+	for _, partition := range bt.partitions {
+		*backlog.Messages += partition.messages
+
+		// a partition without pending messages has no age
+		if partition.messages == 0 || partition.oldestUnprocessedTime.IsZero() {
+			continue
+		}
+
+		if age := now.Sub(partition.oldestUnprocessedTime); age > backlog.OldestUnprocessedAge {
+			backlog.OldestUnprocessedAge = age
+		}
+	}
+
+	return &backlog, nil
+}
@@ -0,0 +1,61 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BacklogTrackerTestSuite struct {
+	suite.Suite
+}
+
+func (suite *BacklogTrackerTestSuite) TestGetBacklog() {
+	backlogTracker := NewBacklogTracker()
+
+	backlog, err := backlogTracker.GetBacklog()
+	suite.Require().NoError(err)
+	suite.Require().Equal(&Backlog{Messages: new(uint64)}, backlog)
+
+	backlogTracker.Update("topic/0", 10, time.Now().Add(-time.Minute))
+	backlogTracker.Update("topic/1", 5, time.Now().Add(-time.Hour))
+	backlogTracker.Update("topic/2", 3, time.Time{})
+
+	// oldest message is an hour old
+	backlog, err = backlogTracker.GetBacklog()
+	suite.Require().NoError(err)
+	suite.Require().Equal(uint64(18), *backlog.Messages)
+	suite.Require().InDelta(time.Hour.Seconds(), backlog.OldestUnprocessedAge.Seconds(), 1)
+
+	// a caught up partition has no age, and a removed one doesn't count
+	backlogTracker.Update("topic/1", 0, time.Now().Add(-time.Hour))
+	backlogTracker.Remove("topic/2")
+
+	backlog, err = backlogTracker.GetBacklog()
+	suite.Require().NoError(err)
+	suite.Require().Equal(uint64(10), *backlog.Messages)
+	suite.Require().InDelta(time.Minute.Seconds(), backlog.OldestUnprocessedAge.Seconds(), 1)
+}
+
+func TestBacklogTrackerTestSuite(t *testing.T) {
+	suite.Run(t, new(BacklogTrackerTestSuite))
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/nuclio/nuclio/pkg/processor/outputbinding"
+	"github.com/nuclio/nuclio/pkg/processor/util/schemaregistry"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+)
+
+// ErrMessageDecodingFailed matches the errors of messages that failed decoding in the "fail" error mode
+// (see errors.Is), which the trigger must treat as failed events
+var ErrMessageDecodingFailed = errors.New("Message decoding failed")
+
+// messageDecodingFailedError keeps the decoding error as its cause
+type messageDecodingFailedError struct {
+	cause error
+}
+
+func (mdfe *messageDecodingFailedError) Error() string {
+	return fmt.Sprintf("Message decoding failed: %s", mdfe.cause.Error())
+}
+
+func (mdfe *messageDecodingFailedError) Unwrap() error {
+	return mdfe.cause
+}
+
+func (mdfe *messageDecodingFailedError) Is(target error) bool {
+	return target == ErrMessageDecodingFailed
+}
+
+// MessageDecoder decodes the messages of stream triggers that are encoded in the schema registry wire format,
+// and resolves what happens to messages that fail decoding according to the configured error mode
+type MessageDecoder struct {
+	logger  logger.Logger
+	decoder *schemaregistry.Decoder
+
+	// publishers are owned by a single worker, while messages are decoded concurrently
+	deadLetterPublisherLock sync.Mutex
+	deadLetterPublisher     outputbinding.Publisher
+}
+
+// NewMessageDecoder creates a message decoder, or returns nil if decoding isn't enabled in the configuration
+func NewMessageDecoder(parentLogger logger.Logger, configuration *schemaregistry.Configuration) (*MessageDecoder, error) {
+	if !configuration.Enabled() {
+		return nil, nil
+	}
+
+	decoder, err := schemaregistry.NewDecoder(parentLogger, configuration)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create schema registry decoder")
+	}
+
+	newMessageDecoder := &MessageDecoder{
+		logger:  parentLogger,
+		decoder: decoder,
+	}
+
+	if decoder.GetErrorMode() == schemaregistry.ErrorModeDeadLetter {
+		deadLetter := decoder.GetDeadLetter()
+
+		newMessageDecoder.deadLetterPublisher, err = outputbinding.RegistrySingleton.NewPublisher(parentLogger,
+			deadLetter.Kind,
+			"dead-letter",
+			deadLetter)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to create dead letter publisher")
+		}
+	}
+
+	return newMessageDecoder, nil
+}
+
+// Decode returns the payload the handler should get, and whether the message should be handled at all. messages
+// that should not be handled are acked / committed by the trigger, unless an error is returned. setErrorHeader is
+// called with the decoding error of messages that are passed through undecoded
+func (md *MessageDecoder) Decode(payload []byte,
+	setErrorHeader func(string),
+	logVars ...interface{}) ([]byte, bool, error) {
+	decodedPayload, err := md.decoder.Decode(payload)
+	if err == nil {
+		return decodedPayload, true, nil
+	}
+
+	md.logger.DebugWith("Failed to decode message",
+		append(logVars,
+			"errorMode", md.decoder.GetErrorMode(),
+			"err", err.Error())...)
+
+	switch md.decoder.GetErrorMode() {
+	case schemaregistry.ErrorModeSkip:
+		return nil, false, nil
+
+	case schemaregistry.ErrorModePassthrough:
+		setErrorHeader(err.Error())
+		return payload, true, nil
+
+	case schemaregistry.ErrorModeDeadLetter:
+		if publishErr := md.publishDeadLetter(payload, err); publishErr != nil {
+			return nil, false, &messageDecodingFailedError{cause: publishErr}
+		}
+		return nil, false, nil
+
+	default:
+		return nil, false, &messageDecodingFailedError{cause: err}
+	}
+}
+
+// Close releases the connection to the dead letter destination, if any
+func (md *MessageDecoder) Close() error {
+	if md.deadLetterPublisher == nil {
+		return nil
+	}
+
+	return md.deadLetterPublisher.Close()
+}
+
+func (md *MessageDecoder) publishDeadLetter(payload []byte, decodingErr error) error {
+	md.deadLetterPublisherLock.Lock()
+	defer md.deadLetterPublisherLock.Unlock()
+
+	if err := md.deadLetterPublisher.Publish(&outputbinding.Message{
+		Body: payload,
+		Headers: map[string]string{
+			schemaregistry.ErrorHeaderKey: decodingErr.Error(),
+		},
+	}); err != nil {
+		return errors.Wrap(err, "Failed to publish message to dead letter destination")
+	}
+
+	return nil
+}
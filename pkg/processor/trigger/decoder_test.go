@@ -0,0 +1,151 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/processor/util/schemaregistry"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/suite"
+)
+
+type MessageDecoderTestSuite struct {
+	suite.Suite
+	logger logger.Logger
+}
+
+func (suite *MessageDecoderTestSuite) SetupSuite() {
+	suite.logger, _ = nucliozap.NewNuclioZapTest("test")
+}
+
+func (suite *MessageDecoderTestSuite) TestDecodingDisabled() {
+	messageDecoder, err := NewMessageDecoder(suite.logger, nil)
+	suite.Require().NoError(err)
+	suite.Require().Nil(messageDecoder)
+}
+
+func (suite *MessageDecoderTestSuite) TestUndecodableMessage() {
+
+	// not in the schema registry wire format, so decoding fails without reaching the registry
+	payload := []byte("raw")
+
+	for _, testCase := range []struct {
+		name                  string
+		errorMode             schemaregistry.ErrorMode
+		expectedPayload       []byte
+		expectedHandleMessage bool
+		expectedErrorHeader   bool
+		expectedError         bool
+	}{
+		{
+			name:          "fail",
+			errorMode:     schemaregistry.ErrorModeFail,
+			expectedError: true,
+		},
+		{
+			name:      "skip",
+			errorMode: schemaregistry.ErrorModeSkip,
+		},
+		{
+			name:                  "passthrough",
+			errorMode:             schemaregistry.ErrorModePassthrough,
+			expectedPayload:       payload,
+			expectedHandleMessage: true,
+			expectedErrorHeader:   true,
+		},
+	} {
+		suite.Run(testCase.name, func() {
+			messageDecoder, err := NewMessageDecoder(suite.logger, &schemaregistry.Configuration{
+				URL:     "http://schema-registry",
+				OnError: testCase.errorMode,
+			})
+			suite.Require().NoError(err)
+
+			var errorHeader string
+			decodedPayload, handleMessage, err := messageDecoder.Decode(payload, func(decodingError string) {
+				errorHeader = decodingError
+			})
+
+			if testCase.expectedError {
+				suite.Require().Error(err)
+				suite.Require().True(errors.Is(err, ErrMessageDecodingFailed))
+			} else {
+				suite.Require().NoError(err)
+			}
+
+			suite.Require().Equal(testCase.expectedPayload, decodedPayload)
+			suite.Require().Equal(testCase.expectedHandleMessage, handleMessage)
+			suite.Require().Equal(testCase.expectedErrorHeader, errorHeader != "")
+		})
+	}
+}
+
+func (suite *MessageDecoderTestSuite) TestDeadLetter() {
+	var deadLetterBody []byte
+	var deadLetterErrorHeader string
+	deadLetterStatusCode := http.StatusOK
+
+	// an in-process stand-in for the dead letter destination
+	deadLetterServer := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		deadLetterBody, _ = io.ReadAll(request.Body)
+		deadLetterErrorHeader = request.Header.Get(schemaregistry.ErrorHeaderKey)
+		responseWriter.WriteHeader(deadLetterStatusCode)
+	}))
+	defer deadLetterServer.Close()
+
+	messageDecoder, err := NewMessageDecoder(suite.logger, &schemaregistry.Configuration{
+		URL:     "http://schema-registry",
+		OnError: schemaregistry.ErrorModeDeadLetter,
+		DeadLetter: &functionconfig.OutputBinding{
+			Kind: "http",
+			URL:  deadLetterServer.URL,
+		},
+	})
+	suite.Require().NoError(err)
+
+	defer messageDecoder.Close() // nolint: errcheck
+
+	// the raw message is published with the decoding error, and isn't handled
+	decodedPayload, handleMessage, err := messageDecoder.Decode([]byte("raw"), nil)
+	suite.Require().NoError(err)
+	suite.Require().Nil(decodedPayload)
+	suite.Require().False(handleMessage)
+	suite.Require().Equal([]byte("raw"), deadLetterBody)
+	suite.Require().NotEmpty(deadLetterErrorHeader)
+
+	// a message that can't be dead lettered is failed
+	deadLetterStatusCode = http.StatusInternalServerError
+
+	_, handleMessage, err = messageDecoder.Decode([]byte("raw"), nil)
+	suite.Require().Error(err)
+	suite.Require().True(errors.Is(err, ErrMessageDecodingFailed))
+	suite.Require().False(handleMessage)
+}
+
+func TestMessageDecoderTestSuite(t *testing.T) {
+	suite.Run(t, new(MessageDecoderTestSuite))
+}
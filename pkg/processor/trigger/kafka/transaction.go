@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/Shopify/sarama"
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+	"github.com/nuclio/nuclio-sdk-go"
+)
+
+const (
+
+	// response headers through which a handler asks for its response body to be produced
+	// as an output record, as part of the event's transaction
+	OutputTopicHeaderKey = "x-nuclio-kafka-output-topic"
+	OutputKeyHeaderKey   = "x-nuclio-kafka-output-key"
+)
+
+// errTransactionAborted matches the errors of aborted transactions (see errors.Is), after which the offset of the
+// message isn't committed and the claim must be consumed again from the last committed offset
+var errTransactionAborted = errors.New("Transaction aborted")
+
+// transactionAbortedError is the error of an aborted transaction, keeping the error that caused it as its cause
+type transactionAbortedError struct {
+	cause error
+}
+
+func newTransactionAbortedError(cause error) error {
+	return &transactionAbortedError{
+		cause: cause,
+	}
+}
+
+func (tae *transactionAbortedError) Error() string {
+	return fmt.Sprintf("Transaction aborted: %s", tae.cause.Error())
+}
+
+func (tae *transactionAbortedError) Unwrap() error {
+	return tae.cause
+}
+
+func (tae *transactionAbortedError) Is(target error) bool {
+	return target == errTransactionAborted
+}
+
+// transactionalProducer commits a consumed message's offset together with the output records
+// produced while handling it, in a single kafka transaction
+type transactionalProducer struct {
+	logger        logger.Logger
+	producer      sarama.SyncProducer
+	consumerGroup string
+}
+
+func newTransactionalProducer(parentLogger logger.Logger,
+	producer sarama.SyncProducer,
+	consumerGroup string) *transactionalProducer {
+	return &transactionalProducer{
+		logger:        parentLogger,
+		producer:      producer,
+		consumerGroup: consumerGroup,
+	}
+}
+
+// commit produces the records and commits the offset of the message in a single transaction. if any
+// of the steps fail the transaction is aborted, and neither the records nor the offset are visible
+func (tp *transactionalProducer) commit(message *sarama.ConsumerMessage, records []*sarama.ProducerMessage) error {
+
+	// there's no transaction to abort yet, but the offset isn't committed all the same
+	if err := tp.producer.BeginTxn(); err != nil {
+		return newTransactionAbortedError(errors.Wrap(err, "Failed to begin transaction"))
+	}
+
+	if len(records) > 0 {
+		if err := tp.producer.SendMessages(records); err != nil {
+			return tp.abort(errors.Wrap(err, "Failed to produce output records"))
+		}
+	}
+
+	if err := tp.producer.AddMessageToTxn(message, tp.consumerGroup, nil); err != nil {
+		return tp.abort(errors.Wrap(err, "Failed to add offset to transaction"))
+	}
+
+	if err := tp.producer.CommitTxn(); err != nil {
+		return tp.abort(errors.Wrap(err, "Failed to commit transaction"))
+	}
+
+	return nil
+}
+
+// abort aborts the current transaction, returning an error with the cause of it
+func (tp *transactionalProducer) abort(cause error) error {
+	tp.logger.WarnWith("Aborting transaction", "err", errors.Cause(cause).Error())
+
+	if err := tp.producer.AbortTxn(); err != nil {
+		return newTransactionAbortedError(errors.Wrapf(cause, "Failed to abort transaction (%s)", err.Error()))
+	}
+
+	return newTransactionAbortedError(cause)
+}
+
+func (tp *transactionalProducer) close() error {
+	return tp.producer.Close()
+}
+
+// resolveOutputRecords returns the records a handler asked to produce through its response
+func resolveOutputRecords(response interface{}) ([]*sarama.ProducerMessage, error) {
+	var typedResponse *nuclio.Response
+
+	switch responseValue := response.(type) {
+	case nuclio.Response:
+		typedResponse = &responseValue
+	case *nuclio.Response:
+		typedResponse = responseValue
+	default:
+		return nil, nil
+	}
+
+	if typedResponse == nil {
+		return nil, nil
+	}
+
+	outputTopic, found := typedResponse.Headers[OutputTopicHeaderKey]
+	if !found {
+		return nil, nil
+	}
+
+	outputTopicString, ok := outputTopic.(string)
+	if !ok || outputTopicString == "" {
+		return nil, errors.Errorf("Invalid output topic header value: %v", outputTopic)
+	}
+
+	record := &sarama.ProducerMessage{
+		Topic: outputTopicString,
+		Value: sarama.ByteEncoder(typedResponse.Body),
+	}
+
+	if outputKey, found := typedResponse.Headers[OutputKeyHeaderKey]; found {
+		record.Key = sarama.StringEncoder(fmt.Sprint(outputKey))
+	}
+
+	return []*sarama.ProducerMessage{record}, nil
+}
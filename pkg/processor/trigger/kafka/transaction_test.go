@@ -0,0 +1,490 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/processor"
+	"github.com/nuclio/nuclio/pkg/processor/runtime"
+	"github.com/nuclio/nuclio/pkg/processor/trigger"
+	"github.com/nuclio/nuclio/pkg/processor/util/partitionworker"
+	"github.com/nuclio/nuclio/pkg/processor/util/schemaregistry"
+	"github.com/nuclio/nuclio/pkg/processor/worker"
+
+	"github.com/Shopify/sarama"
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+	"github.com/nuclio/nuclio-sdk-go"
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/suite"
+)
+
+// brokerStandIn is an in-process stand-in for a transactional kafka broker. records and offsets
+// only become visible once the transaction that added them is committed
+type brokerStandIn struct {
+	sarama.SyncProducer
+	inTransaction    bool
+	pendingRecords   []*sarama.ProducerMessage
+	pendingOffsets   map[string]int64
+	committedRecords []*sarama.ProducerMessage
+	committedOffsets map[string]int64
+	beginErr         error
+	sendErr          error
+	commitErr        error
+	aborts           int
+}
+
+func newBrokerStandIn() *brokerStandIn {
+	return &brokerStandIn{
+		committedOffsets: map[string]int64{},
+	}
+}
+
+func (b *brokerStandIn) BeginTxn() error {
+	if b.beginErr != nil {
+		return b.beginErr
+	}
+
+	if b.inTransaction {
+		return errors.New("Transaction already in progress")
+	}
+
+	b.inTransaction = true
+	b.pendingRecords = nil
+	b.pendingOffsets = map[string]int64{}
+	return nil
+}
+
+func (b *brokerStandIn) SendMessages(records []*sarama.ProducerMessage) error {
+	if b.sendErr != nil {
+		return b.sendErr
+	}
+
+	b.pendingRecords = append(b.pendingRecords, records...)
+	return nil
+}
+
+func (b *brokerStandIn) AddMessageToTxn(message *sarama.ConsumerMessage, groupID string, metadata *string) error {
+	b.pendingOffsets[groupID] = message.Offset + 1
+	return nil
+}
+
+func (b *brokerStandIn) CommitTxn() error {
+	if b.commitErr != nil {
+		return b.commitErr
+	}
+
+	b.committedRecords = append(b.committedRecords, b.pendingRecords...)
+	for groupID, offset := range b.pendingOffsets {
+		b.committedOffsets[groupID] = offset
+	}
+
+	b.inTransaction = false
+	return nil
+}
+
+func (b *brokerStandIn) AbortTxn() error {
+	b.inTransaction = false
+	b.aborts++
+	return nil
+}
+
+func (b *brokerStandIn) Close() error {
+	return nil
+}
+
+// offsetRecordingRuntime handles every event successfully with the given response, recording the offsets of
+// the handled messages
+type offsetRecordingRuntime struct {
+	runtime.Runtime
+	handledOffsets []int
+	response       interface{}
+}
+
+func (r *offsetRecordingRuntime) ProcessEvent(event nuclio.Event, functionLogger logger.Logger) (interface{}, error) {
+	r.handledOffsets = append(r.handledOffsets, event.GetOffset())
+	return r.response, nil
+}
+
+type sessionStandIn struct {
+	sarama.ConsumerGroupSession
+}
+
+func (s *sessionStandIn) Context() context.Context {
+	return context.Background()
+}
+
+type claimStandIn struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *claimStandIn) Topic() string {
+	return "input"
+}
+
+func (c *claimStandIn) Partition() int32 {
+	return 0
+}
+
+func (c *claimStandIn) HighWaterMarkOffset() int64 {
+	return int64(cap(c.messages))
+}
+
+func (c *claimStandIn) Messages() <-chan *sarama.ConsumerMessage {
+	return c.messages
+}
+
+type TransactionTestSuite struct {
+	suite.Suite
+	logger   logger.Logger
+	broker   *brokerStandIn
+	producer *transactionalProducer
+}
+
+func (suite *TransactionTestSuite) SetupSuite() {
+	suite.logger, _ = nucliozap.NewNuclioZapTest("test")
+}
+
+func (suite *TransactionTestSuite) SetupTest() {
+	suite.broker = newBrokerStandIn()
+	suite.producer = newTransactionalProducer(suite.logger, suite.broker, "my-group")
+}
+
+func (suite *TransactionTestSuite) TestCommitRecordsAndOffset() {
+	records, err := resolveOutputRecords(nuclio.Response{
+		Body: []byte("transformed"),
+		Headers: map[string]interface{}{
+			OutputTopicHeaderKey: "output",
+			OutputKeyHeaderKey:   "some-key",
+		},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(records, 1)
+
+	err = suite.producer.commit(&sarama.ConsumerMessage{Topic: "input", Partition: 2, Offset: 41}, records)
+	suite.Require().NoError(err)
+
+	suite.Require().Len(suite.broker.committedRecords, 1)
+	suite.Require().Equal("output", suite.broker.committedRecords[0].Topic)
+	suite.Require().Equal(sarama.StringEncoder("some-key"), suite.broker.committedRecords[0].Key)
+	suite.Require().Equal(sarama.ByteEncoder("transformed"), suite.broker.committedRecords[0].Value)
+	suite.Require().Equal(int64(42), suite.broker.committedOffsets["my-group"])
+}
+
+func (suite *TransactionTestSuite) TestCommitOffsetWithoutRecords() {
+	records, err := resolveOutputRecords(nuclio.Response{Body: []byte("not produced")})
+	suite.Require().NoError(err)
+	suite.Require().Empty(records)
+
+	err = suite.producer.commit(&sarama.ConsumerMessage{Topic: "input", Offset: 9}, records)
+	suite.Require().NoError(err)
+
+	suite.Require().Empty(suite.broker.committedRecords)
+	suite.Require().Equal(int64(10), suite.broker.committedOffsets["my-group"])
+}
+
+func (suite *TransactionTestSuite) TestAbortOnFailure() {
+	records := []*sarama.ProducerMessage{{Topic: "output", Value: sarama.StringEncoder("value")}}
+	message := &sarama.ConsumerMessage{Topic: "input", Offset: 5}
+
+	for _, testCase := range []struct {
+		name      string
+		sendErr   error
+		commitErr error
+	}{
+		{name: "send", sendErr: errors.New("send failed")},
+		{name: "commit", commitErr: errors.New("commit failed")},
+	} {
+		suite.Run(testCase.name, func() {
+			suite.SetupTest()
+			suite.broker.sendErr = testCase.sendErr
+			suite.broker.commitErr = testCase.commitErr
+
+			err := suite.producer.commit(message, records)
+			suite.Require().Error(err)
+			suite.Require().True(errors.Is(err, errTransactionAborted))
+
+			// the cause is kept
+			if testCase.sendErr != nil {
+				suite.Require().True(errors.Is(err, testCase.sendErr))
+			} else {
+				suite.Require().True(errors.Is(err, testCase.commitErr))
+			}
+
+			// nothing is visible and the producer is ready for the next transaction
+			suite.Require().Equal(1, suite.broker.aborts)
+			suite.Require().Empty(suite.broker.committedRecords)
+			suite.Require().Empty(suite.broker.committedOffsets)
+			suite.Require().False(suite.broker.inTransaction)
+		})
+	}
+}
+
+func (suite *TransactionTestSuite) TestRedeliverMessageOfAbortedTransaction() {
+	handlerRuntime := &offsetRecordingRuntime{}
+	kafkaTrigger := suite.createTransactionalTrigger(handlerRuntime)
+
+	// the transaction of the first message fails, the second one would succeed
+	suite.broker.commitErr = errors.New("commit failed")
+	err := suite.consumeClaim(kafkaTrigger, 5, 7)
+	suite.Require().Error(err)
+	suite.Require().True(errors.Is(err, errTransactionAborted))
+	suite.Require().True(errors.Is(err, suite.broker.commitErr))
+
+	// the claim stopped, rather than committing the offset of the next message past the failed one
+	suite.Require().Equal([]int{5}, handlerRuntime.handledOffsets)
+	suite.Require().Empty(suite.broker.committedOffsets)
+
+	// the next session consumes the failed message again
+	suite.broker.commitErr = nil
+	err = suite.consumeClaim(kafkaTrigger, 5, 7)
+	suite.Require().NoError(err)
+
+	suite.Require().Equal([]int{5, 5, 6}, handlerRuntime.handledOffsets)
+	suite.Require().Equal(int64(7), suite.broker.committedOffsets["my-group"])
+}
+
+func (suite *TransactionTestSuite) TestRedeliverMessageOfTransactionNotStarted() {
+	for _, testCase := range []struct {
+		name     string
+		beginErr error
+		response interface{}
+	}{
+		{
+			name:     "beginFailed",
+			beginErr: errors.New("begin failed"),
+		},
+		{
+			name: "invalidOutputRecords",
+			response: nuclio.Response{
+				Headers: map[string]interface{}{
+					OutputTopicHeaderKey: 3,
+				},
+			},
+		},
+	} {
+		suite.Run(testCase.name, func() {
+			suite.SetupTest()
+			suite.broker.beginErr = testCase.beginErr
+			handlerRuntime := &offsetRecordingRuntime{
+				response: testCase.response,
+			}
+
+			// the claim stops at the first message, rather than moving past it
+			err := suite.consumeClaim(suite.createTransactionalTrigger(handlerRuntime), 5, 7)
+			suite.Require().Error(err)
+			suite.Require().True(errors.Is(err, errTransactionAborted))
+			if testCase.beginErr != nil {
+				suite.Require().True(errors.Is(err, testCase.beginErr))
+			}
+			suite.Require().Equal([]int{5}, handlerRuntime.handledOffsets)
+			suite.Require().Empty(suite.broker.committedOffsets)
+		})
+	}
+}
+
+func (suite *TransactionTestSuite) TestUndecodableMessage() {
+	var err error
+
+	handlerRuntime := &offsetRecordingRuntime{}
+	kafkaTrigger := suite.createTransactionalTrigger(handlerRuntime)
+
+	// the messages aren't in the schema registry wire format, so they fail decoding without reaching the registry
+	kafkaTrigger.messageDecoder, err = trigger.NewMessageDecoder(suite.logger, &schemaregistry.Configuration{
+		URL: "http://schema-registry",
+	})
+	suite.Require().NoError(err)
+
+	// the partition stops at the first message, rather than moving past it
+	err = suite.consumeClaim(kafkaTrigger, 5, 7)
+	suite.Require().Error(err)
+	suite.Require().True(errors.Is(err, trigger.ErrMessageDecodingFailed))
+	suite.Require().Empty(handlerRuntime.handledOffsets)
+	suite.Require().Empty(suite.broker.committedOffsets)
+
+	// skipped messages are committed without being handled
+	kafkaTrigger.messageDecoder, err = trigger.NewMessageDecoder(suite.logger, &schemaregistry.Configuration{
+		URL:     "http://schema-registry",
+		OnError: schemaregistry.ErrorModeSkip,
+	})
+	suite.Require().NoError(err)
+
+	err = suite.consumeClaim(kafkaTrigger, 5, 7)
+	suite.Require().NoError(err)
+	suite.Require().Empty(handlerRuntime.handledOffsets)
+	suite.Require().Equal(int64(7), suite.broker.committedOffsets["my-group"])
+}
+
+func (suite *TransactionTestSuite) TestResolveOutputRecordsInvalidTopic() {
+	_, err := resolveOutputRecords(&nuclio.Response{
+		Headers: map[string]interface{}{
+			OutputTopicHeaderKey: 3,
+		},
+	})
+	suite.Require().Error(err)
+}
+
+func (suite *TransactionTestSuite) TestConfiguration() {
+	for _, testCase := range []struct {
+		name                  string
+		attributes            map[string]interface{}
+		explicitAckMode       functionconfig.ExplicitAckMode
+		expectedTransactionID string
+		expectError           bool
+	}{
+		{
+			name:                  "defaultTransactionID",
+			attributes:            map[string]interface{}{},
+			expectedTransactionID: "my-group",
+		},
+		{
+			name: "customTransactionID",
+			attributes: map[string]interface{}{
+				"transaction": map[string]interface{}{"enable": true, "id": "my-pipeline"},
+			},
+			expectedTransactionID: "my-pipeline",
+		},
+		{
+			name:            "explicitAck",
+			attributes:      map[string]interface{}{},
+			explicitAckMode: functionconfig.ExplicitAckModeEnable,
+			expectError:     true,
+		},
+		{
+			name: "ackWindow",
+			attributes: map[string]interface{}{
+				"ackWindowSize": 10,
+			},
+			expectError: true,
+		},
+	} {
+		suite.Run(testCase.name, func() {
+			attributes := map[string]interface{}{
+				"topics":        []string{"input"},
+				"consumerGroup": "my-group",
+				"brokers":       []string{"localhost:9092"},
+				"transaction":   map[string]interface{}{"enable": true},
+			}
+			for key, value := range testCase.attributes {
+				attributes[key] = value
+			}
+
+			triggerConfiguration := &functionconfig.Trigger{
+				Kind:            "kafka-cluster",
+				ExplicitAckMode: testCase.explicitAckMode,
+				Attributes:      attributes,
+			}
+
+			configuration, err := NewConfiguration("test",
+				triggerConfiguration,
+				&runtime.Configuration{
+					Configuration: &processor.Configuration{
+						Config: functionconfig.Config{
+							Meta: functionconfig.Meta{
+								Annotations: map[string]string{
+									"nuclio.io/kafka-worker-allocation-mode": "static",
+								},
+							},
+						},
+					},
+				},
+				suite.logger)
+			if testCase.expectError {
+				suite.Require().Error(err)
+				suite.Require().Contains(errors.Cause(err).Error(), "transactions are enabled")
+				return
+			}
+
+			suite.Require().NoError(err)
+			suite.Require().True(configuration.Transaction.Enable)
+			suite.Require().Equal(testCase.expectedTransactionID, configuration.Transaction.ID)
+		})
+	}
+}
+
+func (suite *TransactionTestSuite) createTransactionalTrigger(handlerRuntime runtime.Runtime) *kafka {
+	workerInstance, err := worker.NewWorker(suite.logger, 0, handlerRuntime)
+	suite.Require().NoError(err)
+
+	workerAllocator, err := worker.NewSingletonWorkerAllocator(suite.logger, workerInstance)
+	suite.Require().NoError(err)
+
+	partitionWorkerAllocator, err := partitionworker.NewPooledWorkerAllocator(suite.logger, workerAllocator)
+	suite.Require().NoError(err)
+
+	configuration := &Configuration{
+		ConsumerGroup: "my-group",
+	}
+	configuration.ExplicitAckMode = functionconfig.ExplicitAckModeDisable
+	configuration.RuntimeConfiguration = &runtime.Configuration{
+		Configuration: &processor.Configuration{},
+	}
+	configuration.Transaction.Enable = true
+	configuration.Transaction.ID = "my-group"
+
+	kafkaTrigger := &kafka{
+		configuration:            configuration,
+		kafkaConfig:              sarama.NewConfig(),
+		partitionWorkerAllocator: partitionWorkerAllocator,
+		backlogTracker:           trigger.NewBacklogTracker(),
+		newSyncProducer: func([]string, *sarama.Config) (sarama.SyncProducer, error) {
+			return suite.broker, nil
+		},
+	}
+
+	kafkaTrigger.AbstractTrigger, err = trigger.NewAbstractTrigger(suite.logger,
+		workerAllocator,
+		&configuration.Configuration,
+		"async",
+		"kafka-cluster",
+		"test",
+		nil)
+	suite.Require().NoError(err)
+
+	return kafkaTrigger
+}
+
+// consumeClaim consumes a partition from the last committed offset (or the given one, if none was committed)
+// up to the high water mark, as a new consumer session would
+func (suite *TransactionTestSuite) consumeClaim(kafkaTrigger *kafka, initialOffset int64, highWaterMark int64) error {
+	offset := initialOffset
+	if committedOffset, found := suite.broker.committedOffsets["my-group"]; found {
+		offset = committedOffset
+	}
+
+	messages := make(chan *sarama.ConsumerMessage, highWaterMark)
+	for ; offset < highWaterMark; offset++ {
+		messages <- &sarama.ConsumerMessage{
+			Topic:  "input",
+			Offset: offset,
+			Value:  []byte("value"),
+		}
+	}
+	close(messages)
+
+	return kafkaTrigger.ConsumeClaim(&sessionStandIn{}, &claimStandIn{messages: messages})
+}
+
+func TestTransactionTestSuite(t *testing.T) {
+	suite.Run(t, new(TransactionTestSuite))
+}
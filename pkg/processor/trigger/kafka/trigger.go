@@ -32,6 +32,7 @@ import (
 	"github.com/nuclio/nuclio/pkg/processor/trigger/kafka/scram"
 	"github.com/nuclio/nuclio/pkg/processor/trigger/kafka/tokenprovider/oauth"
 	"github.com/nuclio/nuclio/pkg/processor/util/partitionworker"
+	"github.com/nuclio/nuclio/pkg/processor/util/schemaregistry"
 	"github.com/nuclio/nuclio/pkg/processor/worker"
 
 	"github.com/Shopify/sarama"
@@ -56,7 +57,12 @@ type kafka struct {
 	shutdownSignal           chan struct{}
 	stopConsumptionChan      chan struct{}
 	partitionWorkerAllocator partitionworker.Allocator
+	messageDecoder           *trigger.MessageDecoder
+	backlogTracker           *trigger.BacklogTracker
 	ctx                      context.Context
+
+	// creates the producers of transactions, replaced in tests
+	newSyncProducer func([]string, *sarama.Config) (sarama.SyncProducer, error)
 }
 
 func newTrigger(parentLogger logger.Logger,
@@ -75,6 +81,8 @@ func newTrigger(parentLogger logger.Logger,
 	newTrigger := &kafka{
 		configuration:       configuration,
 		stopConsumptionChan: make(chan struct{}, 1),
+		backlogTracker:      trigger.NewBacklogTracker(),
+		newSyncProducer:     sarama.NewSyncProducer,
 	}
 
 	newTrigger.AbstractTrigger, err = trigger.NewAbstractTrigger(loggerInstance,
@@ -112,6 +120,11 @@ func newTrigger(parentLogger logger.Logger,
 		return nil, errors.Wrap(err, "Failed to create configuration")
 	}
 
+	newTrigger.messageDecoder, err = trigger.NewMessageDecoder(newTrigger.Logger, configuration.SchemaRegistry)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create message decoder")
+	}
+
 	return newTrigger, nil
 }
 
@@ -153,6 +166,13 @@ func (k *kafka) Stop(force bool) (functionconfig.Checkpoint, error) {
 	if err := k.consumerGroup.Close(); err != nil {
 		return nil, errors.Wrap(err, "Failed to close consumer")
 	}
+
+	if k.messageDecoder != nil {
+		if err := k.messageDecoder.Close(); err != nil {
+			k.Logger.WarnWith("Failed to close message decoder", "err", err.Error())
+		}
+	}
+
 	return nil, nil
 }
 
@@ -202,8 +222,27 @@ func (k *kafka) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.C
 	submittedEventChan := make(chan *submittedEvent)
 	explicitAckControlMessageChan := make(chan *controlcommunication.ControlMessage)
 
+	// when transactions are enabled, offsets are committed by a producer dedicated to this claim
+	var transactionalProducerInstance *transactionalProducer
+	if k.configuration.Transaction.Enable {
+		var err error
+
+		transactionalProducerInstance, err = k.newTransactionalProducer(claim)
+		if err != nil {
+			return errors.Wrap(err, "Failed to create transactional producer")
+		}
+
+		defer func() {
+			if err := transactionalProducerInstance.close(); err != nil {
+				k.Logger.WarnWith("Failed to close transactional producer",
+					"partition", claim.Partition(),
+					"err", err.Error())
+			}
+		}()
+	}
+
 	// submit the events in a goroutine so that we can unblock immediately
-	go k.eventSubmitter(claim, submittedEventChan)
+	go k.eventSubmitter(claim, submittedEventChan, transactionalProducerInstance)
 
 	ackWindowSize := int64(k.configuration.ackWindowSize)
 
@@ -221,6 +260,9 @@ func (k *kafka) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.C
 		"partition", claim.Partition(),
 		"ackWindowSize", ackWindowSize)
 
+	backlogPartitionID := fmt.Sprintf("%s/%d", claim.Topic(), claim.Partition())
+	defer k.backlogTracker.Remove(backlogPartitionID)
+
 	// the exit condition is that (a) the Messages() channel was closed and (b) we got a signal telling us
 	// to stop consumption
 	for message := range claim.Messages() {
@@ -241,6 +283,12 @@ func (k *kafka) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.C
 		submittedEventInstance.event.kafkaMessage = message
 		submittedEventInstance.worker = workerInstance
 
+		// the high water mark is the offset of the next message to be produced, so this message is the
+		// oldest not yet processed
+		k.backlogTracker.Update(backlogPartitionID,
+			k.getPartitionLag(claim, message.Offset),
+			message.Timestamp)
+
 		// handle in the goroutine so we don't block
 		submittedEventChan <- &submittedEventInstance
 
@@ -248,8 +296,26 @@ func (k *kafka) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.C
 		select {
 		case err := <-submittedEventInstance.done:
 
-			// we successfully submitted the message to the handler. mark it
-			if err == nil {
+			// the offset of the message wasn't committed, and committing the offset of the next one would skip it.
+			// stop the claim, so that the session is re-created and the message is consumed again from the
+			// last committed offset
+			switch {
+			case errors.Is(err, errTransactionAborted):
+				submitError = errors.Wrapf(err, "Failed to commit offset %d of partition %d",
+					message.Offset,
+					message.Partition)
+
+			// the partition doesn't progress past a message that can't be decoded (e.g. while the schema
+			// registry is unavailable)
+			case errors.Is(err, trigger.ErrMessageDecodingFailed):
+				submitError = errors.Wrapf(err, "Failed to decode offset %d of partition %d",
+					message.Offset,
+					message.Partition)
+			}
+
+			// we successfully submitted the message to the handler. mark it, unless it was already
+			// committed as part of a transaction
+			if err == nil && transactionalProducerInstance == nil {
 				session.MarkOffset(
 					message.Topic,
 					message.Partition,
@@ -297,6 +363,12 @@ func (k *kafka) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.C
 		if err := k.partitionWorkerAllocator.ReleaseWorker(cookie, workerInstance); err != nil {
 			return errors.Wrap(err, "Failed to release worker")
 		}
+
+		if submitError != nil {
+			break
+		}
+
+		k.backlogTracker.Update(backlogPartitionID, k.getPartitionLag(claim, message.Offset+1), time.Time{})
 	}
 
 	k.Logger.DebugWith("Claim consumption stopped", "partition", claim.Partition())
@@ -308,20 +380,42 @@ func (k *kafka) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.C
 	return submitError
 }
 
-func (k *kafka) eventSubmitter(claim sarama.ConsumerGroupClaim, submittedEventChan chan *submittedEvent) {
+// GetBacklog returns the lag of the claimed partitions
+func (k *kafka) GetBacklog() (*trigger.Backlog, error) {
+	return k.backlogTracker.GetBacklog()
+}
+
+func (k *kafka) getPartitionLag(claim sarama.ConsumerGroupClaim, offset int64) uint64 {
+	lag := claim.HighWaterMarkOffset() - offset
+	if lag < 0 {
+		return 0
+	}
+
+	return uint64(lag)
+}
+
+func (k *kafka) eventSubmitter(claim sarama.ConsumerGroupClaim,
+	submittedEventChan chan *submittedEvent,
+	transactionalProducerInstance *transactionalProducer) {
 	k.Logger.DebugWith("Event submitter started",
 		"topic", claim.Topic(),
 		"partition", claim.Partition())
 
 	// while there are events to submit, submit them to the given worker
 	for submittedEvent := range submittedEventChan {
-
-		// submit the event to the worker
-		response, processErr := k.SubmitEventToWorker(nil, submittedEvent.worker, &submittedEvent.event) // nolint: errcheck
-		if processErr != nil {
-			k.Logger.DebugWith("Process error",
-				"partition", submittedEvent.event.kafkaMessage.Partition,
-				"err", processErr)
+		var response interface{}
+
+		// decode the message if needed. messages that fail decoding may not reach the handler
+		handleMessage, processErr := k.decodeMessage(submittedEvent.event.kafkaMessage)
+		if handleMessage {
+
+			// submit the event to the worker
+			response, processErr = k.SubmitEventToWorker(nil, submittedEvent.worker, &submittedEvent.event) // nolint: errcheck
+			if processErr != nil {
+				k.Logger.DebugWith("Process error",
+					"partition", submittedEvent.event.kafkaMessage.Partition,
+					"err", processErr)
+			}
 		}
 
 		switch k.configuration.ExplicitAckMode {
@@ -336,6 +430,11 @@ func (k *kafka) eventSubmitter(claim sarama.ConsumerGroupClaim, submittedEventCh
 
 		case functionconfig.ExplicitAckModeDisable:
 
+			// produce the output records and commit the offset atomically
+			if processErr == nil && transactionalProducerInstance != nil {
+				processErr = k.commitTransaction(transactionalProducerInstance, response, submittedEvent)
+			}
+
 			// indicate that we're done
 			submittedEvent.done <- processErr
 
@@ -351,6 +450,31 @@ func (k *kafka) eventSubmitter(claim sarama.ConsumerGroupClaim, submittedEventCh
 		"partition", claim.Partition())
 }
 
+// decodeMessage decodes the message value in place, and returns whether the message should be handled.
+// messages that should not be handled are committed, unless an error is returned
+func (k *kafka) decodeMessage(message *sarama.ConsumerMessage) (bool, error) {
+	if k.messageDecoder == nil {
+		return true, nil
+	}
+
+	decodedValue, handleMessage, err := k.messageDecoder.Decode(message.Value,
+		func(decodingError string) {
+			message.Headers = append(message.Headers, &sarama.RecordHeader{
+				Key:   []byte(schemaregistry.ErrorHeaderKey),
+				Value: []byte(decodingError),
+			})
+		},
+		"partition", message.Partition,
+		"offset", message.Offset)
+	if err != nil {
+		k.UpdateStatistics(false)
+		return false, err
+	}
+
+	message.Value = decodedValue
+	return handleMessage, nil
+}
+
 func (k *kafka) cancelEventHandling(workerInstance *worker.Worker,
 	claim sarama.ConsumerGroupClaim) error {
 	if workerInstance.SupportsRestart() {
@@ -383,6 +507,17 @@ func (k *kafka) newKafkaConfig() (*sarama.Config, error) {
 	config.Consumer.MaxProcessingTime = k.configuration.maxProcessingTime
 	config.ChannelBufferSize = k.configuration.ChannelBufferSize
 
+	// configure an idempotent, transactional producer if applicable. the transactional id itself
+	// is set per claim when the producer is created
+	if k.configuration.Transaction.Enable {
+		config.Consumer.IsolationLevel = sarama.ReadCommitted
+		config.Producer.Idempotent = true
+		config.Producer.RequiredAcks = sarama.WaitForAll
+		config.Producer.Return.Successes = true
+		config.Producer.Transaction.Timeout = k.configuration.transactionTimeout
+		config.Net.MaxOpenRequests = 1
+	}
+
 	// configure TLS if applicable
 	config.Net.TLS.Enable = k.configuration.CACert != "" || k.configuration.TLS.Enable
 	if config.Net.TLS.Enable {
@@ -490,6 +625,48 @@ func (k *kafka) newConsumerGroup() (sarama.ConsumerGroup, error) {
 	return consumerGroup, nil
 }
 
+func (k *kafka) newTransactionalProducer(claim sarama.ConsumerGroupClaim) (*transactionalProducer, error) {
+
+	// transactional ids are unique per consumed partition, so that a producer left behind by
+	// a previous owner of the partition is fenced by the broker
+	producerConfig := *k.kafkaConfig
+	producerConfig.Producer.Transaction.ID = fmt.Sprintf("%s-%s-%d",
+		k.configuration.Transaction.ID,
+		claim.Topic(),
+		claim.Partition())
+
+	producer, err := k.newSyncProducer(k.configuration.brokers, &producerConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create producer")
+	}
+
+	k.Logger.DebugWith("Transactional producer created",
+		"transactionalID", producerConfig.Producer.Transaction.ID,
+		"partition", claim.Partition())
+
+	return newTransactionalProducer(k.Logger, producer, k.configuration.ConsumerGroup), nil
+}
+
+func (k *kafka) commitTransaction(transactionalProducerInstance *transactionalProducer,
+	response interface{},
+	submittedEvent *submittedEvent) error {
+	// committing the offset alone would skip the message without the output it asked for
+	outputRecords, err := resolveOutputRecords(response)
+	if err != nil {
+		return newTransactionAbortedError(errors.Wrap(err, "Failed to resolve output records"))
+	}
+
+	if err := transactionalProducerInstance.commit(submittedEvent.event.kafkaMessage, outputRecords); err != nil {
+		k.Logger.WarnWith("Failed to commit transaction",
+			"partition", submittedEvent.event.kafkaMessage.Partition,
+			"offset", submittedEvent.event.kafkaMessage.Offset,
+			"err", err.Error())
+		return err
+	}
+
+	return nil
+}
+
 func (k *kafka) createPartitionWorkerAllocator(session sarama.ConsumerGroupSession) (partitionworker.Allocator, error) {
 	switch k.configuration.WorkerAllocationMode {
 	case partitionworker.AllocationModePool:
@@ -25,6 +25,7 @@ import (
 	"github.com/nuclio/nuclio/pkg/processor/runtime"
 	"github.com/nuclio/nuclio/pkg/processor/trigger"
 	"github.com/nuclio/nuclio/pkg/processor/util/partitionworker"
+	"github.com/nuclio/nuclio/pkg/processor/util/schemaregistry"
 
 	"github.com/Shopify/sarama"
 	"github.com/mitchellh/mapstructure"
@@ -62,6 +63,14 @@ type Configuration struct {
 		MinimumVersion     string
 	}
 
+	// when enabled, output records produced by the handler and the consumer offset are
+	// committed in a single kafka transaction (exactly-once consume-transform-produce)
+	Transaction struct {
+		Enable  bool
+		ID      string
+		Timeout string
+	}
+
 	SessionTimeout                string
 	HeartbeatInterval             string
 	MaxProcessingTime             string
@@ -82,6 +91,7 @@ type Configuration struct {
 	LogLevel                      int
 	AckWindowSize                 int
 	Version                       string
+	SchemaRegistry                *schemaregistry.Configuration
 
 	// resolved fields
 	brokers                       []string
@@ -96,6 +106,7 @@ type Configuration struct {
 	maxWaitTime                   time.Duration
 	maxWaitHandlerDuringRebalance time.Duration
 	ackWindowSize                 int
+	transactionTimeout            time.Duration
 }
 
 func NewConfiguration(id string,
@@ -151,6 +162,11 @@ func NewConfiguration(id string,
 		{Key: "nuclio.io/kafka-sasl-oauth-token-url", ValueString: &newConfiguration.SASL.OAuth.TokenURL},
 		{Key: "nuclio.io/kafka-sasl-oauth-scopes", ValueListString: newConfiguration.SASL.OAuth.Scopes},
 
+		// transaction
+		{Key: "nuclio.io/kafka-transaction-enabled", ValueBool: &newConfiguration.Transaction.Enable},
+		{Key: "nuclio.io/kafka-transaction-id", ValueString: &newConfiguration.Transaction.ID},
+		{Key: "nuclio.io/kafka-transaction-timeout", ValueString: &newConfiguration.Transaction.Timeout},
+
 		// window-ack
 		{Key: "nuclio.io/kafka-window-size", ValueInt: &newConfiguration.ackWindowSize},
 
@@ -223,6 +239,10 @@ func NewConfiguration(id string,
 		return nil, errors.New("Consumer group must be set")
 	}
 
+	if err := newConfiguration.validateTransaction(); err != nil {
+		return nil, errors.Wrap(err, "Failed to validate transaction configuration")
+	}
+
 	newConfiguration.initialOffset, err = newConfiguration.resolveInitialOffset(newConfiguration.InitialOffset)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to resolve initial offset")
@@ -287,6 +307,12 @@ func NewConfiguration(id string,
 			Field:   &newConfiguration.maxWaitHandlerDuringRebalance,
 			Default: 5 * time.Second,
 		},
+		{
+			Name:    "transaction timeout",
+			Value:   newConfiguration.Transaction.Timeout,
+			Field:   &newConfiguration.transactionTimeout,
+			Default: 1 * time.Minute,
+		},
 	} {
 		if err = newConfiguration.ParseDurationOrDefault(&durationConfigField); err != nil {
 			return nil, err
@@ -342,6 +368,30 @@ func NewConfiguration(id string,
 	return &newConfiguration, nil
 }
 
+func (c *Configuration) validateTransaction() error {
+	if !c.Transaction.Enable {
+		return nil
+	}
+
+	// offsets are committed by the transaction, the handler cannot take over acking
+	if functionconfig.ExplicitAckEnabled(c.ExplicitAckMode) {
+		return errors.New("Explicit ack mode is not allowed when transactions are enabled")
+	}
+
+	// committing an offset behind the processed message would re-produce its output records
+	if c.ackWindowSize > 0 {
+		return errors.New("Ack window size is not allowed when transactions are enabled")
+	}
+
+	// transactional ids must be stable across restarts so that the broker can fence zombie producers.
+	// a per-partition suffix is added when the producers are created
+	if c.Transaction.ID == "" {
+		c.Transaction.ID = c.ConsumerGroup
+	}
+
+	return nil
+}
+
 func (c *Configuration) resolveInitialOffset(initialOffset string) (int64, error) {
 	if initialOffset == "" {
 		return sarama.OffsetNewest, nil
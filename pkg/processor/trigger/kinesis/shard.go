@@ -19,6 +19,7 @@ package kinesis
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/nuclio/nuclio/pkg/processor/worker"
@@ -35,6 +36,10 @@ type shard struct {
 	kinesisTrigger *kinesis
 	shardID        string
 	worker         *worker.Worker
+
+	// the arrival time of the oldest record not yet processed, zero if the shard is caught up
+	oldestUnprocessedTime     time.Time
+	oldestUnprocessedTimeLock sync.Mutex
 }
 
 func newShard(parentLogger logger.Logger, kinesisTrigger *kinesis, shardID string) (*shard, error) {
@@ -83,6 +88,9 @@ func (s *shard) readFromShard() error {
 			continue
 		}
 
+		// the records following this batch are as old as the batch is behind the tip of the shard
+		behindLatestTime := s.getBehindLatestTime(getRecordsResponse)
+
 		// if we got records, handle them
 		if len(getRecordsResponse.Records) > 0 {
 			for _, record := range getRecordsResponse.Records {
@@ -90,20 +98,63 @@ func (s *shard) readFromShard() error {
 					body: record.Data,
 				}
 
+				recordArrivalTime := s.getRecordArrivalTime(record)
+				if recordArrivalTime.IsZero() {
+					recordArrivalTime = behindLatestTime
+				}
+
+				s.setOldestUnprocessedTime(recordArrivalTime)
+
 				// process the event, don't really do anything with response
 				s.kinesisTrigger.SubmitEventToWorker(nil, s.worker, &event) // nolint: errcheck
 			}
 
+			s.setOldestUnprocessedTime(behindLatestTime)
+
 			// save last sequence number in the batch. we might need to create a shard iterator at this
 			// sequence number
 			lastRecordSequenceNumber = getRecordsResponse.Records[len(getRecordsResponse.Records)-1].SequenceNumber
 
 		} else {
+			s.setOldestUnprocessedTime(behindLatestTime)
 			time.Sleep(s.kinesisTrigger.configuration.pollingPeriodDuration)
 		}
 	}
 }
 
+func (s *shard) getOldestUnprocessedTime() time.Time {
+	s.oldestUnprocessedTimeLock.Lock()
+	defer s.oldestUnprocessedTimeLock.Unlock()
+
+	return s.oldestUnprocessedTime
+}
+
+func (s *shard) setOldestUnprocessedTime(oldestUnprocessedTime time.Time) {
+	s.oldestUnprocessedTimeLock.Lock()
+	defer s.oldestUnprocessedTimeLock.Unlock()
+
+	s.oldestUnprocessedTime = oldestUnprocessedTime
+}
+
+// getBehindLatestTime returns the arrival time of the records following the ones returned, or zero if
+// the response reached the tip of the shard
+func (s *shard) getBehindLatestTime(getRecordsResponse *kinesisclient.GetRecordsResp) time.Time {
+	if getRecordsResponse.MillisBehindLatest <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(-time.Duration(getRecordsResponse.MillisBehindLatest) * time.Millisecond)
+}
+
+func (s *shard) getRecordArrivalTime(record kinesisclient.GetRecordsRecords) time.Time {
+	if record.ApproximateArrivalTimestamp == 0 {
+		return time.Time{}
+	}
+
+	// seconds since epoch, with millisecond precision
+	return time.UnixMilli(int64(record.ApproximateArrivalTimestamp * 1000))
+}
+
 func (s *shard) getNextRecords(getRecordArgs *kinesisclient.RequestArgs,
 	getRecordsResponse *kinesisclient.GetRecordsResp,
 	lastRecordSequenceNumber string) (*kinesisclient.GetRecordsResp, error) {
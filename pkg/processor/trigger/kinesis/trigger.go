@@ -17,6 +17,8 @@ limitations under the License.
 package kinesis
 
 import (
+	"time"
+
 	"github.com/nuclio/nuclio/pkg/common"
 	"github.com/nuclio/nuclio/pkg/functionconfig"
 	"github.com/nuclio/nuclio/pkg/processor/trigger"
@@ -113,3 +115,23 @@ func (k *kinesis) Stop(force bool) (functionconfig.Checkpoint, error) {
 func (k *kinesis) GetConfig() map[string]interface{} {
 	return common.StructureToMap(k.configuration)
 }
+
+// GetBacklog returns how far behind the tip of its shards the trigger is. kinesis only reports this in time,
+// so the number of messages is unknown
+func (k *kinesis) GetBacklog() (*trigger.Backlog, error) {
+	backlog := trigger.Backlog{}
+	now := time.Now()
+
+	for _, shardInstance := range k.shards {
+		oldestUnprocessedTime := shardInstance.getOldestUnprocessedTime()
+		if oldestUnprocessedTime.IsZero() {
+			continue
+		}
+
+		if age := now.Sub(oldestUnprocessedTime); age > backlog.OldestUnprocessedAge {
+			backlog.OldestUnprocessedAge = age
+		}
+	}
+
+	return &backlog, nil
+}
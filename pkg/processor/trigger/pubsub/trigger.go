@@ -25,6 +25,7 @@ import (
 	"github.com/nuclio/nuclio/pkg/common"
 	"github.com/nuclio/nuclio/pkg/functionconfig"
 	"github.com/nuclio/nuclio/pkg/processor/trigger"
+	"github.com/nuclio/nuclio/pkg/processor/util/schemaregistry"
 	"github.com/nuclio/nuclio/pkg/processor/worker"
 
 	pubsubClient "cloud.google.com/go/pubsub"
@@ -35,9 +36,10 @@ import (
 
 type pubsub struct {
 	trigger.AbstractTrigger
-	configuration *Configuration
-	stop          chan bool
-	client        *pubsubClient.Client
+	configuration  *Configuration
+	stop           chan bool
+	client         *pubsubClient.Client
+	messageDecoder *trigger.MessageDecoder
 }
 
 func newTrigger(parentLogger logger.Logger,
@@ -62,6 +64,11 @@ func newTrigger(parentLogger logger.Logger,
 	}
 	newTrigger.AbstractTrigger.Trigger = newTrigger
 
+	newTrigger.messageDecoder, err = trigger.NewMessageDecoder(newTrigger.Logger, configuration.SchemaRegistry)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create message decoder")
+	}
+
 	return newTrigger, nil
 }
 
@@ -102,6 +109,11 @@ func (p *pubsub) Start(checkpoint functionconfig.Checkpoint) error {
 }
 
 func (p *pubsub) Stop(force bool) (functionconfig.Checkpoint, error) {
+	if p.messageDecoder != nil {
+		if err := p.messageDecoder.Close(); err != nil {
+			p.Logger.WarnWith("Failed to close message decoder", "err", err.Error())
+		}
+	}
 
 	// TODO:
 	// err := p.client.Close()
@@ -152,6 +164,18 @@ func (p *pubsub) receiveFromSubscription(subscriptionConfig *Subscription) error
 		// set the message
 		event.message = message
 
+		// decode the message if needed. messages that fail decoding may not reach the handler
+		if handleMessage, err := p.decodeMessage(message); !handleMessage {
+			if err != nil {
+				message.Nack()
+			} else {
+				message.Ack()
+			}
+
+			eventsChan <- event
+			return
+		}
+
 		// process the event, don't really do anything with response
 		_, submitError, processError := p.AllocateWorkerAndSubmitEvent(event, p.Logger, 10*time.Second)
 		if submitError != nil {
@@ -182,6 +206,30 @@ func (p *pubsub) receiveFromSubscription(subscriptionConfig *Subscription) error
 	return nil
 }
 
+// decodeMessage decodes the message data in place, and returns whether the message should be handled.
+// messages that should not be handled are acked, unless an error is returned
+func (p *pubsub) decodeMessage(message *pubsubClient.Message) (bool, error) {
+	if p.messageDecoder == nil {
+		return true, nil
+	}
+
+	decodedData, handleMessage, err := p.messageDecoder.Decode(message.Data,
+		func(decodingError string) {
+			if message.Attributes == nil {
+				message.Attributes = map[string]string{}
+			}
+			message.Attributes[schemaregistry.ErrorHeaderKey] = decodingError
+		},
+		"messageID", message.ID)
+	if err != nil {
+		p.UpdateStatistics(false)
+		return false, err
+	}
+
+	message.Data = decodedData
+	return handleMessage, nil
+}
+
 func (p *pubsub) getSubscriptionID(subscriptionConfig *Subscription) string {
 	subscriptionID := subscriptionConfig.IDPrefix + subscriptionConfig.Topic
 
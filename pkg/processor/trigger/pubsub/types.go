@@ -20,6 +20,7 @@ import (
 	"github.com/nuclio/nuclio/pkg/functionconfig"
 	"github.com/nuclio/nuclio/pkg/processor/runtime"
 	"github.com/nuclio/nuclio/pkg/processor/trigger"
+	"github.com/nuclio/nuclio/pkg/processor/util/schemaregistry"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/nuclio/errors"
@@ -41,11 +42,12 @@ type Subscription struct {
 
 type Configuration struct {
 	trigger.Configuration
-	Subscriptions []Subscription
-	ProjectID     string
-	AckDeadline   string
-	Credentials   trigger.Secret
-	NoCredentials bool
+	Subscriptions  []Subscription
+	ProjectID      string
+	AckDeadline    string
+	Credentials    trigger.Secret
+	NoCredentials  bool
+	SchemaRegistry *schemaregistry.Configuration
 }
 
 func NewConfiguration(id string,
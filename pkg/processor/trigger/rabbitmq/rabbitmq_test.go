@@ -77,6 +77,20 @@ func (suite *TestSuite) TestSetEmptyParametersSetsEmptyQueueName() {
 	suite.EqualValues("nuclio-mynamespace-myname", suite.trigger.configuration.QueueName)
 }
 
+func (suite *TestSuite) TestGetBacklogNotConnected() {
+	_, err := suite.trigger.GetBacklog()
+	suite.Require().Error(err)
+}
+
+func (suite *TestSuite) TestGetConsumerShare() {
+	suite.Require().Equal(uint64(10), *suite.trigger.getConsumerShare(30, 3))
+	suite.Require().Equal(uint64(1), *suite.trigger.getConsumerShare(1, 3))
+	suite.Require().Equal(uint64(0), *suite.trigger.getConsumerShare(0, 3))
+
+	// queues aren't inspected before consuming, but don't divide by zero
+	suite.Require().Equal(uint64(5), *suite.trigger.getConsumerShare(5, 0))
+}
+
 func (suite *TestSuite) TestSetEmptyParametersMakesNoChange() {
 	suite.trigger.configuration.Topics = []string{}
 
@@ -20,11 +20,14 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/nuclio/nuclio/pkg/common"
 	"github.com/nuclio/nuclio/pkg/functionconfig"
 	"github.com/nuclio/nuclio/pkg/processor/trigger"
+	"github.com/nuclio/nuclio/pkg/processor/util/schemaregistry"
 	"github.com/nuclio/nuclio/pkg/processor/worker"
 
 	"github.com/nuclio/errors"
@@ -39,11 +42,16 @@ type rabbitMq struct {
 	configuration              *Configuration
 	consumerName               string
 	brokerConn                 *amqp.Connection
+	brokerConnLock             sync.RWMutex
 	brokerChannel              *amqp.Channel
 	brokerQueue                amqp.Queue
 	brokerInputMessagesChannel <-chan amqp.Delivery
 	stopChan                   chan struct{}
 	connectionErrorChan        chan *amqp.Error
+	messageDecoder             *trigger.MessageDecoder
+
+	// the publish time of the message being processed, accessed atomically as unix nanoseconds
+	processedMessageTimestamp int64
 }
 
 func newTrigger(parentLogger logger.Logger,
@@ -68,6 +76,11 @@ func newTrigger(parentLogger logger.Logger,
 	}
 	newTrigger.AbstractTrigger.Trigger = &newTrigger
 
+	newTrigger.messageDecoder, err = trigger.NewMessageDecoder(newTrigger.Logger, configuration.SchemaRegistry)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create message decoder")
+	}
+
 	return &newTrigger, nil
 }
 
@@ -109,6 +122,12 @@ func (rmq *rabbitMq) Stop(force bool) (functionconfig.Checkpoint, error) {
 		rmq.Logger.WarnWith("Failed to close broker channel", "err", err.Error())
 	}
 
+	if rmq.messageDecoder != nil {
+		if err := rmq.messageDecoder.Close(); err != nil {
+			rmq.Logger.WarnWith("Failed to close message decoder", "err", err.Error())
+		}
+	}
+
 	close(rmq.connectionErrorChan)
 	return nil, nil
 }
@@ -215,11 +234,13 @@ func (rmq *rabbitMq) reconnect() error {
 func (rmq *rabbitMq) connect() error {
 	var err error
 
-	rmq.brokerConn, err = amqp.DialConfig(rmq.configuration.URL, *rmq.getConnectionConfig())
+	brokerConn, err := amqp.DialConfig(rmq.configuration.URL, *rmq.getConnectionConfig())
 	if err != nil {
 		return errors.Wrap(err, "Failed to create connection to broker")
 	}
 
+	rmq.setBrokerConn(brokerConn)
+
 	rmq.connectionErrorChan = make(chan *amqp.Error)
 	rmq.brokerConn.NotifyClose(rmq.connectionErrorChan)
 	rmq.Logger.DebugWith("Connected to broker", "brokerUrl", rmq.configuration.URL)
@@ -316,6 +337,7 @@ func (rmq *rabbitMq) handleConnectionError(handleErr *amqp.Error) error {
 	if err := rmq.brokerConn.Close(); err != nil {
 		rmq.Logger.WarnWith("Failed to close broker connection", "err", err.Error())
 	}
+	rmq.setBrokerConn(nil)
 	if err := rmq.brokerChannel.Close(); err != nil {
 		rmq.Logger.WarnWith("Failed to close broker channel", "err", err.Error())
 	}
@@ -333,6 +355,25 @@ func (rmq *rabbitMq) handleConnectionError(handleErr *amqp.Error) error {
 
 func (rmq *rabbitMq) processMessage(message *amqp.Delivery) {
 
+	// decode the message if needed. messages that fail decoding may not reach the handler
+	if handleMessage, err := rmq.decodeMessage(message); !handleMessage {
+		if err != nil {
+
+			// reject without requeueing, so that the message is dead-lettered if the queue is set to
+			message.Nack(false, false) // nolint: errcheck
+		} else {
+			message.Ack(false) // nolint: errcheck
+		}
+
+		return
+	}
+
+	// messages published without a timestamp have no age
+	if !message.Timestamp.IsZero() {
+		atomic.StoreInt64(&rmq.processedMessageTimestamp, message.Timestamp.UnixNano())
+		defer atomic.StoreInt64(&rmq.processedMessageTimestamp, 0)
+	}
+
 	// bind to delivery
 
 	// TODO: when moving to multiworkers - need to create event per message
@@ -350,6 +391,95 @@ func (rmq *rabbitMq) processMessage(message *amqp.Delivery) {
 	}
 }
 
+// GetBacklog returns this replica's share of the messages ready in the queue and the age of the message being
+// processed. every replica consumes the same queue, so reporting the whole queue from each of them would keep the
+// average backlog of the replicas at the queue depth however many there are
+func (rmq *rabbitMq) GetBacklog() (*trigger.Backlog, error) {
+	backlog := trigger.Backlog{}
+
+	if processedMessageTimestamp := atomic.LoadInt64(&rmq.processedMessageTimestamp); processedMessageTimestamp != 0 {
+		backlog.OldestUnprocessedAge = time.Since(time.Unix(0, processedMessageTimestamp))
+	}
+
+	// the connection is replaced while reconnecting
+	rmq.brokerConnLock.RLock()
+	defer rmq.brokerConnLock.RUnlock()
+
+	if rmq.brokerConn == nil || rmq.brokerConn.IsClosed() {
+		return nil, errors.New("Not connected to broker")
+	}
+
+	// a failed passive declaration closes the channel, so don't use the consuming one
+	inspectionChannel, err := rmq.brokerConn.Channel()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create channel")
+	}
+	defer inspectionChannel.Close() // nolint: errcheck
+
+	queue, err := inspectionChannel.QueueDeclarePassive(rmq.configuration.QueueName,
+		false,
+		false,
+		false,
+		false,
+		nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to inspect queue")
+	}
+
+	backlog.Messages = rmq.getConsumerShare(queue.Messages, queue.Consumers)
+
+	return &backlog, nil
+}
+
+// getConsumerShare returns the messages a consumer of the queue is left with when they're split evenly between its
+// consumers, rounded up so that a few pending messages still count
+func (rmq *rabbitMq) getConsumerShare(messages int, consumers int) *uint64 {
+	if consumers < 1 {
+		consumers = 1
+	}
+
+	consumerShare := uint64((messages + consumers - 1) / consumers)
+	return &consumerShare
+}
+
+func (rmq *rabbitMq) setBrokerConn(brokerConn *amqp.Connection) {
+	rmq.brokerConnLock.Lock()
+	defer rmq.brokerConnLock.Unlock()
+
+	rmq.brokerConn = brokerConn
+}
+
+// decodeMessage decodes the message body in place, and returns whether the message should be handled.
+// messages that should not be handled are acked, unless an error is returned
+func (rmq *rabbitMq) decodeMessage(message *amqp.Delivery) (bool, error) {
+	if rmq.messageDecoder == nil {
+		return true, nil
+	}
+
+	passedThrough := false
+	decodedBody, handleMessage, err := rmq.messageDecoder.Decode(message.Body,
+		func(decodingError string) {
+			passedThrough = true
+			if message.Headers == nil {
+				message.Headers = amqp.Table{}
+			}
+			message.Headers[schemaregistry.ErrorHeaderKey] = decodingError
+		},
+		"messageID", message.MessageId)
+	if err != nil {
+		rmq.UpdateStatistics(false)
+		return false, err
+	}
+
+	// decoded messages are JSON, regardless of the content type they were produced with
+	if handleMessage && !passedThrough {
+		message.ContentType = "application/json"
+	}
+
+	message.Body = decodedBody
+	return handleMessage, nil
+}
+
 func (rmq *rabbitMq) getConsumerName() (string, error) {
 	var consumerName string
 	var err error
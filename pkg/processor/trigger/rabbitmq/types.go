@@ -22,6 +22,7 @@ import (
 	"github.com/nuclio/nuclio/pkg/functionconfig"
 	"github.com/nuclio/nuclio/pkg/processor/runtime"
 	"github.com/nuclio/nuclio/pkg/processor/trigger"
+	"github.com/nuclio/nuclio/pkg/processor/util/schemaregistry"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/nuclio/errors"
@@ -34,6 +35,7 @@ type Configuration struct {
 	Topics            []string
 	ReconnectDuration string
 	ReconnectInterval string
+	SchemaRegistry    *schemaregistry.Configuration
 
 	reconnectDuration time.Duration
 	reconnectInterval time.Duration
@@ -17,6 +17,11 @@ limitations under the License.
 package v3iostream
 
 import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/nuclio/nuclio/pkg/common"
@@ -51,6 +56,11 @@ type v3iostream struct {
 	stopConsumptionChan       chan struct{}
 	partitionWorkerAllocator  partitionworker.Allocator
 	topic                     string
+	v3ioContainer             v3io.Container
+
+	// the sequence number of the last record handled from each claimed shard, zero until one is handled
+	handledSequenceNumbers     map[int]uint64
+	handledSequenceNumbersLock sync.Mutex
 }
 
 func newTrigger(parentLogger logger.Logger,
@@ -62,9 +72,10 @@ func newTrigger(parentLogger logger.Logger,
 	loggerInstance := parentLogger.GetChild(configuration.ID)
 
 	newTrigger := &v3iostream{
-		configuration:       configuration,
-		stopConsumptionChan: make(chan struct{}, 1),
-		topic:               "v3io", // v3io doesn't support topics, use constant (never goes to v3io)
+		configuration:          configuration,
+		stopConsumptionChan:    make(chan struct{}, 1),
+		topic:                  "v3io", // v3io doesn't support topics, use constant (never goes to v3io)
+		handledSequenceNumbers: map[int]uint64{},
 	}
 
 	newTrigger.AbstractTrigger, err = trigger.NewAbstractTrigger(loggerInstance,
@@ -185,6 +196,9 @@ func (vs *v3iostream) ConsumeClaim(session streamconsumergroup.Session, claim st
 
 	commitRecordFuncHandler := vs.resolveCommitRecordFuncHandler(session)
 
+	vs.setHandledSequenceNumber(claim.GetShardID(), 0)
+	defer vs.removeHandledSequenceNumber(claim.GetShardID())
+
 	// listen for explicit ack messages if enabled
 	if functionconfig.ExplicitAckEnabled(vs.configuration.ExplicitAckMode) {
 		if err := vs.SubscribeToControlMessageKind(controlcommunication.StreamMessageAckKind, explicitAckControlMessageChan); err != nil {
@@ -220,6 +234,8 @@ func (vs *v3iostream) ConsumeClaim(session streamconsumergroup.Session, claim st
 				commitRecordFuncHandler(record)
 			}
 
+			vs.setHandledSequenceNumber(claim.GetShardID(), record.SequenceNumber)
+
 			// release the worker from whence it came
 			if err := vs.partitionWorkerAllocator.ReleaseWorker(cookie, workerInstance); err != nil {
 				return errors.Wrap(err, "Failed to release worker")
@@ -237,6 +253,31 @@ func (vs *v3iostream) ConsumeClaim(session streamconsumergroup.Session, claim st
 	return submitError
 }
 
+// GetBacklog returns the records between the last record handled from each claimed shard and the shard's tail
+func (vs *v3iostream) GetBacklog() (*trigger.Backlog, error) {
+	vs.handledSequenceNumbersLock.Lock()
+	handledSequenceNumbers := make(map[int]uint64, len(vs.handledSequenceNumbers))
+	for shardID, handledSequenceNumber := range vs.handledSequenceNumbers {
+		handledSequenceNumbers[shardID] = handledSequenceNumber
+	}
+	vs.handledSequenceNumbersLock.Unlock()
+
+	backlog := trigger.Backlog{
+		Messages: new(uint64),
+	}
+
+	for shardID, handledSequenceNumber := range handledSequenceNumbers {
+		lastSequenceNumber, committedSequenceNumber, err := vs.getShardSequenceNumbers(shardID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to get sequence numbers of shard %d", shardID)
+		}
+
+		*backlog.Messages += getShardLag(lastSequenceNumber, committedSequenceNumber, handledSequenceNumber)
+	}
+
+	return &backlog, nil
+}
+
 func (vs *v3iostream) Abort(session streamconsumergroup.Session) error {
 	vs.Logger.Warn("Abort called in trigger", "triggerKind", vs.GetKind(), "triggerName", vs.GetName())
 
@@ -339,6 +380,8 @@ func (vs *v3iostream) newConsumerGroupMember() (streamconsumergroup.Member, erro
 		maxReplicas = *vs.configuration.RuntimeConfiguration.Config.Spec.MaxReplicas
 	}
 
+	vs.v3ioContainer = v3ioContainer
+
 	streamConsumerGroup, err := streamconsumergroup.NewStreamConsumerGroup(vs.Logger,
 		vs.configuration.ConsumerGroup,
 		vs.v3iostreamConfig,
@@ -362,6 +405,57 @@ func (vs *v3iostream) newConsumerGroupMember() (streamconsumergroup.Member, erro
 	return streamConsumerGroupMember, nil
 }
 
+// getShardSequenceNumbers returns the sequence number of the last record in the shard, and the last one the consumer
+// group committed (zero if it didn't commit any yet)
+func (vs *v3iostream) getShardSequenceNumbers(shardID int) (uint64, uint64, error) {
+	committedSequenceNumberAttribute := fmt.Sprintf("__%s_committed_sequence_number", vs.configuration.ConsumerGroup)
+
+	response, err := vs.v3ioContainer.GetItemSync(&v3io.GetItemInput{
+		Path: path.Join(vs.configuration.StreamPath, strconv.Itoa(shardID)),
+		AttributeNames: []string{
+			"__last_sequence_num",
+			committedSequenceNumberAttribute,
+		},
+	})
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "Failed to get shard item")
+	}
+	defer response.Release()
+
+	item := response.Output.(*v3io.GetItemOutput).Item
+
+	lastSequenceNumber, err := item.GetFieldInt("__last_sequence_num")
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "Failed to get last sequence number")
+	}
+
+	committedSequenceNumber, err := item.GetFieldInt(committedSequenceNumberAttribute)
+	if err != nil {
+		if !strings.Contains(err.Error(), "Not found") {
+			return 0, 0, errors.Wrap(err, "Failed to get committed sequence number")
+		}
+
+		// the consumer group didn't commit anything in this shard yet
+		committedSequenceNumber = 0
+	}
+
+	return uint64(lastSequenceNumber), uint64(committedSequenceNumber), nil
+}
+
+func (vs *v3iostream) setHandledSequenceNumber(shardID int, sequenceNumber uint64) {
+	vs.handledSequenceNumbersLock.Lock()
+	defer vs.handledSequenceNumbersLock.Unlock()
+
+	vs.handledSequenceNumbers[shardID] = sequenceNumber
+}
+
+func (vs *v3iostream) removeHandledSequenceNumber(shardID int) {
+	vs.handledSequenceNumbersLock.Lock()
+	defer vs.handledSequenceNumbersLock.Unlock()
+
+	delete(vs.handledSequenceNumbers, shardID)
+}
+
 func (vs *v3iostream) createPartitionWorkerAllocator(session streamconsumergroup.Session) (partitionworker.Allocator, error) {
 	switch vs.configuration.WorkerAllocationMode {
 	case partitionworker.AllocationModePool:
@@ -466,3 +560,17 @@ func (vs *v3iostream) resolveNoAckMessage(response interface{}, submittedEvent *
 
 	return nil
 }
+
+// getShardLag returns the records after the last one handled from the shard, which is the last one the consumer
+// group committed until a record is handled by this replica (commits lag behind handling)
+func getShardLag(lastSequenceNumber uint64, committedSequenceNumber uint64, handledSequenceNumber uint64) uint64 {
+	if committedSequenceNumber > handledSequenceNumber {
+		handledSequenceNumber = committedSequenceNumber
+	}
+
+	if lastSequenceNumber <= handledSequenceNumber {
+		return 0
+	}
+
+	return lastSequenceNumber - handledSequenceNumber
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaregistry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/nuclio/nuclio/pkg/common"
+
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+)
+
+// Client fetches schemas from a confluent compatible schema registry. schemas are immutable once
+// registered, so they are cached for the lifetime of the client
+type Client struct {
+	logger        logger.Logger
+	configuration *Configuration
+	httpClient    *http.Client
+	schemasLock   sync.RWMutex
+	schemas       map[string]*Schema
+}
+
+func NewClient(parentLogger logger.Logger, configuration *Configuration) *Client {
+	return &Client{
+		logger:        parentLogger.GetChild("schema-registry"),
+		configuration: configuration,
+		httpClient: &http.Client{
+			Timeout: configuration.requestTimeout,
+		},
+		schemas: map[string]*Schema{},
+	}
+}
+
+// GetSchemaByID returns the schema registered with the given id
+func (c *Client) GetSchemaByID(id int) (*Schema, error) {
+	return c.getSchema(fmt.Sprintf("id:%d", id), fmt.Sprintf("schemas/ids/%d", id))
+}
+
+// GetSchemaByReference returns the schema a reference points to
+func (c *Client) GetSchemaByReference(reference *SchemaReference) (*Schema, error) {
+	return c.getSchema(fmt.Sprintf("ref:%s:%d", reference.Subject, reference.Version),
+		fmt.Sprintf("subjects/%s/versions/%d", reference.Subject, reference.Version))
+}
+
+func (c *Client) getSchema(cacheKey string, path string) (*Schema, error) {
+	c.schemasLock.RLock()
+	schema, found := c.schemas[cacheKey]
+	c.schemasLock.RUnlock()
+
+	if found {
+		return schema, nil
+	}
+
+	headers := map[string]string{
+		"Accept": "application/vnd.schemaregistry.v1+json",
+	}
+
+	if c.configuration.Username != "" {
+		credentials := c.configuration.Username + ":" + c.configuration.Password
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	}
+
+	responseBody, _, err := common.SendHTTPRequest(c.httpClient,
+		http.MethodGet,
+		fmt.Sprintf("%s/%s", strings.TrimSuffix(c.configuration.URL, "/"), path),
+		nil,
+		headers,
+		nil,
+		http.StatusOK)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to get schema from registry (%s)", path)
+	}
+
+	schema = &Schema{}
+	if err := json.Unmarshal(responseBody, schema); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal schema registry response")
+	}
+
+	// avro is implied when the schema type is omitted
+	if schema.SchemaType == "" {
+		schema.SchemaType = SchemaTypeAvro
+	}
+
+	c.logger.DebugWith("Fetched schema from registry", "path", path, "schemaType", schema.SchemaType)
+
+	c.schemasLock.Lock()
+	c.schemas[cacheKey] = schema
+	c.schemasLock.Unlock()
+
+	return schema, nil
+}
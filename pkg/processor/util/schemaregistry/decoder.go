@@ -0,0 +1,322 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/linkedin/goavro/v2"
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// the confluent wire format is a zero magic byte, followed by a 4 byte big endian schema id
+const (
+	wireFormatMagicByte  = 0
+	wireFormatHeaderSize = 5
+)
+
+// rootProtobufFileName is the name under which a protobuf schema is parsed. references are
+// parsed under their own names, so that imports can be resolved
+const rootProtobufFileName = "nuclio-schema-registry-root.proto"
+
+type payloadDecoder interface {
+	decode(payload []byte) ([]byte, error)
+}
+
+// Decoder decodes messages in the confluent schema registry wire format into JSON
+type Decoder struct {
+	logger        logger.Logger
+	configuration *Configuration
+	client        *Client
+	decodersLock  sync.RWMutex
+	decoders      map[int]payloadDecoder
+}
+
+func NewDecoder(parentLogger logger.Logger, configuration *Configuration) (*Decoder, error) {
+	if err := configuration.Resolve(); err != nil {
+		return nil, errors.Wrap(err, "Failed to resolve schema registry configuration")
+	}
+
+	decoderLogger := parentLogger.GetChild("schema-decoder")
+
+	return &Decoder{
+		logger:        decoderLogger,
+		configuration: configuration,
+		client:        NewClient(decoderLogger, configuration),
+		decoders:      map[int]payloadDecoder{},
+	}, nil
+}
+
+// GetErrorMode returns what the trigger should do with messages that fail decoding
+func (d *Decoder) GetErrorMode() ErrorMode {
+	return d.configuration.OnError
+}
+
+// GetDeadLetter returns where messages that fail decoding are published to in the dead letter error mode
+func (d *Decoder) GetDeadLetter() *functionconfig.OutputBinding {
+	return d.configuration.DeadLetter
+}
+
+// Decode resolves the schema the message was encoded with and returns the message as JSON
+func (d *Decoder) Decode(message []byte) ([]byte, error) {
+	if len(message) < wireFormatHeaderSize || message[0] != wireFormatMagicByte {
+		return nil, errors.New("Message is not in schema registry wire format")
+	}
+
+	schemaID := int(binary.BigEndian.Uint32(message[1:wireFormatHeaderSize]))
+
+	decoder, err := d.getPayloadDecoder(schemaID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to get decoder for schema %d", schemaID)
+	}
+
+	decoded, err := decoder.decode(message[wireFormatHeaderSize:])
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to decode message with schema %d", schemaID)
+	}
+
+	return decoded, nil
+}
+
+func (d *Decoder) getPayloadDecoder(schemaID int) (payloadDecoder, error) {
+	d.decodersLock.RLock()
+	decoder, found := d.decoders[schemaID]
+	d.decodersLock.RUnlock()
+
+	if found {
+		return decoder, nil
+	}
+
+	// the schema is fetched without holding the lock, so that messages of resolved schemas aren't held up
+	// by the registry. concurrent misses of the same schema may create the decoder more than once
+	schema, err := d.client.GetSchemaByID(schemaID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get schema")
+	}
+
+	switch schema.SchemaType {
+	case SchemaTypeAvro:
+		decoder, err = newAvroDecoder(schema)
+	case SchemaTypeProtobuf:
+		decoder, err = newProtobufDecoder(d.client, schema)
+	case SchemaTypeJSONSchema:
+		decoder = &jsonSchemaDecoder{}
+	default:
+		return nil, errors.Errorf("Unsupported schema type: %s", schema.SchemaType)
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to create %s decoder", schema.SchemaType)
+	}
+
+	d.decodersLock.Lock()
+	defer d.decodersLock.Unlock()
+
+	if existingDecoder, found := d.decoders[schemaID]; found {
+		return existingDecoder, nil
+	}
+
+	d.decoders[schemaID] = decoder
+	return decoder, nil
+}
+
+type avroDecoder struct {
+	codec *goavro.Codec
+}
+
+func newAvroDecoder(schema *Schema) (*avroDecoder, error) {
+	codec, err := goavro.NewCodec(schema.Schema)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse avro schema")
+	}
+
+	return &avroDecoder{codec: codec}, nil
+}
+
+func (ad *avroDecoder) decode(payload []byte) ([]byte, error) {
+	native, _, err := ad.codec.NativeFromBinary(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to decode avro payload")
+	}
+
+	return ad.codec.TextualFromNative(nil, native)
+}
+
+type protobufDecoder struct {
+
+	// message descriptors by their path of indexes in the schema (top level and nested messages)
+	messageDescriptors map[string]protoreflect.MessageDescriptor
+}
+
+func newProtobufDecoder(client *Client, schema *Schema) (*protobufDecoder, error) {
+	sources := map[string]string{
+		rootProtobufFileName: schema.Schema,
+	}
+
+	if err := resolveProtobufReferences(client, schema, sources); err != nil {
+		return nil, errors.Wrap(err, "Failed to resolve protobuf references")
+	}
+
+	parser := protoparse.Parser{
+		Accessor: protoparse.FileContentsFromMap(sources),
+	}
+
+	fileDescriptors, err := parser.ParseFiles(rootProtobufFileName)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse protobuf schema")
+	}
+
+	files, err := protodesc.NewFiles(desc.ToFileDescriptorSet(fileDescriptors...))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create protobuf file registry")
+	}
+
+	decoder := &protobufDecoder{
+		messageDescriptors: map[string]protoreflect.MessageDescriptor{},
+	}
+
+	var indexMessages func(prefix string, messages []*desc.MessageDescriptor) error
+	indexMessages = func(prefix string, messages []*desc.MessageDescriptor) error {
+		for messageIndex, message := range messages {
+			path := fmt.Sprintf("%s/%d", prefix, messageIndex)
+
+			descriptor, err := files.FindDescriptorByName(protoreflect.FullName(message.GetFullyQualifiedName()))
+			if err != nil {
+				return errors.Wrapf(err, "Failed to find message %s", message.GetFullyQualifiedName())
+			}
+
+			decoder.messageDescriptors[path] = descriptor.(protoreflect.MessageDescriptor)
+
+			if err := indexMessages(path, message.GetNestedMessageTypes()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := indexMessages("", fileDescriptors[0].GetMessageTypes()); err != nil {
+		return nil, errors.Wrap(err, "Failed to index protobuf messages")
+	}
+
+	return decoder, nil
+}
+
+func (pd *protobufDecoder) decode(payload []byte) ([]byte, error) {
+
+	// the payload is prefixed by the path of message indexes that leads to the message type,
+	// encoded as a zigzag varint array. a zero length array is a shorthand for [0]
+	messageIndexes, payload, err := pd.readMessageIndexes(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read message indexes")
+	}
+
+	path := ""
+	for _, messageIndex := range messageIndexes {
+		path += fmt.Sprintf("/%d", messageIndex)
+	}
+
+	messageDescriptor, found := pd.messageDescriptors[path]
+	if !found {
+		return nil, errors.Errorf("Message indexes %v do not match any message in the schema", messageIndexes)
+	}
+
+	message := dynamicpb.NewMessage(messageDescriptor)
+	if err := proto.Unmarshal(payload, message); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode protobuf payload")
+	}
+
+	return protojson.Marshal(message)
+}
+
+func (pd *protobufDecoder) readMessageIndexes(payload []byte) ([]int64, []byte, error) {
+	numMessageIndexes, bytesRead := binary.Varint(payload)
+	if bytesRead <= 0 || numMessageIndexes < 0 {
+		return nil, nil, errors.New("Invalid message indexes length")
+	}
+
+	payload = payload[bytesRead:]
+
+	if numMessageIndexes == 0 {
+		return []int64{0}, payload, nil
+	}
+
+	// every index takes at least a byte, so a corrupt length can't make us allocate more than the payload
+	if numMessageIndexes > int64(len(payload)) {
+		return nil, nil, errors.Errorf("Invalid message indexes length: %d", numMessageIndexes)
+	}
+
+	messageIndexes := make([]int64, numMessageIndexes)
+	for messageIndexIdx := range messageIndexes {
+		messageIndexes[messageIndexIdx], bytesRead = binary.Varint(payload)
+		if bytesRead <= 0 {
+			return nil, nil, errors.New("Invalid message index")
+		}
+
+		payload = payload[bytesRead:]
+	}
+
+	return messageIndexes, payload, nil
+}
+
+// resolveProtobufReferences adds the sources of all (transitively) referenced schemas, by import name
+func resolveProtobufReferences(client *Client, schema *Schema, sources map[string]string) error {
+	for referenceIdx := range schema.References {
+		reference := &schema.References[referenceIdx]
+
+		if _, found := sources[reference.Name]; found {
+			continue
+		}
+
+		referencedSchema, err := client.GetSchemaByReference(reference)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to get referenced schema %s", reference.Name)
+		}
+
+		sources[reference.Name] = referencedSchema.Schema
+
+		if err := resolveProtobufReferences(client, referencedSchema, sources); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// jsonSchemaDecoder passes through the payload, which is already JSON
+type jsonSchemaDecoder struct{}
+
+func (jd *jsonSchemaDecoder) decode(payload []byte) ([]byte, error) {
+	if !json.Valid(payload) {
+		return nil, errors.New("Payload is not valid JSON")
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,271 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/nuclio/errors"
+	"github.com/nuclio/logger"
+	nucliozap "github.com/nuclio/zap"
+	"github.com/stretchr/testify/suite"
+)
+
+const avroSchema = `{
+	"type": "record",
+	"name": "Reading",
+	"fields": [
+		{"name": "sensor", "type": "string"},
+		{"name": "value", "type": "long"}
+	]
+}`
+
+const protobufSchema = `
+syntax = "proto3";
+package readings;
+
+import "unit.proto";
+
+message Reading {
+	message Location {
+		string site = 1;
+	}
+
+	string sensor = 1;
+	int32 value = 2;
+	Unit unit = 3;
+}
+`
+
+const protobufReferencedSchema = `
+syntax = "proto3";
+package readings;
+
+enum Unit {
+	UNKNOWN = 0;
+	CELSIUS = 1;
+}
+`
+
+type DecoderTestSuite struct {
+	suite.Suite
+	logger             logger.Logger
+	registry           *httptest.Server
+	schemaRequestsLock sync.Mutex
+	schemaRequests     map[string]int
+	schemaGates        map[string]chan struct{}
+	decoder            *Decoder
+}
+
+func (suite *DecoderTestSuite) SetupSuite() {
+	suite.logger, _ = nucliozap.NewNuclioZapTest("test")
+}
+
+func (suite *DecoderTestSuite) SetupTest() {
+	var err error
+
+	schemas := map[string]*Schema{
+		"/schemas/ids/1": {Schema: avroSchema},
+		"/schemas/ids/2": {
+			Schema:     protobufSchema,
+			SchemaType: SchemaTypeProtobuf,
+			References: []SchemaReference{{Name: "unit.proto", Subject: "unit", Version: 3}},
+		},
+		"/subjects/unit/versions/3": {Schema: protobufReferencedSchema, SchemaType: SchemaTypeProtobuf},
+		"/schemas/ids/3":            {Schema: `{"type": "object"}`, SchemaType: SchemaTypeJSONSchema},
+	}
+
+	// an in-process stand-in for the schema registry
+	suite.schemaRequests = map[string]int{}
+	suite.schemaGates = map[string]chan struct{}{}
+	suite.registry = httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		suite.schemaRequestsLock.Lock()
+		suite.schemaRequests[request.URL.Path]++
+		gate := suite.schemaGates[request.URL.Path]
+		suite.schemaRequestsLock.Unlock()
+
+		// hold the response until the test releases it
+		if gate != nil {
+			<-gate
+		}
+
+		schema, found := schemas[request.URL.Path]
+		if !found {
+			responseWriter.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		encodedSchema, _ := json.Marshal(schema)
+		responseWriter.Write(encodedSchema) // nolint: errcheck
+	}))
+
+	suite.decoder, err = NewDecoder(suite.logger, &Configuration{URL: suite.registry.URL})
+	suite.Require().NoError(err)
+}
+
+func (suite *DecoderTestSuite) TearDownTest() {
+	suite.registry.Close()
+}
+
+func (suite *DecoderTestSuite) TestDecodeAvro() {
+	codec, err := goavro.NewCodec(avroSchema)
+	suite.Require().NoError(err)
+
+	payload, err := codec.BinaryFromNative(nil, map[string]interface{}{
+		"sensor": "temperature",
+		"value":  int64(21),
+	})
+	suite.Require().NoError(err)
+
+	// decode twice, schema must only be fetched once
+	for i := 0; i < 2; i++ {
+		decoded, err := suite.decoder.Decode(suite.encodeWireFormat(1, payload))
+		suite.Require().NoError(err)
+		suite.Require().JSONEq(`{"sensor": "temperature", "value": 21}`, string(decoded))
+	}
+
+	suite.Require().Equal(1, suite.schemaRequests["/schemas/ids/1"])
+}
+
+func (suite *DecoderTestSuite) TestDecodeProtobuf() {
+
+	// sensor = "temperature", value = 21, unit = CELSIUS
+	payload := append([]byte{0x0a, 0x0b}, []byte("temperature")...)
+	payload = append(payload, 0x10, 0x15, 0x18, 0x01)
+
+	// a zero length message index array stands for the first message
+	decoded, err := suite.decoder.Decode(suite.encodeWireFormat(2, append([]byte{0x00}, payload...)))
+	suite.Require().NoError(err)
+	suite.Require().JSONEq(`{"sensor": "temperature", "value": 21, "unit": "CELSIUS"}`, string(decoded))
+
+	// a nested message, by its explicit index path [0, 0]
+	nestedPayload := append([]byte{0x0a, 0x03}, []byte("lab")...)
+	decoded, err = suite.decoder.Decode(suite.encodeWireFormat(2, append([]byte{0x04, 0x00, 0x00}, nestedPayload...)))
+	suite.Require().NoError(err)
+	suite.Require().JSONEq(`{"site": "lab"}`, string(decoded))
+
+	// out of range index path
+	_, err = suite.decoder.Decode(suite.encodeWireFormat(2, append([]byte{0x02, 0x04}, payload...)))
+	suite.Require().Error(err)
+
+	// an index path longer than the message (2^62 indexes)
+	_, err = suite.decoder.Decode(suite.encodeWireFormat(2, []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}))
+	suite.Require().Error(err)
+	suite.Require().Contains(errors.RootCause(err).Error(), "Invalid message indexes length")
+}
+
+func (suite *DecoderTestSuite) TestDecodeJSONSchema() {
+	decoded, err := suite.decoder.Decode(suite.encodeWireFormat(3, []byte(`{"sensor": "temperature"}`)))
+	suite.Require().NoError(err)
+	suite.Require().JSONEq(`{"sensor": "temperature"}`, string(decoded))
+
+	_, err = suite.decoder.Decode(suite.encodeWireFormat(3, []byte(`not json`)))
+	suite.Require().Error(err)
+}
+
+func (suite *DecoderTestSuite) TestDecodeWhileFetchingSchema() {
+	codec, err := goavro.NewCodec(avroSchema)
+	suite.Require().NoError(err)
+
+	payload, err := codec.BinaryFromNative(nil, map[string]interface{}{
+		"sensor": "temperature",
+		"value":  int64(21),
+	})
+	suite.Require().NoError(err)
+
+	_, err = suite.decoder.Decode(suite.encodeWireFormat(1, payload))
+	suite.Require().NoError(err)
+
+	// hold the registry response of another schema
+	gate := make(chan struct{})
+	suite.schemaRequestsLock.Lock()
+	suite.schemaGates["/schemas/ids/3"] = gate
+	suite.schemaRequestsLock.Unlock()
+
+	decodeErrChan := make(chan error, 1)
+	go func() {
+		_, err := suite.decoder.Decode(suite.encodeWireFormat(3, []byte(`{"sensor": "humidity"}`)))
+		decodeErrChan <- err
+	}()
+
+	suite.Require().Eventually(func() bool {
+		suite.schemaRequestsLock.Lock()
+		defer suite.schemaRequestsLock.Unlock()
+
+		return suite.schemaRequests["/schemas/ids/3"] == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// messages of the resolved schema are decoded while the other schema is being fetched
+	decoded, err := suite.decoder.Decode(suite.encodeWireFormat(1, payload))
+	suite.Require().NoError(err)
+	suite.Require().JSONEq(`{"sensor": "temperature", "value": 21}`, string(decoded))
+
+	close(gate)
+	suite.Require().NoError(<-decodeErrChan)
+}
+
+func (suite *DecoderTestSuite) TestDecodeInvalidMessages() {
+	for _, message := range [][]byte{
+		[]byte("raw"),
+		{0x01, 0x00, 0x00, 0x00, 0x01, 0x00},
+		suite.encodeWireFormat(404, []byte("unknown schema")),
+	} {
+		_, err := suite.decoder.Decode(message)
+		suite.Require().Error(err)
+	}
+}
+
+func (suite *DecoderTestSuite) TestResolveConfiguration() {
+	configuration := &Configuration{URL: "http://registry"}
+	suite.Require().NoError(configuration.Resolve())
+	suite.Require().Equal(ErrorModeFail, configuration.OnError)
+	suite.Require().Equal(DefaultRequestTimeout, configuration.requestTimeout)
+
+	configuration = &Configuration{URL: "http://registry", OnError: "drop"}
+	suite.Require().Error(configuration.Resolve())
+
+	// dead lettering requires a destination
+	configuration = &Configuration{URL: "http://registry", OnError: ErrorModeDeadLetter}
+	suite.Require().Error(configuration.Resolve())
+
+	configuration.DeadLetter = &functionconfig.OutputBinding{Kind: "kafka", URL: "kafka:9092", Target: "undecodable"}
+	suite.Require().NoError(configuration.Resolve())
+
+	var nilConfiguration *Configuration
+	suite.Require().False(nilConfiguration.Enabled())
+}
+
+func (suite *DecoderTestSuite) encodeWireFormat(schemaID uint32, payload []byte) []byte {
+	message := make([]byte, wireFormatHeaderSize, wireFormatHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(message[1:], schemaID)
+	return append(message, payload...)
+}
+
+func TestDecoderTestSuite(t *testing.T) {
+	suite.Run(t, new(DecoderTestSuite))
+}
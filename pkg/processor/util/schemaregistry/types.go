@@ -0,0 +1,125 @@
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaregistry
+
+import (
+	"time"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+
+	"github.com/nuclio/errors"
+)
+
+type SchemaType string
+
+const (
+	SchemaTypeAvro       SchemaType = "AVRO"
+	SchemaTypeProtobuf   SchemaType = "PROTOBUF"
+	SchemaTypeJSONSchema SchemaType = "JSON"
+)
+
+// ErrorMode determines what happens to a message that cannot be decoded
+type ErrorMode string
+
+const (
+
+	// the message is treated as a failed event, and is nacked / not committed by the trigger. the kafka
+	// trigger stops consuming the partition at the message, rather than committing the messages after it
+	ErrorModeFail ErrorMode = "fail"
+
+	// the message is acked without being handled
+	ErrorModeSkip ErrorMode = "skip"
+
+	// the raw message is handled as is, with the decoding error set in the ErrorHeaderKey header
+	ErrorModePassthrough ErrorMode = "passthrough"
+
+	// the raw message is published to the dead letter destination, with the decoding error set in the
+	// ErrorHeaderKey header, and is acked without being handled
+	ErrorModeDeadLetter ErrorMode = "deadLetter"
+)
+
+// ErrorHeaderKey holds the decoding error of a message that was passed through undecoded
+const ErrorHeaderKey = "x-nuclio-schema-registry-error"
+
+const DefaultRequestTimeout = 10 * time.Second
+
+// Configuration is embedded in stream trigger attributes under "schemaRegistry"
+type Configuration struct {
+	URL            string
+	Username       string
+	Password       string
+	OnError        ErrorMode
+	RequestTimeout string
+
+	// where undecodable messages are published to in the dead letter error mode. it is configured like
+	// an output binding of the function
+	DeadLetter *functionconfig.OutputBinding
+
+	// resolved fields
+	requestTimeout time.Duration
+}
+
+// Enabled returns whether messages should be decoded
+func (c *Configuration) Enabled() bool {
+	return c != nil && c.URL != ""
+}
+
+// Resolve validates the configuration and populates defaults
+func (c *Configuration) Resolve() error {
+	var err error
+
+	switch c.OnError {
+	case "":
+		c.OnError = ErrorModeFail
+	case ErrorModeFail, ErrorModeSkip, ErrorModePassthrough:
+	case ErrorModeDeadLetter:
+		if c.DeadLetter == nil || c.DeadLetter.Kind == "" {
+			return errors.Errorf("Schema registry error mode '%s' requires a dead letter destination", ErrorModeDeadLetter)
+		}
+	default:
+		return errors.Errorf("Schema registry error mode must be either '%s', '%s', '%s' or '%s', not '%s'",
+			ErrorModeFail,
+			ErrorModeSkip,
+			ErrorModePassthrough,
+			ErrorModeDeadLetter,
+			c.OnError)
+	}
+
+	c.requestTimeout = DefaultRequestTimeout
+	if c.RequestTimeout != "" {
+		c.requestTimeout, err = time.ParseDuration(c.RequestTimeout)
+		if err != nil {
+			return errors.Wrap(err, "Failed to parse schema registry request timeout")
+		}
+	}
+
+	return nil
+}
+
+// Schema is a schema as registered in the registry
+type Schema struct {
+	Schema     string            `json:"schema"`
+	SchemaType SchemaType        `json:"schemaType,omitempty"`
+	References []SchemaReference `json:"references,omitempty"`
+}
+
+// SchemaReference is a reference from one schema to another (e.g. a protobuf import)
+type SchemaReference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
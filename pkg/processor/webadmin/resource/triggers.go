@@ -17,12 +17,15 @@ limitations under the License.
 package resource
 
 import (
+	"fmt"
 	"net/http"
 
+	"github.com/nuclio/nuclio/pkg/processor/trigger"
 	"github.com/nuclio/nuclio/pkg/processor/webadmin"
 	"github.com/nuclio/nuclio/pkg/restful"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/nuclio/nuclio-sdk-go"
 )
 
 type triggersResource struct {
@@ -64,8 +67,6 @@ func (tr *triggersResource) GetByID(request *http.Request, id string) (restful.A
 
 // GetCustomRoutes returns a list of custom routes for the resource
 func (tr *triggersResource) GetCustomRoutes() ([]restful.CustomRoute, error) {
-
-	// just for demonstration. when stats are supported, this will be wired
 	return []restful.CustomRoute{
 		{
 			Pattern:   "/{id}/stats",
@@ -75,17 +76,38 @@ func (tr *triggersResource) GetCustomRoutes() ([]restful.CustomRoute, error) {
 	}, nil
 }
 
+// getStatistics returns the trigger's event and worker allocation counters, accumulated since the processor started
 func (tr *triggersResource) getStatistics(request *http.Request) (*restful.CustomRouteFuncResponse, error) {
 	resourceID := chi.URLParam(request, "id")
 
-	return &restful.CustomRouteFuncResponse{
-		ResourceType: "statistics",
-		Resources: map[string]restful.Attributes{
-			resourceID: {"stats": "example"},
-		},
-		Single:     true,
-		StatusCode: http.StatusOK,
-	}, nil
+	for _, triggerInstance := range tr.getProcessor().GetTriggers() {
+		if triggerInstance.GetID() != resourceID {
+			continue
+		}
+
+		// diffing from zero loads the counters atomically
+		statistics := triggerInstance.GetStatistics().DiffFrom(&trigger.Statistics{})
+		allocatorStatistics := statistics.WorkerAllocatorStatistics
+
+		return &restful.CustomRouteFuncResponse{
+			ResourceType: "statistics",
+			Resources: map[string]restful.Attributes{
+				resourceID: {
+					"eventsHandledSuccessTotal":                   statistics.EventsHandledSuccessTotal,
+					"eventsHandledFailureTotal":                   statistics.EventsHandledFailureTotal,
+					"workerAllocationCount":                       allocatorStatistics.WorkerAllocationCount,
+					"workerAllocationSuccessImmediateTotal":       allocatorStatistics.WorkerAllocationSuccessImmediateTotal,
+					"workerAllocationSuccessAfterWaitTotal":       allocatorStatistics.WorkerAllocationSuccessAfterWaitTotal,
+					"workerAllocationTimeoutTotal":                allocatorStatistics.WorkerAllocationTimeoutTotal,
+					"workerAllocationWaitDurationMilliSecondsSum": allocatorStatistics.WorkerAllocationWaitDurationMilliSecondsSum,
+				},
+			},
+			Single:     true,
+			StatusCode: http.StatusOK,
+		}, nil
+	}
+
+	return nil, nuclio.NewErrNotFound(fmt.Sprintf("Trigger %s not found", resourceID))
 }
 
 func (tr *triggersResource) extractIDFromConfiguration(configuration map[string]interface{}) string {
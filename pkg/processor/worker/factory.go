@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/nuclio/nuclio/pkg/errgroup"
+	"github.com/nuclio/nuclio/pkg/processor/outputbinding"
 	"github.com/nuclio/nuclio/pkg/processor/runtime"
 
 	"github.com/nuclio/errors"
@@ -100,7 +101,31 @@ func (waf *Factory) createWorker(parentLogger logger.Logger,
 		return nil, errors.Wrap(err, "Failed to start runtime")
 	}
 
-	return NewWorker(workerLogger, workerIndex, runtimeInstance)
+	workerInstance, err := NewWorker(workerLogger, workerIndex, runtimeInstance)
+	if err != nil {
+		waf.stopRuntime(workerLogger, runtimeInstance)
+		return nil, errors.Wrap(err, "Failed to create worker")
+	}
+
+	if len(runtimeConfigurationCopy.Spec.OutputBindings) > 0 {
+		outputEmitter, err := outputbinding.NewEmitter(workerLogger, runtimeConfigurationCopy.Spec.OutputBindings)
+		if err != nil {
+			waf.stopRuntime(workerLogger, runtimeInstance)
+			return nil, errors.Wrap(err, "Failed to create output bindings")
+		}
+
+		workerInstance.SetOutputEmitter(outputEmitter)
+	}
+
+	return workerInstance, nil
+}
+
+// stopRuntime stops a runtime that was started for a worker that couldn't be created, so that it
+// doesn't outlive it
+func (waf *Factory) stopRuntime(workerLogger logger.Logger, runtimeInstance runtime.Runtime) {
+	if err := runtimeInstance.Stop(); err != nil {
+		workerLogger.WarnWith("Failed to stop runtime", "err", err.Error())
+	}
 }
 
 func (waf *Factory) createWorkers(logger logger.Logger,
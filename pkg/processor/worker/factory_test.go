@@ -0,0 +1,72 @@
+//go:build test_unit
+
+/*
+Copyright 2023 The Nuclio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worker
+
+import (
+	"testing"
+
+	"github.com/nuclio/nuclio/pkg/functionconfig"
+	"github.com/nuclio/nuclio/pkg/processor"
+	"github.com/nuclio/nuclio/pkg/processor/runtime"
+
+	"github.com/nuclio/logger"
+	"github.com/nuclio/zap"
+	"github.com/stretchr/testify/suite"
+)
+
+type mockRuntimeCreator struct {
+	runtimeInstance runtime.Runtime
+}
+
+func (mrc *mockRuntimeCreator) Create(logger.Logger, *runtime.Configuration) (runtime.Runtime, error) {
+	return mrc.runtimeInstance, nil
+}
+
+type FactoryTestSuite struct {
+	suite.Suite
+	logger logger.Logger
+}
+
+func (suite *FactoryTestSuite) SetupSuite() {
+	suite.logger, _ = nucliozap.NewNuclioZapTest("test")
+}
+
+func (suite *FactoryTestSuite) TestCreateWorkerStopsRuntimeOnFailure() {
+	mockRuntime := MockRuntime{}
+	runtime.RegistrySingleton.Register("factory-test", &mockRuntimeCreator{runtimeInstance: &mockRuntime})
+
+	runtimeConfiguration := &runtime.Configuration{
+		Configuration: &processor.Configuration{},
+	}
+	runtimeConfiguration.Spec.Runtime = "factory-test"
+	runtimeConfiguration.Spec.OutputBindings = map[string]functionconfig.OutputBinding{
+		"my-binding": {Kind: "unknown"},
+	}
+
+	// the runtime was started, so it's stopped when the output bindings can't be created
+	mockRuntime.On("Stop").Return(nil).Once()
+
+	_, err := (&Factory{}).createWorker(suite.logger, 0, runtimeConfiguration)
+	suite.Require().Error(err)
+	mockRuntime.AssertExpectations(suite.T())
+}
+
+func TestFactoryTestSuite(t *testing.T) {
+	suite.Run(t, new(FactoryTestSuite))
+}
@@ -24,6 +24,8 @@ import (
 	"github.com/nuclio/nuclio/pkg/common/status"
 	"github.com/nuclio/nuclio/pkg/processor/cloudevent"
 	"github.com/nuclio/nuclio/pkg/processor/controlcommunication"
+	"github.com/nuclio/nuclio/pkg/processor/invoker"
+	"github.com/nuclio/nuclio/pkg/processor/outputbinding"
 	"github.com/nuclio/nuclio/pkg/processor/runtime"
 	"github.com/nuclio/nuclio/pkg/processor/util/clock"
 
@@ -44,6 +46,7 @@ type Worker struct {
 	binaryCloudEvent     cloudevent.Binary
 	eventTime            *time.Time
 	isTerminated         bool
+	outputEmitter        *outputbinding.Emitter
 }
 
 // NewWorker creates a new worker
@@ -82,6 +85,15 @@ func (w *Worker) ProcessEvent(event nuclio.Event, functionLogger logger.Logger)
 			success = typedResponse.StatusCode < http.StatusBadRequest
 		}
 
+		// publish successful responses to the output bindings. failing to deliver fails the event, so
+		// that the trigger doesn't ack it
+		if success && w.outputEmitter != nil {
+			if err = w.outputEmitter.Emit(response); err != nil {
+				w.logger.WarnWith("Failed to publish response to output bindings", "err", err.Error())
+				success = false
+			}
+		}
+
 		if success {
 			atomic.AddUint64(&w.statistics.EventsHandledSuccess, 1)
 		} else {
@@ -112,8 +124,22 @@ func (w *Worker) GetStatus() status.Status {
 	return w.runtime.GetStatus()
 }
 
+// SetOutputEmitter sets the emitter that publishes responses to the function's output bindings
+func (w *Worker) SetOutputEmitter(outputEmitter *outputbinding.Emitter) {
+	w.outputEmitter = outputEmitter
+}
+
 // Stop stops the worker and associated runtime
 func (w *Worker) Stop() error {
+	if w.outputEmitter != nil {
+		if err := w.outputEmitter.Close(); err != nil {
+			w.logger.WarnWith("Failed to close output bindings", "err", err.Error())
+		}
+	}
+
+	// the worker won't handle events anymore, release the invoker handlers reached through the context
+	invoker.Unregister(w.runtime.GetContext())
+
 	return w.runtime.Stop()
 }
 
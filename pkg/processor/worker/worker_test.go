@@ -19,10 +19,14 @@ limitations under the License.
 package worker
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/nuclio/nuclio/pkg/common/status"
+	"github.com/nuclio/nuclio/pkg/functionconfig"
 	"github.com/nuclio/nuclio/pkg/processor/controlcommunication"
+	"github.com/nuclio/nuclio/pkg/processor/outputbinding"
 	"github.com/nuclio/nuclio/pkg/processor/runtime"
 
 	"github.com/nuclio/logger"
@@ -65,7 +69,8 @@ func (mr *MockRuntime) Start() error {
 }
 
 func (mr *MockRuntime) Stop() error {
-	return nil
+	args := mr.Called()
+	return args.Error(0)
 }
 
 func (mr *MockRuntime) Restart() error {
@@ -86,6 +91,10 @@ func (mr *MockRuntime) SupportsControlCommunication() bool {
 	return args.Bool(0)
 }
 
+func (mr *MockRuntime) GetContext() *nuclio.Context {
+	return nil
+}
+
 func (mr *MockRuntime) GetControlMessageBroker() controlcommunication.ControlMessageBroker {
 	return nil
 }
@@ -118,6 +127,36 @@ func (suite *WorkerTestSuite) TestProcessEvent() {
 	suite.Require().NotNil(event.GetID())
 }
 
+func (suite *WorkerTestSuite) TestProcessEventOutputBindingFailure() {
+	endpoint := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		responseWriter.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer endpoint.Close()
+
+	outputEmitter, err := outputbinding.NewEmitter(suite.logger, map[string]functionconfig.OutputBinding{
+		"failing": {
+			Kind: "http",
+			URL:  endpoint.URL,
+		},
+	})
+	suite.Require().NoError(err)
+
+	mockRuntime := MockRuntime{}
+	worker, _ := NewWorker(suite.logger, 100, &mockRuntime)
+	worker.SetOutputEmitter(outputEmitter)
+	event := &nuclio.AbstractEvent{}
+
+	// stream triggers submit events without a function logger
+	mockRuntime.On("ProcessEvent", event, nil).Return(nuclio.Response{StatusCode: http.StatusOK}, nil).Once()
+
+	_, err = worker.ProcessEvent(event, nil)
+	suite.Require().Error(err)
+	suite.Require().Equal(uint64(1), worker.GetStatistics().EventsHandledError)
+	suite.Require().Zero(worker.GetStatistics().EventsHandledSuccess)
+
+	mockRuntime.AssertExpectations(suite.T())
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestWorkerTestSuite(t *testing.T) {